@@ -15,6 +15,10 @@ func New() error {
 		fmt.Println("\nCommands:")
 		fmt.Println("    serve:  start the headless CMS server")
 		fmt.Println("  version:  show hugoverse command version")
+		fmt.Println("      new:  create new content from an archetype, e.g. new posts posts/hello.md")
+		fmt.Println("       gc:  remove unused cache entries, e.g. gc --images")
+		fmt.Println("   backup:  write an archive of the CMS data, e.g. backup --out data.tar.gz")
+		fmt.Println("  restore:  replace the CMS data with an archive, e.g. restore data.tar.gz")
 
 		fmt.Println("\nExample:")
 		fmt.Println("  hugov version")
@@ -83,6 +87,38 @@ func New() error {
 			if err := loadCmd.Run(); err != nil {
 				return err
 			}
+		case "new":
+			newCmd, err := cli.NewNewCmd(topLevel)
+			if err != nil {
+				return err
+			}
+			if err := newCmd.Run(); err != nil {
+				return err
+			}
+		case "gc":
+			gcCmd, err := cli.NewGCCmd(topLevel)
+			if err != nil {
+				return err
+			}
+			if err := gcCmd.Run(); err != nil {
+				return err
+			}
+		case "backup":
+			backupCmd, err := cli.NewBackupCmd(topLevel)
+			if err != nil {
+				return err
+			}
+			if err := backupCmd.Run(); err != nil {
+				return err
+			}
+		case "restore":
+			restoreCmd, err := cli.NewRestoreCmd(topLevel)
+			if err != nil {
+				return err
+			}
+			if err := restoreCmd.Run(); err != nil {
+				return err
+			}
 
 		default:
 			topLevel.Usage()
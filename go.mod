@@ -8,7 +8,6 @@ require (
 	github.com/armon/go-radix v1.0.1-0.20221118154546-54df44f2176c
 	github.com/bep/clocks v0.5.0
 	github.com/bep/debounce v1.2.1
-	github.com/bep/gitmap v1.6.0
 	github.com/bep/goat v0.5.0
 	github.com/bep/godartsass v1.2.0
 	github.com/bep/godartsass/v2 v2.3.2
@@ -33,6 +32,7 @@ require (
 	github.com/gohugoio/locales v0.14.0
 	github.com/gohugoio/localescompressed v1.0.1
 	github.com/gorilla/schema v1.2.1
+	github.com/graphql-go/graphql v0.8.1
 	github.com/jdkato/prose v1.2.1
 	github.com/kyokomi/emoji/v2 v2.2.13
 	github.com/makeworld-the-better-one/dither/v2 v2.4.0
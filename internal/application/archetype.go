@@ -0,0 +1,255 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mdfriday/hugoverse/pkg/paths"
+	"github.com/spf13/afero"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const defaultArchetype = `---
+title: "{{ replace .Name "-" " " | title }}"
+date: {{ .Date }}
+draft: true
+---
+`
+
+// ArchetypeFile is a content file created from an archetype.
+type ArchetypeFile struct {
+	// Path relative to the content directory.
+	Path    string `json:"path"`
+	Content []byte `json:"content"`
+}
+
+// archetypeData is the data archetype templates are executed with.
+type archetypeData struct {
+	// Name is the content base name, e.g. my-post for posts/my-post.md,
+	// or the bundle name for posts/my-post/index.md.
+	Name string
+	// Date is the creation time in RFC 3339 format.
+	Date string
+	// Type is the archetype kind.
+	Type string
+	Site any
+	File archetypeFileData
+}
+
+type archetypeFileData struct {
+	Path            string
+	Section         string
+	ContentBaseName string
+}
+
+// NewContent creates the content at targetPath, relative to the content
+// directory of the site in the working directory, from the archetype of
+// kind. It returns the filenames written.
+func NewContent(kind, targetPath string) ([]string, error) {
+	ss, err := loadStaticSite(getWd())
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ss.newContentFiles(kind, targetPath)
+	if err != nil {
+		return nil, err
+	}
+
+	contentDir := paths.AbsPathify(ss.config.WorkingDir(), ss.config.ContentDir())
+	osFs := afero.NewOsFs()
+	for _, f := range files {
+		if exists, _ := afero.Exists(osFs, filepath.Join(contentDir, f.Path)); exists {
+			return nil, fmt.Errorf("%q already exists", filepath.Join(contentDir, f.Path))
+		}
+	}
+
+	var filenames []string
+	for _, f := range files {
+		filename := filepath.Join(contentDir, filepath.FromSlash(f.Path))
+		if err := osFs.MkdirAll(filepath.Dir(filename), 0o777); err != nil {
+			return nil, err
+		}
+		if err := afero.WriteFile(osFs, filename, f.Content, 0o666); err != nil {
+			return nil, err
+		}
+		filenames = append(filenames, filename)
+	}
+
+	return filenames, nil
+}
+
+// NewContentWithTarget renders the files for new content at targetPath from
+// the archetype of kind of the site in target, without writing them.
+func NewContentWithTarget(target, kind, targetPath string) ([]ArchetypeFile, error) {
+	if err := checkTarget(target); err != nil {
+		return nil, err
+	}
+
+	ss, err := loadStaticSite(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return ss.newContentFiles(kind, targetPath)
+}
+
+// newContentFiles resolves the archetype across the project and its
+// themes: a directory bundle archetypes/kind/, a file archetypes/kind.md,
+// then archetypes/default.md and last the embedded default.
+// A kind left empty is the first section of targetPath.
+func (ss *staticSite) newContentFiles(kind, targetPath string) ([]ArchetypeFile, error) {
+	targetPath = strings.TrimPrefix(filepath.ToSlash(targetPath), "/")
+	if targetPath == "" {
+		return nil, errors.New("missing content path")
+	}
+	if err := checkLocal(targetPath); err != nil {
+		return nil, err
+	}
+	targetPath = path.Clean(targetPath)
+	if targetPath == "." {
+		return nil, errors.New("missing content path")
+	}
+	if kind != "" {
+		if err := checkLocal(kind); err != nil {
+			return nil, err
+		}
+	}
+
+	section, _, _ := strings.Cut(targetPath, "/")
+	if section == targetPath {
+		section = ""
+	}
+	if kind == "" {
+		kind = section
+	}
+
+	ss.site.UseDefaultLanguage()
+	data := archetypeData{
+		Date: time.Now().Format(time.RFC3339),
+		Type: kind,
+		Site: ss.site,
+		File: archetypeFileData{Path: targetPath, Section: section},
+	}
+
+	afs := ss.fs.Archetypes
+	ext := path.Ext(targetPath)
+
+	if kind != "" {
+		if fi, err := afs.Stat(kind); err == nil && fi.IsDir() {
+			// A bundle, targetPath is the bundle directory.
+			data.Name = path.Base(targetPath)
+			data.File.ContentBaseName = data.Name
+			return ss.newBundleFiles(kind, targetPath, data)
+		}
+	}
+
+	if ext == "" {
+		ext = ".md"
+		targetPath += ext
+		data.File.Path = targetPath
+	}
+	data.Name = strings.TrimSuffix(path.Base(targetPath), ext)
+	data.File.ContentBaseName = data.Name
+
+	var candidates []string
+	if kind != "" {
+		candidates = append(candidates, kind+ext)
+	}
+	candidates = append(candidates, "default"+ext)
+
+	archetype := defaultArchetype
+	for _, name := range candidates {
+		b, err := afero.ReadFile(afs, name)
+		if err == nil {
+			archetype = string(b)
+			break
+		}
+		if !os.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	name := kind
+	if name == "" {
+		name = "default"
+	}
+	content, err := ss.executeArchetype(name+ext, archetype, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []ArchetypeFile{{Path: targetPath, Content: content}}, nil
+}
+
+func (ss *staticSite) newBundleFiles(kind, targetPath string, data archetypeData) ([]ArchetypeFile, error) {
+	var files []ArchetypeFile
+
+	err := afero.Walk(ss.fs.Archetypes, kind, func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+
+		b, err := afero.ReadFile(ss.fs.Archetypes, name)
+		if err != nil {
+			return err
+		}
+
+		rel := strings.TrimPrefix(filepath.ToSlash(name), kind+"/")
+		if isContentFile(rel) {
+			if b, err = ss.executeArchetype(path.Join(kind, rel), string(b), data); err != nil {
+				return err
+			}
+		}
+		files = append(files, ArchetypeFile{Path: path.Join(targetPath, rel), Content: b})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("archetype bundle %q is empty", kind)
+	}
+
+	return files, nil
+}
+
+func (ss *staticSite) executeArchetype(name, archetype string, data archetypeData) ([]byte, error) {
+	templ, err := ss.exec.Parse("_text/archetypes/"+name, archetype)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse archetype %q: %w", name, err)
+	}
+
+	var b bytes.Buffer
+	if err := ss.exec.ExecuteWithContext(context.Background(), templ, &b, data); err != nil {
+		return nil, fmt.Errorf("failed to execute archetype %q: %w", name, err)
+	}
+
+	return b.Bytes(), nil
+}
+
+// checkLocal rejects a path, slash separated, that is absolute or reaches
+// out of the directory it is relative to, e.g. ../config.toml.
+func checkLocal(name string) error {
+	if !filepath.IsLocal(filepath.FromSlash(name)) {
+		return fmt.Errorf("%q is not a local path", name)
+	}
+	return nil
+}
+
+func isContentFile(name string) bool {
+	switch paths.ExtNoDelimiter(name) {
+	case "md", "markdown", "html", "htm":
+		return true
+	}
+	return false
+}
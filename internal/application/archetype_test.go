@@ -0,0 +1,230 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+	"github.com/spf13/afero"
+)
+
+func TestNewContentFiles(t *testing.T) {
+	c := qt.New(t)
+
+	fs := afero.NewMemMapFs()
+	for name, content := range map[string]string{
+		"config.toml":                  "baseURL = \"https://example.org/\"\ntitle = \"Notes\"\n",
+		"go.mod":                       "module example.org/site\n\ngo 1.18\n",
+		"layouts/_default/single.html": `{{ .Content }}`,
+		"archetypes/posts.md":          "---\ntitle: {{ .Name }}\nsection: {{ .File.Section }}\nsite: {{ .Site.Title }}\n---\n",
+		"archetypes/gallery/index.md":  "---\ntitle: {{ .Name }}\n---\n",
+		"archetypes/gallery/cover.txt": "{{ .Name }}",
+	} {
+		c.Assert(afero.WriteFile(fs, filepath.Join("/site", name), []byte(content), 0o644), qt.IsNil)
+	}
+	ss, err := loadSite(BuildOptions{Fs: fs, WorkingDir: "/site"})
+	c.Assert(err, qt.IsNil)
+
+	// The kind defaults to the section.
+	files, err := ss.newContentFiles("", "/posts/hello-world")
+	c.Assert(err, qt.IsNil)
+	c.Assert(files, qt.HasLen, 1)
+	c.Assert(files[0].Path, qt.Equals, "posts/hello-world.md")
+	c.Assert(string(files[0].Content), qt.Equals, "---\ntitle: hello-world\nsection: posts\nsite: Notes\n---\n")
+
+	// Only the content files of a bundle are templates.
+	files, err = ss.newContentFiles("gallery", "galleries/summer")
+	c.Assert(err, qt.IsNil)
+	c.Assert(files, qt.HasLen, 2)
+	byPath := map[string]string{}
+	for _, f := range files {
+		byPath[f.Path] = string(f.Content)
+	}
+	c.Assert(byPath, qt.DeepEquals, map[string]string{
+		"galleries/summer/index.md":  "---\ntitle: summer\n---\n",
+		"galleries/summer/cover.txt": "{{ .Name }}",
+	})
+
+	// The embedded default.
+	files, err = ss.newContentFiles("", "about.md")
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(files[0].Content), qt.Contains, `title: "About"`)
+
+	for _, test := range []struct {
+		kind, path string
+	}{
+		{"", "../config.toml"},
+		{"", "posts/../../config.toml"},
+		{"../layouts", "posts/a.md"},
+	} {
+		_, err := ss.newContentFiles(test.kind, test.path)
+		c.Assert(err, qt.ErrorMatches, `.* is not a local path`, qt.Commentf("%v", test))
+	}
+	_, err = ss.newContentFiles("", "/")
+	c.Assert(err, qt.ErrorMatches, "missing content path")
+}
+
+func TestNewContent(t *testing.T) {
+	c := qt.New(t)
+
+	dir := t.TempDir()
+	for name, content := range map[string]string{
+		"config.toml": "baseURL = \"https://example.org/\"\ncontentDir = \"docs\"\n",
+		"go.mod":      "module example.org/site\n\ngo 1.18\n",
+	} {
+		c.Assert(os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644), qt.IsNil)
+	}
+	wd, err := os.Getwd()
+	c.Assert(err, qt.IsNil)
+	c.Assert(os.Chdir(dir), qt.IsNil)
+	defer os.Chdir(wd)
+
+	filenames, err := NewContent("", "posts/hello.md")
+	c.Assert(err, qt.IsNil)
+	c.Assert(filenames, qt.DeepEquals, []string{filepath.Join(dir, "docs", "posts", "hello.md")})
+	b, err := os.ReadFile(filenames[0])
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Contains, `title: "Hello"`)
+
+	_, err = NewContent("", "posts/hello.md")
+	c.Assert(err, qt.ErrorMatches, `.*hello\.md" already exists`)
+}
+
+func TestNewSitePost(t *testing.T) {
+	c := qt.New(t)
+
+	// The contents are sorted and the search index of the site posts
+	// updated in the background, possibly after the test, so the stores
+	// are left open and the data dir removed at last. The open stores are
+	// cached by user, which is unique to the test run.
+	dir, err := os.MkdirTemp("", "hugoverse-sitepost")
+	c.Assert(err, qt.IsNil)
+	defer os.RemoveAll(dir)
+	user := filepath.Base(dir)
+
+	newContent := func(user string, buckets func([]string) []string) *database.Database {
+		d, err := database.New(dir)
+		c.Assert(err, qt.IsNil)
+		ct := NewContentServer(d)
+		d.RegisterContentBuckets(buckets(ct.AllContentTypeNames()))
+		c.Assert(d.StartUserDir(user), qt.IsNil)
+
+		site := &valueobject.Site{Item: valueobject.Item{Namespace: "Site", Status: content.Public}, Title: "Notes"}
+		id, err := d.NextContentId("Site")
+		c.Assert(err, qt.IsNil)
+		site.SetItemID(int(id))
+		site.SetSlug(fmt.Sprintf("site-%d", id))
+		b, err := json.Marshal(site)
+		c.Assert(err, qt.IsNil)
+		c.Assert(d.NewContent(site, b), qt.IsNil)
+
+		return d
+	}
+	all := func(names []string) []string { return names }
+
+	d := newContent(user, all)
+	ct := NewContentServer(d)
+
+	id, err := ct.NewSitePost("1", "posts/hello.md", []byte("---\ntitle: Hello\ntags: [a]\n---\nHello.\n"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(id, qt.Equals, "1")
+
+	var post valueobject.Post
+	b, err := d.GetContent("Post", id)
+	c.Assert(err, qt.IsNil)
+	c.Assert(json.Unmarshal(b, &post), qt.IsNil)
+	c.Assert(post.Title, qt.Equals, "Hello")
+	c.Assert(post.Content, qt.Equals, "Hello.\n")
+
+	var sitePost valueobject.SitePost
+	b, err = d.GetContent("SitePost", "1")
+	c.Assert(err, qt.IsNil)
+	c.Assert(json.Unmarshal(b, &sitePost), qt.IsNil)
+	c.Assert(sitePost.Post, qt.Equals, post.QueryString())
+	c.Assert(sitePost.Site, qt.Equals, "/api/content?type=Site&id=1")
+	c.Assert(sitePost.Path, qt.Equals, "content/posts/hello.md")
+
+	// Without a SitePost bucket, the site post can't be created, nor then
+	// the post.
+	broken := newContent(user+"-broken", func(names []string) []string {
+		var buckets []string
+		for _, name := range names {
+			if !strings.HasPrefix(name, "SitePost") {
+				buckets = append(buckets, name)
+			}
+		}
+		return buckets
+	})
+	_, err = NewContentServer(broken).NewSitePost("1", "posts/hello.md", []byte("---\ntitle: Hello\n---\n"))
+	c.Assert(err, qt.IsNotNil)
+	c.Assert(broken.AllContent("Post"), qt.HasLen, 0)
+}
+
+func TestArchetypeTarget(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := database.New(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	ct := NewContentServer(d)
+	d.RegisterContentBuckets(ct.AllContentTypeNames())
+	c.Assert(d.StartUserDir("archetype"), qt.IsNil)
+	defer d.Close()
+
+	uploads := t.TempDir()
+	for name, content := range map[string]string{
+		"posts.md":  "---\ntitle: {{ .Name }}\n---\n",
+		"style.css": "body {}",
+	} {
+		c.Assert(os.WriteFile(filepath.Join(uploads, name), []byte(content), 0o644), qt.IsNil)
+	}
+	ct.Hugo.DirService = &uploadDir{uploads: uploads}
+
+	var ops []*valueobject.BatchOp
+	for _, values := range []url.Values{
+		{"title": {"Notes"}},
+		{"name": {"posts.md"}, "asset": {"/api/uploads/posts.md"}},
+		{"name": {"style.css"}, "asset": {"/api/uploads/style.css"}},
+		{"site": {"/api/content?type=Site&id=1"}, "resource": {"/api/content?type=Resource&id=1"}, "path": {"/archetypes/posts.md"}},
+		{"site": {"/api/content?type=Site&id=1"}, "resource": {"/api/content?type=Resource&id=2"}, "path": {"/assets/style.css"}},
+	} {
+		contentType := "Site"
+		switch {
+		case values.Has("asset"):
+			contentType = "Resource"
+		case values.Has("resource"):
+			contentType = "SiteResource"
+		}
+		ops = append(ops, &valueobject.BatchOp{Op: valueobject.BatchCreate, Type: contentType, Values: values})
+	}
+	for i, r := range ct.Batch(ops, true) {
+		c.Assert(r.OK(), qt.IsTrue, qt.Commentf("%d: %s", i, r.Error))
+	}
+
+	// Only the config and the archetypes are written.
+	target, clean, err := ct.ArchetypeTarget("1")
+	c.Assert(err, qt.IsNil)
+	for _, name := range []string{"config.toml", "go.mod", "archetypes/posts.md"} {
+		_, err := os.Stat(filepath.Join(target, name))
+		c.Assert(err, qt.IsNil, qt.Commentf(name))
+	}
+	_, err = os.Stat(filepath.Join(target, "assets", "style.css"))
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+	_, err = os.Stat(filepath.Join(target, "content"))
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+
+	b, err := os.ReadFile(filepath.Join(target, "archetypes", "posts.md"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "---\ntitle: {{ .Name }}\n---\n")
+
+	clean()
+	_, err = os.Stat(target)
+	c.Assert(os.IsNotExist(err), qt.IsTrue)
+}
@@ -0,0 +1,111 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/mdfriday/hugoverse/internal/domain/content/entity"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+	"github.com/mdfriday/hugoverse/pkg/db"
+)
+
+var errDataDirInUse = errors.New("the data dir is in use by a running server, stop it first")
+
+// Backup writes an archive of the CMS data to w. It opens the data dir
+// itself, so the server must be stopped; a running server is backed up
+// at /admin/backup instead.
+func Backup(w io.Writer) (*database.Manifest, error) {
+	if err := checkDataDirFree(); err != nil {
+		return nil, err
+	}
+
+	d, _, err := openDatabase()
+	if err != nil {
+		return nil, err
+	}
+	defer d.Close()
+
+	return d.Backup(w, UploadDir())
+}
+
+// Restore replaces the CMS data with the archive read from r, and rebuilds
+// the search indexes from it. It returns the manifest of the archive, the
+// number of items indexed and the dir the replaced data was moved to.
+func Restore(r io.Reader) (*database.Manifest, int, string, error) {
+	if err := checkDataDirFree(); err != nil {
+		return nil, 0, "", err
+	}
+
+	m, aside, err := database.Restore(DataDir(), UploadDir(), r)
+	if err != nil {
+		return nil, 0, aside, err
+	}
+
+	n, err := rebuildIndices(m.Users)
+	if err != nil {
+		return m, n, aside, fmt.Errorf("rebuild search indexes: %w", err)
+	}
+
+	return m, n, aside, nil
+}
+
+func rebuildIndices(users []string) (int, error) {
+	d, ct, err := openDatabase()
+	if err != nil {
+		return 0, err
+	}
+	defer d.Close()
+	defer ct.CloseIndices()
+
+	total := 0
+	rebuild := func(types []string) error {
+		for _, t := range types {
+			n, err := ct.RebuildIndex(t)
+			if err != nil {
+				return fmt.Errorf("%s: %w", t, err)
+			}
+			total += n
+		}
+		return nil
+	}
+
+	if err := rebuild(ct.AllAdminTypeNames()); err != nil {
+		return total, err
+	}
+	for _, ud := range users {
+		if err := d.StartUserDir(ud); err != nil {
+			return total, err
+		}
+		if err := rebuild(ct.AllContentTypeNames()); err != nil {
+			return total, fmt.Errorf("user %s: %w", ud, err)
+		}
+	}
+
+	return total, nil
+}
+
+func openDatabase() (*database.Database, *entity.Content, error) {
+	d, err := database.New(DataDir())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ct := NewContentServer(d)
+	d.RegisterContentBuckets(ct.AllContentTypeNames())
+	if err := d.StartAdminDatabase(ct.AllAdminTypeNames()); err != nil {
+		return nil, nil, err
+	}
+
+	return d, ct, nil
+}
+
+// checkDataDirFree fails if a server has the admin store open, which would
+// otherwise block opening it.
+func checkDataDirFree() error {
+	if err := db.Check(filepath.Join(DataDir(), "system.db")); errors.Is(err, db.ErrInUse) {
+		return errDataDirInUse
+	}
+	return nil
+}
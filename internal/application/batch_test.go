@@ -0,0 +1,137 @@
+package application
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	contentEntity "github.com/mdfriday/hugoverse/internal/domain/content/entity"
+	"github.com/mdfriday/hugoverse/internal/domain/content/repository"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+)
+
+// batchRepo counts the batches, and fails writing the items titled fail.
+type batchRepo struct {
+	repository.Repository
+	batches int
+}
+
+func (r *batchRepo) Batch(fn func(tx repository.Tx) error) error {
+	r.batches++
+	return r.Repository.Batch(func(tx repository.Tx) error {
+		return fn(&failingTx{Tx: tx})
+	})
+}
+
+type failingTx struct {
+	repository.Tx
+}
+
+func (tx *failingTx) NewContent(ci any, data []byte) error {
+	if bytes.Contains(data, []byte(`"title":"fail"`)) {
+		return errors.New("disk full")
+	}
+	return tx.Tx.NewContent(ci, data)
+}
+
+func TestBatch(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := database.New(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	ct := NewContentServer(d)
+	d.RegisterContentBuckets(ct.AllContentTypeNames())
+	c.Assert(d.StartUserDir("batch"), qt.IsNil)
+	defer d.Close()
+	repo := &batchRepo{Repository: d}
+	ct.Repo = repo
+
+	create := func(title, status string) *valueobject.BatchOp {
+		return &valueobject.BatchOp{Op: valueobject.BatchCreate, Type: "Post", Status: status, Values: url.Values{"title": {title}}}
+	}
+	batch := func(atomic bool, ops ...*valueobject.BatchOp) []*valueobject.BatchResult {
+		repo.batches = 0
+		return ct.Batch(ops, atomic)
+	}
+	errs := func(results []*valueobject.BatchResult) []string {
+		var errs []string
+		for _, r := range results {
+			errs = append(errs, r.Error)
+		}
+		return errs
+	}
+	title := func(status, id string) string {
+		b, err := d.GetContent(contentEntity.GetNamespace("Post", status), id)
+		c.Assert(err, qt.IsNil)
+		if b == nil {
+			return ""
+		}
+		var post valueobject.Post
+		c.Assert(json.Unmarshal(b, &post), qt.IsNil)
+		return post.Title
+	}
+
+	// The ops are applied BatchChunkSize to a transaction.
+	defer func(size int) { contentEntity.BatchChunkSize = size }(contentEntity.BatchChunkSize)
+	contentEntity.BatchChunkSize = 4
+	var ops []*valueobject.BatchOp
+	for i := 0; i <= contentEntity.BatchChunkSize; i++ {
+		ops = append(ops, create("post "+strconv.Itoa(i), ""))
+	}
+	results := batch(false, ops...)
+	c.Assert(repo.batches, qt.Equals, 2)
+	c.Assert(results, qt.HasLen, 5)
+	for i, r := range results {
+		c.Assert(r.Index, qt.Equals, i)
+		c.Assert(r.OK(), qt.IsTrue, qt.Commentf("%d: %s", i, r.Error))
+		c.Assert(r.ID, qt.Equals, strconv.Itoa(i+1))
+		c.Assert(r.Status, qt.Equals, "public")
+	}
+	c.Assert(d.AllContent("Post"), qt.HasLen, 5)
+
+	// An op failing to write rolls its transaction back, retried without it.
+	results = batch(false, create("a", ""), create("fail", ""), create("b", "pending"))
+	c.Assert(repo.batches, qt.Equals, 2)
+	c.Assert(errs(results), qt.DeepEquals, []string{"", "disk full", ""})
+	c.Assert(title("", results[0].ID), qt.Equals, "a")
+	c.Assert(results[2].Status, qt.Equals, "pending")
+	c.Assert(title("pending", results[2].ID), qt.Equals, "b")
+	pending := results[2].ID
+
+	// An op failing before writing is left out of its transaction.
+	results = batch(false,
+		&valueobject.BatchOp{Op: valueobject.BatchUpdate, Type: "Post", ID: "9999", Values: url.Values{"title": {"x"}}},
+		&valueobject.BatchOp{Op: valueobject.BatchUpdate, Type: "Post", ID: "1", Values: url.Values{"title": {"first"}, "id": {"7"}}},
+		&valueobject.BatchOp{Op: valueobject.BatchDelete, Type: "Post", ID: "2"},
+		&valueobject.BatchOp{Op: valueobject.BatchApprove, Type: "Post", ID: pending},
+		&valueobject.BatchOp{Op: "rename", Type: "Post", ID: "3"},
+	)
+	c.Assert(repo.batches, qt.Equals, 1)
+	c.Assert(errs(results), qt.DeepEquals, []string{"Post 9999 not found", "", "", "", `unknown op "rename"`})
+	c.Assert(title("", "1"), qt.Equals, "first")
+	c.Assert(title("", "2"), qt.Equals, "")
+	approved := results[3]
+	c.Assert(approved.Status, qt.Equals, "public")
+	c.Assert(title("", approved.ID), qt.Equals, "b")
+	c.Assert(title("pending", pending), qt.Equals, "")
+
+	// An atomic batch is applied in full or not at all.
+	before := len(d.AllContent("Post"))
+	results = batch(true, create("c", ""), &valueobject.BatchOp{Op: valueobject.BatchDelete, Type: "Post", ID: "9999"})
+	c.Assert(repo.batches, qt.Equals, 1)
+	c.Assert(errs(results), qt.DeepEquals, []string{contentEntity.ErrBatchNotApplied.Error(), "Post 9999 not found"})
+	c.Assert(d.AllContent("Post"), qt.HasLen, before)
+
+	results = batch(true, create("c", ""), &valueobject.BatchOp{Op: valueobject.BatchDelete, Type: "Post"})
+	c.Assert(repo.batches, qt.Equals, 0)
+	c.Assert(errs(results), qt.DeepEquals, []string{contentEntity.ErrBatchNotApplied.Error(), "delete needs an id"})
+
+	results = batch(true, create("c", ""), create("d", ""))
+	c.Assert(errs(results), qt.DeepEquals, []string{"", ""})
+	c.Assert(d.AllContent("Post"), qt.HasLen, before+2)
+}
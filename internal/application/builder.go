@@ -0,0 +1,102 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+	"github.com/spf13/afero"
+)
+
+// BuildOptions describes the site a Builder builds.
+type BuildOptions struct {
+	// Fs holds the project, the OS file system if nil.
+	Fs afero.Fs
+
+	// WorkingDir is the project directory on Fs. It must be absolute
+	// unless Fs is nil.
+	WorkingDir string
+
+	// Config is set on top of the project config, e.g. baseURL or
+	// params.env.
+	Config map[string]any
+
+	// PublishFs receives the published site, the publish dir of the
+	// project on Fs if nil.
+	PublishFs afero.Fs
+
+	// Logger receives the log of the build, the process log if nil.
+	Logger loggers.Logger
+}
+
+func (opts BuildOptions) logger() loggers.Logger {
+	if opts.Logger != nil {
+		return opts.Logger
+	}
+	return logger
+}
+
+// Builder builds a site from its BuildOptions alone, running the config,
+// module, fs, content hub, resources, site and template pipeline afresh
+// on each Build. It keeps no state between builds and shares none with
+// other Builders, so builds of the same or different sites can run at the
+// same time in one process.
+type Builder struct {
+	opts BuildOptions
+}
+
+// NewBuilder creates a Builder, checking that the working dir exists.
+func NewBuilder(opts BuildOptions) (*Builder, error) {
+	if opts.Fs == nil {
+		opts.Fs = afero.NewOsFs()
+		dir, err := filepath.Abs(opts.WorkingDir)
+		if err != nil {
+			return nil, err
+		}
+		opts.WorkingDir = dir
+	}
+	if !filepath.IsAbs(opts.WorkingDir) {
+		return nil, fmt.Errorf("working dir %q is not absolute", opts.WorkingDir)
+	}
+	if err := checkDir(opts.Fs, opts.WorkingDir); err != nil {
+		return nil, err
+	}
+
+	return &Builder{opts: opts}, nil
+}
+
+// Build builds the site and publishes it.
+func (b *Builder) Build() error {
+	return b.BuildContext(context.Background())
+}
+
+// BuildContext builds the site like Build, stopping with the error of ctx
+// at the next phase of the build once ctx is done.
+func (b *Builder) BuildContext(ctx context.Context) error {
+	ss, err := generateStaticSite(ctx, b.opts)
+	if err != nil {
+		return err
+	}
+
+	logImageStats(b.opts.logger(), ss.resources)
+
+	return nil
+}
+
+func checkDir(fs afero.Fs, dir string) error {
+	info, err := fs.Stat(dir)
+	if errors.Is(err, afero.ErrFileNotFound) {
+		return errors.New("file not exist")
+	}
+	if err != nil {
+		return err
+	}
+
+	if !info.IsDir() {
+		return errors.New("target is not a directory")
+	}
+
+	return nil
+}
@@ -0,0 +1,93 @@
+package application
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+	"github.com/spf13/afero"
+)
+
+func TestBuilder(t *testing.T) {
+	c := qt.New(t)
+
+	newSite := func(title string) afero.Fs {
+		fs := afero.NewMemMapFs()
+		for name, content := range map[string]string{
+			"config.toml":                  fmt.Sprintf("baseURL = \"https://example.org/\"\ntitle = %q\n", title),
+			"go.mod":                       "module example.org/site\n\ngo 1.18\n",
+			"layouts/_default/single.html": `{{ .Site.Title }}|{{ .Title }}|{{ site.Params.env }}`,
+			"layouts/_default/list.html":   `{{ .Title }}`,
+			"content/posts/hello.md":       "---\ntitle: Hello\n---\nHello.\n",
+			"static/robots.txt":            "User-agent: *\n",
+		} {
+			c.Assert(afero.WriteFile(fs, filepath.Join("/site", name), []byte(content), 0o644), qt.IsNil)
+		}
+		return fs
+	}
+
+	// Two sites built at the same time, from memory to memory.
+	titles := []string{"Alpha", "Beta"}
+	outs := make([]afero.Fs, len(titles))
+	var wg sync.WaitGroup
+	for i, title := range titles {
+		outs[i] = afero.NewMemMapFs()
+		b, err := NewBuilder(BuildOptions{
+			Fs:         newSite(title),
+			WorkingDir: "/site",
+			Config:     map[string]any{"params.env": "prod-" + title},
+			PublishFs:  outs[i],
+		})
+		c.Assert(err, qt.IsNil)
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Check(b.Build(), qt.IsNil)
+		}()
+	}
+	wg.Wait()
+
+	for i, title := range titles {
+		b, err := afero.ReadFile(outs[i], "/posts/hello.html")
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(b), qt.Equals, title+"|Hello|prod-"+title)
+
+		b, err = afero.ReadFile(outs[i], "/robots.txt")
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(b), qt.Equals, "User-agent: *\n")
+	}
+
+	// The log of a build goes to its logger, and a done context stops it.
+	var lines []string
+	b, err := NewBuilder(BuildOptions{
+		Fs:         newSite("Gamma"),
+		WorkingDir: "/site",
+		PublishFs:  afero.NewMemMapFs(),
+		Logger: loggers.NewPrintfLogger(func(format string, v ...any) {
+			lines = append(lines, fmt.Sprintf(format, v...))
+		}),
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(b.Build(), qt.IsNil)
+	c.Assert(lines, qt.Contains, "Rendering pages")
+	c.Assert(lines[len(lines)-1], qt.Matches, "Processed images: .*")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	out := afero.NewMemMapFs()
+	b, err = NewBuilder(BuildOptions{Fs: newSite("Delta"), WorkingDir: "/site", PublishFs: out})
+	c.Assert(err, qt.IsNil)
+	c.Assert(b.BuildContext(ctx), qt.ErrorIs, context.Canceled)
+	_, err = out.Stat("/posts/hello.html")
+	c.Assert(err, qt.IsNotNil)
+
+	_, err = NewBuilder(BuildOptions{Fs: afero.NewMemMapFs(), WorkingDir: "site"})
+	c.Assert(err, qt.ErrorMatches, `working dir "site" is not absolute`)
+	_, err = NewBuilder(BuildOptions{Fs: afero.NewMemMapFs(), WorkingDir: "/missing"})
+	c.Assert(err, qt.ErrorMatches, `file not exist`)
+}
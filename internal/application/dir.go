@@ -28,6 +28,11 @@ func UploadDir() string {
 	return filepath.Join(DataDir(), "uploads")
 }
 
+// SchemaDir is the dir of the schema files of user-defined content types.
+func SchemaDir() string {
+	return filepath.Join(DataDir(), "schemas")
+}
+
 func PreviewDir() string {
 	return filepath.Join(DataDir(), folderPreview)
 }
@@ -90,3 +95,7 @@ func (d *dir) PreviewFolder() string {
 func (d *dir) UploadDir() string {
 	return UploadDir()
 }
+
+func (d *dir) SchemaDir() string {
+	return SchemaDir()
+}
@@ -0,0 +1,205 @@
+package application
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+)
+
+// contact is a type with its own CSV columns.
+type contact struct {
+	valueobject.Item
+
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (c *contact) FormatCSV() []string {
+	return []string{"email", "name", "id"}
+}
+
+// uploadDir is the dirs of the app with the uploads in a dir of their own.
+type uploadDir struct {
+	dir
+	uploads string
+}
+
+func (d *uploadDir) UploadDir() string {
+	return d.uploads
+}
+
+func TestExport(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := database.New(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	ct := NewContentServer(d)
+	ct.UserTypes["Contact"] = func() interface{} { return new(contact) }
+	d.RegisterContentBuckets(ct.AllContentTypeNames())
+	c.Assert(d.StartUserDir("export"), qt.IsNil)
+	defer d.Close()
+
+	uploads := t.TempDir()
+	c.Assert(os.MkdirAll(filepath.Join(uploads, "2024", "01"), 0755), qt.IsNil)
+	c.Assert(os.WriteFile(filepath.Join(uploads, "2024", "01", "a.png"), []byte("png"), 0644), qt.IsNil)
+	ct.Hugo.DirService = &uploadDir{uploads: uploads}
+
+	jan := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	ms := func(t time.Time) string { return strconv.FormatInt(t.UnixMilli(), 10) }
+	create := func(contentType, status string, values url.Values) string {
+		results := ct.Batch([]*valueobject.BatchOp{{Op: valueobject.BatchCreate, Type: contentType, Status: status, Values: values}}, true)
+		c.Assert(results[0].OK(), qt.IsTrue, qt.Commentf("%s", results[0].Error))
+		return results[0].ID
+	}
+	create("Post", "", url.Values{"title": {"Hello"}, "content": {"hi"}, "params": {"tags: [x]"},
+		"timestamp": {ms(jan)}, "assets": {"/api/uploads/2024/01/a.png"}})
+	create("Post", "", url.Values{"title": {"World"}, "content": {"all"}, "timestamp": {ms(feb)},
+		"assets": {"/api/uploads/../../secret.txt"}})
+	create("Post", "pending", url.Values{"title": {"Draft"}, "content": {"wip"}, "timestamp": {ms(feb)}})
+	create("Site", "", url.Values{"title": {"Blog"}, "base_url": {"https://example.org/"}})
+	create("Site", "", url.Values{"title": {"Other"}})
+	// The second post takes the path of the first, which falls back to its slug.
+	create("SitePost", "", url.Values{"site": {"/api/content?type=Site&id=1"}, "post": {"/api/content?type=Post&id=1"}, "path": {"/content/blog/hello.md"}})
+	create("SitePost", "", url.Values{"site": {"/api/content?type=Site&id=1"}, "post": {"/api/content?type=Post&id=2"}, "path": {"content/blog/hello.md"}})
+	create("Contact", "", url.Values{"name": {"Ann"}, "email": {"ann@example.org"}})
+	create("Contact", "", url.Values{"name": {"Bob, Jr."}, "email": {"bob@example.org"}})
+
+	export := func(contentType string, format valueobject.ExportFormat, f valueobject.ExportFilter) (string, int) {
+		var b bytes.Buffer
+		n, err := ct.Export(&b, contentType, format, f)
+		c.Assert(err, qt.IsNil)
+		return b.String(), n
+	}
+	titles := func(ndjson string) []string {
+		var titles []string
+		for _, line := range strings.Split(strings.TrimSuffix(ndjson, "\n"), "\n") {
+			if line == "" {
+				continue
+			}
+			var post valueobject.Post
+			c.Assert(json.Unmarshal([]byte(line), &post), qt.IsNil)
+			titles = append(titles, post.Title)
+		}
+		return titles
+	}
+
+	c.Run("CSV", func(c *qt.C) {
+		out, n := export("Contact", valueobject.ExportCSV, valueobject.ExportFilter{})
+		c.Assert(n, qt.Equals, 2)
+		c.Assert(out, qt.Equals, "email,name,id\nann@example.org,Ann,1\nbob@example.org,\"Bob, Jr.\",2\n")
+
+		out, n = export("Post", valueobject.ExportCSV, valueobject.ExportFilter{})
+		c.Assert(n, qt.Equals, 2)
+		rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+		c.Assert(err, qt.IsNil)
+		c.Assert(rows, qt.HasLen, 3)
+		c.Assert(rows[0], qt.DeepEquals, []string{"uuid", "status", "namespace", "id", "slug", "hash", "timestamp", "updated",
+			"title", "content", "author", "params", "assets"})
+		c.Assert(rows[1][3], qt.Equals, "1")
+		c.Assert(rows[1][6], qt.Equals, ms(jan))
+		c.Assert(rows[1][8], qt.Equals, "Hello")
+		c.Assert(rows[1][12], qt.Equals, `["/api/uploads/2024/01/a.png"]`)
+	})
+
+	c.Run("NDJSON", func(c *qt.C) {
+		out, n := export("Post", valueobject.ExportNDJSON, valueobject.ExportFilter{})
+		c.Assert(n, qt.Equals, 2)
+		c.Assert(strings.Count(out, "\n"), qt.Equals, 2)
+		c.Assert(titles(out), qt.DeepEquals, []string{"Hello", "World"})
+	})
+
+	c.Run("Filters", func(c *qt.C) {
+		for _, test := range []struct {
+			name   string
+			filter valueobject.ExportFilter
+			titles []string
+		}{
+			{"pending", valueobject.ExportFilter{Status: content.Pending}, []string{"Draft"}},
+			{"since", valueobject.ExportFilter{Since: feb}, []string{"World"}},
+			{"until", valueobject.ExportFilter{Until: feb}, []string{"Hello"}},
+			{"site", valueobject.ExportFilter{Site: "1"}, []string{"Hello", "World"}},
+			{"site with no posts", valueobject.ExportFilter{Site: "2"}, nil},
+		} {
+			out, n := export("Post", valueobject.ExportNDJSON, test.filter)
+			c.Assert(titles(out), qt.DeepEquals, test.titles, qt.Commentf(test.name))
+			c.Assert(n, qt.Equals, len(test.titles), qt.Commentf(test.name))
+		}
+
+		for _, f := range []valueobject.ExportFilter{
+			{Site: "9"},
+			{Status: "draft"},
+			{Since: feb, Until: jan},
+		} {
+			_, err := ct.Export(io.Discard, "Post", valueobject.ExportNDJSON, f)
+			c.Assert(err, qt.IsNotNil, qt.Commentf("%v", f))
+		}
+		_, err := ct.Export(io.Discard, "Site", valueobject.ExportHugo, valueobject.ExportFilter{})
+		c.Assert(err, qt.ErrorMatches, "only posts export to hugo")
+	})
+
+	c.Run("Hugo", func(c *qt.C) {
+		out, n := export("Post", valueobject.ExportHugo, valueobject.ExportFilter{Site: "1"})
+		c.Assert(n, qt.Equals, 2)
+
+		gr, err := gzip.NewReader(strings.NewReader(out))
+		c.Assert(err, qt.IsNil)
+		tr := tar.NewReader(gr)
+		files := make(map[string]string)
+		for {
+			h, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			c.Assert(err, qt.IsNil)
+			b, err := io.ReadAll(tr)
+			c.Assert(err, qt.IsNil)
+			files[h.Name] = string(b)
+		}
+
+		var names []string
+		for name := range files {
+			names = append(names, name)
+		}
+		var slug string
+		for _, name := range names {
+			if strings.HasPrefix(name, "content/posts/") {
+				slug = strings.TrimSuffix(strings.TrimPrefix(name, "content/posts/"), ".md")
+			}
+		}
+		c.Assert(slug, qt.Not(qt.Equals), "")
+		c.Assert(files, qt.HasLen, 4, qt.Commentf("%v", names))
+
+		c.Assert(files["config.toml"], qt.Contains, `title = "Blog"`)
+		c.Assert(files["config.toml"], qt.Contains, `baseURL = "https://example.org/"`)
+
+		// The post with assets is a bundle, the asset outside the uploads is
+		// left out.
+		hello := files["content/blog/hello/index.md"]
+		c.Assert(strings.HasPrefix(hello, "---\n"), qt.IsTrue)
+		c.Assert(hello, qt.Contains, "title: Hello\n")
+		c.Assert(hello, qt.Contains, "date: \"2024-01-01T00:00:00Z\"\n")
+		c.Assert(hello, qt.Contains, "tags:\n")
+		c.Assert(strings.HasSuffix(hello, "---\nhi"), qt.IsTrue)
+		c.Assert(files["content/blog/hello/a.png"], qt.Equals, "png")
+
+		world := files["content/posts/"+slug+".md"]
+		c.Assert(world, qt.Contains, "title: World\n")
+		c.Assert(world, qt.Not(qt.Contains), "draft")
+	})
+}
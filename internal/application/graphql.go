@@ -0,0 +1,70 @@
+package application
+
+import (
+	"os"
+	"path/filepath"
+
+	configAgr "github.com/mdfriday/hugoverse/internal/domain/config/entity"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	contentEntity "github.com/mdfriday/hugoverse/internal/domain/content/entity"
+	"github.com/mdfriday/hugoverse/internal/domain/content/factory"
+	chAgr "github.com/mdfriday/hugoverse/internal/domain/contenthub/entity"
+)
+
+// NewGraphQL creates the GraphQL API over the content of cs, loading the
+// page trees of its sites from the projects written to build them.
+func NewGraphQL(cs *contentEntity.Content) (*contentEntity.GraphQL, error) {
+	return factory.NewGraphQL(cs, &pageTrees{content: cs})
+}
+
+type pageTrees struct {
+	content *contentEntity.Content
+}
+
+// PageTree writes the project of the site with id, and collects its pages
+// without rendering them. The project is removed on release.
+func (t *pageTrees) PageTree(id, status string) (content.PageTree, func(), error) {
+	target, err := t.content.BuildTarget("Site", id, status)
+	if err != nil {
+		return nil, nil, err
+	}
+	release := func() {
+		if err := os.RemoveAll(target); err != nil {
+			logger.Printf("Error removing the project of site %s: %v", id, err)
+		}
+	}
+
+	dir, err := filepath.Abs(target)
+	if err == nil {
+		err = checkTarget(dir)
+	}
+	var tree *pageTree
+	if err == nil {
+		tree, err = loadPageTree(BuildOptions{WorkingDir: dir})
+	}
+	if err != nil {
+		release()
+		return nil, nil, err
+	}
+
+	return tree, release, nil
+}
+
+// pageTree is the page tree of a site, with the languages of its config.
+type pageTree struct {
+	*chAgr.ContentHub
+	*configAgr.Config
+}
+
+func loadPageTree(opts BuildOptions) (*pageTree, error) {
+	ss, err := loadSite(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ss.ch.CollectPages(ss.exec); err != nil {
+		return nil, err
+	}
+
+	return &pageTree{ContentHub: ss.ch, Config: ss.config}, nil
+}
@@ -0,0 +1,257 @@
+package application
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	contentEntity "github.com/mdfriday/hugoverse/internal/domain/content/entity"
+	"github.com/mdfriday/hugoverse/internal/domain/content/factory"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+	"github.com/spf13/afero"
+)
+
+// gqlClient runs queries against a GraphQL API in process, as a client
+// would over HTTP.
+type gqlClient struct {
+	c *qt.C
+	g *contentEntity.GraphQL
+}
+
+func (cl *gqlClient) do(r *valueobject.GraphQLRequest) map[string]any {
+	req := httptest.NewRequest(http.MethodPost, "/api/graphql", nil)
+	b, err := json.Marshal(cl.g.Execute(httptest.NewRecorder(), req, r))
+	cl.c.Assert(err, qt.IsNil)
+
+	var result map[string]any
+	cl.c.Assert(json.Unmarshal(b, &result), qt.IsNil)
+	return result
+}
+
+func (cl *gqlClient) query(query string) any {
+	result := cl.do(&valueobject.GraphQLRequest{Query: query})
+	cl.c.Assert(result["errors"], qt.IsNil)
+	return result["data"]
+}
+
+// memPageTrees loads the page tree of every site from one project.
+type memPageTrees struct {
+	fs       afero.Fs
+	loaded   int
+	released int
+}
+
+func (t *memPageTrees) PageTree(id, status string) (content.PageTree, func(), error) {
+	tree, err := loadPageTree(BuildOptions{Fs: t.fs, WorkingDir: "/site"})
+	if err != nil {
+		return nil, nil, err
+	}
+	t.loaded++
+	return tree, func() { t.released++ }, nil
+}
+
+func TestGraphQL(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := database.New(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	ct := NewContentServer(d)
+	d.RegisterContentBuckets(ct.AllContentTypeNames())
+	c.Assert(d.StartUserDir("user"), qt.IsNil)
+	defer d.Close()
+
+	put := func(ci any) {
+		cii := ci.(content.Identifiable)
+		id, err := d.NextContentId(cii.ItemName())
+		c.Assert(err, qt.IsNil)
+		cii.SetItemID(int(id))
+		ci.(content.Sluggable).SetSlug(fmt.Sprintf("%s-%d", cii.ItemName(), id))
+		b, err := json.Marshal(ci)
+		c.Assert(err, qt.IsNil)
+		c.Assert(d.NewContent(ci, b), qt.IsNil)
+	}
+	item := func(namespace string, timestamp int64) valueobject.Item {
+		return valueobject.Item{Namespace: namespace, Status: content.Public, Timestamp: timestamp}
+	}
+	put(&valueobject.Site{Item: item("Site", 1), Title: "Notes"})
+	posts := []*valueobject.Post{
+		{Item: item("Post", 1), Title: "Hello", Content: "Hello."},
+		{Item: item("Post", 2), Title: "World", Content: "World."},
+	}
+	sorted := make(map[string][]byte)
+	for i, p := range posts {
+		put(p)
+		b, err := json.Marshal(p)
+		c.Assert(err, qt.IsNil)
+		sorted[fmt.Sprintf("%d:%d", p.Time(), i)] = b
+	}
+	// The lists are read from the sorted items, as SortContent keeps them.
+	c.Assert(d.PutSortedContent("Post", sorted), qt.IsNil)
+	put(&valueobject.SitePost{
+		Item: item("SitePost", 1),
+		Site: "/api/content?type=Site&id=1",
+		Post: "/api/content?type=Post&id=2",
+		Path: "posts/world.md",
+	})
+
+	fs := afero.NewMemMapFs()
+	for name, content := range map[string]string{
+		"config.toml": `baseURL = "https://example.org/"
+title = "Notes"
+defaultContentLanguage = "en"
+
+[taxonomies]
+tag = "tags"
+
+[languages]
+[languages.en]
+  contentDir = "content"
+  weight = 1
+[languages.fr]
+  contentDir = "content.fr"
+  weight = 2
+`,
+		"go.mod":                       "module example.org/site\n\ngo 1.18\n",
+		"layouts/_default/single.html": `{{ .Content }}`,
+		"layouts/_default/list.html":   `{{ .Title }}`,
+		"content/posts/_index.md":      "---\ntitle: Posts\n---\n",
+		"content/posts/hello.md":       "---\ntitle: Hello\ntags: [go]\n---\nHello *world*.\n",
+		"content/posts/world.md":       "---\ntitle: World\ntags: [go, hugo]\n---\nWorld.\n",
+		"content.fr/posts/hello.md":    "---\ntitle: Bonjour\n---\nBonjour.\n",
+	} {
+		c.Assert(afero.WriteFile(fs, filepath.Join("/site", name), []byte(content), 0o644), qt.IsNil)
+	}
+	trees := &memPageTrees{fs: fs}
+
+	g, err := factory.NewGraphQL(ct, trees)
+	c.Assert(err, qt.IsNil)
+	cl := &gqlClient{c: c, g: g}
+
+	// Items, paginated, with their references resolved.
+	c.Assert(cl.query(`{
+		postList(count: 1, offset: 1, order: "asc") { total items { id title } }
+		sitePost(id: 1) { path site { title } post { title } }
+		site(id: 9) { title }
+	}`), qt.DeepEquals, map[string]any{
+		"postList": map[string]any{
+			"total": 2.0,
+			"items": []any{map[string]any{"id": 2.0, "title": "World"}},
+		},
+		"sitePost": map[string]any{
+			"path": "posts/world.md",
+			"site": map[string]any{"title": "Notes"},
+			"post": map[string]any{"title": "World"},
+		},
+		"site": nil,
+	})
+
+	c.Assert(cl.query(`{
+		all: postList(count: -1) { total items { title } }
+		past: postList(count: 1, offset: 5) { total items { title } }
+	}`), qt.DeepEquals, map[string]any{
+		"all": map[string]any{
+			"total": 2.0,
+			"items": []any{map[string]any{"title": "World"}, map[string]any{"title": "Hello"}},
+		},
+		"past": map[string]any{"total": 2.0, "items": []any{}},
+	})
+
+	// The page tree of the site, loaded once for the query.
+	c.Assert(g.QueriesPageTree(&valueobject.GraphQLRequest{Query: `{ post(id: 1) { title } }`}), qt.IsFalse)
+	c.Assert(g.QueriesPageTree(&valueobject.GraphQLRequest{Query: `query Q { t: pageTree(site: 1) { lang } }`}), qt.IsTrue)
+	c.Assert(cl.query(`{
+		pageTree(site: 1) {
+			lang
+			languages
+			home { kind sections { path title } }
+			page(path: "/posts/hello") {
+				title
+				content
+				parent { title }
+				translations { lang title }
+				terms(taxonomy: "tags") { path }
+			}
+			pages(kind: "page", section: "posts") { total items { title } }
+			taxonomies { name terms { name count pages { items { title } } } }
+		}
+		fr: pageTree(site: 1, lang: "fr") { page(path: "/posts") { regularPages { items { title } } } }
+	}`), qt.DeepEquals, map[string]any{
+		"pageTree": map[string]any{
+			"lang":      "en",
+			"languages": []any{"en", "fr"},
+			"home": map[string]any{
+				"kind":     "home",
+				"sections": []any{map[string]any{"path": "/posts", "title": "Posts"}},
+			},
+			"page": map[string]any{
+				"title":        "Hello",
+				"content":      "<p>Hello <em>world</em>.</p>\n",
+				"parent":       map[string]any{"title": "Posts"},
+				"translations": []any{map[string]any{"lang": "fr", "title": "Bonjour"}},
+				"terms":        []any{map[string]any{"path": "/tags/go"}},
+			},
+			"pages": map[string]any{
+				"total": 2.0,
+				"items": []any{map[string]any{"title": "Hello"}, map[string]any{"title": "World"}},
+			},
+			"taxonomies": []any{map[string]any{
+				"name": "tags",
+				"terms": []any{
+					map[string]any{"name": "go", "count": 2.0, "pages": map[string]any{"items": []any{
+						map[string]any{"title": "Hello"}, map[string]any{"title": "World"},
+					}}},
+					map[string]any{"name": "hugo", "count": 1.0, "pages": map[string]any{"items": []any{
+						map[string]any{"title": "World"},
+					}}},
+				},
+			}},
+		},
+		"fr": map[string]any{"page": map[string]any{"regularPages": map[string]any{
+			"items": []any{map[string]any{"title": "Bonjour"}},
+		}}},
+	})
+	c.Assert(trees.loaded, qt.Equals, 1)
+
+	// It is kept while the content of the site is unchanged.
+	tree := `{ pageTree(site: 1) { page(path: "/posts/hello") { title } } }`
+	c.Assert(cl.query(tree), qt.DeepEquals, map[string]any{
+		"pageTree": map[string]any{"page": map[string]any{"title": "Hello"}},
+	})
+	c.Assert(trees.loaded, qt.Equals, 1)
+	c.Assert(trees.released, qt.Equals, 0)
+
+	put(&valueobject.SitePost{
+		Item: item("SitePost", 2),
+		Site: "/api/content?type=Site&id=1",
+		Post: "/api/content?type=Post&id=1",
+		Path: "posts/hello.md",
+	})
+	cl.query(tree)
+	c.Assert(trees.loaded, qt.Equals, 2)
+	c.Assert(trees.released, qt.Equals, 1)
+
+	// A persisted query is sent once, then by its hash only.
+	query := `{ post(id: 1) { title } }`
+	pq := &valueobject.GraphQLRequest{}
+	pq.Extensions.PersistedQuery = &valueobject.PersistedQuery{Version: 1, Hash: valueobject.QueryHash(query)}
+	result := cl.do(pq)
+	c.Assert(result["errors"], qt.DeepEquals, []any{map[string]any{
+		"message":    "PersistedQueryNotFound",
+		"locations":  []any{},
+		"extensions": map[string]any{"code": "PERSISTED_QUERY_NOT_FOUND"},
+	}})
+
+	pq.Query = query
+	c.Assert(cl.do(pq)["data"], qt.DeepEquals, map[string]any{"post": map[string]any{"title": "Hello"}})
+	pq.Query = ""
+	c.Assert(cl.do(pq)["data"], qt.DeepEquals, map[string]any{"post": map[string]any{"title": "Hello"}})
+
+	pq.Query = `{ post(id: 2) { title } }`
+	c.Assert(cl.do(pq)["errors"], qt.Not(qt.IsNil))
+}
@@ -0,0 +1,21 @@
+package application
+
+import (
+	tmplVO "github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
+)
+
+// ShortcodesWithTarget lists the shortcodes available to the content of the
+// site in target: the embedded ones and those of the site and its theme,
+// with their parameter schemas.
+func ShortcodesWithTarget(target string) ([]tmplVO.ShortcodeInfo, error) {
+	if err := checkTarget(target); err != nil {
+		return nil, err
+	}
+
+	ss, err := loadStaticSite(target)
+	if err != nil {
+		return nil, err
+	}
+
+	return ss.exec.Shortcodes(), nil
+}
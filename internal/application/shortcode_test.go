@@ -0,0 +1,50 @@
+package application
+
+import (
+	"path/filepath"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/afero"
+)
+
+func TestShortcodeSchemaBuild(t *testing.T) {
+	c := qt.New(t)
+
+	build := func(post string) (afero.Fs, error) {
+		fs := afero.NewMemMapFs()
+		for name, content := range map[string]string{
+			"config.toml":                   "baseURL = \"https://example.org/\"\n",
+			"go.mod":                        "module example.org/site\n\ngo 1.18\n",
+			"layouts/_default/single.html":  `{{ .Content }}`,
+			"layouts/_default/list.html":    `{{ .Title }}`,
+			"layouts/shortcodes/video.html": `{{ .IsNamedParams }}|{{ .Get "width" }}`,
+			"layouts/shortcodes/video.schema.yaml": `
+params:
+  - name: width
+    type: int
+    default: 640
+`,
+			"content/posts/hello.md": post,
+		} {
+			c.Assert(afero.WriteFile(fs, filepath.Join("/site", name), []byte(content), 0o644), qt.IsNil)
+		}
+
+		out := afero.NewMemMapFs()
+		b, err := NewBuilder(BuildOptions{Fs: fs, WorkingDir: "/site", PublishFs: out})
+		c.Assert(err, qt.IsNil)
+
+		return out, b.Build()
+	}
+
+	out, err := build("---\ntitle: Hello\n---\n\n{{< video >}}\n\n{{< video width=\"320\" >}}\n")
+	c.Assert(err, qt.IsNil)
+	b, err := afero.ReadFile(out, "/posts/hello.html")
+	c.Assert(err, qt.IsNil)
+	// Without parameters the invocation gets no defaults, and stays not named.
+	c.Assert(string(b), qt.Contains, "false|")
+	c.Assert(string(b), qt.Contains, "true|320")
+
+	_, err = build("---\ntitle: Hello\n---\n\nSee:\n  {{< video width=\"wide\" >}}\n")
+	c.Assert(err, qt.ErrorMatches, `(?s).*hello\.md:6:3.*shortcode "video": parameter "width": expected int.*`)
+}
@@ -1,9 +1,11 @@
 package application
 
 import (
-	"errors"
+	"context"
+	"fmt"
 	configAgr "github.com/mdfriday/hugoverse/internal/domain/config/entity"
 	configFact "github.com/mdfriday/hugoverse/internal/domain/config/factory"
+	configVO "github.com/mdfriday/hugoverse/internal/domain/config/valueobject"
 	"github.com/mdfriday/hugoverse/internal/domain/contenthub"
 	chAgr "github.com/mdfriday/hugoverse/internal/domain/contenthub/entity"
 	contentHubFact "github.com/mdfriday/hugoverse/internal/domain/contenthub/factory"
@@ -18,69 +20,158 @@ import (
 	"github.com/mdfriday/hugoverse/internal/domain/site"
 	siteAgr "github.com/mdfriday/hugoverse/internal/domain/site/entity"
 	siteFact "github.com/mdfriday/hugoverse/internal/domain/site/factory"
+	tmplAgr "github.com/mdfriday/hugoverse/internal/domain/template/entity"
 	tmplFact "github.com/mdfriday/hugoverse/internal/domain/template/factory"
+	"github.com/mdfriday/hugoverse/pkg/herrors"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+	"github.com/mdfriday/hugoverse/pkg/maps"
 	"github.com/spf13/afero"
-	"os"
 	"sort"
 )
 
-var publishDirFs afero.Fs
-
 func ServeGenerateStaticSite() (afero.Fs, error) {
-	if err := GenerateStaticSite(); err != nil {
+	ss, err := generateStaticSite(context.Background(), BuildOptions{WorkingDir: getWd()})
+	if err != nil {
 		return nil, err
 	}
 
-	return publishDirFs, nil
+	logImageStats(logger, ss.resources)
+
+	return ss.fs.PublishDirStatic(), nil
 }
 
+// GenerateStaticSiteWithTarget builds the site in target. The paths of the
+// site are resolved against target instead of the process working
+// directory, so several sites can be built at the same time.
 func GenerateStaticSiteWithTarget(target string) error {
-	info, err := os.Stat(target)
+	b, err := NewBuilder(BuildOptions{WorkingDir: target})
+	if err != nil {
+		return err
+	}
+
+	return b.Build()
+}
+
+func checkTarget(target string) error {
+	return checkDir(afero.NewOsFs(), target)
+}
+
+func GenerateStaticSite() error {
+	return GenerateStaticSiteWithTarget(getWd())
+}
+
+// GCImages builds the site, so that the images in use are known, and
+// removes the unused derivatives from the image cache.
+func GCImages() (int, error) {
+	ss, err := generateStaticSite(context.Background(), BuildOptions{WorkingDir: getWd()})
+	if err != nil {
+		return 0, err
+	}
+
+	logImageStats(logger, ss.resources)
+
+	return ss.resources.ImageCache().PruneUnused()
+}
+
+func logImageStats(log loggers.Logger, resources *rsAgr.Resources) {
+	stats := resources.ImagePool.Stats()
+	log.Printf("Processed images: %d regenerated, %d reused from cache, %d deduplicated",
+		stats.Regenerated, stats.Reused, stats.Deduplicated)
+}
+
+func generateStaticSite(ctx context.Context, opts BuildOptions) (*staticSite, error) {
+	log := opts.logger()
+	log.Printf("Loading site %s", opts.WorkingDir)
+	ss, err := loadSite(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Copy the static files while building, the build is done when both are.
+	copied := make(chan error, 1)
+	go func() {
+		copied <- staticCopy(ss.fs.Static, ss.fs.PublishDirStatic())
+	}()
 
-	if os.IsNotExist(err) {
-		return errors.New("file not exist")
+	err = ss.build(ctx, log)
+	if cerr := <-copied; err == nil && cerr != nil && !herrors.IsNotExist(cerr) {
+		err = fmt.Errorf("copy static files: %w", cerr)
 	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ss, nil
+}
 
-	if !info.IsDir() {
-		return errors.New("target is not a directory")
+// build builds the loaded site, checking ctx between the phases.
+func (ss *staticSite) build(ctx context.Context, log loggers.Logger) error {
+	log.Printf("Collecting pages")
+	if err := ss.ch.CollectPages(ss.exec); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	if err := os.Chdir(target); err != nil {
+	log.Printf("Rendering pages")
+	if err := ss.site.Build(ss.exec); err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
 		return err
 	}
 
-	return GenerateStaticSite()
+	log.Printf("Post-processing")
+	return ss.resources.ApplyPostProcess()
 }
 
-func GenerateStaticSite() error {
-	c, err := configFact.LoadConfig()
+// staticSite holds the services of a site loaded from its project
+// directory, ready to build.
+type staticSite struct {
+	config    *configAgr.Config
+	fs        *fsAgr.Fs
+	ch        *chAgr.ContentHub
+	site      *siteAgr.Site
+	resources *rsAgr.Resources
+	exec      *tmplAgr.Template
+}
+
+func loadStaticSite(dir string) (*staticSite, error) {
+	return loadSite(BuildOptions{WorkingDir: dir})
+}
+
+func loadSite(opts BuildOptions) (*staticSite, error) {
+	sourceFs := opts.Fs
+	if sourceFs == nil {
+		sourceFs = afero.NewOsFs()
+	}
+	c, err := configFact.LoadConfigFromFs(sourceFs, opts.WorkingDir, opts.Config)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	c.DestinationFs = opts.PublishFs
 
 	mods, err := moduleFact.New(c)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	fs, err := fsFact.New(c, mods)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	publishDirFs = fs.PublishDirStatic()
-
-	go func() {
-		_ = staticCopy(fs.Static, fs.PublishDirStatic())
-	}()
-
 	ch, err := contentHubFact.New(&chServices{
 		Config: c,
 		Fs:     fs,
 		Module: mods,
 	})
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	ws := &resourcesWorkspaceProvider{
@@ -89,7 +180,7 @@ func GenerateStaticSite() error {
 	}
 	resources, err := rsFact.NewResources(ws)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	s := siteFact.New(&siteServices{
@@ -112,18 +203,19 @@ func GenerateStaticSite() error {
 	resources.SetupTemplateClient(exec) // Expose template service to resources operations
 
 	if err != nil {
-		return err
-	}
-
-	if err := ch.CollectPages(exec); err != nil {
-		return err
+		return nil, err
 	}
 
-	if err := s.Build(exec); err != nil {
-		return err
+	if opts.Logger != nil {
+		s.Log = opts.Logger
+		exec.Log = opts.Logger
+		ch.Log = opts.Logger
+		ch.Translator.Log = opts.Logger
+		ch.PageMap.Log = opts.Logger
+		ch.PageMap.PageBuilder.Log = opts.Logger
 	}
 
-	return nil
+	return &staticSite{config: c, fs: fs, ch: ch, site: s, resources: resources, exec: exec}, nil
 }
 
 type resourcesWorkspaceProvider struct {
@@ -181,10 +273,10 @@ type siteServices struct {
 	*rsAgr.Resources
 }
 
-func (s *siteServices) Menus() map[string][]site.Menu {
+func (s *siteServices) Menus(lang string) map[string][]site.Menu {
 	siteMenus := make(map[string][]site.Menu)
 
-	ms := s.Config.AllMenus()
+	ms := s.Config.LanguageMenuConfigs(lang)
 
 	for k, v := range ms {
 		if siteMenus[k] == nil {
@@ -193,11 +285,7 @@ func (s *siteServices) Menus() map[string][]site.Menu {
 
 		var menus []site.Menu
 		for _, menu := range v {
-			menus = append(menus, &siteMenu{
-				name:   menu.Name,
-				url:    menu.URL,
-				weight: menu.Weight,
-			})
+			menus = append(menus, &siteMenu{cfg: menu})
 		}
 
 		siteMenus[k] = append(siteMenus[k], menus...)
@@ -207,19 +295,45 @@ func (s *siteServices) Menus() map[string][]site.Menu {
 }
 
 type siteMenu struct {
-	name   string
-	url    string
-	weight int
+	cfg configVO.MenuConfig
+}
+
+func (s *siteMenu) Identifier() string {
+	return s.cfg.Identifier
+}
+
+func (s *siteMenu) Parent() string {
+	return s.cfg.Parent
 }
 
 func (s *siteMenu) Name() string {
-	return s.name
+	return s.cfg.Name
+}
+
+func (s *siteMenu) Pre() string {
+	return string(s.cfg.Pre)
+}
+
+func (s *siteMenu) Post() string {
+	return string(s.cfg.Post)
 }
 
 func (s *siteMenu) URL() string {
-	return s.url
+	return s.cfg.URL
+}
+
+func (s *siteMenu) PageRef() string {
+	return s.cfg.PageRef
 }
 
 func (s *siteMenu) Weight() int {
-	return s.weight
+	return s.cfg.Weight
+}
+
+func (s *siteMenu) Title() string {
+	return s.cfg.Title
+}
+
+func (s *siteMenu) Params() maps.Params {
+	return s.cfg.Params
 }
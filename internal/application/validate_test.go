@@ -0,0 +1,81 @@
+package application
+
+import (
+	"net/url"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+	"github.com/mdfriday/hugoverse/pkg/validate"
+)
+
+// handle is a type with a field unique across its items.
+type handle struct {
+	valueobject.Item
+
+	Name string `json:"name"`
+	Bio  string `json:"bio"`
+}
+
+func (h *handle) Rules() []validate.Rule {
+	return []validate.Rule{
+		validate.Required("name"),
+		validate.Unique("name"),
+	}
+}
+
+func TestValidate(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := database.New(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	ct := NewContentServer(d)
+	ct.UserTypes["Handle"] = func() interface{} { return new(handle) }
+	d.RegisterContentBuckets(ct.AllContentTypeNames())
+	c.Assert(d.StartUserDir("validate"), qt.IsNil)
+	defer d.Close()
+
+	results := ct.Batch([]*valueobject.BatchOp{
+		{Op: valueobject.BatchCreate, Type: "Handle", Values: url.Values{"name": {"ann"}}},
+		{Op: valueobject.BatchCreate, Type: "Handle", Status: "pending", Values: url.Values{"name": {"bob"}}},
+	}, true)
+	for i, r := range results {
+		c.Assert(r.OK(), qt.IsTrue, qt.Commentf("%d: %s", i, r.Error))
+	}
+	ann, bob := results[0].ID, results[1].ID
+
+	// The pending items are looked up too.
+	c.Assert(ct.Validate("Handle", "-1", url.Values{"name": {"bob"}}), qt.ErrorMatches, "name is already taken")
+	c.Assert(ct.Validate("Handle", "-1", url.Values{"name": {"cat"}}), qt.IsNil)
+	c.Assert(ct.Validate("Handle", ann, url.Values{"name": {"ann"}}), qt.IsNil)
+
+	// An update is checked merged onto the stored item.
+	c.Assert(ct.ValidateUpdate("Handle", ann, "", url.Values{"bio": {"hi"}}), qt.IsNil)
+	c.Assert(ct.ValidateUpdate("Handle", ann, "", url.Values{"name": {""}}), qt.ErrorMatches, "name is required")
+	c.Assert(ct.ValidateUpdate("Handle", ann, "", url.Values{"name": {"bob"}}), qt.ErrorMatches, "name is already taken")
+	c.Assert(ct.ValidateUpdate("Handle", bob, "pending", url.Values{"bio": {"hi"}}), qt.IsNil)
+	c.Assert(ct.ValidateUpdate("Handle", "9999", "", url.Values{"bio": {"hi"}}), qt.ErrorMatches, "Handle 9999 not found")
+
+	// A validator checks the items against those set or deleted before.
+	v := ct.NewValidator()
+	c.Assert(v.Validate("Handle", "-1", url.Values{"name": {"cat"}}), qt.IsNil)
+	c.Assert(v.Set("Handle", "", "", url.Values{"name": {"cat"}}), qt.IsNil)
+	c.Assert(v.Validate("Handle", "-1", url.Values{"name": {"cat"}}), qt.ErrorMatches, "name is already taken")
+
+	v.Delete("Handle", ann, "")
+	c.Assert(v.Validate("Handle", "-1", url.Values{"name": {"ann"}}), qt.IsNil)
+	c.Assert(v.ValidateUpdate("Handle", ann, "", url.Values{"bio": {"hi"}}), qt.ErrorMatches, "Handle "+ann+" not found")
+
+	c.Assert(v.Set("Handle", bob, "pending", url.Values{"name": {"bobby"}}), qt.IsNil)
+	c.Assert(v.Validate("Handle", "-1", url.Values{"name": {"bob"}}), qt.IsNil)
+	c.Assert(v.Validate("Handle", "-1", url.Values{"name": {"bobby"}}), qt.ErrorMatches, "name is already taken")
+
+	// Values escaped in JSON are looked up too.
+	results = ct.Batch([]*valueobject.BatchOp{
+		{Op: valueobject.BatchCreate, Type: "Handle", Values: url.Values{"name": {`<"dan">`}}},
+	}, true)
+	c.Assert(results[0].OK(), qt.IsTrue, qt.Commentf("%s", results[0].Error))
+	c.Assert(ct.Validate("Handle", "-1", url.Values{"name": {`<"dan">`}}), qt.ErrorMatches, "name is already taken")
+	c.Assert(ct.Validate("Handle", results[0].ID, url.Values{"name": {`<"dan">`}}), qt.IsNil)
+}
@@ -17,6 +17,7 @@ type Admin struct {
 	*Http
 	*Cache
 	*Controller
+	*Throttle
 	*Client
 	*Netlify
 
@@ -110,3 +111,9 @@ func (a *Admin) InvalidateCache() error {
 }
 
 func (a *Admin) Name() string { return a.Conf.Name }
+
+// BackupCredentials returns the basic auth user and password guarding the
+// backups, empty unless configured.
+func (a *Admin) BackupCredentials() (string, string) {
+	return a.Conf.BackupBasicAuthUser, a.Conf.BackupBasicAuthPassword
+}
@@ -0,0 +1,75 @@
+package entity
+
+import (
+	"net/netip"
+	"strings"
+	"time"
+
+	"github.com/mdfriday/hugoverse/internal/domain/admin/valueobject"
+)
+
+const (
+	// DefaultRateLimit is the API requests a minute of a client, unless
+	// configured.
+	DefaultRateLimit = 300
+	// DefaultBuildRateLimit is the builds, previews, deploys, batches,
+	// exports and queries of page trees an hour of a client, unless
+	// configured.
+	DefaultBuildRateLimit = 20
+	// DefaultLoginMaxFailures is the failed logins before a lockout, unless
+	// configured.
+	DefaultLoginMaxFailures = 5
+	// DefaultLoginLockout is the lockout after failed logins, unless
+	// configured.
+	DefaultLoginLockout = 15 * time.Minute
+)
+
+type Throttle struct {
+	Conf *valueobject.Config
+}
+
+func (a *Throttle) RateLimitDisabled() bool { return a.Conf.DisableRateLimit }
+
+func (a *Throttle) RateLimit() int {
+	if a.Conf.RateLimit <= 0 {
+		return DefaultRateLimit
+	}
+	return a.Conf.RateLimit
+}
+
+func (a *Throttle) BuildRateLimit() int {
+	if a.Conf.BuildRateLimit <= 0 {
+		return DefaultBuildRateLimit
+	}
+	return a.Conf.BuildRateLimit
+}
+
+func (a *Throttle) LoginMaxFailures() int {
+	if a.Conf.LoginMaxFailures <= 0 {
+		return DefaultLoginMaxFailures
+	}
+	return a.Conf.LoginMaxFailures
+}
+
+func (a *Throttle) LoginLockout() time.Duration {
+	if a.Conf.LoginLockout <= 0 {
+		return DefaultLoginLockout
+	}
+	return time.Duration(a.Conf.LoginLockout) * time.Minute
+}
+
+// TrustedProxies returns the IP ranges of the trusted proxies, a single
+// address being a range of its own. Invalid ones are left out.
+func (a *Throttle) TrustedProxies() []netip.Prefix {
+	var ps []netip.Prefix
+	for _, f := range strings.FieldsFunc(a.Conf.TrustedProxies, func(r rune) bool {
+		return r == ',' || r == ' '
+	}) {
+		if p, err := netip.ParsePrefix(f); err == nil {
+			ps = append(ps, p.Masked())
+		} else if ip, err := netip.ParseAddr(f); err == nil {
+			ps = append(ps, netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()))
+		}
+	}
+	return ps
+}
@@ -46,6 +46,7 @@ func NewAdmin(repo repository.Repository) (*entity.Admin, error) {
 	a.Http = &entity.Http{Conf: a.Conf}
 	a.Cache = &entity.Cache{Conf: a.Conf}
 	a.Controller = &entity.Controller{Conf: a.Conf}
+	a.Throttle = &entity.Throttle{Conf: a.Conf}
 	a.Client = &entity.Client{Conf: a.Conf}
 	a.Netlify = &entity.Netlify{Conf: a.Conf}
 
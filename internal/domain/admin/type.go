@@ -1,7 +1,9 @@
 package admin
 
 import (
+	"net/netip"
 	"net/url"
+	"time"
 )
 
 type Admin interface {
@@ -12,6 +14,7 @@ type Admin interface {
 	Persistence
 	Cache
 	Controller
+	Throttle
 	Upload
 	Http
 	Client
@@ -69,6 +72,15 @@ type Controller interface {
 	GzipDisabled() bool
 }
 
+type Throttle interface {
+	RateLimitDisabled() bool
+	RateLimit() int
+	BuildRateLimit() int
+	LoginMaxFailures() int
+	LoginLockout() time.Duration
+	TrustedProxies() []netip.Prefix
+}
+
 type Client interface {
 	ClientSecret() string
 }
@@ -15,6 +15,10 @@ const (
 		<p class="flow-text">Database Backup Credentials:</p>
 		<p>Add a user name and password to download a backup of your data via HTTP.</p>
 	`
+	rateLimitInfo = `
+		<p class="flow-text">Rate Limits:</p>
+		<p>Limit the API requests of each IP address, user and API key, and lock logins out after failed attempts.</p>
+	`
 )
 
 type Config struct {
@@ -37,6 +41,12 @@ type Config struct {
 	CacheInvalidate         []string `json:"cache"`
 	BackupBasicAuthUser     string   `json:"backup_basic_auth_user"`
 	BackupBasicAuthPassword string   `json:"backup_basic_auth_password"`
+	DisableRateLimit        bool     `json:"rate_limit_disabled"`
+	RateLimit               int      `json:"rate_limit"`
+	BuildRateLimit          int      `json:"build_rate_limit"`
+	LoginMaxFailures        int      `json:"login_max_failures"`
+	LoginLockout            int      `json:"login_lockout"`
+	TrustedProxies          string   `json:"trusted_proxies"`
 }
 
 func (c *Config) IsCacheInvalidate() bool {
@@ -219,6 +229,47 @@ func (c *Config) MarshalEditor() ([]byte, error) {
 				"type":        "password",
 			}),
 		},
+		editor.Field{
+			View: []byte(rateLimitInfo),
+		},
+		editor.Field{
+			View: editor.Checkbox("DisableRateLimit", c, map[string]string{
+				"label": "Disable Rate Limits (lets any client send requests without limit)",
+			}, map[string]string{
+				"true": "Disable Rate Limits",
+			}),
+		},
+		editor.Field{
+			View: editor.Input("RateLimit", c, map[string]string{
+				"label": "API requests a minute by client (0 = 300)",
+				"type":  "text",
+			}),
+		},
+		editor.Field{
+			View: editor.Input("BuildRateLimit", c, map[string]string{
+				"label": "Builds, previews, deploys, batches, exports and page tree queries an hour by client (0 = 20)",
+				"type":  "text",
+			}),
+		},
+		editor.Field{
+			View: editor.Input("LoginMaxFailures", c, map[string]string{
+				"label": "Failed logins before a lockout (0 = 5)",
+				"type":  "text",
+			}),
+		},
+		editor.Field{
+			View: editor.Input("LoginLockout", c, map[string]string{
+				"label": "Lockout after failed logins (in minutes, 0 = 15)",
+				"type":  "text",
+			}),
+		},
+		editor.Field{
+			View: editor.Input("TrustedProxies", c, map[string]string{
+				"label":       "Trusted proxies, naming the clients in X-Forwarded-For and X-Real-IP",
+				"placeholder": "IP addresses or ranges, comma separated, e.g. 10.0.0.0/8, 127.0.0.1",
+				"type":        "text",
+			}),
+		},
 	)
 	if err != nil {
 		return nil, err
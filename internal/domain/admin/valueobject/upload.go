@@ -15,6 +15,10 @@ type FileUpload struct {
 	Path          string `json:"path"`
 	ContentLength int64  `json:"content_length"`
 	ContentType   string `json:"content_type"`
+
+	// Width and Height are set for images.
+	Width  int `json:"width,omitempty"`
+	Height int `json:"height,omitempty"`
 }
 
 // String partially implements item.Identifiable and overrides Item's String()
@@ -39,7 +43,7 @@ func (f *FileUpload) MarshalEditor() ([]byte, error) {
 				<h5>` + f.Name + `</h5>
 				<ul>
 					<li><span class="grey-text text-lighten-1">Content-Length:</span> ` + fmt.Sprintf("%s", FmtBytes(float64(f.ContentLength))) + `</li>
-					<li><span class="grey-text text-lighten-1">Content-Type:</span> ` + f.ContentType + `</li>
+					<li><span class="grey-text text-lighten-1">Content-Type:</span> ` + f.ContentType + `</li>` + f.dimensions() + `
 					<li><span class="grey-text text-lighten-1">Uploaded:</span> ` + FmtTime(f.Timestamp) + `</li>
 				</ul>
             </div>
@@ -102,6 +106,14 @@ func (f *FileUpload) MarshalEditor() ([]byte, error) {
 	return view, nil
 }
 
+func (f *FileUpload) dimensions() string {
+	if f.Width == 0 || f.Height == 0 {
+		return ""
+	}
+	return fmt.Sprintf(`
+					<li><span class="grey-text text-lighten-1">Dimensions:</span> %dx%d</li>`, f.Width, f.Height)
+}
+
 func (f *FileUpload) Push() []string {
 	return []string{
 		"path",
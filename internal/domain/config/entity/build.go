@@ -0,0 +1,17 @@
+package entity
+
+import "github.com/mdfriday/hugoverse/internal/domain/config/valueobject"
+
+type Build struct {
+	valueobject.BuildConfig
+}
+
+func (b Build) IsBuildStatsEnabled() bool {
+	return b.BuildStats.Enable
+}
+
+// BuildStatsOptions returns which of the tags, classes and ids to leave out
+// of the build stats.
+func (b Build) BuildStatsOptions() (disableTags, disableClasses, disableIDs bool) {
+	return b.BuildStats.DisableTags, b.BuildStats.DisableClasses, b.BuildStats.DisableIDs
+}
@@ -9,11 +9,16 @@ import (
 type Config struct {
 	ConfigSourceFs afero.Fs
 
+	// DestinationFs is the file system the site is published to, nil
+	// to publish to the publish dir on ConfigSourceFs.
+	DestinationFs afero.Fs
+
 	Provider config.Provider
 
 	Root
 	Caches
 	Security
+	Build
 	Menu
 	Module
 	Service
@@ -33,6 +38,10 @@ func (c *Config) Fs() afero.Fs {
 	return c.ConfigSourceFs
 }
 
+func (c *Config) OutputFs() afero.Fs {
+	return c.DestinationFs
+}
+
 func (c *Config) Theme() string {
 	return c.Root.DefaultTheme()
 }
@@ -20,6 +20,8 @@ func (c *Config) PublishDir() string {
 
 func (c *Config) ResourceDir() string { return c.Root.RootConfig.ResourceDir }
 
+func (c *Config) ContentDir() string { return c.Root.RootConfig.ContentDir }
+
 func (c *Config) AbsoluteResourcesDir() string {
 	absResourcesDir := paths.AbsPathify(c.WorkingDir(), c.Root.RootConfig.ResourceDir)
 	if !strings.HasSuffix(absResourcesDir, paths.FilePathSeparator) {
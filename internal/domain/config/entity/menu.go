@@ -4,8 +4,30 @@ import "github.com/mdfriday/hugoverse/internal/domain/config/valueobject"
 
 type Menu struct {
 	Menus map[string][]valueobject.MenuConfig
+
+	// LanguageMenus holds the menus defined per language, keyed by language.
+	LanguageMenus map[string]map[string][]valueobject.MenuConfig
 }
 
 func (m Menu) AllMenus() map[string][]valueobject.MenuConfig {
 	return m.Menus
 }
+
+// LanguageMenuConfigs returns the site menus merged with the menus
+// defined for the given language.
+func (m Menu) LanguageMenuConfigs(lang string) map[string][]valueobject.MenuConfig {
+	lm, found := m.LanguageMenus[lang]
+	if !found {
+		return m.Menus
+	}
+
+	menus := make(map[string][]valueobject.MenuConfig, len(m.Menus)+len(lm))
+	for name, entries := range m.Menus {
+		menus[name] = append(menus[name], entries...)
+	}
+	for name, entries := range lm {
+		menus[name] = append(menus[name], entries...)
+	}
+
+	return menus
+}
@@ -44,3 +44,11 @@ func (r Root) ConfigParams() map[string]any {
 func (r Root) SiteTitle() string {
 	return r.RootConfig.Title
 }
+
+func (r Root) IsGitInfoEnabled() bool {
+	return r.RootConfig.EnableGitInfo
+}
+
+func (r Root) SectionPagesMenu() string {
+	return r.RootConfig.SectionPagesMenu
+}
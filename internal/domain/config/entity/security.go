@@ -23,3 +23,29 @@ func (s Security) CheckAllowedGetEnv(name string) error {
 	}
 	return nil
 }
+
+// CheckAllowedHTTPURL checks the URL against the security.http.urls policy
+// used in resources.GetRemote.
+func (s Security) CheckAllowedHTTPURL(url string) error {
+	if !s.HTTP.URLs.Accept(url) {
+		return &hexec.AccessDeniedError{
+			Name:     url,
+			Path:     "security.http.urls",
+			Policies: hexec.ToTOML(s.SecurityConfig),
+		}
+	}
+	return nil
+}
+
+// CheckAllowedHTTPMethod checks the HTTP method against the
+// security.http.methods policy used in resources.GetRemote.
+func (s Security) CheckAllowedHTTPMethod(method string) error {
+	if !s.HTTP.Methods.Accept(method) {
+		return &hexec.AccessDeniedError{
+			Name:     method,
+			Path:     "security.http.methods",
+			Policies: hexec.ToTOML(s.SecurityConfig),
+		}
+	}
+	return nil
+}
@@ -25,6 +25,9 @@ type ConfigLoader struct {
 
 	BaseDirs valueobject.BaseDirs
 
+	// Overrides are set on top of the config file.
+	Overrides map[string]any
+
 	Logger loggers.Logger
 }
 
@@ -33,6 +36,9 @@ func (cl *ConfigLoader) loadConfigByDefault() (config.Provider, error) {
 	if err := cl.loadProvider(filename); err != nil {
 		return nil, err
 	}
+	for k, v := range cl.Overrides {
+		cl.Cfg.Set(k, v)
+	}
 	if err := cl.applyDefaultConfig(); err != nil {
 		return nil, err
 	}
@@ -178,12 +184,24 @@ func (cl *ConfigLoader) decodeConfig(p config.Provider, target *entity.Config) e
 	}
 	target.Security.SecurityConfig = sec
 
+	build, err := valueobject.DecodeBuildConfig(p)
+	if err != nil {
+		return err
+	}
+	target.Build.BuildConfig = build
+
 	menus, err := valueobject.DecodeMenuConfig(p)
 	if err != nil {
 		return err
 	}
 	target.Menu.Menus = menus
 
+	langMenus, err := valueobject.DecodeLanguageMenuConfig(p)
+	if err != nil {
+		return err
+	}
+	target.Menu.LanguageMenus = langMenus
+
 	svc, err := valueobject.DecodeServiceConfig(p)
 	if err != nil {
 		return err
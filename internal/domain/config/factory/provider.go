@@ -18,14 +18,29 @@ const (
 
 func LoadConfig() (*entity.Config, error) {
 	currentDir, _ := os.Getwd()
-	workingDir := filepath.Clean(currentDir)
+	return LoadConfigFromDir(currentDir)
+}
+
+// LoadConfigFromDir loads the config of the project in workingDir, which
+// every path of the site is resolved against, so that several projects can
+// be loaded side by side without changing the process working directory.
+func LoadConfigFromDir(workingDir string) (*entity.Config, error) {
+	return LoadConfigFromFs(&afero.OsFs{}, workingDir, nil)
+}
+
+// LoadConfigFromFs loads the config of the project in workingDir on fs,
+// with overrides set on top of the config file, e.g. baseURL or
+// params.env.
+func LoadConfigFromFs(fs afero.Fs, workingDir string, overrides map[string]any) (*entity.Config, error) {
+	workingDir = filepath.Clean(workingDir)
 
 	l := &ConfigLoader{
 		SourceDescriptor: &sourceDescriptor{
-			fs:       &afero.OsFs{},
+			fs:       fs,
 			filename: path.Join(workingDir, "config.toml"),
 		},
-		Cfg: valueobject.NewDefaultProvider(),
+		Overrides: overrides,
+		Cfg:       valueobject.NewDefaultProvider(),
 		BaseDirs: valueobject.BaseDirs{
 			WorkingDir: workingDir,
 			ThemesDir:  paths.AbsPathify(workingDir, DefaultThemesDir),
@@ -53,6 +68,7 @@ func LoadConfig() (*entity.Config, error) {
 		Root:      entity.Root{},
 		Caches:    entity.Caches{},
 		Security:  entity.Security{},
+		Build:     entity.Build{},
 		Menu:      entity.Menu{},
 		Module:    entity.Module{},
 		Service:   entity.Service{},
@@ -0,0 +1,34 @@
+package valueobject
+
+import (
+	"github.com/mdfriday/hugoverse/internal/domain/config"
+	"github.com/mitchellh/mapstructure"
+)
+
+const buildConfigKey = "build"
+
+// BuildConfig holds some build related configuration.
+type BuildConfig struct {
+	// When enabled, will collect and write a hugo_stats.json with some build
+	// related aggregated data (e.g. CSS class names).
+	BuildStats BuildStats
+}
+
+// BuildStats configures if and what to write to the hugo_stats.json file.
+type BuildStats struct {
+	Enable         bool
+	DisableTags    bool
+	DisableClasses bool
+	DisableIDs     bool
+}
+
+func DecodeBuildConfig(cfg config.Provider) (BuildConfig, error) {
+	var c BuildConfig
+	if !cfg.IsSet(buildConfigKey) {
+		return c, nil
+	}
+
+	err := mapstructure.WeakDecode(cfg.GetStringMap(buildConfigKey), &c)
+
+	return c, err
+}
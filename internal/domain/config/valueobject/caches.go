@@ -49,6 +49,11 @@ var defaultCacheConfigs = CachesConfig{
 		MaxAge: -1, // Never expire
 		Dir:    cacheDirProject,
 	},
+	// The persistent partialCached store is opt-in, set a max age to enable it.
+	cache.KeyPartials: FileCacheConfig{
+		MaxAge: 0,
+		Dir:    cacheDirProject,
+	},
 }
 
 type CachesConfig map[string]FileCacheConfig
@@ -1,6 +1,7 @@
 package valueobject
 
 import (
+	"fmt"
 	"github.com/mdfriday/hugoverse/internal/domain/config"
 	"github.com/mdfriday/hugoverse/pkg/maps"
 	"github.com/mitchellh/mapstructure"
@@ -26,14 +27,62 @@ func DecodeMenuConfig(cfg config.Provider) (map[string][]MenuConfig, error) {
 	return decodeMenuConfig(cfg)
 }
 
+// decodeMenuConfig decodes both the menu and the menus key, Hugo accepts
+// either.
 func decodeMenuConfig(cfg config.Provider) (map[string][]MenuConfig, error) {
 	menuMap := make(map[string][]MenuConfig)
 
-	if !cfg.IsSet("menu") {
-		return menuMap, nil
+	for _, key := range []string{"menus", "menu"} {
+		if !cfg.IsSet(key) {
+			continue
+		}
+		menus, err := decodeMenus(cfg.Get(key))
+		if err != nil {
+			return menuMap, err
+		}
+		for name, entries := range menus {
+			menuMap[name] = append(menuMap[name], entries...)
+		}
+	}
+
+	return menuMap, nil
+}
+
+// DecodeLanguageMenuConfig decodes the menus defined per language, e.g.
+// languages.en.menus.main.
+func DecodeLanguageMenuConfig(cfg config.Provider) (map[string]map[string][]MenuConfig, error) {
+	langMenus := make(map[string]map[string][]MenuConfig)
+
+	for lang, v := range cfg.GetStringMap("languages") {
+		lm, err := maps.ToStringMapE(v)
+		if err != nil {
+			continue
+		}
+		lm = maps.CleanConfigStringMap(lm)
+
+		for _, key := range []string{"menus", "menu"} {
+			m, found := lm[key]
+			if !found {
+				continue
+			}
+			menus, err := decodeMenus(m)
+			if err != nil {
+				return nil, fmt.Errorf("language %q: %w", lang, err)
+			}
+			if langMenus[lang] == nil {
+				langMenus[lang] = make(map[string][]MenuConfig)
+			}
+			for name, entries := range menus {
+				langMenus[lang][name] = append(langMenus[lang][name], entries...)
+			}
+		}
 	}
 
-	m := cfg.Get("menu")
+	return langMenus, nil
+}
+
+func decodeMenus(m any) (map[string][]MenuConfig, error) {
+	menuMap := make(map[string][]MenuConfig)
 
 	menus, err := maps.ToStringMapE(m)
 	if err != nil {
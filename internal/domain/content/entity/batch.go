@@ -0,0 +1,364 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/repository"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+)
+
+// BatchChunkSize is the number of ops of a batch applied in one
+// transaction, unless the batch is atomic.
+var BatchChunkSize = 500
+
+// ErrBatchNotApplied is the error of the ops of an atomic batch left out
+// because another op failed.
+var ErrBatchNotApplied = errors.New("not applied, another op of the batch failed")
+
+// readOnlyFields are the fields an update can't set.
+var readOnlyFields = []string{"id", "uuid", "namespace", "slug", "hash", "status", "timestamp"}
+
+// Batch applies ops to the user content types, BatchChunkSize ops to a
+// transaction, and returns the result of each. An op that fails is left
+// out of its transaction, the others are applied. If atomic, ops are all
+// applied in one transaction or none is. The search indexes and the sorted
+// contents are updated once the ops are applied.
+func (c *Content) Batch(ops []*valueobject.BatchOp, atomic bool) []*valueobject.BatchResult {
+	results := make([]*valueobject.BatchResult, len(ops))
+	var valid []int
+	for i, op := range ops {
+		results[i] = &valueobject.BatchResult{Index: i, Op: op.Op, Type: op.Type, ID: op.ID}
+
+		err := op.Check()
+		if err == nil {
+			if _, ok := c.UserTypes[op.Type]; !ok {
+				err = fmt.Errorf("invalid content type %q", op.Type)
+			}
+		}
+		if err != nil {
+			results[i].Error = err.Error()
+			continue
+		}
+		valid = append(valid, i)
+	}
+
+	var changes []*batchChange
+	if atomic {
+		if len(valid) < len(ops) {
+			failBatch(results, ErrBatchNotApplied)
+			return results
+		}
+		changes = c.applyBatch(ops, results, valid, true)
+	} else {
+		for start := 0; start < len(valid); start += BatchChunkSize {
+			end := min(start+BatchChunkSize, len(valid))
+			changes = append(changes, c.applyBatch(ops, results, valid[start:end], false)...)
+		}
+	}
+
+	c.afterBatch(changes)
+
+	return results
+}
+
+// batchChange is what an op applied changed, to be seen once committed.
+type batchChange struct {
+	result *valueobject.BatchResult
+	id     string
+	status content.Status
+
+	// indices are the search index changes of the op.
+	indices []indexChange
+	// sorted is the type to sort again, if any.
+	sorted string
+}
+
+// batchWriteError is an error writing an op, leaving its transaction in
+// an unknown state.
+type batchWriteError struct {
+	err error
+}
+
+func (e *batchWriteError) Error() string {
+	return e.err.Error()
+}
+
+// applyBatch applies the ops at idx in one transaction, and returns what
+// they changed. An op failing before writing anything is skipped, unless
+// atomic. One failing while writing rolls the transaction back, which is
+// then retried without it.
+func (c *Content) applyBatch(ops []*valueobject.BatchOp, results []*valueobject.BatchResult,
+	idx []int, atomic bool) []*batchChange {
+
+	for len(idx) > 0 {
+		var changes []*batchChange
+		failed := -1
+		err := c.Repo.Batch(func(tx repository.Tx) error {
+			changes = changes[:0]
+			for _, i := range idx {
+				ch, err := c.applyOp(tx, ops[i])
+				if err != nil {
+					results[i].Error = err.Error()
+
+					var we *batchWriteError
+					if atomic || errors.As(err, &we) {
+						failed = i
+						return err
+					}
+					continue
+				}
+				ch.result = results[i]
+				changes = append(changes, ch)
+			}
+			return nil
+		})
+
+		if err == nil {
+			for _, ch := range changes {
+				ch.result.ID = ch.id
+				ch.result.Status = string(ch.status)
+			}
+			return changes
+		}
+
+		if failed < 0 {
+			// The commit failed.
+			for _, i := range idx {
+				results[i].Error = err.Error()
+			}
+			return nil
+		}
+		if atomic {
+			for _, i := range idx {
+				if i != failed {
+					results[i].Error = ErrBatchNotApplied.Error()
+				}
+			}
+			return nil
+		}
+
+		rest := make([]int, 0, len(idx)-1)
+		for _, i := range idx {
+			if i != failed && results[i].Error == "" {
+				rest = append(rest, i)
+			}
+		}
+		idx = rest
+	}
+
+	return nil
+}
+
+func failBatch(results []*valueobject.BatchResult, err error) {
+	for _, r := range results {
+		if r.Error == "" {
+			r.Error = err.Error()
+		}
+	}
+}
+
+func (c *Content) applyOp(tx repository.Tx, op *valueobject.BatchOp) (*batchChange, error) {
+	switch op.Op {
+	case valueobject.BatchCreate:
+		return c.batchCreate(tx, op)
+	case valueobject.BatchUpdate:
+		return c.batchUpdate(tx, op)
+	case valueobject.BatchDelete:
+		return c.batchDelete(tx, op)
+	case valueobject.BatchApprove:
+		return c.batchApprove(tx, op)
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+func (c *Content) batchCreate(tx repository.Tx, op *valueobject.BatchOp) (*batchChange, error) {
+	values := cloneValues(op.Values)
+	values.Set("namespace", op.Type)
+	values.Set("status", string(statusOf(op.Status)))
+
+	ci, err := c.DecodeContent(op.Type, values)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.batchNew(tx, op.Type, ci)
+}
+
+func (c *Content) batchNew(tx repository.Tx, contentType string, ci any) (*batchChange, error) {
+	b, err := c.saveNewContent(tx, contentType, ci)
+	if err != nil {
+		return nil, &batchWriteError{err: err}
+	}
+
+	id := strconv.Itoa(ci.(content.Identifiable).ItemID())
+	status := ci.(content.Statusable).ItemStatus()
+	ch := &batchChange{
+		id:      id,
+		status:  status,
+		indices: []indexChange{{ns: GetNamespace(contentType, string(status)), id: id, data: b}},
+	}
+	if status == content.Public {
+		ch.sorted = contentType
+	}
+	return ch, nil
+}
+
+func (c *Content) batchUpdate(tx repository.Tx, op *valueobject.BatchOp) (*batchChange, error) {
+	status := statusOf(op.Status)
+	ns := GetNamespace(op.Type, string(status))
+	ci, err := c.batchGet(tx, op.Type, ns, op.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	values := cloneValues(op.Values)
+	for _, f := range readOnlyFields {
+		values.Del(f)
+	}
+	if err := decodeValues(ci, values); err != nil {
+		return nil, err
+	}
+	if cih, ok := ci.(content.Hashable); ok {
+		cih.SetHash()
+	}
+
+	b, err := c.Marshal(ci)
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.PutContent(ci, b); err != nil {
+		return nil, &batchWriteError{err: err}
+	}
+
+	ch := &batchChange{
+		id:      op.ID,
+		status:  status,
+		indices: []indexChange{{ns: ns, id: op.ID, data: b}},
+	}
+	if status == content.Public {
+		ch.sorted = op.Type
+	}
+	return ch, nil
+}
+
+func (c *Content) batchDelete(tx repository.Tx, op *valueobject.BatchOp) (*batchChange, error) {
+	status := statusOf(op.Status)
+	ns := GetNamespace(op.Type, string(status))
+	ci, err := c.batchGet(tx, op.Type, ns, op.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := batchDeleteItem(tx, ns, op.ID, ci); err != nil {
+		return nil, err
+	}
+
+	ch := &batchChange{
+		id:      op.ID,
+		status:  status,
+		indices: []indexChange{{ns: ns, id: op.ID}},
+	}
+	if status == content.Public {
+		ch.sorted = op.Type
+	}
+	return ch, nil
+}
+
+// batchApprove moves a pending item to the public items, where it gets a
+// new id.
+func (c *Content) batchApprove(tx repository.Tx, op *valueobject.BatchOp) (*batchChange, error) {
+	ns := GetNamespace(op.Type, string(content.Pending))
+	ci, err := c.batchGet(tx, op.Type, ns, op.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := batchDeleteItem(tx, ns, op.ID, ci); err != nil {
+		return nil, err
+	}
+
+	ci.(content.Statusable).SetItemStatus(content.Public)
+	ch, err := c.batchNew(tx, op.Type, ci)
+	if err != nil {
+		return nil, err
+	}
+	ch.indices = append(ch.indices, indexChange{ns: ns, id: op.ID})
+	return ch, nil
+}
+
+func (c *Content) batchGet(tx repository.Tx, contentType, ns, id string) (any, error) {
+	data, err := tx.GetContent(ns, id)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("%s %s not found", ns, id)
+	}
+
+	t, ok := c.GetContentCreator(contentType)
+	if !ok {
+		return nil, errors.New("invalid content type")
+	}
+	ci := t()
+	if err := json.Unmarshal(data, ci); err != nil {
+		return nil, err
+	}
+	return ci, nil
+}
+
+func batchDeleteItem(tx repository.Tx, ns, id string, ci any) error {
+	cis, ok := ci.(content.Sluggable)
+	if !ok {
+		return errors.New("content type does not implement Sluggable")
+	}
+	hash := ""
+	if cih, ok := ci.(content.Hashable); ok {
+		hash = cih.ItemHash()
+	}
+
+	if err := tx.DeleteContent(ns, id, cis.ItemSlug(), hash); err != nil {
+		return &batchWriteError{err: err}
+	}
+	return nil
+}
+
+// afterBatch updates the search indexes and sorted contents with changes.
+func (c *Content) afterBatch(changes []*batchChange) {
+	var indices []indexChange
+	sorted := make(map[string]bool)
+	for _, ch := range changes {
+		indices = append(indices, ch.indices...)
+		if ch.sorted != "" {
+			sorted[ch.sorted] = true
+		}
+	}
+
+	if err := c.Search.updateIndices(indices); err != nil {
+		c.Log.Errorln("[search] Batch updateIndices Error:", err)
+	}
+	for t := range sorted {
+		if err := c.SortContent(t); err != nil {
+			c.Log.Errorln("sort content err: ", err)
+		}
+	}
+}
+
+func statusOf(s string) content.Status {
+	if s == "" {
+		return content.Public
+	}
+	return content.Status(s)
+}
+
+func cloneValues(v url.Values) url.Values {
+	c := make(url.Values, len(v))
+	for k, vs := range v {
+		c[k] = append([]string(nil), vs...)
+	}
+	return c
+}
@@ -13,6 +13,7 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 )
@@ -42,7 +43,7 @@ func (c *Content) BuildTarget(contentType, id, status string) (string, error) {
 			Content: []byte("module github.com/mdfriday/temp-build\n\ngo 1.18"),
 		}
 
-		confFile, err := c.Hugo.siteConfigFile(site, dir)
+		confFile, err := c.siteConfigFile(site, dir)
 		if err != nil {
 			c.Log.Errorf("failed to get site config file: %v", err)
 			writer.close()
@@ -56,7 +57,13 @@ func (c *Content) BuildTarget(contentType, id, status string) (string, error) {
 			return "", err
 		}
 
-		if err := c.writeSiteResource(site.ID, dir); err != nil {
+		if err := c.writeSiteData(site, dir, writer.files); err != nil {
+			c.Log.Errorf("failed to write site data: %v", err)
+			writer.close()
+			return "", err
+		}
+
+		if err := c.writeSiteResource(site.ID, dir, ""); err != nil {
 			c.Log.Errorf("failed to write site resources: %v", err)
 			writer.close()
 			return "", err
@@ -98,6 +105,60 @@ func (c *Content) BuildTarget(contentType, id, status string) (string, error) {
 	return "", errors.New("only site could be built")
 }
 
+// ConfigTarget writes the config of the site id, without its content, to a
+// new directory, enough to load the site's templates. The returned func
+// removes the directory.
+func (c *Content) ConfigTarget(id string) (string, func(), error) {
+	content, err := c.getContent("Site", id)
+	if err != nil {
+		return "", nil, err
+	}
+	site, ok := content.(*valueobject.Site)
+	if !ok {
+		return "", nil, errors.New("only site could be built")
+	}
+
+	dir, clean, err := c.Hugo.tempDir(site.Title, c.Repo.UserDataDir())
+	if err != nil {
+		return "", nil, err
+	}
+
+	confFile, err := c.siteConfigFile(site, dir)
+	if err == nil {
+		err = afero.WriteFile(c.Hugo.Fs, path.Join(dir, "go.mod"),
+			[]byte("module github.com/mdfriday/temp-build\n\ngo 1.18"), 0o644)
+	}
+	if err == nil {
+		err = afero.WriteFile(c.Hugo.Fs, confFile.Path, confFile.Content, 0o644)
+	}
+	if err != nil {
+		clean()
+		return "", nil, err
+	}
+
+	return dir, clean, nil
+}
+
+// ArchetypeTarget is ConfigTarget with the archetypes of the site id, its
+// resources in the archetypes directory, to create content from.
+func (c *Content) ArchetypeTarget(id string) (string, func(), error) {
+	dir, clean, err := c.ConfigTarget(id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	siteID, err := strconv.Atoi(id)
+	if err == nil {
+		err = c.writeSiteResource(siteID, dir, "archetypes")
+	}
+	if err != nil {
+		clean()
+		return "", nil, err
+	}
+
+	return dir, clean, nil
+}
+
 func (c *Content) writeSitePosts(siteId int, dir string, writerFiles chan *valueobject.File) error {
 	q := fmt.Sprintf(`site%d`, siteId)
 
@@ -139,7 +200,9 @@ func (c *Content) writeSitePosts(siteId int, dir string, writerFiles chan *value
 	return nil
 }
 
-func (c *Content) writeSiteResource(siteId int, dir string) error {
+// writeSiteResource writes the resources of the site with a path in the
+// directory under, or all of them if under is empty, to dir.
+func (c *Content) writeSiteResource(siteId int, dir, under string) error {
 	q := fmt.Sprintf(`site%d`, siteId)
 
 	siteResources, err := c.Repo.ContentByPrefix(GetNamespace("SiteResource", ""), q)
@@ -152,6 +215,9 @@ func (c *Content) writeSiteResource(siteId int, dir string) error {
 		if err := json.Unmarshal(data, &sr); err != nil {
 			return err
 		}
+		if under != "" && !strings.HasPrefix(path.Clean("/"+sr.Path), "/"+under+"/") {
+			continue
+		}
 
 		res, err := c.getResource(sr.Resource)
 		if err != nil {
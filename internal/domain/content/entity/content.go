@@ -4,11 +4,9 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/gorilla/schema"
 	"github.com/mdfriday/hugoverse/internal/domain/content"
 	"github.com/mdfriday/hugoverse/internal/domain/content/repository"
 	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
-	"github.com/mdfriday/hugoverse/pkg/form"
 	"github.com/mdfriday/hugoverse/pkg/loggers"
 	"log"
 	"net/url"
@@ -31,6 +29,15 @@ type Content struct {
 	Log loggers.Logger
 }
 
+// ForRepo returns a copy of c on repo, e.g. the store of a user apart from
+// the one of the request being served.
+func (c *Content) ForRepo(repo repository.Repository) *Content {
+	cc := *c
+	cc.Repo = repo
+	cc.Search = c.Search.withRepo(repo)
+	return &cc
+}
+
 func (c *Content) GetContents(ids []content.Identifier) ([][]byte, error) {
 	var contents [][]byte
 	for _, id := range ids {
@@ -104,16 +111,7 @@ func (c *Content) UpdateContent(contentType string, data url.Values) error {
 	}
 	ci := t()
 
-	d, err := form.Convert(data)
-	if err != nil {
-		return err
-	}
-	// Decode Content
-	dec := schema.NewDecoder()
-	dec.SetAliasTag("json")     // allows simpler struct tagging when creating a content type
-	dec.IgnoreUnknownKeys(true) // will skip over form values submitted, but not in struct
-	err = dec.Decode(ci, d)
-	if err != nil {
+	if err := decodeValues(ci, data); err != nil {
 		return err
 	}
 
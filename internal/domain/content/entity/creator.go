@@ -14,61 +14,128 @@ import (
 )
 
 func (c *Content) NewContent(contentType string, data url.Values) (string, error) {
+	ci, err := c.DecodeContent(contentType, data)
+	if err != nil {
+		return "", err
+	}
+
+	return c.newContent(contentType, ci)
+}
+
+// DecodeContent decodes the form values data into a new item of contentType.
+func (c *Content) DecodeContent(contentType string, data url.Values) (any, error) {
 	t, ok := c.GetContentCreator(contentType)
 	if !ok {
-		return "", errors.New("invalid content type")
+		return nil, errors.New("invalid content type")
 	}
 	ci := t()
 
+	if err := decodeValues(ci, data); err != nil {
+		return nil, err
+	}
+	return ci, nil
+}
+
+func decodeValues(ci any, data url.Values) error {
 	d, err := form.Convert(data)
 	if err != nil {
-		return "", err
+		return err
 	}
 	// Decode Content
 	dec := schema.NewDecoder()
 	dec.SetAliasTag("json")     // allows simpler struct tagging when creating a content type
 	dec.IgnoreUnknownKeys(true) // will skip over form values submitted, but not in struct
-	err = dec.Decode(ci, d)
+	if err := dec.Decode(ci, d); err != nil {
+		return err
+	}
+
+	if ext, ok := ci.(content.Extensible); ok {
+		return dec.Decode(ext.Fields(), d)
+	}
+	return nil
+}
+
+func (c *Content) newContent(contentType string, ci any) (string, error) {
+	b, err := c.saveNewContent(c.Repo, contentType, ci)
 	if err != nil {
 		return "", err
 	}
 
-	return c.newContent(contentType, ci)
+	return c.afterNewContent(contentType, ci, b), nil
 }
 
-func (c *Content) newContent(contentType string, ci any) (string, error) {
+// afterNewContent sorts the contents of contentType and indexes ci, newly
+// stored as b, and returns its id.
+func (c *Content) afterNewContent(contentType string, ci any, b []byte) string {
+	cii := ci.(content.Identifiable)
+	cis := ci.(content.Statusable)
+
+	if cis.ItemStatus() == content.Public {
+		go func() {
+			if err := c.SortContent(contentType); err != nil {
+				log.Println("sort content err: ", err)
+			}
+		}()
+	}
+
+	id := int64(cii.ItemID())
+
+	go func() {
+		// update data in search index
+		if err := c.Search.UpdateIndex(
+			GetNamespace(contentType, string(cis.ItemStatus())),
+			fmt.Sprintf("%d", id), b); err != nil {
+
+			log.Println("[search] UpdateIndex Error:", err)
+		}
+	}()
+
+	return strconv.FormatInt(id, 10)
+}
+
+// contentCreator is what saveNewContent needs of a repository.Repository,
+// or of a repository.Tx.
+type contentCreator interface {
+	NewContent(ci any, data []byte) error
+	NextContentId(ns string) (uint64, error)
+	CheckSlugForDuplicate(namespace string, slug string) (string, error)
+}
+
+// saveNewContent gives ci an id, a uuid and a unique slug, and stores it
+// with w, returning it as stored.
+func (c *Content) saveNewContent(w contentCreator, contentType string, ci any) ([]byte, error) {
 	cii, ok := ci.(content.Identifiable)
 	if ok {
 		uid, err := uuid.NewV4()
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		cii.SetUniqueID(uid)
 
-		id, err := c.Repo.NextContentId(contentType)
+		id, err := w.NextContentId(contentType)
 		if err != nil {
-			return "", err
+			return nil, err
 		}
 		cii.SetItemID(int(id))
 	} else {
-		return "", errors.New("content type does not implement Identifiable")
+		return nil, errors.New("content type does not implement Identifiable")
 	}
 
 	slug, err := valueobject.Slug(cii)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	slug, err = c.Repo.CheckSlugForDuplicate(contentType, slug)
+	slug, err = w.CheckSlugForDuplicate(contentType, slug)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	ciSlug, ok := ci.(content.Sluggable)
 	if ok {
 		ciSlug.SetSlug(slug)
 	} else {
-		return "", errors.New("content type does not implement Sluggable")
+		return nil, errors.New("content type does not implement Sluggable")
 	}
 
 	cis, ok := ci.(content.Statusable)
@@ -77,7 +144,7 @@ func (c *Content) newContent(contentType string, ci any) (string, error) {
 			cis.SetItemStatus(content.Public)
 		}
 	} else {
-		return "", errors.New("content type does not implement Statusable")
+		return nil, errors.New("content type does not implement Statusable")
 	}
 
 	cih, ok := ci.(content.Hashable)
@@ -87,34 +154,14 @@ func (c *Content) newContent(contentType string, ci any) (string, error) {
 
 	b, err := c.Marshal(ci)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	if err := c.Repo.NewContent(ci, b); err != nil {
-		return "", err
-	}
-
-	if cis.ItemStatus() == content.Public {
-		go func() {
-			if err := c.SortContent(contentType); err != nil {
-				log.Println("sort content err: ", err)
-			}
-		}()
+	if err := w.NewContent(ci, b); err != nil {
+		return nil, err
 	}
 
-	id := int64(cii.ItemID())
-
-	go func() {
-		// update data in search index
-		if err := c.Search.UpdateIndex(
-			GetNamespace(contentType, string(cis.ItemStatus())),
-			fmt.Sprintf("%d", id), b); err != nil {
-
-			log.Println("[search] UpdateIndex Error:", err)
-		}
-	}()
-
-	return strconv.FormatInt(id, 10), nil
+	return b, nil
 }
 
 func (c *Content) syncCheck(sp *valueobject.SitePost) {
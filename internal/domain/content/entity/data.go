@@ -0,0 +1,41 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"path"
+)
+
+// writeSiteData writes the data files edited in the CMS to the data
+// directory of the site.
+func (c *Content) writeSiteData(site *valueobject.Site, dir string, writerFiles chan *valueobject.File) error {
+	data, err := c.Repo.ContentByPrefix(GetNamespace("SiteData", ""), fmt.Sprintf(`site%d`, site.ID))
+	if err != nil {
+		return err
+	}
+
+	for _, d := range data {
+		sd := &valueobject.SiteData{}
+		if err := json.Unmarshal(d, sd); err != nil {
+			return err
+		}
+		// The prefix matches site1 for site10 as well.
+		if sd.Site != site.QueryString() {
+			continue
+		}
+
+		p, err := sd.DataPath()
+		if err != nil {
+			return fmt.Errorf("site data %q: %w", sd.Path, err)
+		}
+
+		writerFiles <- &valueobject.File{
+			Fs:      c.Hugo.Fs,
+			Path:    path.Join(dir, "data", p),
+			Content: []byte(sd.Content),
+		}
+	}
+
+	return nil
+}
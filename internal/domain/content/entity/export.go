@@ -0,0 +1,422 @@
+package entity
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+)
+
+// exportItem is an item to export, as stored and decoded.
+type exportItem struct {
+	id   int
+	data []byte
+	ci   any
+}
+
+// Export writes the items of contentType selected by f to w in format, and
+// returns the number of items written. Any type exports to CSV and NDJSON,
+// posts also export to a Hugo project.
+func (c *Content) Export(w io.Writer, contentType string, format valueobject.ExportFormat, f valueobject.ExportFilter) (int, error) {
+	t, ok := c.AllTypes()[contentType]
+	if !ok {
+		return 0, errors.New("invalid content type")
+	}
+	if err := f.Check(); err != nil {
+		return 0, err
+	}
+
+	switch format {
+	case valueobject.ExportCSV, valueobject.ExportNDJSON:
+	case valueobject.ExportHugo:
+		if contentType != "Post" {
+			return 0, fmt.Errorf("only posts export to %s", format)
+		}
+	default:
+		return 0, fmt.Errorf("unknown export format %q", format)
+	}
+
+	items, err := c.exportItems(contentType, t, f)
+	if err != nil {
+		return 0, err
+	}
+
+	switch format {
+	case valueobject.ExportCSV:
+		err = exportCSV(w, t, items)
+	case valueobject.ExportNDJSON:
+		err = exportNDJSON(w, items)
+	case valueobject.ExportHugo:
+		err = c.exportHugo(w, items, f)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return len(items), nil
+}
+
+func (c *Content) exportItems(contentType string, t content.Creator, f valueobject.ExportFilter) ([]*exportItem, error) {
+	var refs map[string]bool
+	if f.Site != "" {
+		var err error
+		if refs, err = c.siteRefs(f.Site); err != nil {
+			return nil, err
+		}
+	}
+
+	var items []*exportItem
+	for _, data := range c.Repo.AllContent(GetNamespace(contentType, string(f.Status))) {
+		ci := t()
+		if err := json.Unmarshal(data, ci); err != nil {
+			return nil, err
+		}
+		cii, ok := ci.(content.Identifiable)
+		if !ok {
+			return nil, fmt.Errorf("type %s isn't identifiable", contentType)
+		}
+
+		if cs, ok := ci.(content.Sortable); ok && !f.Created(cs.Time()) {
+			continue
+		}
+		if refs != nil && !refs[ref(contentType, cii.ItemID())] {
+			continue
+		}
+
+		if f.Item != nil {
+			var err error
+			if data, err = f.Item(ci, data); err != nil {
+				return nil, err
+			}
+			if data == nil {
+				continue
+			}
+			ci = t()
+			if err := json.Unmarshal(data, ci); err != nil {
+				return nil, err
+			}
+		}
+
+		items = append(items, &exportItem{id: cii.ItemID(), data: data, ci: ci})
+	}
+
+	sort.Slice(items, func(i, j int) bool {
+		return items[i].id < items[j].id
+	})
+
+	return items, nil
+}
+
+// siteRefs returns the items of the site with id, the site itself and the
+// user content with a site field set to it, with all the items they refer
+// to, as refs.
+func (c *Content) siteRefs(id string) (map[string]bool, error) {
+	data, err := c.GetContent("Site", id, "")
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, fmt.Errorf("site %s not found", id)
+	}
+	site := "Site:" + id
+
+	refs := map[string]bool{site: true}
+	for _, t := range c.AllContentTypeNames() {
+		for _, data := range c.Repo.AllContent(t) {
+			var m map[string]any
+			if err := json.Unmarshal(data, &m); err != nil {
+				return nil, err
+			}
+			if s, _ := m["site"].(string); parseRef(s) != site {
+				continue
+			}
+
+			if id, ok := m["id"].(float64); ok {
+				refs[ref(t, int(id))] = true
+			}
+			for _, v := range m {
+				if s, ok := v.(string); ok {
+					if r := parseRef(s); r != "" {
+						refs[r] = true
+					}
+				}
+			}
+		}
+	}
+
+	return refs, nil
+}
+
+func ref(contentType string, id int) string {
+	return fmt.Sprintf("%s:%d", contentType, id)
+}
+
+// parseRef returns the ref of the item at the query string s, e.g.
+// Post:1 for /api/content?type=Post&id=1, or "" if s isn't one.
+func parseRef(s string) string {
+	if !strings.HasPrefix(s, "/api/content?") {
+		return ""
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return ""
+	}
+	q := u.Query()
+	if q.Get("type") == "" || q.Get("id") == "" {
+		return ""
+	}
+	return q.Get("type") + ":" + q.Get("id")
+}
+
+func exportNDJSON(w io.Writer, items []*exportItem) error {
+	var b bytes.Buffer
+	for _, it := range items {
+		b.Reset()
+		if err := json.Compact(&b, it.data); err != nil {
+			return err
+		}
+		b.WriteByte('\n')
+		if _, err := w.Write(b.Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exportCSV writes items with a column to a field, in the order of
+// FormatCSV if the type implements content.CSVFormattable, and of its JSON
+// encoding otherwise. Fields that aren't strings or numbers are written
+// as JSON.
+func exportCSV(w io.Writer, t content.Creator, items []*exportItem) error {
+	columns, err := csvColumns(t())
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+
+	row := make([]string, len(columns))
+	for _, it := range items {
+		d := json.NewDecoder(bytes.NewReader(it.data))
+		d.UseNumber()
+		var m map[string]any
+		if err := d.Decode(&m); err != nil {
+			return err
+		}
+
+		for i, col := range columns {
+			switch v := m[col].(type) {
+			case nil:
+				row[i] = ""
+			case string:
+				row[i] = v
+			case json.Number:
+				row[i] = v.String()
+			default:
+				b, err := json.Marshal(v)
+				if err != nil {
+					return err
+				}
+				row[i] = string(b)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func csvColumns(ci any) ([]string, error) {
+	if cf, ok := ci.(content.CSVFormattable); ok {
+		return cf.FormatCSV(), nil
+	}
+
+	b, err := json.Marshal(ci)
+	if err != nil {
+		return nil, err
+	}
+
+	// The keys of the top level object, in order.
+	d := json.NewDecoder(bytes.NewReader(b))
+	if _, err := d.Token(); err != nil {
+		return nil, err
+	}
+	var columns []string
+	for d.More() {
+		k, err := d.Token()
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, k.(string))
+
+		var skip json.RawMessage
+		if err := d.Decode(&skip); err != nil {
+			return nil, err
+		}
+	}
+
+	return columns, nil
+}
+
+// exportHugo writes a gzipped tar of a Hugo project with the posts in
+// items, at the path of their site post, or in content/posts if they have
+// none. Posts with assets are written as page bundles. The config of the
+// site is added if f selects one.
+func (c *Content) exportHugo(w io.Writer, items []*exportItem, f valueobject.ExportFilter) error {
+	paths, err := c.sitePostPaths(f.Site)
+	if err != nil {
+		return err
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+	now := time.Now()
+
+	if f.Site != "" {
+		s, err := c.getContent("Site", f.Site)
+		if err != nil {
+			return err
+		}
+		site, ok := s.(*valueobject.Site)
+		if !ok {
+			return errors.New("invalid site")
+		}
+		conf, err := site.Toml()
+		if err != nil {
+			return err
+		}
+		if err := writeTarFile(tw, "config.toml", conf, now); err != nil {
+			return err
+		}
+	}
+
+	written := make(map[string]bool)
+	for _, it := range items {
+		post := it.ci.(*valueobject.Post)
+
+		// A post taking the path of one written before, or of its bundle,
+		// falls back to its slug, and to its slug and id if that is taken
+		// too.
+		name := paths[ref("Post", post.ID)]
+		if name == "" || written[name] {
+			name = path.Join("content", "posts", post.Slug+".md")
+		}
+		if written[name] {
+			name = path.Join("content", "posts", fmt.Sprintf("%s-%d.md", post.Slug, post.ID))
+		}
+		written[name] = true
+		if len(post.Assets) > 0 {
+			name = bundlePath(name)
+			written[name] = true
+		}
+
+		page, err := post.Page(post.Status == content.Pending)
+		if err != nil {
+			return err
+		}
+		if err := writeTarFile(tw, name, page, time.UnixMilli(post.Updated)); err != nil {
+			return err
+		}
+
+		for _, asset := range post.Assets {
+			_, p, err := parseURL(asset)
+			if err != nil {
+				c.Log.Warnf("skip asset of post %d: %v", post.ID, err)
+				continue
+			}
+			p = filepath.Clean(filepath.FromSlash(p))
+			if !filepath.IsLocal(p) {
+				c.Log.Warnf("skip asset of post %d: %s is not in the upload dir", post.ID, asset)
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(c.Hugo.DirService.UploadDir(), p))
+			if err != nil {
+				c.Log.Warnf("skip asset of post %d: %v", post.ID, err)
+				continue
+			}
+			if err := writeTarFile(tw, path.Join(path.Dir(name), filepath.Base(p)), b, now); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gw.Close()
+}
+
+// sitePostPaths returns the paths of the posts of the site with id, or of
+// all sites if id is empty, by ref. The first site post of a post wins.
+func (c *Content) sitePostPaths(id string) (map[string]string, error) {
+	paths := make(map[string]string)
+	var sps []*valueobject.SitePost
+	for _, data := range c.Repo.AllContent("SitePost") {
+		sp := &valueobject.SitePost{}
+		if err := json.Unmarshal(data, sp); err != nil {
+			return nil, err
+		}
+		if id != "" && parseRef(sp.Site) != "Site:"+id {
+			continue
+		}
+		sps = append(sps, sp)
+	}
+	sort.Slice(sps, func(i, j int) bool {
+		return sps[i].ID < sps[j].ID
+	})
+
+	for _, sp := range sps {
+		r := parseRef(sp.Post)
+		p := path.Clean(strings.TrimPrefix(sp.Path, "/"))
+		if r == "" || paths[r] != "" || !fs.ValidPath(p) || p == "." {
+			continue
+		}
+		paths[r] = p
+	}
+
+	return paths, nil
+}
+
+// bundlePath returns the path of the leaf bundle of the page at name,
+// e.g. content/posts/hello/index.md for content/posts/hello.md.
+func bundlePath(name string) string {
+	base := path.Base(name)
+	if base == "index.md" || base == "_index.md" {
+		return name
+	}
+	return path.Join(strings.TrimSuffix(name, path.Ext(name)), "index.md")
+}
+
+func writeTarFile(tw *tar.Writer, name string, b []byte, modTime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0644,
+		Size:    int64(len(b)),
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(b)
+	return err
+}
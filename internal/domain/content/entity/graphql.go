@@ -0,0 +1,532 @@
+package entity
+
+import (
+	"context"
+	"encoding"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/db"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+	"github.com/mdfriday/hugoverse/pkg/openapi"
+)
+
+// maxPersistedQueries bounds the queries persisted by their hash, the
+// first persisted are dropped first.
+const maxPersistedQueries = 1000
+
+// GraphQL is the read API over the public items of the content types, with
+// the items their reference fields refer to, and over the page trees of the
+// sites built by the CMS.
+//
+// A type T is queried by t(id) and tList(count, offset, order), e.g.
+//
+//	{ sitePostList(count: 5) { total items { path site { title } } } }
+type GraphQL struct {
+	Content   *Content
+	PageTrees content.PageTreeService
+	Log       loggers.Logger
+
+	schema graphql.Schema
+
+	mu        sync.Mutex
+	persisted map[string]string
+	hashes    []string
+	trees     map[string]*cachedPageTree
+}
+
+// gqlRequest is the state of the execution of a query, kept in its
+// context.
+type gqlRequest struct {
+	res http.ResponseWriter
+	req *http.Request
+
+	// items are the items loaded by ref, nil if not found or hidden.
+	items map[string]map[string]any
+	trees map[string]content.PageTree
+
+	mu       sync.Mutex
+	releases []func()
+}
+
+type gqlRequestKey struct{}
+
+func requestOf(ctx context.Context) *gqlRequest {
+	return ctx.Value(gqlRequestKey{}).(*gqlRequest)
+}
+
+func (r *gqlRequest) release() {
+	for _, f := range r.releases {
+		f()
+	}
+}
+
+var gqlNameRe = regexp.MustCompile(`^[_A-Za-z][_0-9A-Za-z]*$`)
+
+var (
+	gqlLong = graphql.NewScalar(graphql.ScalarConfig{
+		Name:        "Long",
+		Description: "A 64-bit integer, e.g. a timestamp in milliseconds.",
+		Serialize:   serializeLong,
+		ParseValue:  serializeLong,
+		ParseLiteral: func(v ast.Value) any {
+			if v, ok := v.(*ast.IntValue); ok {
+				var n int64
+				if _, err := fmt.Sscan(v.Value, &n); err == nil {
+					return n
+				}
+			}
+			return nil
+		},
+	})
+
+	gqlJSON = graphql.NewScalar(graphql.ScalarConfig{
+		Name:         "JSON",
+		Description:  "Any JSON value.",
+		Serialize:    func(v any) any { return v },
+		ParseValue:   func(v any) any { return v },
+		ParseLiteral: func(v ast.Value) any { return v.GetValue() },
+	})
+)
+
+func serializeLong(v any) any {
+	switch v := v.(type) {
+	case float64:
+		if v < math.MinInt64 || v > math.MaxInt64 {
+			return nil
+		}
+		return int64(v)
+	case int:
+		return int64(v)
+	case int64:
+		return v
+	}
+	return nil
+}
+
+// maxPageCount is the max number of items of a page of a list.
+const maxPageCount = 100
+
+// pageArgs are the arguments paginating a list, as for /api/contents.
+var pageArgs = graphql.FieldConfigArgument{
+	"count": &graphql.ArgumentConfig{
+		Type:         graphql.Int,
+		DefaultValue: 10,
+		Description:  fmt.Sprintf("The number of items to return, at most %d.", maxPageCount),
+	},
+	"offset": &graphql.ArgumentConfig{
+		Type:         graphql.Int,
+		DefaultValue: 0,
+		Description:  "The page of count items to return, from 0.",
+	},
+}
+
+// pageCount returns the count of args, up to maxPageCount, which a
+// negative count is too.
+func pageCount(args map[string]any) int {
+	count, _ := args["count"].(int)
+	if count < 0 || count > maxPageCount {
+		return maxPageCount
+	}
+	return count
+}
+
+// paginate returns the total and the items of list in the page of args.
+func paginate[T any](list []T, args map[string]any) map[string]any {
+	count := pageCount(args)
+	offset, _ := args["offset"].(int)
+
+	start := min(count*max(offset, 0), len(list))
+	end := min(start+count, len(list))
+
+	items := make([]any, 0, end-start)
+	for _, it := range list[start:end] {
+		items = append(items, it)
+	}
+	return map[string]any{"total": len(list), "items": items}
+}
+
+func listType(name string, item graphql.Output) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name,
+		Fields: graphql.Fields{
+			"total": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+			"items": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(item))},
+		},
+	})
+}
+
+// BuildSchema builds the schema of the API from the content types, which
+// must all be registered.
+func (g *GraphQL) BuildSchema() error {
+	names := g.Content.AllContentTypeNames()
+	sort.Strings(names)
+
+	taken := map[string]bool{"Query": true, "Long": true, "JSON": true, "pageTree": true}
+	for _, t := range gqlPageTypes {
+		taken[t] = true
+	}
+
+	objects := make(map[string]*graphql.Object)
+	query := graphql.Fields{}
+	for _, name := range names {
+		field := strings.ToLower(name[:1]) + name[1:]
+		if taken[name] || taken[name+"List"] || taken[field] || taken[field+"List"] {
+			g.Log.Errorf("Error exposing content type %s to GraphQL: name taken", name)
+			continue
+		}
+		for _, n := range []string{name, name + "List", field, field + "List"} {
+			taken[n] = true
+		}
+
+		t := g.Content.UserTypes[name]
+		objects[name] = g.contentObject(name, t, objects)
+
+		query[field] = &graphql.Field{
+			Type: objects[name],
+			Args: graphql.FieldConfigArgument{
+				"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				id, _ := p.Args["id"].(string)
+				return orNull(g.item(p.Context, name, id))
+			},
+		}
+		query[field+"List"] = &graphql.Field{
+			Type: listType(name+"List", graphql.NewNonNull(objects[name])),
+			Args: graphql.FieldConfigArgument{
+				"count":  pageArgs["count"],
+				"offset": pageArgs["offset"],
+				"order": &graphql.ArgumentConfig{
+					Type:         graphql.String,
+					DefaultValue: "desc",
+					Description:  "The order of the items by creation time, asc or desc.",
+				},
+			},
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				return orNull(g.items(p.Context, name, t, p.Args))
+			},
+		}
+	}
+
+	g.pageTreeFields(query)
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: query}),
+	})
+	if err != nil {
+		return err
+	}
+	g.schema = schema
+	return nil
+}
+
+// contentObject is the object type of the items of contentType. Its fields
+// are the json fields of the type named as GraphQL requires, reference
+// fields being the items they refer to if their type is exposed too.
+func (g *GraphQL) contentObject(name string, t content.Creator, objects map[string]*graphql.Object) *graphql.Object {
+	return graphql.NewObject(graphql.ObjectConfig{
+		Name: name,
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			ci := t()
+			var refs map[string]string
+			if r, ok := ci.(content.Referencing); ok {
+				refs = r.ReferenceFields()
+			}
+
+			fields := graphql.Fields{}
+			for _, f := range jsonFields(ci) {
+				if !gqlNameRe.MatchString(f.Name) {
+					continue
+				}
+				if to, ok := objects[refs[f.Name]]; ok {
+					fields[f.Name] = g.refField(f, to)
+					continue
+				}
+				fields[f.Name] = &graphql.Field{Type: gqlType(f.Type)}
+			}
+			return fields
+		}),
+	})
+}
+
+func (g *GraphQL) refField(f openapi.Field, to *graphql.Object) *graphql.Field {
+	name := f.Name
+	if f.Type.Kind() == reflect.Slice {
+		return &graphql.Field{
+			Type: graphql.NewList(to),
+			Resolve: func(p graphql.ResolveParams) (any, error) {
+				refs, _ := p.Source.(map[string]any)[name].([]any)
+				var items []any
+				for _, r := range refs {
+					s, _ := r.(string)
+					it, err := g.ref(p.Context, s)
+					if err != nil {
+						return nil, err
+					}
+					if it != nil {
+						items = append(items, it)
+					}
+				}
+				return items, nil
+			},
+		}
+	}
+
+	return &graphql.Field{
+		Type: to,
+		Resolve: func(p graphql.ResolveParams) (any, error) {
+			s, _ := p.Source.(map[string]any)[name].(string)
+			return orNull(g.ref(p.Context, s))
+		},
+	}
+}
+
+// orNull returns it, or a nil any if it's nil, which GraphQL takes as null.
+func orNull(it map[string]any, err error) (any, error) {
+	if it == nil {
+		return nil, err
+	}
+	return it, err
+}
+
+// ref returns the item at the query string s, nil if there is none.
+func (g *GraphQL) ref(ctx context.Context, s string) (map[string]any, error) {
+	t, id, ok := strings.Cut(parseRef(s), ":")
+	if !ok {
+		return nil, nil
+	}
+	return g.item(ctx, t, id)
+}
+
+// item returns the public item of contentType with id, without the fields
+// it omits, or nil if it's not found or hidden.
+func (g *GraphQL) item(ctx context.Context, contentType, id string) (map[string]any, error) {
+	rs := requestOf(ctx)
+	key := contentType + ":" + id
+
+	rs.mu.Lock()
+	it, ok := rs.items[key]
+	rs.mu.Unlock()
+	if ok {
+		return it, nil
+	}
+
+	t, ok := g.Content.UserTypes[contentType]
+	if !ok {
+		return nil, nil
+	}
+	data, err := g.Content.GetContent(contentType, id, "")
+	if err != nil {
+		return nil, err
+	}
+	if data != nil {
+		ci := t()
+		if err := json.Unmarshal(data, ci); err != nil {
+			return nil, err
+		}
+		if it, err = g.visible(rs, ci, data); err != nil {
+			return nil, err
+		}
+	}
+
+	rs.mu.Lock()
+	rs.items[key] = it
+	rs.mu.Unlock()
+	return it, nil
+}
+
+// items returns the total and the page of args of the public items of
+// contentType, read from its sorted items, or nil if the type is hidden.
+// The items hidden are left out of the page.
+func (g *GraphQL) items(ctx context.Context, contentType string, t content.Creator, args map[string]any) (map[string]any, error) {
+	rs := requestOf(ctx)
+	if hidden(rs, t()) {
+		return nil, nil
+	}
+
+	count := pageCount(args)
+	offset, _ := args["offset"].(int)
+	offset = max(offset, 0)
+	order := "desc"
+	if args["order"] == "asc" {
+		order = "asc"
+	}
+	total, page := g.Content.Repo.Query(contentType+"__sorted", db.QueryOptions{Count: count, Offset: offset, Order: order})
+	if count*offset >= total {
+		// Query returns the last page past the end.
+		page = nil
+	}
+
+	items := make([]any, 0, len(page))
+	for _, data := range page {
+		ci := t()
+		if err := json.Unmarshal(data, ci); err != nil {
+			return nil, err
+		}
+		it, err := g.visible(rs, ci, data)
+		if err != nil {
+			return nil, err
+		}
+		if it != nil {
+			items = append(items, it)
+		}
+	}
+	return map[string]any{"total": total, "items": items}, nil
+}
+
+// visible returns data, the item ci, without the fields it omits, or nil
+// if it's hidden.
+func (g *GraphQL) visible(rs *gqlRequest, ci any, data []byte) (map[string]any, error) {
+	if hidden(rs, ci) {
+		return nil, nil
+	}
+
+	var it map[string]any
+	if err := json.Unmarshal(data, &it); err != nil {
+		return nil, err
+	}
+
+	if om, ok := ci.(content.Omittable); ok {
+		fields, err := om.Omit(rs.res, rs.req)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			delete(it, f)
+		}
+	}
+
+	return it, nil
+}
+
+// hidden reports whether ci is content.Hideable and hidden from the client
+// of rs.
+func hidden(rs *gqlRequest, ci any) bool {
+	h, ok := ci.(content.Hideable)
+	if !ok {
+		return false
+	}
+	err := h.Hide(rs.res, rs.req)
+	return !errors.Is(err, content.ErrAllowHiddenItem)
+}
+
+var (
+	jsonMarshaler = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// gqlType is the type of the JSON encoding of values of t, e.g. String
+// for a uuid.UUID, or JSON if it has no GraphQL equivalent.
+func gqlType(t reflect.Type) graphql.Output {
+	if t.Implements(textMarshaler) || reflect.PointerTo(t).Implements(textMarshaler) {
+		return graphql.String
+	}
+	if t.Implements(jsonMarshaler) || reflect.PointerTo(t).Implements(jsonMarshaler) {
+		return gqlJSON
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return graphql.String
+	case reflect.Bool:
+		return graphql.Boolean
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Int:
+		return graphql.Int
+	case reflect.Int64, reflect.Uint32, reflect.Uint, reflect.Uint64:
+		return gqlLong
+	case reflect.Float32, reflect.Float64:
+		return graphql.Float
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return graphql.String
+		}
+		if et := gqlType(t.Elem()); et != gqlJSON {
+			return graphql.NewList(et)
+		}
+	case reflect.Ptr:
+		return gqlType(t.Elem())
+	}
+	return gqlJSON
+}
+
+// Execute runs the query of r for the client of req, looking it up by its
+// hash if r only has a persisted query.
+func (g *GraphQL) Execute(res http.ResponseWriter, req *http.Request, r *valueobject.GraphQLRequest) *graphql.Result {
+	query, err := g.query(r)
+	if err != nil {
+		fe := gqlerrors.NewFormattedError(err.Error())
+		if errors.Is(err, valueobject.ErrPersistedQueryNotFound) {
+			fe.Extensions = map[string]any{"code": "PERSISTED_QUERY_NOT_FOUND"}
+		}
+		return &graphql.Result{Errors: []gqlerrors.FormattedError{fe}}
+	}
+
+	rs := &gqlRequest{
+		res:   res,
+		req:   req,
+		items: make(map[string]map[string]any),
+		trees: make(map[string]content.PageTree),
+	}
+	defer rs.release()
+
+	return graphql.Do(graphql.Params{
+		Schema:         g.schema,
+		RequestString:  query,
+		VariableValues: r.Variables,
+		OperationName:  r.OperationName,
+		Context:        context.WithValue(req.Context(), gqlRequestKey{}, rs),
+	})
+}
+
+// query returns the query of r, persisting it if r has a persisted query
+// with its hash.
+func (g *GraphQL) query(r *valueobject.GraphQLRequest) (string, error) {
+	pq := r.Extensions.PersistedQuery
+	if pq == nil {
+		if r.Query == "" {
+			return "", errors.New("no query")
+		}
+		return r.Query, nil
+	}
+	if err := pq.Check(r.Query); err != nil {
+		return "", err
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if r.Query == "" {
+		query, ok := g.persisted[pq.Hash]
+		if !ok {
+			return "", valueobject.ErrPersistedQueryNotFound
+		}
+		return query, nil
+	}
+
+	if g.persisted == nil {
+		g.persisted = make(map[string]string)
+	}
+	if _, ok := g.persisted[pq.Hash]; !ok {
+		if len(g.hashes) == maxPersistedQueries {
+			delete(g.persisted, g.hashes[0])
+			g.hashes = g.hashes[1:]
+		}
+		g.persisted[pq.Hash] = r.Query
+		g.hashes = append(g.hashes, pq.Hash)
+	}
+	return r.Query, nil
+}
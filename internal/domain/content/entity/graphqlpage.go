@@ -0,0 +1,511 @@
+package entity
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/visitor"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/domain/contenthub"
+)
+
+// gqlPageTypes are the names of the types of the page trees, which content
+// types can't take.
+var gqlPageTypes = []string{"HugoPageTree", "HugoPage", "HugoPageList", "HugoTaxonomy", "HugoTerm"}
+
+// gqlPageTree is the page tree of a site in a language.
+type gqlPageTree struct {
+	tree      content.PageTree
+	lang      string
+	langIndex int
+}
+
+// walk returns the pages of t kept by keep, in the order of their paths.
+func (t *gqlPageTree) walk(keep func(p contenthub.Page) bool) ([]contenthub.Page, error) {
+	var pages []contenthub.Page
+	err := t.tree.WalkPages(t.langIndex, func(p contenthub.Page) error {
+		if p.PageIdentity().PageLanguage() != t.lang {
+			return nil
+		}
+		switch p.Kind() {
+		case contenthub.KindHome, contenthub.KindPage, contenthub.KindSection,
+			contenthub.KindTaxonomy, contenthub.KindTerm:
+		default:
+			return nil
+		}
+		if keep(p) {
+			pages = append(pages, p)
+		}
+		return nil
+	})
+	return pages, err
+}
+
+func (t *gqlPageTree) page(keep func(p contenthub.Page) bool) (any, error) {
+	pages, err := t.walk(keep)
+	if err != nil || len(pages) == 0 {
+		return nil, err
+	}
+	return pages[0], nil
+}
+
+// gqlTaxonomy is a taxonomy of a page tree, e.g. tags.
+type gqlTaxonomy struct {
+	Name  string     `json:"name"`
+	Terms []*gqlTerm `json:"terms"`
+}
+
+// gqlTerm is a term of a taxonomy, with the pages classified with it.
+type gqlTerm struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+
+	pages []contenthub.Page
+}
+
+func (t *gqlPageTree) taxonomies() ([]*gqlTaxonomy, error) {
+	terms := make(map[string]map[string]*gqlTerm)
+	err := t.tree.WalkTaxonomies(t.langIndex, func(taxonomy, term string, p contenthub.OrdinalWeightPage) error {
+		if terms[taxonomy] == nil {
+			terms[taxonomy] = make(map[string]*gqlTerm)
+		}
+		tm, ok := terms[taxonomy][term]
+		if !ok {
+			tm = &gqlTerm{Name: term}
+			terms[taxonomy][term] = tm
+		}
+		tm.pages = append(tm.pages, p.Page())
+		tm.Count++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var taxonomies []*gqlTaxonomy
+	for name, m := range terms {
+		tx := &gqlTaxonomy{Name: name}
+		for _, tm := range m {
+			tx.Terms = append(tx.Terms, tm)
+		}
+		sort.Slice(tx.Terms, func(i, j int) bool { return tx.Terms[i].Name < tx.Terms[j].Name })
+		taxonomies = append(taxonomies, tx)
+	}
+	sort.Slice(taxonomies, func(i, j int) bool { return taxonomies[i].Name < taxonomies[j].Name })
+	return taxonomies, nil
+}
+
+// maxPageTrees bounds the page trees kept loaded, the least recently used
+// being released first.
+const maxPageTrees = 8
+
+// cachedPageTree is the page tree of a site loaded for its content with
+// hash, kept for the queries after while the site is unchanged. It is
+// released once dropped and done with by the queries using it.
+type cachedPageTree struct {
+	hash    string
+	loaded  chan struct{}
+	tree    content.PageTree
+	release func()
+	err     error
+
+	used    int
+	last    time.Time
+	dropped bool
+}
+
+// pageTree returns the page tree of the site with id, kept for a request
+// until done with it, or nil if there is no such site.
+func (g *GraphQL) pageTree(ctx context.Context, id string) (content.PageTree, error) {
+	site, err := g.item(ctx, "Site", id)
+	if err != nil || site == nil {
+		return nil, err
+	}
+
+	rs := requestOf(ctx)
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if t, ok := rs.trees[id]; ok {
+		return t, nil
+	}
+	hash, err := g.siteHash(id)
+	if err != nil {
+		return nil, err
+	}
+	t, err := g.usePageTree(id, hash)
+	if err != nil {
+		return nil, fmt.Errorf("load page tree of site %s: %w", id, err)
+	}
+	rs.trees[id] = t.tree
+	rs.releases = append(rs.releases, func() { g.donePageTree(id, t) })
+	return t.tree, nil
+}
+
+// usePageTree returns the page tree of the site with id for its content
+// with hash, loading it if the one kept is of another hash.
+func (g *GraphQL) usePageTree(id, hash string) (*cachedPageTree, error) {
+	g.mu.Lock()
+	t, ok := g.trees[id]
+	load := !ok || t.hash != hash
+	if load {
+		if ok {
+			g.dropPageTree(id)
+		}
+		if g.trees == nil {
+			g.trees = make(map[string]*cachedPageTree)
+		}
+		t = &cachedPageTree{hash: hash, loaded: make(chan struct{})}
+		g.trees[id] = t
+	}
+	t.used++
+	t.last = time.Now()
+	g.evictPageTrees()
+	g.mu.Unlock()
+
+	if load {
+		t.tree, t.release, t.err = g.PageTrees.PageTree(id, "")
+		close(t.loaded)
+	}
+	<-t.loaded
+
+	if t.err != nil {
+		g.donePageTree(id, t)
+		return nil, t.err
+	}
+	return t, nil
+}
+
+// donePageTree is called by a request done with t, the page tree of the
+// site with id.
+func (g *GraphQL) donePageTree(id string, t *cachedPageTree) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	t.used--
+	if t.err != nil && g.trees[id] == t {
+		g.dropPageTree(id)
+	}
+	if t.dropped && t.used == 0 && t.release != nil {
+		t.release()
+		t.release = nil
+	}
+}
+
+// dropPageTree drops the page tree kept of the site with id, released now
+// if unused. g.mu must be held.
+func (g *GraphQL) dropPageTree(id string) {
+	t := g.trees[id]
+	delete(g.trees, id)
+	t.dropped = true
+	if t.used == 0 && t.release != nil {
+		t.release()
+		t.release = nil
+	}
+}
+
+// evictPageTrees drops the least recently used page trees over
+// maxPageTrees. g.mu must be held.
+func (g *GraphQL) evictPageTrees() {
+	for len(g.trees) > maxPageTrees {
+		var oldest string
+		for id, t := range g.trees {
+			if oldest == "" || t.last.Before(g.trees[oldest].last) {
+				oldest = id
+			}
+		}
+		g.dropPageTree(oldest)
+	}
+}
+
+// siteHash returns a hash of the content of the site with id, the site and
+// the items of the site, with those they refer to, which its page tree is
+// built from.
+func (g *GraphQL) siteHash(id string) (string, error) {
+	refs, err := g.Content.siteRefs(id)
+	if err != nil {
+		return "", err
+	}
+	sorted := make([]string, 0, len(refs))
+	for r := range refs {
+		sorted = append(sorted, r)
+	}
+	sort.Strings(sorted)
+
+	h := sha256.New()
+	for _, r := range sorted {
+		contentType, id, _ := strings.Cut(r, ":")
+		if _, ok := g.Content.AllTypes()[contentType]; !ok {
+			continue
+		}
+		data, err := g.Content.GetContent(contentType, id, "")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(h, "%s %d\n", r, len(data))
+		h.Write(data)
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// QueriesPageTree reports whether the query of r selects a page tree, for
+// such queries may load one. Queries failing to parse select none.
+func (g *GraphQL) QueriesPageTree(r *valueobject.GraphQLRequest) bool {
+	query, err := g.query(r)
+	if err != nil {
+		return false
+	}
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return false
+	}
+
+	found := false
+	visitor.Visit(doc, &visitor.VisitorOptions{
+		Enter: func(p visitor.VisitFuncParams) (string, any) {
+			if f, ok := p.Node.(*ast.Field); ok && f.Name != nil && f.Name.Value == "pageTree" {
+				found = true
+				return visitor.ActionBreak, nil
+			}
+			return visitor.ActionNoChange, nil
+		},
+	}, nil)
+	return found
+}
+
+// pageTreeFields adds pageTree(site, lang) to query, the page tree of a
+// site built by the CMS, with its sections, taxonomies and translations.
+func (g *GraphQL) pageTreeFields(query graphql.Fields) {
+	var page *graphql.Object
+	pageList := graphql.NewObject(graphql.ObjectConfig{
+		Name: "HugoPageList",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"total": &graphql.Field{Type: graphql.NewNonNull(graphql.Int)},
+				"items": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(page)))},
+			}
+		}),
+	})
+
+	pageField := func(typ graphql.Output, fn func(p contenthub.Page) (any, error)) *graphql.Field {
+		return &graphql.Field{
+			Type: typ,
+			Resolve: func(rp graphql.ResolveParams) (any, error) {
+				return fn(rp.Source.(contenthub.Page))
+			},
+		}
+	}
+	pagesField := func(fn func(p contenthub.Page) contenthub.Pages) *graphql.Field {
+		return &graphql.Field{
+			Type: pageList,
+			Args: pageArgs,
+			Resolve: func(rp graphql.ResolveParams) (any, error) {
+				return paginate(fn(rp.Source.(contenthub.Page)), rp.Args), nil
+			},
+		}
+	}
+	output := func(fn func(o contenthub.PageOutput) (any, error)) func(p contenthub.Page) (any, error) {
+		return func(p contenthub.Page) (any, error) {
+			outputs, err := p.PageOutputs()
+			if err != nil || len(outputs) == 0 {
+				return nil, err
+			}
+			return fn(outputs[0])
+		}
+	}
+	date := func(t time.Time) (any, error) {
+		if t.IsZero() {
+			return nil, nil
+		}
+		return t.Format(time.RFC3339), nil
+	}
+	pages := func(ps contenthub.Pages) (any, error) {
+		return []contenthub.Page(ps), nil
+	}
+
+	page = graphql.NewObject(graphql.ObjectConfig{
+		Name: "HugoPage",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"path":  pageField(graphql.String, func(p contenthub.Page) (any, error) { return p.Paths().Base(), nil }),
+				"kind":  pageField(graphql.String, func(p contenthub.Page) (any, error) { return p.Kind(), nil }),
+				"title": pageField(graphql.String, func(p contenthub.Page) (any, error) { return p.Title(), nil }),
+				"description": pageField(graphql.String, func(p contenthub.Page) (any, error) {
+					return p.Description(), nil
+				}),
+				"section": pageField(graphql.String, func(p contenthub.Page) (any, error) { return p.Section(), nil }),
+				"lang": pageField(graphql.String, func(p contenthub.Page) (any, error) {
+					return p.PageIdentity().PageLanguage(), nil
+				}),
+				"weight":      pageField(graphql.Int, func(p contenthub.Page) (any, error) { return p.PageWeight(), nil }),
+				"date":        pageField(graphql.String, func(p contenthub.Page) (any, error) { return date(p.PageDate()) }),
+				"lastmod":     pageField(graphql.String, func(p contenthub.Page) (any, error) { return date(p.PageLastmod()) }),
+				"publishDate": pageField(graphql.String, func(p contenthub.Page) (any, error) { return date(p.PublishDate()) }),
+				"params": pageField(gqlJSON, func(p contenthub.Page) (any, error) {
+					return map[string]any(p.Params()), nil
+				}),
+				"rawContent": pageField(graphql.String, func(p contenthub.Page) (any, error) { return p.RawContent(), nil }),
+				"content": pageField(graphql.String, output(func(o contenthub.PageOutput) (any, error) {
+					c, err := o.Content()
+					if err != nil || c == nil {
+						return nil, err
+					}
+					return fmt.Sprint(c), nil
+				})),
+				"summary": pageField(graphql.String, output(func(o contenthub.PageOutput) (any, error) {
+					return string(o.Summary()), nil
+				})),
+				"tableOfContents": pageField(graphql.String, output(func(o contenthub.PageOutput) (any, error) {
+					return string(o.TableOfContents()), nil
+				})),
+				"wordCount": pageField(graphql.Int, output(func(o contenthub.PageOutput) (any, error) {
+					return o.WordCount(), nil
+				})),
+				"readingTime": pageField(graphql.Int, output(func(o contenthub.PageOutput) (any, error) {
+					return o.ReadingTime(), nil
+				})),
+				"parent": pageField(page, func(p contenthub.Page) (any, error) {
+					if parent := p.Parent(); parent != nil {
+						return parent, nil
+					}
+					return nil, nil
+				}),
+				"pages":        pagesField(contenthub.Page.Pages),
+				"regularPages": pagesField(contenthub.Page.RegularPages),
+				"sections": pageField(graphql.NewList(page), func(p contenthub.Page) (any, error) {
+					var sections contenthub.Pages
+					for _, pp := range p.Pages() {
+						if pp.IsSection() {
+							sections = append(sections, pp)
+						}
+					}
+					return pages(sections)
+				}),
+				"translations": pageField(graphql.NewList(page), func(p contenthub.Page) (any, error) {
+					return pages(p.Translations())
+				}),
+				"terms": &graphql.Field{
+					Type: graphql.NewList(page),
+					Args: graphql.FieldConfigArgument{
+						"taxonomy": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					},
+					Resolve: func(rp graphql.ResolveParams) (any, error) {
+						p := rp.Source.(contenthub.Page)
+						taxonomy, _ := rp.Args["taxonomy"].(string)
+						return pages(p.Terms(p.PageIdentity().PageLanguageIndex(), taxonomy))
+					},
+				},
+			}
+		}),
+	})
+
+	term := graphql.NewObject(graphql.ObjectConfig{
+		Name: "HugoTerm",
+		Fields: graphql.Fields{
+			"name":  &graphql.Field{Type: graphql.String},
+			"count": &graphql.Field{Type: graphql.Int},
+			"pages": &graphql.Field{
+				Type: pageList,
+				Args: pageArgs,
+				Resolve: func(rp graphql.ResolveParams) (any, error) {
+					return paginate(rp.Source.(*gqlTerm).pages, rp.Args), nil
+				},
+			},
+		},
+	})
+	taxonomy := graphql.NewObject(graphql.ObjectConfig{
+		Name: "HugoTaxonomy",
+		Fields: graphql.Fields{
+			"name":  &graphql.Field{Type: graphql.String},
+			"terms": &graphql.Field{Type: graphql.NewList(term)},
+		},
+	})
+
+	treeField := func(typ graphql.Output, args graphql.FieldConfigArgument, fn func(t *gqlPageTree, args map[string]any) (any, error)) *graphql.Field {
+		return &graphql.Field{
+			Type: typ,
+			Args: args,
+			Resolve: func(rp graphql.ResolveParams) (any, error) {
+				return fn(rp.Source.(*gqlPageTree), rp.Args)
+			},
+		}
+	}
+
+	tree := graphql.NewObject(graphql.ObjectConfig{
+		Name: "HugoPageTree",
+		Fields: graphql.Fields{
+			"lang": treeField(graphql.String, nil, func(t *gqlPageTree, _ map[string]any) (any, error) {
+				return t.lang, nil
+			}),
+			"languages": treeField(graphql.NewList(graphql.String), nil, func(t *gqlPageTree, _ map[string]any) (any, error) {
+				var langs []string
+				for _, i := range t.tree.LanguageIndexes() {
+					langs = append(langs, t.tree.GetLanguageByIndex(i))
+				}
+				return langs, nil
+			}),
+			"home": treeField(page, nil, func(t *gqlPageTree, _ map[string]any) (any, error) {
+				return t.page(contenthub.Page.IsHome)
+			}),
+			"page": treeField(page, graphql.FieldConfigArgument{
+				"path": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String), Description: "The path of the page, e.g. /posts/hello."},
+			}, func(t *gqlPageTree, args map[string]any) (any, error) {
+				return t.page(func(p contenthub.Page) bool { return p.Paths().Base() == args["path"] })
+			}),
+			"pages": treeField(pageList, graphql.FieldConfigArgument{
+				"kind":    &graphql.ArgumentConfig{Type: graphql.String, Description: "The kind of the pages, e.g. page or section."},
+				"section": &graphql.ArgumentConfig{Type: graphql.String, Description: "The section of the pages, e.g. posts."},
+				"count":   pageArgs["count"],
+				"offset":  pageArgs["offset"],
+			}, func(t *gqlPageTree, args map[string]any) (any, error) {
+				kind, _ := args["kind"].(string)
+				section, hasSection := args["section"].(string)
+				pages, err := t.walk(func(p contenthub.Page) bool {
+					return (kind == "" || p.Kind() == kind) && (!hasSection || p.Section() == section)
+				})
+				if err != nil {
+					return nil, err
+				}
+				return paginate(pages, args), nil
+			}),
+			"taxonomies": treeField(graphql.NewList(taxonomy), nil, func(t *gqlPageTree, _ map[string]any) (any, error) {
+				return t.taxonomies()
+			}),
+		},
+	})
+
+	query["pageTree"] = &graphql.Field{
+		Type:        tree,
+		Description: "The page tree of a site built by the CMS, in lang, the default language of the site if not set.",
+		Args: graphql.FieldConfigArgument{
+			"site": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+			"lang": &graphql.ArgumentConfig{Type: graphql.String},
+		},
+		Resolve: func(rp graphql.ResolveParams) (any, error) {
+			id, _ := rp.Args["site"].(string)
+			t, err := g.pageTree(rp.Context, id)
+			if err != nil || t == nil {
+				return nil, err
+			}
+
+			lang, _ := rp.Args["lang"].(string)
+			if lang == "" {
+				lang = t.DefaultLanguage()
+			}
+			i, err := t.GetLanguageIndex(lang)
+			if err != nil {
+				return nil, fmt.Errorf("unknown language %q", lang)
+			}
+			return &gqlPageTree{tree: t, lang: lang, langIndex: i}, nil
+		},
+	}
+}
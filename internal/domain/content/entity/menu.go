@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+)
+
+// siteConfigFile creates the site config file including the menu trees
+// edited in the CMS.
+func (c *Content) siteConfigFile(site *valueobject.Site, dir string) (*valueobject.File, error) {
+	menus, err := c.siteMenus(site)
+	if err != nil {
+		c.Log.Errorf("failed to get site menus: %v", err)
+		return nil, err
+	}
+	site.SetSiteMenus(menus)
+
+	return c.Hugo.siteConfigFile(site, dir)
+}
+
+func (c *Content) siteMenus(site *valueobject.Site) ([]*valueobject.SiteMenu, error) {
+	data, err := c.Repo.ContentByPrefix(GetNamespace("SiteMenu", ""), fmt.Sprintf(`site%d`, site.ID))
+	if err != nil {
+		return nil, err
+	}
+
+	var menus []*valueobject.SiteMenu
+	for _, d := range data {
+		sm := &valueobject.SiteMenu{}
+		if err := json.Unmarshal(d, sm); err != nil {
+			return nil, err
+		}
+		// The prefix matches site1 for site10 as well.
+		if sm.Site != site.QueryString() {
+			continue
+		}
+		menus = append(menus, sm)
+	}
+
+	return menus, nil
+}
@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/pkg/openapi"
+)
+
+// jsonFields returns the fields of the JSON encoding of ci, those of the
+// struct of its runtime fields too if it's content.Extensible.
+func jsonFields(ci any) []openapi.Field {
+	fields := openapi.Fields(reflect.TypeOf(ci))
+	if ext, ok := ci.(content.Extensible); ok {
+		fields = append(fields, openapi.Fields(reflect.TypeOf(ext.Fields()))...)
+	}
+
+	seen := make(map[string]bool)
+	var unique []openapi.Field
+	for _, f := range fields {
+		if !seen[f.Name] {
+			seen[f.Name] = true
+			unique = append(unique, f)
+		}
+	}
+	return unique
+}
+
+// Schemas returns the OpenAPI schemas of the items of the content types, by
+// type name. Their properties are the json fields of the type, reference
+// fields being the query strings of the items they refer to.
+func (c *Content) Schemas() map[string]*openapi.Schema {
+	schemas := make(map[string]*openapi.Schema)
+	for name, t := range c.AllContentTypes() {
+		ci := t()
+		var refs map[string]string
+		if r, ok := ci.(content.Referencing); ok {
+			refs = r.ReferenceFields()
+		}
+
+		properties := make(map[string]*openapi.Schema)
+		for _, f := range jsonFields(ci) {
+			s := openapi.SchemaOf(f.Type)
+			if to, ok := refs[f.Name]; ok {
+				ref := s
+				if s.Type == "array" {
+					ref = s.Items
+				}
+				ref.Description = fmt.Sprintf("A reference to a %s, e.g. /api/content?type=%s&id=1", to, to)
+			}
+			properties[f.Name] = s
+		}
+		schemas[name] = openapi.Object(properties)
+	}
+	return schemas
+}
@@ -41,7 +41,13 @@ func (c *Content) PreviewTarget(contentType, id, status string) (string, string,
 			return "", "", err
 		}
 
-		if err := c.writeSiteResource(site.ID, dir); err != nil {
+		if err := c.writeSiteData(site, dir, writer.files); err != nil {
+			c.Log.Errorf("failed to write site data: %v", err)
+			writer.close()
+			return "", "", err
+		}
+
+		if err := c.writeSiteResource(site.ID, dir, ""); err != nil {
 			c.Log.Errorf("failed to write site resources: %v", err)
 			writer.close()
 			return "", "", err
@@ -75,7 +81,7 @@ func (c *Content) sitePreviewConfigFile(site *valueobject.Site, dir string) (*va
 	}
 	site.BaseURL = baseURL
 
-	f, err := c.Hugo.siteConfigFile(site, dir)
+	f, err := c.siteConfigFile(site, dir)
 	return f, previewPubDir, err
 }
 
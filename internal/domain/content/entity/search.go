@@ -2,6 +2,7 @@ package entity
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/blevesearch/bleve"
 	"github.com/blevesearch/bleve/search/query"
@@ -44,6 +45,28 @@ type Search struct {
 
 	mu         sync.Mutex
 	IndicesMap map[string]*CacheIndex
+
+	// shared is the Search holding the open indexes of the copies made
+	// by withRepo, as an index can be open once only.
+	shared *Search
+}
+
+// withRepo returns a copy of s on repo, sharing the open indexes with s.
+func (s *Search) withRepo(repo repository.Repository) *Search {
+	return &Search{
+		TypeService: s.TypeService,
+		Repo:        repo,
+		Log:         s.Log,
+		shared:      s.indices(),
+	}
+}
+
+// indices returns the Search holding the open indexes.
+func (s *Search) indices() *Search {
+	if s.shared != nil {
+		return s.shared
+	}
+	return s
 }
 
 func (s *Search) getSearchPath(ns string) string {
@@ -63,10 +86,11 @@ func (s *Search) resetDBTimer(index *CacheIndex) {
 }
 
 func (s *Search) cleanupIdleDB(idleIndex *CacheIndex) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	is := s.indices()
+	is.mu.Lock()
+	defer is.mu.Unlock()
 
-	for searchPath, idx := range s.IndicesMap {
+	for searchPath, idx := range is.IndicesMap {
 		if idx == idleIndex {
 			err := idx.close()
 			if err != nil {
@@ -74,20 +98,21 @@ func (s *Search) cleanupIdleDB(idleIndex *CacheIndex) {
 				return
 			}
 
-			delete(s.IndicesMap, searchPath)
-			s.Log.Printf("Clean search index %s, %d", searchPath, len(s.IndicesMap))
+			delete(is.IndicesMap, searchPath)
+			s.Log.Printf("Clean search index %s, %d", searchPath, len(is.IndicesMap))
 			return
 		}
 	}
 }
 
 func (s *Search) getSearchIndex(ns string) (bleve.Index, error) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	is := s.indices()
+	is.mu.Lock()
+	defer is.mu.Unlock()
 
 	searchPath := s.getSearchPath(ns)
 
-	if idx, ok := s.IndicesMap[searchPath]; ok {
+	if idx, ok := is.IndicesMap[searchPath]; ok {
 		s.resetDBTimer(idx)
 		return idx.Index, nil
 	}
@@ -103,7 +128,7 @@ func (s *Search) getSearchIndex(ns string) (bleve.Index, error) {
 	}
 	s.resetDBTimer(ci)
 
-	s.IndicesMap[searchPath] = ci
+	is.IndicesMap[searchPath] = ci
 	return ci.Index, nil
 }
 
@@ -241,14 +266,33 @@ func (s *Search) UpdateIndex(ns, id string, data []byte) error {
 	if err != nil {
 		return err
 	}
+	doc, err := searchDoc(p, data)
+	if err != nil {
+		return err
+	}
 
 	// add data to search index
 	i := valueobject.NewIndex(ns, id)
-	err = idx.Index(i.String(), p)
+	err = idx.Index(i.String(), doc)
 
 	return err
 }
 
+// searchDoc returns the document to index for p, decoded from data. Types
+// with runtime fields are indexed as their JSON, as bleve only sees the
+// fields of their struct.
+func searchDoc(p any, data []byte) (any, error) {
+	if _, ok := p.(content.Extensible); !ok {
+		return p, nil
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
 // DeleteIndex removes data from a content type's search index at the
 // given identifier
 func (s *Search) DeleteIndex(id string) error {
@@ -277,3 +321,161 @@ func (s *Search) userSearchDir() string {
 func (s *Search) adminSearchDir() string {
 	return filepath.Join(s.Repo.AdminDataDir(), "Search")
 }
+
+// RebuildIndex indexes all content of type ns afresh, dropping its index
+// first, and returns the number of items indexed. Types not indexed are
+// skipped.
+func (s *Search) RebuildIndex(ns string) (int, error) {
+	it, ok := s.TypeService.GetContentCreator(ns)
+	if !ok {
+		return 0, fmt.Errorf("[search] RebuildIndex Error: type '%s' doesn't exist", ns)
+	}
+	if sc, ok := it().(content.Searchable); !ok || !sc.IndexContent() {
+		return 0, nil
+	}
+
+	if err := s.dropIndex(ns); err != nil {
+		return 0, err
+	}
+	idx, err := s.getSearchIndex(ns)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	batch := idx.NewBatch()
+	for _, data := range s.Repo.AllContent(ns) {
+		p := it()
+		if err := json.Unmarshal(data, &p); err != nil {
+			return n, err
+		}
+		ci, ok := p.(content.Identifiable)
+		if !ok {
+			return n, fmt.Errorf("[search] RebuildIndex Error: type '%s' isn't identifiable", ns)
+		}
+		doc, err := searchDoc(p, data)
+		if err != nil {
+			return n, err
+		}
+		i := valueobject.NewIndex(ns, fmt.Sprintf("%d", ci.ItemID()))
+		if err := batch.Index(i.String(), doc); err != nil {
+			return n, err
+		}
+
+		if batch.Size() == indexBatchSize {
+			if err := idx.Batch(batch); err != nil {
+				return n, err
+			}
+			n += indexBatchSize
+			batch.Reset()
+		}
+	}
+
+	n += batch.Size()
+	return n, idx.Batch(batch)
+}
+
+// CloseIndices closes the open search indexes.
+func (s *Search) CloseIndices() error {
+	is := s.indices()
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	var errs []error
+	for searchPath, idx := range is.IndicesMap {
+		if idx.Index != nil {
+			errs = append(errs, idx.close())
+		}
+		delete(is.IndicesMap, searchPath)
+	}
+	return errors.Join(errs...)
+}
+
+func (s *Search) dropIndex(ns string) error {
+	is := s.indices()
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	searchPath := s.getSearchPath(ns)
+	if idx, ok := is.IndicesMap[searchPath]; ok {
+		if idx.Index != nil {
+			if err := idx.close(); err != nil {
+				return err
+			}
+		}
+		delete(is.IndicesMap, searchPath)
+	}
+
+	return os.RemoveAll(filepath.Join(s.getSearchDir(ns), ns+".index"))
+}
+
+// indexBatchSize is the max size of the batches of index changes. Bleve
+// takes longer than one by one to apply larger ones.
+const indexBatchSize = 100
+
+// indexChange is a change of the item id of namespace ns to apply to the
+// search index, its removal if data is nil.
+type indexChange struct {
+	ns   string
+	id   string
+	data []byte
+}
+
+// updateIndices applies changes in one batch per search index. Changes of
+// pending items, and of types not indexed, are skipped.
+func (s *Search) updateIndices(changes []indexChange) error {
+	byNs := make(map[string][]indexChange)
+	for _, ch := range changes {
+		if isPublicNamespace(ch.ns) {
+			byNs[ch.ns] = append(byNs[ch.ns], ch)
+		}
+	}
+
+	var errs []error
+	for ns, chs := range byNs {
+		it, ok := s.TypeService.GetContentCreator(ns)
+		if !ok {
+			errs = append(errs, fmt.Errorf("[search] updateIndices Error: type '%s' doesn't exist", ns))
+			continue
+		}
+		if sc, ok := it().(content.Searchable); !ok || !sc.IndexContent() {
+			continue
+		}
+
+		idx, err := s.getSearchIndex(ns)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		batch := idx.NewBatch()
+		for _, ch := range chs {
+			i := valueobject.NewIndex(ns, ch.id)
+			if ch.data == nil {
+				batch.Delete(i.String())
+			} else {
+				p := it()
+				if err := json.Unmarshal(ch.data, &p); err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				doc, err := searchDoc(p, ch.data)
+				if err != nil {
+					errs = append(errs, err)
+					continue
+				}
+				if err := batch.Index(i.String(), doc); err != nil {
+					errs = append(errs, err)
+				}
+			}
+
+			if batch.Size() == indexBatchSize {
+				errs = append(errs, idx.Batch(batch))
+				batch.Reset()
+			}
+		}
+		errs = append(errs, idx.Batch(batch))
+	}
+
+	return errors.Join(errs...)
+}
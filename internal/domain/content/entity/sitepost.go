@@ -0,0 +1,87 @@
+package entity
+
+import (
+	"bytes"
+	"errors"
+	"github.com/mdfriday/hugoverse/internal/domain/content/repository"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/parser/pageparser"
+	"github.com/spf13/cast"
+	"path"
+	"strings"
+)
+
+// NewSitePost creates a Post from the page source, e.g. rendered from an
+// archetype, and links it to the site with a SitePost at filename,
+// relative to the content directory. It returns the ID of the new post.
+func (c *Content) NewSitePost(siteID, filename string, source []byte) (string, error) {
+	s, err := c.getContent("Site", siteID)
+	if err != nil {
+		return "", err
+	}
+	site, ok := s.(*valueobject.Site)
+	if !ok {
+		return "", errors.New("invalid site")
+	}
+
+	cf, err := pageparser.ParseFrontMatterAndContent(bytes.NewReader(source))
+	if err != nil {
+		return "", err
+	}
+
+	var title string
+	for k, v := range cf.FrontMatter {
+		if strings.EqualFold(k, "title") {
+			title = cast.ToString(v)
+			delete(cf.FrontMatter, k)
+		}
+	}
+
+	var params string
+	if len(cf.FrontMatter) > 0 {
+		if params, err = mapToYAML(cf.FrontMatter); err != nil {
+			return "", err
+		}
+	}
+
+	i, err := valueobject.NewItemWithNamespace("Post")
+	if err != nil {
+		return "", err
+	}
+	post := &valueobject.Post{
+		Item:    *i,
+		Title:   title,
+		Content: strings.TrimPrefix(string(cf.Content), "\n"),
+		Params:  params,
+	}
+
+	spi, err := valueobject.NewItemWithNamespace("SitePost")
+	if err != nil {
+		return "", err
+	}
+	sitePost := &valueobject.SitePost{
+		Item: *spi,
+		Site: site.QueryString(),
+		Path: path.Join("content", filename),
+	}
+
+	// The post and its site post are created together, or not at all.
+	var postData, sitePostData []byte
+	err = c.Repo.Batch(func(tx repository.Tx) error {
+		var err error
+		if postData, err = c.saveNewContent(tx, "Post", post); err != nil {
+			return err
+		}
+		sitePost.Post = post.QueryString()
+		sitePostData, err = c.saveNewContent(tx, "SitePost", sitePost)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	id := c.afterNewContent("Post", post, postData)
+	c.afterNewContent("SitePost", sitePost, sitePostData)
+
+	return id, nil
+}
@@ -0,0 +1,51 @@
+package entity
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"strings"
+)
+
+// Terms collects the terms used by all public contents of the given type
+// for a taxonomy, e.g. tags, so that editors can reuse existing terms.
+// Terms are matched case-insensitively, as they are when the site is built.
+func (c *Content) Terms(contentType, taxonomy string) ([]valueobject.Term, error) {
+	ct, ok := c.GetContentCreator(contentType)
+	if !ok {
+		return nil, errors.New("invalid content type")
+	}
+	if _, ok := ct().(content.Taxonomizable); !ok {
+		return nil, nil
+	}
+
+	var terms []valueobject.Term
+	index := make(map[string]int)
+
+	for _, data := range c.Repo.AllContent(contentType) {
+		ci := ct()
+		if err := json.Unmarshal(data, ci); err != nil {
+			return nil, err
+		}
+
+		for _, name := range ci.(content.Taxonomizable).Terms(taxonomy) {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+
+			key := strings.ToLower(name)
+			if i, found := index[key]; found {
+				terms[i].Count++
+				continue
+			}
+			index[key] = len(terms)
+			terms = append(terms, valueobject.Term{Name: name, Count: 1})
+		}
+	}
+
+	valueobject.SortTerms(terms)
+
+	return terms, nil
+}
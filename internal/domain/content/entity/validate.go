@@ -0,0 +1,390 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/pkg/form"
+	"github.com/mdfriday/hugoverse/pkg/validate"
+)
+
+// Validate checks the form values data of the item of contentType with id,
+// -1 for a new item, against the rules of its type. It returns
+// validate.Errors if any rule fails.
+func (c *Content) Validate(contentType, id string, data url.Values) error {
+	return c.newValidator(false).Validate(contentType, id, data)
+}
+
+// ValidateUpdate is Validate for an update of the stored item with id and
+// status, data being merged onto its values.
+func (c *Content) ValidateUpdate(contentType, id, status string, data url.Values) error {
+	return c.newValidator(false).ValidateUpdate(contentType, id, status, data)
+}
+
+// Validator validates items against the rules of their types, the rules
+// across items looking up the stored items, public and pending, as
+// changed by the ones set or deleted before, e.g. by the ops of a batch.
+type Validator struct {
+	c     *Content
+	types map[string]*typeItems
+	added int
+
+	// batch loads the stored items of a type once, at its first lookup
+	// across items, and indexes them for the lookups after it. Otherwise
+	// the stored items are read at each lookup, the item updated by id.
+	batch bool
+}
+
+// NewValidator returns a Validator of the items of a batch of c.
+func (c *Content) NewValidator() *Validator {
+	return c.newValidator(true)
+}
+
+func (c *Content) newValidator(batch bool) *Validator {
+	return &Validator{c: c, types: make(map[string]*typeItems), batch: batch}
+}
+
+// Validate checks the form values data of the item of contentType with id,
+// -1 for a new item, against the rules of its type. It returns
+// validate.Errors if any rule fails.
+func (v *Validator) Validate(contentType, id string, data url.Values) error {
+	values, err := form.Convert(cloneValues(data))
+	if err != nil {
+		return err
+	}
+	return v.check(contentType, itemKey(content.Public, id), values)
+}
+
+// ValidateUpdate is Validate for an update of the stored item with id and
+// status, data being merged onto its values.
+func (v *Validator) ValidateUpdate(contentType, id, status string, data url.Values) error {
+	values, err := v.merge(contentType, id, status, data)
+	if err != nil {
+		return err
+	}
+	return v.check(contentType, itemKey(statusOf(status), id), values)
+}
+
+// Set sets the values of data on the item of contentType with id and
+// status, or adds a new item with them if id is empty, for the items
+// validated after it.
+func (v *Validator) Set(contentType, id, status string, data url.Values) error {
+	if id == "" {
+		v.added++
+		values, err := form.Convert(cloneValues(data))
+		if err != nil {
+			return err
+		}
+		v.items(contentType).set(fmt.Sprintf("new:%d", v.added), values)
+		return nil
+	}
+
+	values, err := v.merge(contentType, id, status, data)
+	if err != nil {
+		return err
+	}
+	v.items(contentType).set(itemKey(statusOf(status), id), values)
+	return nil
+}
+
+// Delete deletes the item of contentType with id and status, for the items
+// validated after it.
+func (v *Validator) Delete(contentType, id, status string) {
+	v.items(contentType).delete(itemKey(statusOf(status), id))
+}
+
+func (v *Validator) check(contentType, self string, values url.Values) error {
+	t, ok := v.c.GetContentCreator(contentType)
+	if !ok {
+		return errors.New("invalid content type")
+	}
+	vt, ok := t().(content.Validatable)
+	if !ok {
+		return nil
+	}
+
+	errs, err := validate.Check(vt.Rules(), values, &itemLookup{v: v, contentType: contentType, self: self})
+	if err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Merged returns the values of the item of contentType with id and status
+// with those of data set, as ValidateUpdate checks them.
+func (v *Validator) Merged(contentType, id, status string, data url.Values) (url.Values, error) {
+	return v.merge(contentType, id, status, data)
+}
+
+// merge returns the values of the item of contentType with id and status
+// with those of data set, but for the read only fields.
+func (v *Validator) merge(contentType, id, status string, data url.Values) (url.Values, error) {
+	d, err := form.Convert(cloneValues(data))
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range readOnlyFields {
+		d.Del(f)
+	}
+
+	stored, err := v.stored(contentType, id, status)
+	if err != nil {
+		return nil, err
+	}
+	if stored == nil {
+		return nil, fmt.Errorf("%s %s not found", GetNamespace(contentType, status), id)
+	}
+
+	values := cloneValues(stored)
+	for k, vs := range d {
+		values[k] = vs
+	}
+	return values, nil
+}
+
+// stored returns the values of the item of contentType with id and status
+// as set before, or as stored, or nil if there is none.
+func (v *Validator) stored(contentType, id, status string) (url.Values, error) {
+	st := statusOf(status)
+	key := itemKey(st, id)
+	items := v.items(contentType)
+	if values, ok := items.items[key]; ok {
+		return values, nil
+	}
+	if items.loaded || items.deleted[key] || (st != content.Public && st != content.Pending) {
+		return nil, nil
+	}
+
+	data, err := v.c.GetContent(contentType, id, string(st))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	return jsonValues(data)
+}
+
+func (v *Validator) items(contentType string) *typeItems {
+	items, ok := v.types[contentType]
+	if !ok {
+		items = &typeItems{items: make(map[string]url.Values)}
+		v.types[contentType] = items
+	}
+	return items
+}
+
+// loaded returns the items of contentType, loading the stored items the
+// first time.
+func (v *Validator) loaded(contentType string) (*typeItems, error) {
+	items := v.items(contentType)
+	if items.loaded {
+		return items, nil
+	}
+
+	stored := make(map[string]url.Values)
+	for _, status := range []content.Status{content.Public, content.Pending} {
+		for _, data := range v.c.Repo.AllContent(GetNamespace(contentType, string(status))) {
+			values, err := jsonValues(data)
+			if err != nil {
+				return nil, err
+			}
+			stored[itemKey(status, values.Get("id"))] = values
+		}
+	}
+	// The items set or deleted before loading win over the stored ones.
+	for k, values := range items.items {
+		stored[k] = values
+	}
+	for k := range items.deleted {
+		delete(stored, k)
+	}
+
+	items.items = stored
+	items.index = nil
+	items.loaded = true
+	return items, nil
+}
+
+func itemKey(status content.Status, id string) string {
+	return string(status) + ":" + id
+}
+
+// typeItems are the values of the items of a type by key, with the keys of
+// the items by value of the fields looked up.
+type typeItems struct {
+	loaded  bool
+	items   map[string]url.Values
+	deleted map[string]bool
+	index   map[string]map[string][]string
+}
+
+func (t *typeItems) set(key string, values url.Values) {
+	t.items[key] = values
+	delete(t.deleted, key)
+	for field, byValue := range t.index {
+		for _, v := range values[field] {
+			byValue[v] = append(byValue[v], key)
+		}
+	}
+}
+
+func (t *typeItems) delete(key string) {
+	delete(t.items, key)
+	if t.deleted == nil {
+		t.deleted = make(map[string]bool)
+	}
+	t.deleted[key] = true
+}
+
+// keys returns the keys of the items which may have value in field. An
+// item set since its field was indexed is listed with its old value too,
+// so the values of the items listed are to be checked.
+func (t *typeItems) keys(field, value string) []string {
+	if t.index == nil {
+		t.index = make(map[string]map[string][]string)
+	}
+	byValue, ok := t.index[field]
+	if !ok {
+		byValue = make(map[string][]string)
+		for key, values := range t.items {
+			for _, v := range values[field] {
+				byValue[v] = append(byValue[v], key)
+			}
+		}
+		t.index[field] = byValue
+	}
+	return byValue[value]
+}
+
+// itemLookup looks up the items of a type other than the one with key
+// self.
+type itemLookup struct {
+	v           *Validator
+	contentType string
+	self        string
+}
+
+func (l *itemLookup) Exists(field, value string, scope map[string]string) (bool, error) {
+	if !l.v.batch {
+		return l.scan(field, value, scope)
+	}
+
+	items, err := l.v.loaded(l.contentType)
+	if err != nil {
+		return false, err
+	}
+
+	for _, key := range items.keys(field, value) {
+		values, ok := items.items[key]
+		if key != l.self && ok && matches(values, field, value, scope) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// scan is Exists reading the stored items one at a time, up to the first
+// match. Items whose JSON can't hold value are skipped undecoded.
+func (l *itemLookup) scan(field, value string, scope map[string]string) (bool, error) {
+	var raw []byte
+	if b, err := json.Marshal(value); err == nil && value != "" && string(b) == `"`+value+`"` {
+		raw = []byte(value)
+	}
+
+	for _, status := range []content.Status{content.Public, content.Pending} {
+		for _, data := range l.v.c.Repo.AllContent(GetNamespace(l.contentType, string(status))) {
+			if raw != nil && !bytes.Contains(data, raw) {
+				continue
+			}
+			values, err := jsonValues(data)
+			if err != nil {
+				return false, err
+			}
+			if itemKey(status, values.Get("id")) != l.self && matches(values, field, value, scope) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// matches reports whether values has value in field, and the values of
+// scope.
+func matches(values url.Values, field, value string, scope map[string]string) bool {
+	if !hasValue(values[field], value) {
+		return false
+	}
+	for f, v := range scope {
+		if !hasValue(values[f], v) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasValue reports whether vs has value, or has none if value is empty.
+func hasValue(vs []string, value string) bool {
+	if value == "" && len(vs) == 0 {
+		return true
+	}
+	for _, v := range vs {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonValues returns the values of the fields of the item stored as data,
+// as they are posted in a form. Objects are left as JSON.
+func jsonValues(data []byte) (url.Values, error) {
+	d := json.NewDecoder(bytes.NewReader(data))
+	d.UseNumber()
+	var m map[string]any
+	if err := d.Decode(&m); err != nil {
+		return nil, err
+	}
+
+	values := make(url.Values, len(m))
+	for k, v := range m {
+		if vs, ok := v.([]any); ok {
+			for _, e := range vs {
+				s, err := jsonValue(e)
+				if err != nil {
+					return nil, err
+				}
+				values.Add(k, s)
+			}
+			continue
+		}
+
+		if v == nil {
+			continue
+		}
+		s, err := jsonValue(v)
+		if err != nil {
+			return nil, err
+		}
+		values.Set(k, s)
+	}
+	return values, nil
+}
+
+func jsonValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
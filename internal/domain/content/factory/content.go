@@ -30,6 +30,7 @@ func NewContent(repo repository.Repository, dir content.DirService) *entity.Cont
 
 	prepareUserTypes(c)
 	prepareAdminTypes(c)
+	prepareSchemaTypes(c, dir.SchemaDir())
 
 	c.Search = &entity.Search{
 		TypeService: c,
@@ -51,6 +52,8 @@ func prepareUserTypes(c *entity.Content) {
 	c.UserTypes["Site"] = func() interface{} { return new(valueobject.Site) }
 	//c.UserTypes["SiteLanguage"] = func() interface{} { return new(valueobject.SiteLanguage) }
 	c.UserTypes["SitePost"] = func() interface{} { return new(valueobject.SitePost) }
+	c.UserTypes["SiteMenu"] = func() interface{} { return new(valueobject.SiteMenu) }
+	c.UserTypes["SiteData"] = func() interface{} { return new(valueobject.SiteData) }
 	c.UserTypes["SiteResource"] = func() interface{} { return new(valueobject.SiteResource) }
 	c.UserTypes["Deployment"] = func() interface{} { return new(valueobject.Deployment) }
 }
@@ -60,6 +63,45 @@ func prepareAdminTypes(c *entity.Content) {
 	c.AdminTypes["Preview"] = func() interface{} { return new(valueobject.Preview) }
 }
 
+// prepareSchemaTypes registers the user types defined by the schema files
+// in dir. Types clashing with another, or referring to one which doesn't
+// exist, are left out.
+func prepareSchemaTypes(c *entity.Content, dir string) {
+	schemas, err := valueobject.LoadSchemas(dir)
+	if err != nil {
+		c.Log.Errorf("Error loading content type schemas: %v", err)
+		return
+	}
+
+	var registered []*valueobject.Schema
+	for _, s := range schemas {
+		if _, ok := c.AllTypes()[s.Name]; ok {
+			c.Log.Errorf("Error loading content type schema %s: type already exists", s.Name)
+			continue
+		}
+		c.UserTypes[s.Name] = s.Creator()
+		registered = append(registered, s)
+	}
+
+	// Leaving out a type may leave out those referring to it in turn.
+	for removed := true; removed; {
+		removed = false
+		for _, s := range registered {
+			if _, ok := c.UserTypes[s.Name]; !ok {
+				continue
+			}
+			for _, t := range s.References() {
+				if _, ok := c.AllTypes()[t]; !ok {
+					c.Log.Errorf("Error loading content type schema %s: type %s not found", s.Name, t)
+					delete(c.UserTypes, s.Name)
+					removed = true
+					break
+				}
+			}
+		}
+	}
+}
+
 func NewContentWithServices(repo repository.Repository, services content.Services, dirService content.DirService) *entity.Content {
 	c := NewContent(repo, dirService)
 	c.Hugo.Services = services
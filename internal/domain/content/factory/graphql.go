@@ -0,0 +1,22 @@
+package factory
+
+import (
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/entity"
+)
+
+// NewGraphQL creates the GraphQL API over the content types of c, and the
+// page trees loaded by trees.
+func NewGraphQL(c *entity.Content, trees content.PageTreeService) (*entity.GraphQL, error) {
+	g := &entity.GraphQL{
+		Content:   c,
+		PageTrees: trees,
+		Log:       c.Log,
+	}
+
+	if err := g.BuildSchema(); err != nil {
+		return nil, err
+	}
+
+	return g, nil
+}
@@ -1,5 +1,7 @@
 package repository
 
+import "github.com/mdfriday/hugoverse/pkg/db"
+
 type Repository interface {
 	PutContent(ci any, data []byte) error
 	NewContent(ci any, data []byte) error
@@ -14,7 +16,26 @@ type Repository interface {
 	GetIdByHash(namespace string, hash string) ([]byte, error)
 
 	PutSortedContent(namespace string, m map[string][]byte) error
+	// Query returns the number of items in namespace, e.g. the sorted
+	// items of Post__sorted, and the items of the page of opts.
+	Query(namespace string, opts db.QueryOptions) (int, [][]byte)
+
+	// Batch runs fn with the writes of tx applied at once, if fn returns
+	// nil, or not at all.
+	Batch(fn func(tx Tx) error) error
 
 	UserDataDir() string
 	AdminDataDir() string
 }
+
+// Tx is a transaction of content writes, reading its own writes.
+type Tx interface {
+	PutContent(ci any, data []byte) error
+	NewContent(ci any, data []byte) error
+
+	GetContent(namespace string, id string) ([]byte, error)
+	DeleteContent(namespace string, id string, slug string, hash string) error
+
+	NextContentId(ns string) (uint64, error)
+	CheckSlugForDuplicate(namespace string, slug string) (string, error)
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/blevesearch/bleve/mapping"
 	"github.com/gofrs/uuid"
 	"github.com/mdfriday/hugoverse/internal/domain/contenthub"
+	"github.com/mdfriday/hugoverse/pkg/validate"
 	"net/http"
 )
 
@@ -20,6 +21,7 @@ type DirService interface {
 	UploadDir() string
 	PreviewDir() string
 	PreviewFolder() string
+	SchemaDir() string
 }
 
 type Services interface {
@@ -46,6 +48,24 @@ type CHService interface {
 	GetPageSources(page contenthub.Page) ([]contenthub.PageSource, error)
 }
 
+// PageTree is the page tree of a site, once its pages are collected
+type PageTree interface {
+	WalkPages(langIndex int, walker contenthub.WalkFunc) error
+	WalkTaxonomies(langIndex int, walker contenthub.WalkTaxonomyFunc) error
+
+	DefaultLanguage() string
+	GetLanguageIndex(lang string) (int, error)
+	GetLanguageByIndex(idx int) string
+	LanguageIndexes() []int
+}
+
+// PageTreeService loads the page trees of the sites built by the CMS
+type PageTreeService interface {
+	// PageTree loads the page tree of the site with id, with a func
+	// releasing it once done with
+	PageTree(id, status string) (PageTree, func(), error)
+}
+
 type Status string
 
 const (
@@ -57,6 +77,19 @@ type Unmarshalable interface {
 	UnmarshalJSON([]byte) error
 }
 
+// Extensible is implemented by content types with fields defined at runtime,
+// e.g. from a schema file, rather than by their struct
+type Extensible interface {
+	// Fields returns a pointer to the struct holding the runtime fields
+	Fields() any
+}
+
+// Validatable is implemented by content types with rules on the values of
+// their fields, checked before an item is saved from the API or the CMS
+type Validatable interface {
+	Rules() []validate.Rule
+}
+
 // Hideable lets a user keep items hidden
 type Hideable interface {
 	Hide(http.ResponseWriter, *http.Request) error
@@ -70,6 +103,14 @@ type Deployable interface {
 	Deploy() bool
 }
 
+// Referencing is implemented by content types with fields referring to items
+// of other types by their query string, e.g. /api/content?type=Site&id=1
+type Referencing interface {
+	// ReferenceFields returns the type referred to by the json tag names of
+	// the fields
+	ReferenceFields() map[string]string
+}
+
 type RefSelectable interface {
 	SelectContentTypes() []string
 	SetSelectData(data map[string][][]byte)
@@ -114,6 +155,12 @@ type Sortable interface {
 	Touch() int64
 }
 
+// Taxonomizable is implemented by content types which can be classified
+// with taxonomy terms, e.g. tags or categories.
+type Taxonomizable interface {
+	Terms(taxonomy string) []string
+}
+
 // CSVFormattable is implemented with the method FormatCSV, which must return the ordered
 // slice of JSON struct tag names for the type implmenting it
 type CSVFormattable interface {
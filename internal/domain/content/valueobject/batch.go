@@ -0,0 +1,71 @@
+package valueobject
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/pkg/validate"
+)
+
+type BatchOpKind string
+
+const (
+	BatchCreate  BatchOpKind = "create"
+	BatchUpdate  BatchOpKind = "update"
+	BatchDelete  BatchOpKind = "delete"
+	BatchApprove BatchOpKind = "approve"
+)
+
+// BatchOp is one operation of a batch.
+type BatchOp struct {
+	Op   BatchOpKind
+	Type string
+
+	// ID and Status locate the item to update, delete or approve. Status
+	// is also the status of an item created, public if empty.
+	ID     string
+	Status string
+
+	// Values are the fields to create the item from, or to set on it.
+	Values url.Values
+}
+
+// Check checks op is complete.
+func (op *BatchOp) Check() error {
+	switch op.Op {
+	case BatchCreate:
+	case BatchUpdate, BatchDelete, BatchApprove:
+		if op.ID == "" {
+			return fmt.Errorf("%s needs an id", op.Op)
+		}
+	default:
+		return fmt.Errorf("unknown op %q", op.Op)
+	}
+	if op.Type == "" {
+		return fmt.Errorf("%s needs a type", op.Op)
+	}
+	switch content.Status(op.Status) {
+	case "", content.Public, content.Pending:
+	default:
+		return fmt.Errorf("unknown status %q", op.Status)
+	}
+	return nil
+}
+
+// BatchResult is the outcome of the BatchOp at Index of a batch.
+type BatchResult struct {
+	Index  int         `json:"index"`
+	Op     BatchOpKind `json:"op"`
+	Type   string      `json:"type"`
+	ID     string      `json:"id,omitempty"`
+	Status string      `json:"status,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	// Errors are the fields of the op failing validation, if any.
+	Errors validate.Errors `json:"errors,omitempty"`
+}
+
+// OK reports whether the op was applied.
+func (r *BatchResult) OK() bool {
+	return r.Error == ""
+}
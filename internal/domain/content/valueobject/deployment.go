@@ -3,6 +3,7 @@ package valueobject
 import (
 	"fmt"
 	"github.com/mdfriday/hugoverse/pkg/editor"
+	"github.com/mdfriday/hugoverse/pkg/validate"
 	"log"
 	"net/http"
 	"strings"
@@ -88,6 +89,11 @@ func (s *Deployment) SelectContentTypes() []string {
 	return []string{"Domain"}
 }
 
+// ReferenceFields implements content.Referencing
+func (s *Deployment) ReferenceFields() map[string]string {
+	return map[string]string{"domain": "Domain"}
+}
+
 // String defines the display name of a Song in the CMS list-view
 func (s *Deployment) String() string {
 	d, _ := extractTypeAndID(s.Domain)
@@ -99,6 +105,15 @@ func (s *Deployment) SetHash() {
 	s.Hash = Hash([]string{s.Domain, s.HostName})
 }
 
+// Rules implements content.Validatable, with the rules the fields of a
+// Deployment must pass before it is saved
+func (s *Deployment) Rules() []validate.Rule {
+	return []validate.Rule{
+		validate.Required("domain"),
+		validate.Required("host_name"),
+	}
+}
+
 // Create implements api.Createable, and allows external POST requests from clients
 // to add content as long as the request contains the json tag names of the Song
 // struct fields, and is multipart encoded
@@ -3,6 +3,7 @@ package valueobject
 import (
 	"fmt"
 	"github.com/mdfriday/hugoverse/pkg/editor"
+	"github.com/mdfriday/hugoverse/pkg/validate"
 	"net/http"
 )
 
@@ -63,6 +64,19 @@ func (d *Domain) SetHash() {
 	d.Hash = Hash([]string{d.Sub, d.Root})
 }
 
+// Rules implements content.Validatable, with the rules the fields of a
+// Domain must pass before it is saved
+func (d *Domain) Rules() []validate.Rule {
+	return []validate.Rule{
+		validate.Required("root"),
+		validate.Regex("root", hostNameRe, "must be a lower case host name, e.g. example.org"),
+		validate.Required("sub"),
+		validate.Regex("sub", hostLabelRe, "must be a lower case host name label, e.g. my-site"),
+		validate.Required("owner"),
+		validate.Unique("sub", "root"),
+	}
+}
+
 // Create implements api.Createable, and allows external POST requests from clients
 // to add content as long as the request contains the json tag names of the Song
 // struct fields, and is multipart encoded
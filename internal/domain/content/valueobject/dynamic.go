@@ -0,0 +1,341 @@
+package valueobject
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"text/template"
+
+	"github.com/mdfriday/hugoverse/pkg/editor"
+	"github.com/mdfriday/hugoverse/pkg/validate"
+)
+
+// Dynamic is an item of a type defined by a Schema. Its fields are held by
+// a struct built from the schema at runtime, and are encoded to JSON next
+// to those of its Item, as for the built-in types.
+type Dynamic struct {
+	Item
+
+	schema *Schema
+	// fields is a pointer to a struct of the type of the schema.
+	fields reflect.Value
+
+	refSelData map[string][][]byte
+}
+
+func NewDynamic(s *Schema) *Dynamic {
+	return &Dynamic{schema: s, fields: reflect.New(s.typ)}
+}
+
+// Schema returns the schema defining the type of d.
+func (d *Dynamic) Schema() *Schema { return d.schema }
+
+// Fields returns a pointer to the struct holding the fields of d, which
+// the form values of d decode into.
+func (d *Dynamic) Fields() any { return d.fields.Interface() }
+
+func (d *Dynamic) MarshalJSON() ([]byte, error) {
+	item, err := json.Marshal(&d.Item)
+	if err != nil {
+		return nil, err
+	}
+	fields, err := json.Marshal(d.fields.Interface())
+	if err != nil {
+		return nil, err
+	}
+
+	// Merge both objects into one: {item..., fields...}
+	b := make([]byte, 0, len(item)+len(fields))
+	b = append(b, item[:len(item)-1]...)
+	b = append(b, ',')
+	b = append(b, fields[1:]...)
+	return b, nil
+}
+
+func (d *Dynamic) UnmarshalJSON(b []byte) error {
+	if err := json.Unmarshal(b, &d.Item); err != nil {
+		return err
+	}
+	return json.Unmarshal(b, d.fields.Interface())
+}
+
+// MarshalEditor writes a buffer of html to edit a Dynamic within the CMS,
+// with an editor element to a field of its schema, and implements
+// editor.Editable
+func (d *Dynamic) MarshalEditor() ([]byte, error) {
+	p := d.fields.Interface()
+
+	var fields []editor.Field
+	for i := range d.schema.Fields {
+		f := &d.schema.Fields[i]
+		view, err := d.editorView(f, p)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, editor.Field{View: view})
+	}
+
+	view, err := editor.Form(d, fields...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s editor view: %s", d.schema.Name, err.Error())
+	}
+
+	return view, nil
+}
+
+func (d *Dynamic) editorView(f *SchemaField, p any) ([]byte, error) {
+	name := f.goName()
+	attrs := map[string]string{
+		"label":       f.label(),
+		"placeholder": f.Placeholder,
+	}
+
+	switch f.Type {
+	case FieldString, FieldInt, FieldFloat:
+		attrs["type"] = "text"
+		if f.Type != FieldString {
+			attrs["type"] = "number"
+		}
+		if f.Type == FieldFloat {
+			attrs["step"] = "any"
+		}
+		if f.Repeat {
+			return editor.InputRepeater(name, p, attrs), nil
+		}
+		return editor.Input(name, p, attrs), nil
+
+	case FieldText:
+		return editor.Textarea(name, p, attrs), nil
+
+	case FieldRichtext:
+		return editor.Richtext(name, p, attrs), nil
+
+	case FieldBool:
+		return editor.Select(name, p, attrs, map[string]string{
+			"true":  "Yes",
+			"false": "No",
+		}), nil
+
+	case FieldDate:
+		attrs["type"] = "date"
+		return editor.Input(name, p, attrs), nil
+
+	case FieldSelect:
+		options := make(map[string]string, len(f.Options))
+		for _, o := range f.Options {
+			options[o] = o
+		}
+		if f.Repeat {
+			return editor.Checkbox(name, p, attrs, options), nil
+		}
+		return editor.Select(name, p, attrs, options), nil
+
+	case FieldFile:
+		if f.Repeat {
+			return editor.FileRepeater(name, p, attrs), nil
+		}
+		return editor.File(name, p, attrs), nil
+
+	case FieldReference:
+		if f.Repeat {
+			options, err := refOptions(f.To, f.display(), d.refSelData[f.To])
+			if err != nil {
+				return nil, err
+			}
+			return editor.SelectRepeater(name, p, attrs, options), nil
+		}
+		return editor.RefSelect(name, p, attrs, f.To, f.display(), d.refSelData[f.To]), nil
+	}
+
+	return nil, fmt.Errorf("unknown field type %q", f.Type)
+}
+
+func (f *SchemaField) display() string {
+	if f.Display != "" {
+		return f.Display
+	}
+	return `{{ .slug }}`
+}
+
+// refOptions returns the options of the items of contentType in data, by
+// their query string, as editor.RefSelect has them.
+func refOptions(contentType, display string, data [][]byte) (map[string]string, error) {
+	tmpl, err := template.New(contentType).Parse(display)
+	if err != nil {
+		return nil, err
+	}
+
+	options := make(map[string]string, len(data))
+	for _, b := range data {
+		var item map[string]any
+		if err := json.Unmarshal(b, &item); err != nil {
+			return nil, err
+		}
+		id, _ := item["id"].(float64)
+
+		var v bytes.Buffer
+		if err := tmpl.Execute(&v, item); err != nil {
+			return nil, fmt.Errorf("error executing template for reference of %s: %w", contentType, err)
+		}
+		options[fmt.Sprintf("/api/content?type=%s&id=%.0f", contentType, id)] = html.UnescapeString(v.String())
+	}
+
+	return options, nil
+}
+
+func (d *Dynamic) SelectContentTypes() []string {
+	return d.schema.References()
+}
+
+func (d *Dynamic) SetSelectData(data map[string][][]byte) {
+	d.refSelData = data
+}
+
+// ReferenceFields implements content.Referencing, with the reference fields
+// of the schema
+func (d *Dynamic) ReferenceFields() map[string]string {
+	refs := make(map[string]string)
+	for _, f := range d.schema.Fields {
+		if f.Type == FieldReference {
+			refs[f.Name] = f.To
+		}
+	}
+	return refs
+}
+
+// String defines the display name of a Dynamic in the CMS list-view, the
+// value of its title field if it has one.
+func (d *Dynamic) String() string {
+	if d.schema.Title == "" {
+		return d.Item.String()
+	}
+	f := d.schema.Field(d.schema.Title)
+	return editor.ValueFromStructField(f.goName(), d.fields.Interface())
+}
+
+func (d *Dynamic) SetHash() {
+	var values []string
+	for _, f := range d.schema.Fields {
+		values = append(values, editor.ValueFromStructField(f.goName(), d.fields.Interface()))
+	}
+	d.Hash = Hash(values)
+}
+
+// Create implements api.Createable, and allows external POST requests from
+// clients to add content, its values being checked against Rules
+func (d *Dynamic) Create(res http.ResponseWriter, req *http.Request) error {
+	return nil
+}
+
+// Rules implements content.Validatable, with the rules of the fields of
+// the schema
+func (d *Dynamic) Rules() []validate.Rule {
+	var rules []validate.Rule
+	for i := range d.schema.Fields {
+		rules = append(rules, d.schema.Fields[i].rules()...)
+	}
+	return rules
+}
+
+var (
+	intRe  = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	dateRe = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}$`)
+)
+
+func (f *SchemaField) rules() []validate.Rule {
+	var rules []validate.Rule
+	if f.Required {
+		rules = append(rules, validate.Required(f.Name))
+	}
+
+	switch f.Type {
+	case FieldString, FieldText, FieldRichtext:
+		if f.Min != nil || f.Max != nil {
+			min, max := 0, 0
+			if f.Min != nil {
+				min = int(*f.Min)
+			}
+			if f.Max != nil {
+				max = int(*f.Max)
+			}
+			rules = append(rules, validate.Length(f.Name, min, max))
+		}
+	case FieldInt:
+		rules = append(rules, validate.Regex(f.Name, intRe, "must be an integer"))
+		rules = append(rules, validate.Range(f.Name, f.Min, f.Max))
+	case FieldFloat:
+		rules = append(rules, validate.Range(f.Name, f.Min, f.Max))
+	case FieldBool:
+		rules = append(rules, validate.Enum(f.Name, "true", "false"))
+	case FieldDate:
+		rules = append(rules, validate.Regex(f.Name, dateRe, "must be a date, e.g. 2024-01-31"))
+	case FieldSelect:
+		rules = append(rules, validate.Enum(f.Name, f.Options...))
+	case FieldReference:
+		rules = append(rules, refRule(f.Name, f.To))
+	}
+
+	if f.pattern != nil {
+		rules = append(rules, validate.Regex(f.Name, f.pattern, ""))
+	}
+	if f.Unique {
+		var scope []string
+		if f.UniqueWithin != "" {
+			scope = append(scope, f.UniqueWithin)
+		}
+		rules = append(rules, validate.Unique(f.Name, scope...))
+	}
+	if f.GreaterThan != "" {
+		rules = append(rules, greaterThan(f))
+	}
+
+	return rules
+}
+
+// greaterThan is the rule of f being greater than the field f.GreaterThan,
+// if both are set.
+func greaterThan(f *SchemaField) validate.Rule {
+	return validate.Cross("greater_than", []string{f.Name, f.GreaterThan}, func(values url.Values) string {
+		a, b := values.Get(f.Name), values.Get(f.GreaterThan)
+		if a == "" || b == "" {
+			return ""
+		}
+
+		greater := a > b // dates, e.g. 2024-01-31
+		if f.Type != FieldDate {
+			x, errA := strconv.ParseFloat(a, 64)
+			y, errB := strconv.ParseFloat(b, 64)
+			if errA != nil || errB != nil {
+				return ""
+			}
+			greater = x > y
+		}
+		if !greater {
+			return "must be greater than " + f.GreaterThan
+		}
+		return ""
+	})
+}
+
+// Approve implements editor.Mergeable, which enables content supplied by
+// external clients to be approved and thus added to the public content API
+func (d *Dynamic) Approve(res http.ResponseWriter, req *http.Request) error {
+	return nil
+}
+
+// AutoApprove implements api.Trustable, and will automatically approve
+// content that has been submitted by an external client via api.Createable
+func (d *Dynamic) AutoApprove(res http.ResponseWriter, req *http.Request) error {
+	return nil
+}
+
+// IndexContent indexes the items of types with search set in their schema
+func (d *Dynamic) IndexContent() bool {
+	return d.schema.Search
+}
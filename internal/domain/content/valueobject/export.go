@@ -0,0 +1,84 @@
+package valueobject
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+)
+
+type ExportFormat string
+
+const (
+	ExportCSV    ExportFormat = "csv"
+	ExportNDJSON ExportFormat = "ndjson"
+	// ExportHugo is a gzipped tar of a Hugo project with the posts in its
+	// content dir.
+	ExportHugo ExportFormat = "hugo"
+)
+
+// ContentType is the MIME type of an export in format f.
+func (f ExportFormat) ContentType() string {
+	switch f {
+	case ExportCSV:
+		return "text/csv"
+	case ExportNDJSON:
+		return "application/x-ndjson"
+	case ExportHugo:
+		return "application/gzip"
+	}
+	return "application/octet-stream"
+}
+
+// Ext is the file extension of an export in format f.
+func (f ExportFormat) Ext() string {
+	if f == ExportHugo {
+		return "tar.gz"
+	}
+	return string(f)
+}
+
+// ExportFilter selects the items to export. Its zero value selects all
+// public items.
+type ExportFilter struct {
+	// Site is the id of a site, to export only the items of the site, or
+	// those its items refer to, e.g. the posts of its site posts.
+	Site string
+
+	Status content.Status
+
+	// Since and Until select the items created in [Since, Until), if set.
+	Since time.Time
+	Until time.Time
+
+	// Item, if set, returns the data to export of the stored item ci with
+	// data, or nil to leave it out, e.g. to hide it or omit some of its
+	// fields.
+	Item func(ci any, data []byte) ([]byte, error)
+}
+
+// Check checks f is a valid filter.
+func (f *ExportFilter) Check() error {
+	switch f.Status {
+	case "", content.Public, content.Pending:
+	default:
+		return fmt.Errorf("unknown status %q", f.Status)
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && !f.Since.Before(f.Until) {
+		return fmt.Errorf("since %s is not before until %s", f.Since.Format(time.RFC3339), f.Until.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Created reports whether an item created at ms, in milliseconds since
+// the epoch, is in the dates of f.
+func (f *ExportFilter) Created(ms int64) bool {
+	t := time.UnixMilli(ms)
+	if !f.Since.IsZero() && t.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !t.Before(f.Until) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,48 @@
+package valueobject
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrPersistedQueryNotFound is the error of a request with the hash of a
+// query not persisted yet, which the client sends again with the query.
+var ErrPersistedQueryNotFound = errors.New("PersistedQueryNotFound")
+
+// GraphQLRequest is a request to the GraphQL API, as sent in the body of a
+// POST or the query string of a GET.
+type GraphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+	Extensions    struct {
+		PersistedQuery *PersistedQuery `json:"persistedQuery"`
+	} `json:"extensions"`
+}
+
+// PersistedQuery refers to a query by the hex sha256 hash of its text, so
+// that clients send a query once and its hash afterward.
+type PersistedQuery struct {
+	Version int    `json:"version"`
+	Hash    string `json:"sha256Hash"`
+}
+
+// Check checks q is a version 1 persisted query, the hash of query if not
+// empty.
+func (q *PersistedQuery) Check(query string) error {
+	if q.Version != 1 {
+		return fmt.Errorf("unsupported persisted query version %d", q.Version)
+	}
+	if query != "" && QueryHash(query) != q.Hash {
+		return errors.New("provided sha256Hash does not match query")
+	}
+	return nil
+}
+
+// QueryHash returns the hex sha256 hash of query.
+func QueryHash(query string) string {
+	h := sha256.Sum256([]byte(query))
+	return hex.EncodeToString(h[:])
+}
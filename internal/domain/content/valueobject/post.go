@@ -4,8 +4,13 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/mdfriday/hugoverse/pkg/editor"
+	"github.com/mdfriday/hugoverse/pkg/validate"
+	"github.com/spf13/cast"
+	"gopkg.in/yaml.v3"
 	"net/http"
+	"strings"
 	"text/template"
+	"time"
 )
 
 type Post struct {
@@ -72,6 +77,16 @@ func (s *Post) SetHash() {
 	s.Hash = Hash([]string{s.Content})
 }
 
+// Rules implements content.Validatable, with the rules the fields of a
+// Post must pass before it is saved
+func (s *Post) Rules() []validate.Rule {
+	return []validate.Rule{
+		validate.Required("title"),
+		validate.Length("title", 0, 200),
+		validate.Required("content"),
+	}
+}
+
 // Create implements api.Createable, and allows external POST requests from clients
 // to add content as long as the request contains the json tag names of the Song
 // struct fields, and is multipart encoded
@@ -158,13 +173,72 @@ func (s *Post) Push(http.ResponseWriter, *http.Request) ([]string, error) {
 }
 
 func (s *Post) FrontMatter() string {
-	return fmt.Sprintf("---\ntitle: %s\n---", s.Title)
+	params := strings.TrimSpace(s.Params)
+	if params == "" {
+		return fmt.Sprintf("---\ntitle: %s\n---", s.Title)
+	}
+	return fmt.Sprintf("---\ntitle: %s\n%s\n---", s.Title, params)
+}
+
+// Terms returns the terms set in the params for the given taxonomy,
+// e.g. the tags in "tags: [go, hugo]".
+func (s *Post) Terms(taxonomy string) []string {
+	if strings.TrimSpace(s.Params) == "" {
+		return nil
+	}
+
+	var params map[string]any
+	if err := yaml.Unmarshal([]byte(s.Params), &params); err != nil {
+		return nil
+	}
+
+	for k, v := range params {
+		if strings.EqualFold(k, taxonomy) {
+			return cast.ToStringSlice(v)
+		}
+	}
+
+	return nil
 }
 
 func (s *Post) FullContent() string {
 	return s.FrontMatter() + "\n" + s.Content
 }
 
+// Page returns the post as a Hugo page, its params with the title, the
+// date and the last modification in the front matter, and its content.
+func (s *Post) Page(draft bool) ([]byte, error) {
+	fm := make(map[string]any)
+	if strings.TrimSpace(s.Params) != "" {
+		if err := yaml.Unmarshal([]byte(s.Params), &fm); err != nil {
+			return nil, fmt.Errorf("invalid params of post %d: %w", s.ID, err)
+		}
+	}
+
+	fm["title"] = s.Title
+	if _, ok := fm["date"]; !ok && s.Timestamp > 0 {
+		fm["date"] = time.UnixMilli(s.Timestamp).UTC().Format(time.RFC3339)
+	}
+	if _, ok := fm["lastmod"]; !ok && s.Updated > 0 {
+		fm["lastmod"] = time.UnixMilli(s.Updated).UTC().Format(time.RFC3339)
+	}
+	if draft {
+		fm["draft"] = true
+	}
+
+	y, err := yaml.Marshal(fm)
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.WriteString("---\n")
+	b.Write(y)
+	b.WriteString("---\n")
+	b.WriteString(s.Content)
+	return b.Bytes(), nil
+}
+
 func (s *Post) Markdown() ([]byte, error) {
 	const postTemplate = `
 {{.Content}}
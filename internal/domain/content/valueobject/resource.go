@@ -3,6 +3,7 @@ package valueobject
 import (
 	"fmt"
 	"github.com/mdfriday/hugoverse/pkg/editor"
+	"github.com/mdfriday/hugoverse/pkg/validate"
 	"net/http"
 )
 
@@ -53,6 +54,16 @@ func (s *Resource) SetHash() {
 	s.Hash = Hash([]string{s.Name, s.Size})
 }
 
+// Rules implements content.Validatable, with the rules the fields of a
+// Resource must pass before it is saved
+func (s *Resource) Rules() []validate.Rule {
+	return []validate.Rule{
+		validate.Required("name"),
+		validate.Length("name", 0, 200),
+		validate.Required("asset"),
+	}
+}
+
 // Create implements api.Createable, and allows external POST requests from clients
 // to add content as long as the request contains the json tag names of the Song
 // struct fields, and is multipart encoded
@@ -0,0 +1,29 @@
+package valueobject
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/mdfriday/hugoverse/pkg/validate"
+)
+
+var (
+	// hostLabelRe matches a label of a host name, e.g. www.
+	hostLabelRe = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+	// hostNameRe matches a host name, e.g. www.example.org.
+	hostNameRe = regexp.MustCompile(`^([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z][a-z0-9-]{0,61}[a-z0-9]$`)
+	// relPathRe matches a relative slash separated path, e.g. posts/hello.md.
+	relPathRe = regexp.MustCompile(`^/?[^/\s]+(/[^/\s]+)*$`)
+)
+
+// refRule fails if a value of field isn't the query string of an item of
+// contentType, e.g. /api/content?type=Site&id=1
+func refRule(field, contentType string) validate.Rule {
+	re := regexp.MustCompile(fmt.Sprintf(`^/api/content\?type=%s&id=[0-9]+$`, regexp.QuoteMeta(contentType)))
+	return validate.Regex(field, re, "must refer to a "+contentType)
+}
+
+// pathRule fails if a value of field isn't a relative path.
+func pathRule(field string) validate.Rule {
+	return validate.Regex(field, relPathRe, "must be a relative path, e.g. posts/hello.md")
+}
@@ -0,0 +1,375 @@
+package valueobject
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldType is the type of a field of a Schema.
+type FieldType string
+
+const (
+	FieldString   FieldType = "string"
+	FieldText     FieldType = "text"
+	FieldRichtext FieldType = "richtext"
+	FieldInt      FieldType = "int"
+	FieldFloat    FieldType = "float"
+	FieldBool     FieldType = "bool"
+	// FieldDate is a date, e.g. 2024-01-31.
+	FieldDate   FieldType = "date"
+	FieldSelect FieldType = "select"
+	FieldFile   FieldType = "file"
+	// FieldReference is the query string of an item of another type, e.g.
+	// /api/content?type=Site&id=1
+	FieldReference FieldType = "reference"
+)
+
+// Schema defines a content type at runtime, from a YAML or JSON file, e.g.
+//
+//	name: Product
+//	title: name
+//	search: true
+//	fields:
+//	  - name: name
+//	    type: string
+//	    required: true
+//	  - name: price
+//	    type: float
+//	    min: 0
+//	  - name: site
+//	    type: reference
+//	    to: Site
+//	  - name: images
+//	    type: file
+//	    repeat: true
+//
+// Its items are checked against the rules of its fields, see Dynamic.Rules.
+type Schema struct {
+	Name string `yaml:"name" json:"name"`
+	// Title is the field naming an item, in the CMS and in its slug.
+	Title string `yaml:"title" json:"title"`
+	// Search indexes the items of the type for searching.
+	Search bool          `yaml:"search" json:"search"`
+	Fields []SchemaField `yaml:"fields" json:"fields"`
+
+	typ reflect.Type
+}
+
+// SchemaField is a field of a Schema. Min and Max bound the value of
+// numbers, and the length of strings.
+type SchemaField struct {
+	Name        string    `yaml:"name" json:"name"`
+	Label       string    `yaml:"label" json:"label"`
+	Type        FieldType `yaml:"type" json:"type"`
+	Placeholder string    `yaml:"placeholder" json:"placeholder"`
+	// Repeat makes the field a list of values.
+	Repeat   bool     `yaml:"repeat" json:"repeat"`
+	Required bool     `yaml:"required" json:"required"`
+	Min      *float64 `yaml:"min" json:"min"`
+	Max      *float64 `yaml:"max" json:"max"`
+	Pattern  string   `yaml:"pattern" json:"pattern"`
+	// Options are the values of a select field.
+	Options []string `yaml:"options" json:"options"`
+	// To is the type a reference field refers to.
+	To string `yaml:"to" json:"to"`
+	// Display is the template of the option of an item of a reference
+	// field, e.g. {{ .title }}. It defaults to its slug.
+	Display string `yaml:"display" json:"display"`
+	// Unique makes the value unique among the items of the type, or among
+	// those with the same value in the field UniqueWithin, e.g. site.
+	Unique       bool   `yaml:"unique" json:"unique"`
+	UniqueWithin string `yaml:"unique_within" json:"unique_within"`
+	// GreaterThan is a field of the same type the value must be greater
+	// than, e.g. start for an end date.
+	GreaterThan string `yaml:"greater_than" json:"greater_than"`
+
+	pattern *regexp.Regexp
+}
+
+var (
+	schemaNameRe  = regexp.MustCompile(`^[A-Z][A-Za-z0-9]*$`)
+	schemaFieldRe = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+	// itemFields are the json names of the fields of Item, which schema
+	// fields can't use.
+	itemFields = map[string]bool{
+		"uuid": true, "status": true, "namespace": true, "id": true,
+		"slug": true, "hash": true, "timestamp": true, "updated": true,
+	}
+)
+
+// LoadSchemas parses the schema files in dir, with a .yaml, .yml or .json
+// extension, in name order. A dir that doesn't exist has no schemas.
+func LoadSchemas(dir string) ([]*Schema, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var schemas []*Schema
+	names := make(map[string]string)
+	for _, e := range entries {
+		switch strings.ToLower(filepath.Ext(e.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		if e.IsDir() {
+			continue
+		}
+
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s, err := ParseSchema(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		if f, ok := names[s.Name]; ok {
+			return nil, fmt.Errorf("%s: type %s is already defined in %s", e.Name(), s.Name, f)
+		}
+		names[s.Name] = e.Name()
+		schemas = append(schemas, s)
+	}
+
+	return schemas, nil
+}
+
+// ParseSchema parses and checks the schema in b, in YAML or JSON.
+func ParseSchema(b []byte) (*Schema, error) {
+	s := &Schema{}
+	d := yaml.NewDecoder(strings.NewReader(string(b)))
+	d.KnownFields(true)
+	if err := d.Decode(s); err != nil {
+		return nil, err
+	}
+	if err := s.check(); err != nil {
+		return nil, err
+	}
+	s.typ = s.structType()
+	return s, nil
+}
+
+func (s *Schema) check() error {
+	if !schemaNameRe.MatchString(s.Name) {
+		return fmt.Errorf("invalid type name %q, it must start with an upper case letter and be alphanumeric", s.Name)
+	}
+	if strings.Contains(s.Name, "__") {
+		return fmt.Errorf("invalid type name %q", s.Name)
+	}
+	if len(s.Fields) == 0 {
+		return fmt.Errorf("type %s has no fields", s.Name)
+	}
+
+	goNames := make(map[string]string)
+	for i := range s.Fields {
+		f := &s.Fields[i]
+		if err := f.check(); err != nil {
+			return fmt.Errorf("type %s: %w", s.Name, err)
+		}
+
+		n := f.goName()
+		if other, ok := goNames[n]; ok {
+			return fmt.Errorf("type %s: fields %s and %s clash", s.Name, other, f.Name)
+		}
+		goNames[n] = f.Name
+	}
+
+	for _, f := range s.Fields {
+		if f.UniqueWithin != "" {
+			if !f.Unique {
+				return fmt.Errorf("type %s: field %s is unique within %s but not unique", s.Name, f.Name, f.UniqueWithin)
+			}
+			if w := s.Field(f.UniqueWithin); w == nil || w.Repeat {
+				return fmt.Errorf("type %s: field %s is unique within %s, which isn't a field", s.Name, f.Name, f.UniqueWithin)
+			}
+		}
+		if f.GreaterThan != "" {
+			g := s.Field(f.GreaterThan)
+			if g == nil || g.Type != f.Type || g.Repeat || f.Repeat {
+				return fmt.Errorf("type %s: field %s is greater than %s, which isn't a field of the same type", s.Name, f.Name, f.GreaterThan)
+			}
+			switch f.Type {
+			case FieldInt, FieldFloat, FieldDate:
+			default:
+				return fmt.Errorf("type %s: %s fields can't be greater than another", s.Name, f.Type)
+			}
+		}
+	}
+
+	if s.Title != "" {
+		f := s.Field(s.Title)
+		if f == nil {
+			return fmt.Errorf("type %s: title field %s not found", s.Name, s.Title)
+		}
+		if f.Repeat {
+			return fmt.Errorf("type %s: title field %s is a repeater", s.Name, s.Title)
+		}
+	}
+
+	return nil
+}
+
+func (f *SchemaField) check() error {
+	if !schemaFieldRe.MatchString(f.Name) {
+		return fmt.Errorf("invalid field name %q, it must be lower case and alphanumeric", f.Name)
+	}
+	if itemFields[f.Name] {
+		return fmt.Errorf("field name %q is reserved", f.Name)
+	}
+
+	switch f.Type {
+	case FieldString, FieldInt, FieldFloat, FieldFile:
+	case FieldText, FieldRichtext, FieldBool, FieldDate:
+		if f.Repeat {
+			return fmt.Errorf("field %s: %s fields can't repeat", f.Name, f.Type)
+		}
+	case FieldSelect:
+		if len(f.Options) == 0 {
+			return fmt.Errorf("field %s: select fields need options", f.Name)
+		}
+	case FieldReference:
+		if f.To == "" {
+			return fmt.Errorf("field %s: reference fields need a type to refer to", f.Name)
+		}
+	case "":
+		return fmt.Errorf("field %s has no type", f.Name)
+	default:
+		return fmt.Errorf("field %s: unknown type %q", f.Name, f.Type)
+	}
+
+	if len(f.Options) > 0 && f.Type != FieldSelect {
+		return fmt.Errorf("field %s: only select fields have options", f.Name)
+	}
+	if f.To != "" && f.Type != FieldReference {
+		return fmt.Errorf("field %s: only reference fields refer to a type", f.Name)
+	}
+	if f.Display != "" {
+		if f.Type != FieldReference {
+			return fmt.Errorf("field %s: only reference fields have a display", f.Name)
+		}
+		if _, err := template.New(f.Name).Parse(f.Display); err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+	}
+	if (f.Min != nil || f.Max != nil) && !f.bounded() {
+		return fmt.Errorf("field %s: %s fields have no min or max", f.Name, f.Type)
+	}
+	if f.Min != nil && f.Max != nil && *f.Min > *f.Max {
+		return fmt.Errorf("field %s: min is greater than max", f.Name)
+	}
+	if f.Pattern != "" {
+		re, err := regexp.Compile(f.Pattern)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		f.pattern = re
+	}
+
+	return nil
+}
+
+// bounded reports whether f has a min and max, of its value or length.
+func (f *SchemaField) bounded() bool {
+	switch f.Type {
+	case FieldInt, FieldFloat, FieldString, FieldText, FieldRichtext:
+		return true
+	}
+	return false
+}
+
+// Field returns the field with name, or nil if there is none.
+func (s *Schema) Field(name string) *SchemaField {
+	for i := range s.Fields {
+		if s.Fields[i].Name == name {
+			return &s.Fields[i]
+		}
+	}
+	return nil
+}
+
+// References returns the types the reference fields of s refer to, sorted.
+func (s *Schema) References() []string {
+	seen := make(map[string]bool)
+	var types []string
+	for _, f := range s.Fields {
+		if f.Type == FieldReference && !seen[f.To] {
+			seen[f.To] = true
+			types = append(types, f.To)
+		}
+	}
+	sort.Strings(types)
+	return types
+}
+
+// Creator returns the content.Creator of the type s defines.
+func (s *Schema) Creator() func() interface{} {
+	return func() interface{} { return NewDynamic(s) }
+}
+
+// structType returns the struct holding the fields of s, with a json tag
+// to a field of its name.
+func (s *Schema) structType() reflect.Type {
+	fields := make([]reflect.StructField, len(s.Fields))
+	for i, f := range s.Fields {
+		fields[i] = reflect.StructField{
+			Name: f.goName(),
+			Type: f.goType(),
+			Tag:  reflect.StructTag(fmt.Sprintf(`json:"%s"`, f.Name)),
+		}
+	}
+	return reflect.StructOf(fields)
+}
+
+// goName is the name of the struct field of f, e.g. ReleaseDate for
+// release_date.
+func (f *SchemaField) goName() string {
+	var b strings.Builder
+	for _, part := range strings.Split(f.Name, "_") {
+		if part == "" {
+			continue
+		}
+		b.WriteString(strings.ToUpper(part[:1]))
+		b.WriteString(part[1:])
+	}
+	return b.String()
+}
+
+func (f *SchemaField) goType() reflect.Type {
+	var t reflect.Type
+	switch f.Type {
+	case FieldInt:
+		t = reflect.TypeOf(int64(0))
+	case FieldFloat:
+		t = reflect.TypeOf(float64(0))
+	case FieldBool:
+		t = reflect.TypeOf(false)
+	default:
+		t = reflect.TypeOf("")
+	}
+	if f.Repeat {
+		return reflect.SliceOf(t)
+	}
+	return t
+}
+
+func (f *SchemaField) label() string {
+	if f.Label != "" {
+		return f.Label
+	}
+	l := strings.ReplaceAll(f.Name, "_", " ")
+	return strings.ToUpper(l[:1]) + l[1:]
+}
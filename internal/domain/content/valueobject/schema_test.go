@@ -0,0 +1,91 @@
+package valueobject
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/pkg/validate"
+)
+
+const productSchema = `
+name: Product
+title: name
+search: true
+fields:
+  - name: name
+    type: string
+    required: true
+    max: 10
+  - name: price
+    type: float
+    min: 0
+  - name: kind
+    type: select
+    options: [book, music]
+  - name: site
+    type: reference
+    to: Site
+  - name: images
+    type: file
+    repeat: true
+`
+
+func TestParseSchema(t *testing.T) {
+	c := qt.New(t)
+
+	s, err := ParseSchema([]byte(productSchema))
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.Name, qt.Equals, "Product")
+	c.Assert(s.References(), qt.DeepEquals, []string{"Site"})
+
+	for _, bad := range []string{
+		`{"name": "product", "fields": [{"name": "a", "type": "string"}]}`,
+		`{"name": "Product", "fields": []}`,
+		`{"name": "Product", "fields": [{"name": "slug", "type": "string"}]}`,
+		`{"name": "Product", "fields": [{"name": "a", "type": "color"}]}`,
+		`{"name": "Product", "fields": [{"name": "a", "type": "select"}]}`,
+		`{"name": "Product", "fields": [{"name": "a", "type": "date", "repeat": true}]}`,
+		`{"name": "Product", "fields": [{"name": "a", "type": "string", "pattern": "("}]}`,
+		`{"name": "Product", "fields": [{"name": "a", "type": "string", "size": 1}]}`,
+		`{"name": "Product", "title": "b", "fields": [{"name": "a", "type": "string"}]}`,
+		`{"name": "Product", "fields": [{"name": "a", "type": "string", "unique_within": "b"}, {"name": "b", "type": "string"}]}`,
+		`{"name": "Product", "fields": [{"name": "a", "type": "int", "greater_than": "b"}, {"name": "b", "type": "string"}]}`,
+	} {
+		_, err := ParseSchema([]byte(bad))
+		c.Check(err, qt.IsNotNil, qt.Commentf("%s", bad))
+	}
+}
+
+func TestDynamic(t *testing.T) {
+	c := qt.New(t)
+
+	s, err := ParseSchema([]byte(productSchema))
+	c.Assert(err, qt.IsNil)
+
+	in := `{"uuid":"00000000-0000-0000-0000-000000000000","status":"public","namespace":"Product","id":1,"slug":"hello","hash":"","timestamp":1,"updated":2,"name":"Hello","price":9.5,"kind":"book","site":"","images":["/a.png","/b.png"]}`
+	d := NewDynamic(s)
+	c.Assert(json.Unmarshal([]byte(in), d), qt.IsNil)
+	c.Assert(d.ID, qt.Equals, 1)
+	c.Assert(d.String(), qt.Equals, "Hello")
+
+	out, err := json.Marshal(d)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(out), qt.Equals, in)
+
+	check := func(v url.Values) string {
+		errs, err := validate.Check(d.Rules(), v, nil)
+		c.Assert(err, qt.IsNil)
+		if len(errs) == 0 {
+			return ""
+		}
+		return errs.Error()
+	}
+	c.Assert(check(url.Values{"name": {"Hello"}, "images": {"/a.png"}}), qt.Equals, "")
+	c.Assert(check(url.Values{"price": {"1"}}), qt.Equals, "name is required")
+	c.Assert(check(url.Values{"name": {"Hello world!"}}), qt.Equals, "name must have at most 10 characters")
+	c.Assert(check(url.Values{"name": {"a"}, "price": {"-1"}}), qt.Equals, "price must be at least 0")
+	c.Assert(check(url.Values{"name": {"a"}, "kind": {"film"}}), qt.Equals, "kind must be one of book, music")
+	c.Assert(check(url.Values{"name": {"a"}, "site": {"/api/content?type=Post&id=1"}}), qt.Equals, "site must refer to a Site")
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"github.com/mdfriday/hugoverse/pkg/editor"
 	"github.com/mdfriday/hugoverse/pkg/language"
+	"github.com/mdfriday/hugoverse/pkg/validate"
 	"net/http"
 	"strings"
 	"text/template"
@@ -26,6 +27,8 @@ type Site struct {
 	DefaultContentLanguage string   `json:"default_content_language,omitempty"`
 	Languages              []string `json:"languages,omitempty"`
 	Menus                  []string `json:"menus,omitempty"`
+
+	siteMenus []*SiteMenu
 }
 
 // MarshalEditor writes a buffer of html to edit a Song within the CMS
@@ -128,6 +131,19 @@ func (s *Site) MarshalEditor() ([]byte, error) {
 // String defines the display name of a Song in the CMS list-view
 func (s *Site) String() string { return s.Title }
 
+// Rules implements content.Validatable, with the rules the fields of a
+// Site must pass before it is saved
+func (s *Site) Rules() []validate.Rule {
+	return []validate.Rule{
+		validate.Required("title"),
+		validate.Length("title", 0, 200),
+		validate.Required("theme"),
+		validate.Required("owner"),
+		validate.URL("base_url"),
+		validate.Regex("sub_domain", hostLabelRe, "must be a lower case host name label, e.g. my-site"),
+	}
+}
+
 // Create implements api.Createable, and allows external POST requests from clients
 // to add content as long as the request contains the json tag names of the Song
 // struct fields, and is multipart encoded
@@ -266,6 +282,10 @@ googleAnalytics = "{{.GoogleAnalytics}}"
 {{- end }}
 {{- end }}
 
+{{- range $menu := .SiteMenus }}
+{{ siteMenuToml $menu }}
+{{- end }}
+
 
 [params]
 {{.Params}}
@@ -277,6 +297,10 @@ googleAnalytics = "{{.GoogleAnalytics}}"
 		},
 		"split":           strings.Split,
 		"getLanguageName": language.GetLanguageName,
+		"siteMenuToml": func(m *SiteMenu) (string, error) {
+			b, err := m.Toml(s.IsMultiLanguages())
+			return string(b), err
+		},
 	}
 
 	tmpl, err := template.New("toml").Funcs(funcMap).Parse(tomlTemplate)
@@ -300,6 +324,15 @@ func (s *Site) HasMenus() bool {
 	return len(s.Menus) > 0
 }
 
+// SetSiteMenus sets the menu trees written into the site config.
+func (s *Site) SetSiteMenus(menus []*SiteMenu) {
+	s.siteMenus = menus
+}
+
+func (s *Site) SiteMenus() []*SiteMenu {
+	return s.siteMenus
+}
+
 func (s *Site) UnmarshalJSON(data []byte) error {
 	// Create a temporary struct with the same fields
 	type Alias Site
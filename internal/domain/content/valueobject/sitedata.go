@@ -0,0 +1,158 @@
+package valueobject
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdfriday/hugoverse/pkg/editor"
+	"github.com/mdfriday/hugoverse/pkg/parser/metadecoders"
+	"github.com/mdfriday/hugoverse/pkg/validate"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// SiteData is a file in the /data directory of a site, e.g. authors/bep.yaml
+// which is available as .Site.Data.authors.bep in the templates.
+type SiteData struct {
+	Item
+
+	Site    string `json:"site"`
+	Path    string `json:"path"`
+	Content string `json:"content"`
+
+	refSelData map[string][][]byte
+}
+
+// MarshalEditor writes a buffer of html to edit a SiteData within the CMS
+// and implements editor.Editable
+func (s *SiteData) MarshalEditor() ([]byte, error) {
+	view, err := editor.Form(s,
+		editor.Field{
+			View: editor.RefSelect("Site", s, map[string]string{
+				"label": "Site",
+			},
+				"Site",
+				`{{ .title }} `,
+				s.refSelData["Site"],
+			),
+		},
+		editor.Field{
+			View: editor.Input("Path", s, map[string]string{
+				"label":       "Path",
+				"type":        "text",
+				"placeholder": "Enter the path relative to the data directory here, e.g. authors/bep.yaml",
+			}),
+		},
+		editor.Field{
+			View: editor.Textarea("Content", s, map[string]string{
+				"label":       "Content",
+				"type":        "textarea",
+				"placeholder": "Enter the data here in the format given by the path extension: json, toml, yaml, csv or xml",
+			}),
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to render SiteData editor view: %s", err.Error())
+	}
+
+	return view, nil
+}
+
+func (s *SiteData) SetSelectData(data map[string][][]byte) {
+	s.refSelData = data
+}
+
+func (s *SiteData) SelectContentTypes() []string {
+	return []string{"Site"}
+}
+
+// ReferenceFields implements content.Referencing
+func (s *SiteData) ReferenceFields() map[string]string {
+	return map[string]string{"site": "Site"}
+}
+
+// String defines the display name of a SiteData in the CMS list-view
+func (s *SiteData) String() string {
+	t, _ := extractTypeAndID(s.Site)
+
+	return strings.Join([]string{t, s.Path}, " - ")
+}
+
+// Rules implements content.Validatable, with the rules the fields of a
+// SiteData must pass before it is saved
+func (s *SiteData) Rules() []validate.Rule {
+	return []validate.Rule{
+		validate.Required("site"),
+		refRule("site", "Site"),
+		validate.Required("path"),
+		pathRule("path"),
+		validate.Unique("path", "site"),
+	}
+}
+
+// Create implements api.Createable, and allows external POST requests from clients
+// to add content as long as the request contains the json tag names of the SiteData
+// struct fields, and is multipart encoded
+func (s *SiteData) Create(res http.ResponseWriter, req *http.Request) error {
+	// do form data validation for required fields
+	required := []string{
+		"site",
+		"path",
+	}
+
+	for _, r := range required {
+		if req.PostFormValue(r) == "" {
+			err := fmt.Errorf("request missing required field: %s", r)
+			return err
+		}
+	}
+
+	_, err := ValidateDataFile(req.PostFormValue("path"), req.PostFormValue("content"))
+	return err
+}
+
+func (s *SiteData) Update(res http.ResponseWriter, req *http.Request) error {
+	p := s.Path
+	if ps, found := req.PostForm["path"]; found && len(ps) > 0 {
+		p = ps[0]
+	}
+	c := s.Content
+	if cs, found := req.PostForm["content"]; found && len(cs) > 0 {
+		c = cs[0]
+	}
+
+	_, err := ValidateDataFile(p, c)
+	return err
+}
+
+func (s *SiteData) IndexContent() bool {
+	return true
+}
+
+// DataPath returns the cleaned path relative to the data directory.
+func (s *SiteData) DataPath() (string, error) {
+	return ValidateDataFile(s.Path, s.Content)
+}
+
+// ValidateDataFile checks that the path stays within the data directory, has
+// a supported data format and that the content can be decoded in that format.
+// It returns the cleaned path.
+func ValidateDataFile(p, content string) (string, error) {
+	p = path.Clean("/" + strings.ReplaceAll(strings.TrimSpace(p), "\\", "/"))
+	if p == "/" || strings.Contains(p, "..") {
+		return "", fmt.Errorf("invalid data file path: %q", p)
+	}
+	p = strings.TrimPrefix(p, "/")
+
+	format := metadecoders.FormatFromString(path.Ext(p))
+	if format == "" || format == metadecoders.ORG {
+		return "", errors.New("invalid data file path: the extension must be one of json, toml, yaml, yml, csv or xml")
+	}
+
+	if _, err := metadecoders.Default.Unmarshal([]byte(content), format); err != nil {
+		return "", fmt.Errorf("invalid data file content: %w", err)
+	}
+
+	return p, nil
+}
@@ -71,6 +71,11 @@ func (s *SiteLanguage) SelectContentTypes() []string {
 	return []string{"Site", "Language"}
 }
 
+// ReferenceFields implements content.Referencing
+func (s *SiteLanguage) ReferenceFields() map[string]string {
+	return map[string]string{"site": "Site", "language": "Language"}
+}
+
 // String defines the display name of a Song in the CMS list-view
 func (s *SiteLanguage) String() string {
 	t, _ := extractTypeAndID(s.Site)
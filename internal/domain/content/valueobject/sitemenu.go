@@ -0,0 +1,276 @@
+package valueobject
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mdfriday/hugoverse/pkg/editor"
+	"github.com/mdfriday/hugoverse/pkg/validate"
+	"github.com/pelletier/go-toml/v2"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// SiteMenu is a menu tree of a site, for one language or, if Language is
+// blank, for all languages. Its entries are rows across the Entry fields,
+// the name, url, weight and parent of the entry at an index of each.
+type SiteMenu struct {
+	Item
+
+	Site     string `json:"site"`
+	Language string `json:"language"`
+	Name     string `json:"name"`
+
+	EntryNames   []string `json:"entry_names"`
+	EntryURLs    []string `json:"entry_urls"`
+	EntryWeights []string `json:"entry_weights"`
+	EntryParents []string `json:"entry_parents"`
+
+	refSelData map[string][][]byte
+}
+
+// MenuEntry is an entry of a SiteMenu, below the entry named Parent if set.
+type MenuEntry struct {
+	Name   string `toml:"name"`
+	URL    string `toml:"url,omitempty"`
+	Weight int    `toml:"weight"`
+	Parent string `toml:"parent,omitempty"`
+}
+
+// MarshalEditor writes a buffer of html to edit a SiteMenu within the CMS
+// and implements editor.Editable
+func (s *SiteMenu) MarshalEditor() ([]byte, error) {
+	view, err := editor.Form(s,
+		editor.Field{
+			View: editor.RefSelect("Site", s, map[string]string{
+				"label": "Site",
+			},
+				"Site",
+				`{{ .title }} `,
+				s.refSelData["Site"],
+			),
+		},
+		editor.Field{
+			View: editor.Input("Language", s, map[string]string{
+				"label":       "Language",
+				"type":        "text",
+				"placeholder": "Enter the language code here, leave blank for all languages",
+			}),
+		},
+		editor.Field{
+			View: editor.Input("Name", s, map[string]string{
+				"label":       "Name",
+				"type":        "text",
+				"placeholder": "Enter the menu name here, e.g. main",
+			}),
+		},
+		editor.Field{
+			View: editor.InputRepeater("EntryNames", s, map[string]string{
+				"label":       "Entry Names",
+				"type":        "text",
+				"placeholder": "Enter the name of an entry here, e.g. Docs",
+			}),
+		},
+		editor.Field{
+			View: editor.InputRepeater("EntryURLs", s, map[string]string{
+				"label":       "Entry URLs",
+				"type":        "text",
+				"placeholder": "Enter the url of the entry in the same row here, e.g. /docs/",
+			}),
+		},
+		editor.Field{
+			View: editor.InputRepeater("EntryWeights", s, map[string]string{
+				"label":       "Entry Weights",
+				"type":        "text",
+				"placeholder": "Enter the weight of the entry in the same row here, leave blank for its row",
+			}),
+		},
+		editor.Field{
+			View: editor.InputRepeater("EntryParents", s, map[string]string{
+				"label":       "Entry Parents",
+				"type":        "text",
+				"placeholder": "Enter the name of the parent of the entry in the same row here, leave blank at the top",
+			}),
+		},
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to render SiteMenu editor view: %s", err.Error())
+	}
+
+	return view, nil
+}
+
+func (s *SiteMenu) SetSelectData(data map[string][][]byte) {
+	s.refSelData = data
+}
+
+func (s *SiteMenu) SelectContentTypes() []string {
+	return []string{"Site"}
+}
+
+// ReferenceFields implements content.Referencing
+func (s *SiteMenu) ReferenceFields() map[string]string {
+	return map[string]string{"site": "Site"}
+}
+
+// String defines the display name of a SiteMenu in the CMS list-view
+func (s *SiteMenu) String() string {
+	t, _ := extractTypeAndID(s.Site)
+
+	parts := []string{t, s.Name}
+	if s.Language != "" {
+		parts = append(parts, s.Language)
+	}
+	return strings.Join(parts, " - ")
+}
+
+// menuKeyRe matches the names usable as TOML bare keys, in the table names
+// of the menu config.
+var menuKeyRe = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+var weightRe = regexp.MustCompile(`^-?[0-9]+$`)
+
+// Rules implements content.Validatable, with the rules the fields of a
+// SiteMenu must pass before it is saved
+func (s *SiteMenu) Rules() []validate.Rule {
+	return []validate.Rule{
+		validate.Required("site"),
+		refRule("site", "Site"),
+		validate.Required("name"),
+		validate.Regex("name", menuKeyRe, "must only have letters, digits, - and _"),
+		validate.Regex("language", menuKeyRe, "must be a language code, e.g. en"),
+		validate.Unique("name", "site", "language"),
+		validate.Regex("entry_weights", weightRe, "must be whole numbers"),
+	}
+}
+
+// Create implements api.Createable, and allows external POST requests from clients
+// to add content as long as the request contains the json tag names of the SiteMenu
+// struct fields, and is multipart encoded
+func (s *SiteMenu) Create(res http.ResponseWriter, req *http.Request) error {
+	// do form data validation for required fields
+	required := []string{
+		"site",
+		"name",
+	}
+
+	for _, r := range required {
+		if req.PostFormValue(r) == "" {
+			err := fmt.Errorf("request missing required field: %s", r)
+			return err
+		}
+	}
+
+	_, err := formMenu(req).Entries()
+	return err
+}
+
+func (s *SiteMenu) Update(res http.ResponseWriter, req *http.Request) error {
+	if _, found := req.PostForm["entry_names"]; found {
+		if _, err := formMenu(req).Entries(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// formMenu is the menu of the entries in the form of req.
+func formMenu(req *http.Request) *SiteMenu {
+	return &SiteMenu{
+		EntryNames:   req.PostForm["entry_names"],
+		EntryURLs:    req.PostForm["entry_urls"],
+		EntryWeights: req.PostForm["entry_weights"],
+		EntryParents: req.PostForm["entry_parents"],
+	}
+}
+
+func (s *SiteMenu) IndexContent() bool {
+	return true
+}
+
+// Entries returns the entries of the rows with a name, weighted by their
+// row unless set. Names are unique, and parents are the names of other
+// entries.
+func (s *SiteMenu) Entries() ([]MenuEntry, error) {
+	at := func(values []string, i int) string {
+		if i < len(values) {
+			return strings.TrimSpace(values[i])
+		}
+		return ""
+	}
+
+	var entries []MenuEntry
+	parents := make(map[string]string)
+	for i := range s.EntryNames {
+		e := MenuEntry{
+			Name:   at(s.EntryNames, i),
+			URL:    at(s.EntryURLs, i),
+			Weight: i + 1,
+			Parent: at(s.EntryParents, i),
+		}
+		if e.Name == "" {
+			if e.URL != "" || e.Parent != "" {
+				return nil, fmt.Errorf("invalid menu entries: the entry of row %d needs a name", i+1)
+			}
+			continue
+		}
+		if _, ok := parents[e.Name]; ok {
+			return nil, fmt.Errorf("invalid menu entries: there are two entries named %q", e.Name)
+		}
+		if w := at(s.EntryWeights, i); w != "" {
+			weight, err := strconv.Atoi(w)
+			if err != nil {
+				return nil, fmt.Errorf("invalid menu entries: the weight of %q must be a whole number", e.Name)
+			}
+			e.Weight = weight
+		}
+
+		parents[e.Name] = e.Parent
+		entries = append(entries, e)
+	}
+
+	for _, e := range entries {
+		// Up the parents, to the top within as many steps as entries.
+		name := e.Name
+		for i := 0; parents[name] != ""; i++ {
+			name = parents[name]
+			if _, ok := parents[name]; !ok {
+				return nil, fmt.Errorf("invalid menu entries: the parent of %q, %q, is not an entry", e.Name, name)
+			}
+			if i == len(entries) {
+				return nil, fmt.Errorf("invalid menu entries: %q is within itself", e.Name)
+			}
+		}
+	}
+
+	return entries, nil
+}
+
+// Toml writes the menu entries as menu config. Menus for a language in a
+// multilingual site are put below that language.
+func (s *SiteMenu) Toml(multilingual bool) ([]byte, error) {
+	entries, err := s.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	table := "menu." + s.Name
+	if multilingual && s.Language != "" {
+		table = "languages." + s.Language + "." + table
+	}
+
+	var buf bytes.Buffer
+	for _, e := range entries {
+		b, err := toml.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(&buf, "\n[[%s]]\n", table)
+		buf.Write(b)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,57 @@
+package valueobject
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/pelletier/go-toml/v2"
+)
+
+func TestSiteMenuToml(t *testing.T) {
+	c := qt.New(t)
+
+	m := &SiteMenu{
+		Language:     "fr",
+		Name:         "main",
+		EntryNames:   []string{"Docs", "Install \x01 \"now\"", ""},
+		EntryURLs:    []string{"/docs/", "/docs/install/"},
+		EntryWeights: []string{"", "-2"},
+		EntryParents: []string{"", "Docs"},
+	}
+
+	b, err := m.Toml(true)
+	c.Assert(err, qt.IsNil)
+
+	var config struct {
+		Languages map[string]struct {
+			Menu map[string][]MenuEntry
+		}
+	}
+	c.Assert(toml.Unmarshal(b, &config), qt.IsNil, qt.Commentf("%s", b))
+	c.Assert(config.Languages["fr"].Menu["main"], qt.DeepEquals, []MenuEntry{
+		{Name: "Docs", URL: "/docs/", Weight: 1},
+		{Name: "Install \x01 \"now\"", URL: "/docs/install/", Weight: -2, Parent: "Docs"},
+	})
+
+	b, err = m.Toml(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Contains, "[[menu.main]]")
+}
+
+func TestSiteMenuEntries(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		menu *SiteMenu
+		err  string
+	}{
+		{&SiteMenu{EntryNames: []string{"", ""}, EntryURLs: []string{"", "/a/"}}, ".*row 2 needs a name"},
+		{&SiteMenu{EntryNames: []string{"A", "A"}}, `.*two entries named "A"`},
+		{&SiteMenu{EntryNames: []string{"A"}, EntryWeights: []string{"first"}}, `.*weight of "A" must be a whole number`},
+		{&SiteMenu{EntryNames: []string{"A"}, EntryParents: []string{"B"}}, `.*parent of "A", "B", is not an entry`},
+		{&SiteMenu{EntryNames: []string{"A", "B"}, EntryParents: []string{"B", "A"}}, `.*"A" is within itself`},
+	} {
+		_, err := test.menu.Entries()
+		c.Assert(err, qt.ErrorMatches, test.err)
+	}
+}
@@ -3,6 +3,7 @@ package valueobject
 import (
 	"fmt"
 	"github.com/mdfriday/hugoverse/pkg/editor"
+	"github.com/mdfriday/hugoverse/pkg/validate"
 	"net/http"
 	"strings"
 )
@@ -63,6 +64,11 @@ func (s *SiteResource) SelectContentTypes() []string {
 	return []string{"Site", "Resource"}
 }
 
+// ReferenceFields implements content.Referencing
+func (s *SiteResource) ReferenceFields() map[string]string {
+	return map[string]string{"site": "Site", "resource": "Resource"}
+}
+
 // String defines the display name of a Song in the CMS list-view
 func (s *SiteResource) String() string {
 	t, _ := extractTypeAndID(s.Site)
@@ -71,6 +77,20 @@ func (s *SiteResource) String() string {
 	return strings.Join([]string{t, l}, " - ")
 }
 
+// Rules implements content.Validatable, with the rules the fields of a
+// SiteResource must pass before it is saved
+func (s *SiteResource) Rules() []validate.Rule {
+	return []validate.Rule{
+		validate.Required("site"),
+		refRule("site", "Site"),
+		validate.Required("resource"),
+		refRule("resource", "Resource"),
+		validate.Required("path"),
+		pathRule("path"),
+		validate.Unique("path", "site"),
+	}
+}
+
 // Create implements api.Createable, and allows external POST requests from clients
 // to add content as long as the request contains the json tag names of the Song
 // struct fields, and is multipart encoded
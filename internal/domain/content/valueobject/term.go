@@ -0,0 +1,19 @@
+package valueobject
+
+import "sort"
+
+// Term is a taxonomy term together with the number of contents using it.
+type Term struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+// SortTerms sorts terms by count, most used first, then by name.
+func SortTerms(terms []Term) {
+	sort.SliceStable(terms, func(i, j int) bool {
+		if terms[i].Count == terms[j].Count {
+			return terms[i].Name < terms[j].Name
+		}
+		return terms[i].Count > terms[j].Count
+	})
+}
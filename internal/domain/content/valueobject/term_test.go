@@ -0,0 +1,32 @@
+package valueobject
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestSortTerms(t *testing.T) {
+	c := qt.New(t)
+
+	terms := []Term{{"hugo", 1}, {"go", 3}, {"css", 1}, {"web", 3}}
+	SortTerms(terms)
+	c.Assert(terms, qt.DeepEquals, []Term{{"go", 3}, {"web", 3}, {"css", 1}, {"hugo", 1}})
+}
+
+func TestPostTerms(t *testing.T) {
+	c := qt.New(t)
+
+	p := &Post{Title: "Hello", Params: "Tags: [go, hugo]\ncategories: news\n"}
+	c.Assert(p.Terms("tags"), qt.DeepEquals, []string{"go", "hugo"})
+	c.Assert(p.Terms("categories"), qt.DeepEquals, []string{"news"})
+	c.Assert(p.Terms("series"), qt.IsNil)
+	c.Assert(p.FrontMatter(), qt.Equals, "---\ntitle: Hello\nTags: [go, hugo]\ncategories: news\n---")
+
+	p = &Post{Title: "Hello", Params: "tags: [go"}
+	c.Assert(p.Terms("tags"), qt.IsNil)
+
+	p = &Post{Title: "Hello", Params: " \n"}
+	c.Assert(p.Terms("tags"), qt.IsNil)
+	c.Assert(p.FrontMatter(), qt.Equals, "---\ntitle: Hello\n---")
+}
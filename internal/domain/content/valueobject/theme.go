@@ -67,6 +67,11 @@ func (s *Theme) SelectContentTypes() []string {
 	return []string{"Author"}
 }
 
+// ReferenceFields implements content.Referencing
+func (s *Theme) ReferenceFields() map[string]string {
+	return map[string]string{"author": "Author"}
+}
+
 // String defines the display name of a Song in the CMS list-view
 func (s *Theme) String() string { return s.Name }
 
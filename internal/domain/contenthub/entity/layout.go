@@ -29,6 +29,10 @@ const (
 	Sitemap                = "sitemap.xml"
 	DefaultSitemap         = DefaultFolder + "/" + "sitemap.xml"
 	InternalDefaultSitemap = InternalFolder + "/" + DefaultFolder + "/" + "sitemap.xml"
+
+	MarkupFolder     = DefaultFolder + "/_markup"
+	RenderCodeblock  = "render-codeblock"
+	DefaultCodeblock = MarkupFolder + "/" + RenderCodeblock + ".html"
 )
 
 type Layout struct{}
@@ -80,9 +80,11 @@ func newPage(source *Source, content *Content) (*Page, error) {
 		Source:  source,
 		Content: content,
 		Meta: &Meta{
-			List:       Always,
-			Parameters: map[string]any{},
-			Date:       time.Now(),
+			List:             Always,
+			Render:           Always,
+			PublishResources: true,
+			Parameters:       map[string]any{},
+			Date:             time.Now(),
 		},
 
 		kind: valueobject.KindPage,
@@ -214,6 +214,33 @@ func (b *PageBuilder) applyFrontMatter(p *Page) error {
 	p.Meta.Weight = b.fm.Weight
 	p.Meta.Parameters = b.fm.Params
 	p.Meta.Date = b.fm.Date
+	p.Meta.Lastmod = b.fm.Lastmod
+	p.Meta.Cascade = b.fm.Cascade
+
+	if !b.fm.Build.IsZero() {
+		p.Meta.applyBuildConfig(b.fm.Build)
+		p.Meta.buildSet = true
+	}
+
+	return nil
+}
+
+// applyTaxonomyFrontMatter applies the front matter from e.g.
+// content/tags/_index.md or content/tags/go/_index.md, keeping the
+// singular or term name as title unless one is set. Taxonomies and
+// terms, with an _index.md or not, have no weight unless one is set, to
+// sort after the weighted ones in ByWeight.
+func (b *PageBuilder) applyTaxonomyFrontMatter(p *Page) error {
+	title := p.title
+	if err := b.applyFrontMatter(p); err != nil {
+		return err
+	}
+	if p.title == "" {
+		p.title = title
+	}
+	if _, found := b.fm.Params["weight"]; !found {
+		p.Meta.Weight = valueobject.NoWeight
+	}
 
 	return nil
 }
@@ -277,6 +304,9 @@ func (b *PageBuilder) buildTaxonomy() (*TaxonomyPage, error) {
 		return nil, err
 	}
 
+	if err := b.applyTaxonomyFrontMatter(tp.Page); err != nil {
+		return nil, err
+	}
 	tp.pageMap = b.PageMapper
 
 	if err := b.buildOutput(tp.Page); err != nil {
@@ -300,6 +330,9 @@ func (b *PageBuilder) buildTerm() (*TermPage, error) {
 		return nil, err
 	}
 
+	if err := b.applyTaxonomyFrontMatter(t.Page); err != nil {
+		return nil, err
+	}
 	t.pageMap = b.PageMapper
 
 	if err := b.buildOutput(t.Page); err != nil {
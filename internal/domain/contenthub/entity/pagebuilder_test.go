@@ -0,0 +1,55 @@
+package entity
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/contenthub"
+	"github.com/mdfriday/hugoverse/internal/domain/contenthub/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/maps"
+)
+
+// noTaxonomies is a site without taxonomies.
+type noTaxonomies struct{}
+
+func (noTaxonomies) Views() []contenthub.Taxonomy { return nil }
+
+func TestApplyTaxonomyFrontMatter(t *testing.T) {
+	c := qt.New(t)
+
+	frontMatter := func(params maps.Params) *valueobject.FrontMatter {
+		fm, err := (&valueobject.FrontMatterParser{Params: params, TaxonomySvc: noTaxonomies{}}).Parse()
+		c.Assert(err, qt.IsNil)
+		return fm
+	}
+
+	for _, test := range []struct {
+		name   string
+		fm     *valueobject.FrontMatter
+		title  string
+		weight int
+	}{
+		{"synthesized", valueobject.NewFrontMatter(), "go", valueobject.NoWeight},
+		{"index", frontMatter(maps.Params{"description": "All about Go"}), "go", valueobject.NoWeight},
+		{"index with title and weight", frontMatter(maps.Params{"title": "Golang", "weight": 5}), "Golang", 5},
+		{"index with zero weight", frontMatter(maps.Params{"weight": 0}), "go", valueobject.NoWeight},
+	} {
+		term, err := newTerm(nil, nil, "tag", "go")
+		c.Assert(err, qt.IsNil)
+
+		b := &PageBuilder{fm: test.fm}
+		c.Assert(b.applyTaxonomyFrontMatter(term.Page), qt.IsNil)
+		c.Assert(term.Title(), qt.Equals, test.title, qt.Commentf(test.name))
+		c.Assert(term.PageWeight(), qt.Equals, test.weight, qt.Commentf(test.name))
+	}
+
+	// The params of the term are those of its front matter.
+	term, err := newTerm(nil, nil, "tag", "go")
+	c.Assert(err, qt.IsNil)
+	b := &PageBuilder{fm: frontMatter(maps.Params{"description": "All about Go"})}
+	c.Assert(b.applyTaxonomyFrontMatter(term.Page), qt.IsNil)
+	c.Assert(term.Params()["description"], qt.Equals, "All about Go")
+
+	// Regular pages keep the default weight of the front matter.
+	c.Assert(frontMatter(maps.Params{}).Weight, qt.Equals, 10000)
+}
@@ -126,12 +126,10 @@ func (c *ContentProvider) ContentSummary() (valueobject.ContentSummary, error) {
 		}
 
 		res, err := c.converter.Convert(markdown.RenderContext{
-			Ctx:       context.Background(),
-			Src:       contentToRender,
-			RenderTOC: true,
-			GetRenderer: func(t markdown.RendererType, id any) any {
-				return nil
-			},
+			Ctx:         context.Background(),
+			Src:         contentToRender,
+			RenderTOC:   true,
+			GetRenderer: c.renderHook,
 		})
 		if err != nil {
 			return nil, err
@@ -364,12 +362,10 @@ func (c *ContentProvider) doRenderShortcode(sc *valueobject.Shortcode, parent *S
 			var err error
 			b, err := c.converter.Convert(
 				markdown.RenderContext{
-					Ctx:       context.Background(),
-					Src:       []byte(inner),
-					RenderTOC: false,
-					GetRenderer: func(t markdown.RendererType, id any) any {
-						return nil
-					},
+					Ctx:         context.Background(),
+					Src:         []byte(inner),
+					RenderTOC:   false,
+					GetRenderer: c.renderHook,
 				})
 			if err != nil {
 				return valueobject.ZeroShortcode, err
@@ -31,7 +31,8 @@ func (p *Page) Pages() contenthub.Pages {
 	case valueobject.KindTerm:
 		return p.pageMap.getPagesWithTerm(
 			pageMapQueryPagesBelowPath{
-				Path: p.Paths().Base(),
+				Path:    p.Paths().Base(),
+				KeyPart: "term-pages",
 			},
 		)
 	case valueobject.KindTaxonomy:
@@ -90,6 +91,7 @@ func (p *Page) RegularPages() contenthub.Pages {
 		return p.pageMap.getPagesWithTerm(
 			pageMapQueryPagesBelowPath{
 				Path:    p.Paths().Base(),
+				KeyPart: "term-regular-pages",
 				Include: pagePredicates.ShouldListLocal.And(pagePredicates.KindPage),
 			},
 		)
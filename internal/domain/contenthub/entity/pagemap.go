@@ -169,18 +169,79 @@ func (m *PageMap) cleanPages() error {
 }
 
 func (m *PageMap) applyAggregates() error {
-
 	// TODO
-	// Apply cascade Aggregates to pages
 	// Apply Dates to home, section, and meta changed pages
-	// Apply cascade to source page
-	// Use linked list to connect all the cascade in the same path
 
-	// Restore all the changed pages in the cache
+	for _, idx := range m.PageBuilder.LangSvc.LanguageIndexes() {
+		if err := m.applyCascades(idx); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+type pageMetaProvider interface {
+	pageMeta() *Meta
+}
+
+// applyCascades walks the pages from the root and down, so the ancestors are
+// always visited before their descendants, and merges the cascade of the
+// ancestors into the params and build options of every page below them.
+func (m *PageMap) applyCascades(langIndex int) error {
+	cascades := make(map[string]*valueobject.Cascade)
+
+	w := &doctree.NodeShiftTreeWalker[*PageTreesNode]{
+		Tree:     m.TreePages.Shape(0, langIndex),
+		LockType: doctree.LockTypeRead,
+		Handle: func(key string, n *PageTreesNode, match doctree.DimensionFlag) (bool, error) {
+			p, found := n.getPage()
+			if !found {
+				return false, nil
+			}
+			mp, ok := p.(pageMetaProvider)
+			if !ok {
+				return false, nil
+			}
+			meta := mp.pageMeta()
+
+			// The closest ancestor wins.
+			for _, ancestor := range cascadeAncestorKeys(key) {
+				if c, found := cascades[ancestor]; found {
+					c.ApplyTo(p, meta.Parameters)
+				}
+			}
+
+			if !meta.buildSet {
+				b, err := valueobject.BuildConfigFromParams(meta.Parameters)
+				if err != nil {
+					return true, fmt.Errorf("page %q: %w", key, err)
+				}
+				if !b.IsZero() {
+					meta.applyBuildConfig(b)
+				}
+			}
+
+			if meta.Cascade != nil {
+				cascades[key] = meta.Cascade
+			}
+
+			return false, nil
+		},
+	}
+
+	return w.Walk(context.Background())
+}
+
+func cascadeAncestorKeys(key string) []string {
+	var keys []string
+	for key != "" && key != "/" {
+		key = path.Dir(key)
+		keys = append(keys, key)
+	}
+	return append(keys, "")
+}
+
 func (m *PageMap) assembleStructurePages() error {
 
 	if err := m.addMissingTaxonomies(); err != nil {
@@ -363,6 +424,7 @@ func (m *PageMap) getPagesWithTerm(q pageMapQueryPagesBelowPath) contenthub.Page
 
 	v, err := m.Cache.CachePages1.GetOrCreate(key, func(string) (contenthub.Pages, error) {
 		var pas contenthub.Pages
+		weights := make(map[contenthub.Page]int)
 		include := q.Include
 		if include == nil {
 			include = pagePredicates.ShouldListLocal
@@ -375,6 +437,7 @@ func (m *PageMap) getPagesWithTerm(q pageMapQueryPagesBelowPath) contenthub.Page
 				p, found := n.getPage()
 				if found && include(p) {
 					pas = append(pas, p)
+					weights[p] = n.term.Weight()
 				}
 
 				return false, nil
@@ -385,7 +448,12 @@ func (m *PageMap) getPagesWithTerm(q pageMapQueryPagesBelowPath) contenthub.Page
 			return nil, err
 		}
 
+		// Pages within a term are ordered by their taxonomy weight,
+		// e.g. tags_weight, then by the default sort.
 		valueobject.SortByDefault(pas)
+		valueobject.SortByTaxonomyWeight(pas, func(p contenthub.Page) int {
+			return weights[p]
+		})
 
 		return pas, nil
 	})
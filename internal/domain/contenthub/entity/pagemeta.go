@@ -8,18 +8,37 @@ import (
 )
 
 const (
-	Never       = "never"
-	Always      = "always"
-	ListLocally = "local"
-	Link        = "link"
+	Never       = valueobject.Never
+	Always      = valueobject.Always
+	ListLocally = valueobject.ListLocally
+	Link        = valueobject.Link
 )
 
 type Meta struct {
-	List       string
+	List             string
+	Render           string
+	PublishResources bool
+
 	Parameters maps.Params
 	Weight     int
 
-	Date time.Time
+	Date    time.Time
+	Lastmod time.Time
+
+	// buildSet is true if the build options came from the page's own front matter,
+	// in which case they win over any cascade from the ancestors.
+	buildSet bool
+	Cascade  *valueobject.Cascade
+}
+
+func (m *Meta) pageMeta() *Meta {
+	return m
+}
+
+func (m *Meta) applyBuildConfig(b valueobject.BuildConfig) {
+	m.List = b.List
+	m.Render = b.Render
+	m.PublishResources = b.PublishResources
 }
 
 func (m *Meta) Description() string {
@@ -42,6 +61,11 @@ func (m *Meta) PageDate() time.Time {
 	return m.Date
 }
 
+// PageLastmod returns the lastmod set in front matter, zero if not set.
+func (m *Meta) PageLastmod() time.Time {
+	return m.Lastmod
+}
+
 func (m *Meta) PublishDate() time.Time {
 	return m.PageDate()
 }
@@ -85,5 +109,13 @@ func (m *Meta) NoLink() bool {
 }
 
 func (m *Meta) noLink() bool {
-	return false // TODO, updated based on configuration
+	return m.Render == Never
+}
+
+func (m *Meta) ShouldRender() bool {
+	return m.Render == Always
+}
+
+func (m *Meta) ShouldPublishResources() bool {
+	return m.PublishResources
 }
@@ -0,0 +1,69 @@
+package entity
+
+import (
+	"bytes"
+	"context"
+	"github.com/mdfriday/hugoverse/internal/domain/contenthub"
+	"github.com/mdfriday/hugoverse/internal/domain/markdown"
+	"github.com/mdfriday/hugoverse/internal/domain/template"
+	pio "github.com/mdfriday/hugoverse/pkg/io"
+	"github.com/mdfriday/hugoverse/pkg/text"
+)
+
+// codeBlockHook renders code blocks with a render-codeblock template,
+// e.g. _default/_markup/render-codeblock-goat.html for goat code blocks.
+type codeBlockHook struct {
+	templ       template.Preparer
+	templateSvc contenthub.Template
+	source      *Source
+}
+
+func (h *codeBlockHook) RenderCodeblock(cctx context.Context, w pio.FlexiWriter, ctx markdown.CodeblockContext) error {
+	return h.templateSvc.ExecuteWithContext(cctx, h.templ, w, ctx)
+}
+
+// ResolvePosition resolves the position of the opening code fence,
+// in line with how shortcode errors are reported.
+func (h *codeBlockHook) ResolvePosition(ctx any) text.Position {
+	source, err := h.source.contentSource()
+	if err != nil {
+		return text.Position{Filename: h.source.File.Filename(), LineNumber: 1, ColumnNumber: 1}
+	}
+
+	var offset int
+	if cb, ok := ctx.(markdown.CodeblockContext); ok {
+		offset = max(bytes.Index(source, []byte(cb.Inner())), 0)
+	}
+
+	pos := h.source.posFromInput(source, offset)
+	if pos.LineNumber > 1 {
+		pos.LineNumber--
+	}
+
+	return pos
+}
+
+// renderHook returns the render hook of type t for id, nil if the
+// site has no template for it and the default rendering applies.
+func (c *ContentProvider) renderHook(t markdown.RendererType, id any) any {
+	if t != markdown.CodeBlockRendererType {
+		return nil
+	}
+
+	var names []string
+	if lang, _ := id.(string); lang != "" {
+		names = append(names, MarkupFolder+"/"+RenderCodeblock+"-"+lang+".html")
+	}
+	names = append(names, DefaultCodeblock)
+
+	templ, found, err := c.templateSvc.LookupLayout(names)
+	if err != nil {
+		c.log.Errorf("Failed to look up code block render hook %v: %s", names, err)
+		return nil
+	}
+	if !found {
+		return nil
+	}
+
+	return &codeBlockHook{templ: templ, templateSvc: c.templateSvc, source: c.source}
+}
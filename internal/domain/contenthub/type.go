@@ -102,6 +102,12 @@ type Template interface {
 	ExecuteWithContext(ctx context.Context, tmpl template.Preparer, wr io.Writer, data any) error
 	LookupVariants(name string) []template.Preparer
 	LookupVariant(name string, variants template.Variants) (template.Preparer, bool, bool)
+	LookupLayout(names []string) (template.Preparer, bool, error)
+
+	// ValidateShortcode checks the parameters of a shortcode invocation
+	// against the shortcode's schema, returning them with the declared
+	// types and defaults.
+	ValidateShortcode(name string, params any) (any, error)
 }
 
 type BuildStateReseter interface {
@@ -352,12 +358,15 @@ type PageMeta interface {
 	Params() maps.Params
 	PageWeight() int
 	PageDate() time.Time
+	PageLastmod() time.Time
 	PublishDate() time.Time
 	RelatedKeywords(cfg IndexConfig) ([]Keyword, error)
 
 	ShouldList(global bool) bool
 	ShouldListAny() bool
 	NoLink() bool
+	ShouldRender() bool
+	ShouldPublishResources() bool
 }
 
 type PageOutput interface {
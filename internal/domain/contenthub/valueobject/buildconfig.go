@@ -0,0 +1,106 @@
+package valueobject
+
+import (
+	"fmt"
+	"github.com/mdfriday/hugoverse/pkg/maps"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+	"strings"
+)
+
+const (
+	Never       = "never"
+	Always      = "always"
+	ListLocally = "local"
+	Link        = "link"
+)
+
+// DefaultBuildConfig is the build config for pages without any build options.
+var DefaultBuildConfig = BuildConfig{
+	List:             Always,
+	Render:           Always,
+	PublishResources: true,
+	set:              true,
+}
+
+// BuildConfig holds configuration options about how to handle a Page in the
+// build process.
+type BuildConfig struct {
+	// Whether to add it to any of the page collections.
+	// Note that the page can always be found with .Site.GetPage.
+	// Valid values: never, always, local.
+	// Setting it to 'local' means they will be available via the local
+	// page collections, e.g. $section.Pages.
+	// Note: before 0.57.2 this was a bool, so we accept those too.
+	List string
+
+	// Whether to render it.
+	// Valid values: never, always, link.
+	// The value link means it will not be rendered, but it will get a RelPermalink/Permalink.
+	// Note that before 0.76.0 this was a bool, so we accept those too.
+	Render string
+
+	// Whether to publish its resources. These will still be published on demand,
+	// but enabling this can be useful if the originals (e.g. images) are
+	// never used.
+	PublishResources bool
+
+	set bool // BuildCfg is non-zero if this is set to true.
+}
+
+func (b BuildConfig) IsZero() bool {
+	return !b.set
+}
+
+func DecodeBuildConfig(m any) (BuildConfig, error) {
+	b := DefaultBuildConfig
+	if m == nil {
+		return b, nil
+	}
+
+	err := mapstructure.WeakDecode(maps.ToStringMap(m), &b)
+	if err != nil {
+		return b, fmt.Errorf("failed to decode build config: %w", err)
+	}
+
+	b.List = normalizeBuildValue(b.List, ListLocally)
+	b.Render = normalizeBuildValue(b.Render, Link)
+	b.set = true
+
+	return b, nil
+}
+
+// normalizeBuildValue maps the legacy bool values and validates the
+// given value, falling back to Always for anything unknown.
+func normalizeBuildValue(v string, extra string) string {
+	v = strings.ToLower(v)
+	switch v {
+	case "0", "false":
+		return Never
+	case "1", "true":
+		return Always
+	case Always, Never, extra:
+		return v
+	default:
+		return Always
+	}
+}
+
+// BuildConfigFromParams reads the build options from front matter,
+// either from the build (or legacy _build) key or from the headless flag.
+func BuildConfigFromParams(params maps.Params) (BuildConfig, error) {
+	for _, key := range []string{"build", "_build"} {
+		if v, found := params[key]; found {
+			return DecodeBuildConfig(v)
+		}
+	}
+
+	if v, found := params["headless"]; found && cast.ToBool(v) {
+		b := DefaultBuildConfig
+		b.List = Never
+		b.Render = Never
+		return b, nil
+	}
+
+	return BuildConfig{}, nil
+}
@@ -0,0 +1,55 @@
+package valueobject
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/pkg/maps"
+)
+
+func TestDecodeBuildConfig(t *testing.T) {
+	c := qt.New(t)
+
+	b, err := DecodeBuildConfig(map[string]any{
+		"list":             "local",
+		"render":           "link",
+		"publishResources": false,
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(b.List, qt.Equals, ListLocally)
+	c.Assert(b.Render, qt.Equals, Link)
+	c.Assert(b.PublishResources, qt.IsFalse)
+	c.Assert(b.IsZero(), qt.IsFalse)
+
+	// Legacy bool values.
+	b, err = DecodeBuildConfig(map[string]any{
+		"list":   false,
+		"render": true,
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(b.List, qt.Equals, Never)
+	c.Assert(b.Render, qt.Equals, Always)
+	c.Assert(b.PublishResources, qt.IsTrue)
+
+	b, err = DecodeBuildConfig(map[string]any{"list": "sometimes"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(b.List, qt.Equals, Always)
+}
+
+func TestBuildConfigFromParams(t *testing.T) {
+	c := qt.New(t)
+
+	b, err := BuildConfigFromParams(maps.Params{"title": "No build options"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(b.IsZero(), qt.IsTrue)
+
+	b, err = BuildConfigFromParams(maps.Params{"headless": true})
+	c.Assert(err, qt.IsNil)
+	c.Assert(b.List, qt.Equals, Never)
+	c.Assert(b.Render, qt.Equals, Never)
+
+	b, err = BuildConfigFromParams(maps.Params{"_build": maps.Params{"render": "never"}})
+	c.Assert(err, qt.IsNil)
+	c.Assert(b.List, qt.Equals, Always)
+	c.Assert(b.Render, qt.Equals, Never)
+}
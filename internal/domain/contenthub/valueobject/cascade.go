@@ -2,6 +2,7 @@ package valueobject
 
 import (
 	"fmt"
+	"github.com/mdfriday/hugoverse/internal/domain/contenthub"
 	"github.com/mdfriday/hugoverse/pkg/maps"
 	"strings"
 )
@@ -18,6 +19,24 @@ func NewCascade(cas any) (*Cascade, error) {
 	return &Cascade{params: cascade}, nil
 }
 
+// ApplyTo copies the cascading params matching p into params.
+// Keys already set in params are left untouched.
+func (c *Cascade) ApplyTo(p contenthub.Page, params maps.Params) {
+	if c == nil {
+		return
+	}
+	for m, cp := range c.params {
+		if !m.Matches(p) {
+			continue
+		}
+		for k, v := range cp {
+			if _, found := params[k]; !found {
+				params[k] = v
+			}
+		}
+	}
+}
+
 func DecodeCascadeConfig(in any) (map[PageMatcher]maps.Params, error) {
 	cascade := make(map[PageMatcher]maps.Params)
 	if in == nil {
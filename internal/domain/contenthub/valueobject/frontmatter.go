@@ -21,10 +21,13 @@ type FrontMatter struct {
 	Title  string
 	Weight int
 
-	Date time.Time
+	Date    time.Time
+	Lastmod time.Time
 
 	Terms map[string][]string
 
+	Build BuildConfig
+
 	Params maps.Params
 }
 
@@ -74,6 +77,10 @@ func (b *FrontMatterParser) Parse() (*FrontMatter, error) {
 		return nil, err
 	}
 
+	if err := b.parseBuild(fm); err != nil {
+		return nil, err
+	}
+
 	return fm, nil
 }
 
@@ -83,6 +90,23 @@ func (b *FrontMatterParser) parseDate(fm *FrontMatter) error {
 		fm.Date = cast.ToTime(v)
 	}
 
+	for _, key := range []string{"lastmod", "modified"} {
+		if v, found := b.Params[key]; found {
+			fm.Lastmod = cast.ToTime(v)
+			break
+		}
+	}
+
+	return nil
+}
+
+func (b *FrontMatterParser) parseBuild(fm *FrontMatter) error {
+	bc, err := BuildConfigFromParams(b.Params)
+	if err != nil {
+		return err
+	}
+	fm.Build = bc
+
 	return nil
 }
 
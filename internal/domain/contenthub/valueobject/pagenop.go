@@ -70,6 +70,10 @@ func (p *nopPage) PageDate() time.Time {
 	panic("implement me")
 }
 
+func (p *nopPage) PageLastmod() time.Time {
+	return time.Time{}
+}
+
 func (p *nopPage) Truncated() bool {
 	//TODO implement me
 	panic("implement me")
@@ -125,6 +129,14 @@ func (p *nopPage) NoLink() bool {
 	panic("implement me")
 }
 
+func (p *nopPage) ShouldRender() bool {
+	return false
+}
+
+func (p *nopPage) ShouldPublishResources() bool {
+	return false
+}
+
 func (p *nopPage) PageWeight() int {
 	//TODO implement me
 	panic("implement me")
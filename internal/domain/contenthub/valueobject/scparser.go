@@ -1,13 +1,17 @@
 package valueobject
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"github.com/mdfriday/hugoverse/internal/domain/contenthub"
 	"github.com/mdfriday/hugoverse/internal/domain/template"
+	"github.com/mdfriday/hugoverse/pkg/herrors"
 	"github.com/mdfriday/hugoverse/pkg/parser/pageparser"
+	"github.com/mdfriday/hugoverse/pkg/text"
 	"strconv"
 	"sync"
+	"unicode/utf8"
 )
 
 type ShortcodeParser struct {
@@ -44,6 +48,9 @@ func (s *ShortcodeParser) ParseItem(it pageparser.Item, pt *pageparser.Iterator)
 	if err != nil {
 		return nil, err
 	}
+	if err := s.validateParams(currShortcode, it.Pos()); err != nil {
+		return nil, err
+	}
 
 	currShortcode.Pos = it.Pos()
 	currShortcode.Length = pt.Current().Pos() - it.Pos()
@@ -118,6 +125,9 @@ Loop:
 				if nested != nil && nested.Name != "" {
 					s.addName(nested.Name)
 				}
+				if err == nil {
+					err = s.validateParams(nested, currItem.Pos())
+				}
 
 				if err == nil {
 					sc.Inner = append(sc.Inner, nested)
@@ -224,6 +234,30 @@ Loop:
 	return sc, nil
 }
 
+// validateParams validates the shortcode parameters against its schema,
+// reporting errors at pos, the byte offset of the shortcode in the source.
+func (s *ShortcodeParser) validateParams(sc *Shortcode, pos int) error {
+	if sc.IsInline || sc.Name == "" {
+		return nil
+	}
+	params, err := s.tmplSvc.ValidateShortcode(sc.Name, sc.Params)
+	if err != nil {
+		return herrors.NewFileErrorFromPos(err, s.position(pos))
+	}
+	sc.Params = params
+
+	return nil
+}
+
+func (s *ShortcodeParser) position(offset int) text.Position {
+	lineStart := bytes.LastIndexByte(s.source[:offset], '\n') + 1
+	return text.Position{
+		Offset:       offset,
+		LineNumber:   bytes.Count(s.source[:offset], []byte("\n")) + 1,
+		ColumnNumber: utf8.RuneCount(s.source[lineStart:offset]) + 1,
+	}
+}
+
 func (s *ShortcodeParser) addName(name string) {
 	s.nameSetMu.Lock()
 	defer s.nameSetMu.Unlock()
@@ -16,6 +16,32 @@ func SortByWeight(pages contenthub.Pages) {
 	pageBy(weight).Sort(pages)
 }
 
+// SortByTaxonomyWeight stable sorts pages by the weight they are given in a
+// taxonomy. Pages without a weight, i.e. weight 0, are put last.
+func SortByTaxonomyWeight(pages contenthub.Pages, weightOf func(p contenthub.Page) int) {
+	pageBy(func(p1, p2 contenthub.Page) bool {
+		return LessWeight(weightOf(p1), weightOf(p2))
+	}).Sort(pages)
+}
+
+// NoWeight is the weight of the pages not weighted in a taxonomy, and of
+// the taxonomies and terms without a weight set.
+const NoWeight = 0
+
+// LessWeight reports whether weight w1 sorts before w2, zero weights last.
+func LessWeight(w1, w2 int) bool {
+	switch {
+	case w1 == w2:
+		return false
+	case w1 == NoWeight:
+		return false
+	case w2 == NoWeight:
+		return true
+	default:
+		return w1 < w2
+	}
+}
+
 func SortByLanguage(pages contenthub.Pages) {
 	// TODO
 	pageBy(lessPageTitle).Sort(pages)
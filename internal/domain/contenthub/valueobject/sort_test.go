@@ -0,0 +1,56 @@
+package valueobject
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/contenthub"
+)
+
+// namedPage is a page known by its name only.
+type namedPage struct {
+	contenthub.Page
+	name string
+}
+
+func TestLessWeight(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		w1, w2 int
+		less   bool
+	}{
+		{1, 2, true},
+		{2, 1, false},
+		{-1, 1, true},
+		{3, 3, false},
+		{1, NoWeight, true},
+		{-1, NoWeight, true},
+		{NoWeight, 1, false},
+		{NoWeight, NoWeight, false},
+	} {
+		c.Assert(LessWeight(test.w1, test.w2), qt.Equals, test.less, qt.Commentf("%v", test))
+	}
+}
+
+func TestSortByTaxonomyWeight(t *testing.T) {
+	c := qt.New(t)
+
+	weights := map[string]int{"a": NoWeight, "b": 20, "c": 10, "d": NoWeight, "e": 10}
+	var pages contenthub.Pages
+	for _, name := range []string{"a", "b", "c", "d", "e"} {
+		pages = append(pages, &namedPage{name: name})
+	}
+
+	SortByTaxonomyWeight(pages, func(p contenthub.Page) int {
+		return weights[p.(*namedPage).name]
+	})
+
+	var names []string
+	for _, p := range pages {
+		names = append(names, p.(*namedPage).name)
+	}
+	// The pages without a weight go last, the ones of equal weight keep
+	// their order.
+	c.Assert(names, qt.DeepEquals, []string{"c", "e", "b", "a", "d"})
+}
@@ -27,6 +27,10 @@ type OriginFs struct {
 	AbsWorkingDir   string
 	AbsPublishDir   string
 	AbsResourcesDir string
+
+	// PostProcessFileSet holds the published files containing
+	// resources.PostProcess placeholders.
+	PostProcessFileSet *valueobject.FileSet
 }
 
 func (f *OriginFs) Origin() afero.Fs {
@@ -49,6 +53,21 @@ func (f *OriginFs) Working() afero.Fs {
 	return f.WorkingDirReadOnly
 }
 
+// WorkingWritable is the writable file system restricted to the project
+// working dir, e.g. for the build stats.
+func (f *OriginFs) WorkingWritable() afero.Fs {
+	return f.WorkingDirWritable
+}
+
+// PostProcessFiles returns the published files waiting for post processing,
+// relative to the publish dir.
+func (f *OriginFs) PostProcessFiles() []string {
+	if f.PostProcessFileSet == nil {
+		return nil
+	}
+	return f.PostProcessFileSet.Names()
+}
+
 func (f *OriginFs) PublishDirStatic() afero.Fs {
 	return valueobject.NewHashingFs(f.PublishDir, valueobject.NewFileChangeDetector())
 }
@@ -22,6 +22,10 @@ func (f *Fs) WalkI18n(start string, cb fs.WalkCallback, conf fs.WalkwayConfig) e
 	return f.Walk(f.I18n, start, cb, conf)
 }
 
+func (f *Fs) WalkData(start string, cb fs.WalkCallback, conf fs.WalkwayConfig) error {
+	return f.Walk(f.Data, start, cb, conf)
+}
+
 func (f *Fs) Walk(fs afero.Fs, start string, cb fs.WalkCallback, conf fs.WalkwayConfig) error {
 	w, err := valueobject.NewWalkway(fs, cb)
 	if err != nil {
@@ -1,7 +1,6 @@
 package factory
 
 import (
-	"fmt"
 	"github.com/mdfriday/hugoverse/internal/domain/fs"
 	"github.com/mdfriday/hugoverse/internal/domain/fs/entity"
 	"github.com/mdfriday/hugoverse/internal/domain/fs/valueobject"
@@ -46,28 +45,38 @@ func NewOriginFs(dir fs.Dir) *entity.OriginFs {
 		absResourcesDir = paths.FilePathSeparator
 	}
 
-	osFs := afero.NewOsFs()
+	sourceFs := dir.Fs()
+	if sourceFs == nil {
+		sourceFs = afero.NewOsFs()
+	}
+	publishFs := dir.OutputFs()
+	if publishFs == nil {
+		publishFs = afero.NewBasePathFs(sourceFs, absPublishDir)
+	}
+	postProcessFiles := valueobject.NewFileSet()
 
 	return &entity.OriginFs{
-		Source:             valueobject.NewBaseFs(osFs),
-		PublishDir:         valueobject.NewBaseFs(getPublishFs(osFs, absPublishDir, dir.MediaTypes())),
-		WorkingDirReadOnly: getWorkingDirFsReadOnly(osFs, workingDir),
-		WorkingDirWritable: getWorkingDirFsWritable(osFs, workingDir),
+		Source:             valueobject.NewBaseFs(sourceFs),
+		PublishDir:         valueobject.NewBaseFs(getPublishFs(publishFs, dir.MediaTypes(), postProcessFiles)),
+		WorkingDirReadOnly: getWorkingDirFsReadOnly(sourceFs, workingDir),
+		WorkingDirWritable: getWorkingDirFsWritable(sourceFs, workingDir),
 
 		AbsWorkingDir:   workingDir,
 		AbsPublishDir:   absPublishDir,
 		AbsResourcesDir: absResourcesDir,
+
+		PostProcessFileSet: postProcessFiles,
 	}
 }
 
-func getPublishFs(base afero.Fs, publishDir string, mediaTypes media.Types) afero.Fs {
-	pub := afero.NewBasePathFs(base, publishDir)
-
+func getPublishFs(pub afero.Fs, mediaTypes media.Types, postProcessFiles *valueobject.FileSet) afero.Fs {
+	// Files with resources.PostProcess placeholders are rewritten when
+	// the build is done.
 	hashBytesReceiverFunc := func(name string, match bool) {
 		if !match {
 			return
 		}
-		fmt.Println("publish fs hashBytesReceiverFunc: ", name)
+		postProcessFiles.Add(name)
 	}
 
 	hashBytesSHouldCheck := func(name string) bool {
@@ -12,6 +12,12 @@ type Dir interface {
 	PublishDir() string
 	ResourceDir() string
 
+	// Fs is the file system holding the project.
+	Fs() afero.Fs
+	// OutputFs is the file system the site is published to, nil to
+	// publish to PublishDir on Fs.
+	OutputFs() afero.Fs
+
 	MediaTypes() media.Types
 }
 
@@ -0,0 +1,43 @@
+package valueobject
+
+import (
+	"sort"
+	"sync"
+)
+
+// FileSet is a set of filenames safe for concurrent use, e.g. the published
+// files that need post processing.
+type FileSet struct {
+	mu sync.Mutex
+	m  map[string]bool
+}
+
+func NewFileSet() *FileSet {
+	return &FileSet{m: make(map[string]bool)}
+}
+
+func (s *FileSet) Add(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m[name] = true
+}
+
+// Names returns the filenames in the set, sorted.
+func (s *FileSet) Names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.m))
+	for name := range s.m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+func (s *FileSet) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.m = make(map[string]bool)
+}
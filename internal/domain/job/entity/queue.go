@@ -0,0 +1,476 @@
+package entity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/mdfriday/hugoverse/internal/domain/job"
+	"github.com/mdfriday/hugoverse/internal/domain/job/repository"
+	"github.com/mdfriday/hugoverse/internal/domain/job/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+var ErrJobNotFound = errors.New("job not found")
+
+// errInterrupted is the error of the jobs left unfinished by a restart.
+const errInterrupted = "interrupted by a restart"
+
+// logFlushDelay is how long the log lines of a running job wait to be
+// persisted, so a chatty build writes its record once in a while instead
+// of once a line. The followers get the lines right away.
+const logFlushDelay = time.Second
+
+// Queue runs the jobs in the order they were enqueued, at most workers at
+// a time. Every change of a job is persisted, so the records outlive the
+// process, while the queued and running jobs are kept in memory.
+type Queue struct {
+	Repo repository.Repository
+	Log  loggers.Logger
+
+	// Keep is the number of finished jobs kept for each owner, and
+	// KeepFor how long they are kept, the older ones are deleted. Zero
+	// keeps them all.
+	Keep    int
+	KeepFor time.Duration
+
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	pending []*run
+	active  map[string]*run
+	closed  bool
+}
+
+// run is a queued or running job.
+type run struct {
+	q    *Queue
+	task job.Task
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu  sync.Mutex
+	job *valueobject.Job
+
+	// flush persists the log lines added since the last write.
+	flush *time.Timer
+
+	// changed is closed and replaced on each change of the job,
+	// waking up its followers.
+	changed chan struct{}
+}
+
+// Start starts workers goroutines running the queued jobs.
+func (q *Queue) Start(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	q.mu.Lock()
+	q.cond = sync.NewCond(&q.mu)
+	q.active = make(map[string]*run)
+	q.mu.Unlock()
+
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+}
+
+// Close stops the workers and cancels the queued and running jobs.
+func (q *Queue) Close() {
+	q.mu.Lock()
+	q.closed = true
+	runs := make([]*run, 0, len(q.active))
+	for _, r := range q.active {
+		runs = append(runs, r)
+	}
+	q.cond.Broadcast()
+	q.mu.Unlock()
+
+	for _, r := range runs {
+		r.cancel()
+	}
+}
+
+// Recover fails the jobs a previous process left queued or running, as
+// their tasks are gone with it, and deletes the jobs past retention.
+func (q *Queue) Recover() error {
+	jobs, err := q.all()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, j := range jobs {
+		if j.Status.Done() {
+			continue
+		}
+		j.Status = job.StatusFailed
+		j.Error = errInterrupted
+		j.Finished = &now
+		if err := q.put(j); err != nil {
+			return err
+		}
+	}
+
+	return q.prune(jobs)
+}
+
+// Enqueue adds a job of kind running task on the content id of type t,
+// on behalf of owner.
+func (q *Queue) Enqueue(owner string, kind job.Kind, t, id string, task job.Task) (*valueobject.Job, error) {
+	uid, err := uuid.NewV4()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &run{
+		q:      q,
+		task:   task,
+		ctx:    ctx,
+		cancel: cancel,
+		job: &valueobject.Job{
+			ID:        uid.String(),
+			Kind:      kind,
+			Status:    job.StatusQueued,
+			Owner:     owner,
+			Type:      t,
+			ContentID: id,
+			Created:   time.Now(),
+		},
+		changed: make(chan struct{}),
+	}
+	if err := q.put(r.job); err != nil {
+		cancel()
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		cancel()
+		return nil, errors.New("job queue closed")
+	}
+	q.pending = append(q.pending, r)
+	q.active[r.job.ID] = r
+	q.cond.Signal()
+
+	return r.job.Clone(), nil
+}
+
+// Job returns the job id.
+func (q *Queue) Job(id string) (*valueobject.Job, error) {
+	if r, found := q.activeRun(id); found {
+		return r.snapshot(), nil
+	}
+
+	data, err := q.Repo.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return nil, ErrJobNotFound
+	}
+
+	j := &valueobject.Job{}
+	if err := json.Unmarshal(data, j); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// Jobs returns the jobs of owner, the most recent first, without their
+// logs.
+func (q *Queue) Jobs(owner string) ([]*valueobject.Job, error) {
+	all, err := q.all()
+	if err != nil {
+		return nil, err
+	}
+	var jobs []*valueobject.Job
+	for _, j := range all {
+		if j.Owner == owner {
+			j.Log = nil
+			jobs = append(jobs, j)
+		}
+	}
+	sort.SliceStable(jobs, func(i, k int) bool {
+		return jobs[i].Created.After(jobs[k].Created)
+	})
+	return jobs, nil
+}
+
+// Cancel cancels the job id. A queued job is cancelled right away, a
+// running one once its task checks its context, the builds do between
+// their phases. Finished jobs are left as they are.
+func (q *Queue) Cancel(id string) (*valueobject.Job, error) {
+	q.mu.Lock()
+	r, found := q.active[id]
+	queued := false
+	if found {
+		for i, p := range q.pending {
+			if p == r {
+				q.pending = append(q.pending[:i], q.pending[i+1:]...)
+				delete(q.active, id)
+				queued = true
+				break
+			}
+		}
+	}
+	q.mu.Unlock()
+
+	if !found {
+		return q.Job(id)
+	}
+
+	r.cancel()
+	if queued {
+		now := time.Now()
+		r.update(func(j *valueobject.Job) {
+			j.Status = job.StatusCancelled
+			j.Finished = &now
+		})
+	} else {
+		r.Printf("Cancelling")
+	}
+
+	return r.snapshot(), nil
+}
+
+// Follow calls emit with the log lines of the job id, from the line
+// numbered from on, as they are written until the job finishes or ctx is
+// done. It returns the finished job.
+func (q *Queue) Follow(ctx context.Context, id string, from int, emit func(n int, line string) error) (*valueobject.Job, error) {
+	r, found := q.activeRun(id)
+	if !found {
+		j, err := q.Job(id)
+		if err != nil {
+			return nil, err
+		}
+		for n := from; n < len(j.Log); n++ {
+			if err := emit(n, j.Log[n]); err != nil {
+				return nil, err
+			}
+		}
+		return j, nil
+	}
+
+	for {
+		r.mu.Lock()
+		var lines []string
+		if from < len(r.job.Log) {
+			lines = append(lines, r.job.Log[from:]...)
+		}
+		changed := r.changed
+		var done *valueobject.Job
+		if r.job.Status.Done() {
+			done = r.job.Clone()
+		}
+		r.mu.Unlock()
+
+		for _, line := range lines {
+			if err := emit(from, line); err != nil {
+				return nil, err
+			}
+			from++
+		}
+		if done != nil {
+			return done, nil
+		}
+
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+func (q *Queue) work() {
+	for {
+		q.mu.Lock()
+		for len(q.pending) == 0 && !q.closed {
+			q.cond.Wait()
+		}
+		if q.closed {
+			q.mu.Unlock()
+			return
+		}
+		r := q.pending[0]
+		q.pending = q.pending[1:]
+		q.mu.Unlock()
+
+		r.execute()
+
+		q.mu.Lock()
+		delete(q.active, r.job.ID)
+		q.mu.Unlock()
+
+		jobs, err := q.all()
+		if err == nil {
+			err = q.prune(jobs)
+		}
+		if err != nil && q.Log != nil {
+			q.Log.Errorf("Error pruning jobs: %v", err)
+		}
+	}
+}
+
+// prune deletes the finished jobs past the retention of their owner.
+func (q *Queue) prune(jobs []*valueobject.Job) error {
+	if q.Keep <= 0 && q.KeepFor <= 0 {
+		return nil
+	}
+
+	sort.SliceStable(jobs, func(i, k int) bool {
+		return jobs[i].Created.After(jobs[k].Created)
+	})
+	kept := make(map[string]int)
+	for _, j := range jobs {
+		if !j.Status.Done() {
+			continue
+		}
+		kept[j.Owner]++
+		expired := q.KeepFor > 0 && j.Finished != nil && time.Since(*j.Finished) > q.KeepFor
+		if (q.Keep <= 0 || kept[j.Owner] <= q.Keep) && !expired {
+			continue
+		}
+		if err := q.Repo.DeleteJob(j.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (q *Queue) activeRun(id string) (*run, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	r, found := q.active[id]
+	return r, found
+}
+
+func (q *Queue) all() ([]*valueobject.Job, error) {
+	data, err := q.Repo.AllJobs()
+	if err != nil {
+		return nil, err
+	}
+
+	jobs := make([]*valueobject.Job, 0, len(data))
+	for _, d := range data {
+		j := &valueobject.Job{}
+		if err := json.Unmarshal(d, j); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, j)
+	}
+	return jobs, nil
+}
+
+func (q *Queue) put(j *valueobject.Job) error {
+	data, err := json.Marshal(j)
+	if err != nil {
+		return err
+	}
+	return q.Repo.PutJob(j.ID, data)
+}
+
+func (r *run) execute() {
+	defer r.cancel()
+
+	now := time.Now()
+	r.update(func(j *valueobject.Job) {
+		j.Status = job.StatusRunning
+		j.Started = &now
+	})
+
+	result, err := r.runTask()
+
+	finished := time.Now()
+	r.update(func(j *valueobject.Job) {
+		j.Finished = &finished
+		switch {
+		case err != nil && r.ctx.Err() != nil:
+			j.Status = job.StatusCancelled
+		case err != nil:
+			j.Status = job.StatusFailed
+			j.Error = err.Error()
+		default:
+			j.Status = job.StatusSucceeded
+			j.Result = result
+		}
+	})
+}
+
+func (r *run) runTask() (result string, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+	return r.task(r.ctx, r)
+}
+
+// Printf adds a line to the log of the job, persisted within
+// logFlushDelay or with the next change of the job.
+func (r *run) Printf(format string, v ...any) {
+	line := fmt.Sprintf(format, v...)
+	if r.q.Log != nil {
+		r.q.Log.Printf("job %s: %s", r.job.ID, line)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.job.AddLog(line)
+	if r.flush == nil {
+		r.flush = time.AfterFunc(logFlushDelay, r.flushLog)
+	}
+	r.notify()
+}
+
+func (r *run) flushLog() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Persisted meanwhile by update.
+	if r.flush == nil {
+		return
+	}
+	r.flush = nil
+	r.persist()
+}
+
+// update changes the job with f, persists it and wakes up the followers.
+func (r *run) update(f func(j *valueobject.Job)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	f(r.job)
+	if r.flush != nil {
+		r.flush.Stop()
+		r.flush = nil
+	}
+	r.persist()
+	r.notify()
+}
+
+func (r *run) persist() {
+	if err := r.q.put(r.job); err != nil && r.q.Log != nil {
+		r.q.Log.Errorf("Error persisting job %s: %v", r.job.ID, err)
+	}
+}
+
+func (r *run) notify() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
+
+func (r *run) snapshot() *valueobject.Job {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.job.Clone()
+}
@@ -0,0 +1,204 @@
+package entity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/job"
+	"github.com/mdfriday/hugoverse/internal/domain/job/valueobject"
+)
+
+type memRepo struct {
+	mu   sync.Mutex
+	jobs map[string][]byte
+}
+
+func (m *memRepo) PutJob(id string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[id] = data
+	return nil
+}
+
+func (m *memRepo) GetJob(id string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.jobs[id], nil
+}
+
+func (m *memRepo) AllJobs() ([][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var all [][]byte
+	for _, d := range m.jobs {
+		all = append(all, d)
+	}
+	return all, nil
+}
+
+func (m *memRepo) DeleteJob(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.jobs, id)
+	return nil
+}
+
+func waitFor(c *qt.C, q *Queue, id string, status job.Status) *valueobject.Job {
+	for i := 0; i < 1000; i++ {
+		j, err := q.Job(id)
+		c.Assert(err, qt.IsNil)
+		if j.Status == status {
+			return j
+		}
+		time.Sleep(time.Millisecond)
+	}
+	c.Fatalf("job %s never became %s", id, status)
+	return nil
+}
+
+func TestQueue(t *testing.T) {
+	c := qt.New(t)
+
+	repo := &memRepo{jobs: make(map[string][]byte)}
+	q := &Queue{Repo: repo}
+	q.Start(1)
+	defer q.Close()
+
+	release := make(chan struct{})
+	first, err := q.Enqueue("a@example.org", job.KindBuild, "Site", "1", func(ctx context.Context, log job.Logger) (string, error) {
+		log.Printf("building")
+		<-release
+		log.Printf("built")
+		return "https://example.org", nil
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(first.Status, qt.Equals, job.StatusQueued)
+
+	// With one worker the second job waits, and is cancelled right away.
+	second, err := q.Enqueue("a@example.org", job.KindDeploy, "Site", "2", func(ctx context.Context, log job.Logger) (string, error) {
+		c.Error("cancelled job ran")
+		return "", nil
+	})
+	c.Assert(err, qt.IsNil)
+	waitFor(c, q, first.ID, job.StatusRunning)
+	cancelled, err := q.Cancel(second.ID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(cancelled.Status, qt.Equals, job.StatusCancelled)
+
+	// Follow streams the log until the job finishes.
+	var lines []string
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		close(release)
+	}()
+	done, err := q.Follow(context.Background(), first.ID, 0, func(n int, line string) error {
+		c.Check(n, qt.Equals, len(lines))
+		lines = append(lines, line)
+		return nil
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(lines, qt.DeepEquals, []string{"building", "built"})
+	c.Assert(done.Status, qt.Equals, job.StatusSucceeded)
+	c.Assert(done.Result, qt.Equals, "https://example.org")
+
+	// A running job stops at the next check of its context.
+	third, err := q.Enqueue("a@example.org", job.KindPreview, "Site", "3", func(ctx context.Context, log job.Logger) (string, error) {
+		<-ctx.Done()
+		return "", ctx.Err()
+	})
+	c.Assert(err, qt.IsNil)
+	waitFor(c, q, third.ID, job.StatusRunning)
+	_, err = q.Cancel(third.ID)
+	c.Assert(err, qt.IsNil)
+	waitFor(c, q, third.ID, job.StatusCancelled)
+
+	failed, err := q.Enqueue("a@example.org", job.KindBuild, "Site", "4", func(ctx context.Context, log job.Logger) (string, error) {
+		return "", errors.New("no config")
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(waitFor(c, q, failed.ID, job.StatusFailed).Error, qt.Equals, "no config")
+
+	_, err = q.Job("missing")
+	c.Assert(err, qt.Equals, ErrJobNotFound)
+
+	other, err := q.Enqueue("b@example.org", job.KindBuild, "Site", "5", func(ctx context.Context, log job.Logger) (string, error) {
+		return "", nil
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(other.Owner, qt.Equals, "b@example.org")
+	waitFor(c, q, other.ID, job.StatusSucceeded)
+
+	// The log is persisted with the job.
+	var stored valueobject.Job
+	c.Assert(json.Unmarshal(repo.jobs[first.ID], &stored), qt.IsNil)
+	c.Assert(stored.Log, qt.DeepEquals, []string{"building", "built"})
+
+	jobs, err := q.Jobs("a@example.org")
+	c.Assert(err, qt.IsNil)
+	c.Assert(jobs, qt.HasLen, 4)
+	c.Assert(jobs[0].ID, qt.Equals, failed.ID)
+	c.Assert(jobs[3].Log, qt.IsNil)
+	jobs, err = q.Jobs("b@example.org")
+	c.Assert(err, qt.IsNil)
+	c.Assert(jobs, qt.HasLen, 1)
+}
+
+func TestQueuePrune(t *testing.T) {
+	c := qt.New(t)
+
+	repo := &memRepo{jobs: make(map[string][]byte)}
+	now := time.Now()
+	old := now.Add(-48 * time.Hour)
+	for _, j := range []*valueobject.Job{
+		{ID: "a1", Owner: "a", Status: job.StatusSucceeded, Created: now.Add(-3 * time.Minute), Finished: &now},
+		{ID: "a2", Owner: "a", Status: job.StatusFailed, Created: now.Add(-2 * time.Minute), Finished: &now},
+		{ID: "a3", Owner: "a", Status: job.StatusSucceeded, Created: now.Add(-time.Minute), Finished: &now},
+		{ID: "b1", Owner: "b", Status: job.StatusSucceeded, Created: old, Finished: &old},
+		{ID: "b2", Owner: "b", Status: job.StatusSucceeded, Created: now, Finished: &now},
+	} {
+		data, err := json.Marshal(j)
+		c.Assert(err, qt.IsNil)
+		c.Assert(repo.PutJob(j.ID, data), qt.IsNil)
+	}
+
+	q := &Queue{Repo: repo, Keep: 2, KeepFor: 24 * time.Hour}
+	c.Assert(q.Recover(), qt.IsNil)
+
+	var ids []string
+	for id := range repo.jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	c.Assert(ids, qt.DeepEquals, []string{"a2", "a3", "b2"})
+}
+
+func TestQueueRecover(t *testing.T) {
+	c := qt.New(t)
+
+	repo := &memRepo{jobs: make(map[string][]byte)}
+	for _, j := range []*valueobject.Job{
+		{ID: "a", Status: job.StatusRunning},
+		{ID: "b", Status: job.StatusSucceeded},
+	} {
+		data, err := json.Marshal(j)
+		c.Assert(err, qt.IsNil)
+		c.Assert(repo.PutJob(j.ID, data), qt.IsNil)
+	}
+
+	q := &Queue{Repo: repo}
+	c.Assert(q.Recover(), qt.IsNil)
+
+	a, err := q.Job("a")
+	c.Assert(err, qt.IsNil)
+	c.Assert(a.Status, qt.Equals, job.StatusFailed)
+	c.Assert(a.Error, qt.Equals, errInterrupted)
+	b, err := q.Job("b")
+	c.Assert(err, qt.IsNil)
+	c.Assert(b.Status, qt.Equals, job.StatusSucceeded)
+}
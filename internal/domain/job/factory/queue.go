@@ -0,0 +1,29 @@
+package factory
+
+import (
+	"github.com/mdfriday/hugoverse/internal/domain/job/entity"
+	"github.com/mdfriday/hugoverse/internal/domain/job/repository"
+	"github.com/mdfriday/hugoverse/internal/domain/job/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/env"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+// NewQueue creates the job queue persisted in repo, failing the jobs a
+// previous process left unfinished and those past retention, and starts
+// its workers.
+func NewQueue(repo repository.Repository) (*entity.Queue, error) {
+	q := &entity.Queue{
+		Repo: repo,
+		Log:  loggers.NewDefault(),
+
+		Keep:    valueobject.KeepJobs,
+		KeepFor: valueobject.KeepJobsFor,
+	}
+
+	if err := q.Recover(); err != nil {
+		return nil, err
+	}
+	q.Start(env.GetNumJobWorkers())
+
+	return q, nil
+}
@@ -0,0 +1,8 @@
+package repository
+
+type Repository interface {
+	PutJob(id string, data []byte) error
+	GetJob(id string) ([]byte, error)
+	AllJobs() ([][]byte, error)
+	DeleteJob(id string) error
+}
@@ -0,0 +1,38 @@
+package job
+
+import "context"
+
+// Kind is the kind of work a job does.
+type Kind string
+
+const (
+	KindBuild   Kind = "build"
+	KindPreview Kind = "preview"
+	KindDeploy  Kind = "deploy"
+)
+
+// Status is the state of a job, from queued to one of the final states.
+type Status string
+
+const (
+	StatusQueued    Status = "queued"
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+	StatusCancelled Status = "cancelled"
+)
+
+// Done reports whether s is a final state.
+func (s Status) Done() bool {
+	return s == StatusSucceeded || s == StatusFailed || s == StatusCancelled
+}
+
+// Logger receives the log of a running job.
+type Logger interface {
+	Printf(format string, v ...any)
+}
+
+// Task is the work of a job. It returns the result, e.g. the URL of the
+// deployed site, and checks ctx between its steps to stop early once the
+// job is cancelled.
+type Task func(ctx context.Context, log Logger) (string, error)
@@ -0,0 +1,61 @@
+package valueobject
+
+import (
+	"time"
+
+	"github.com/mdfriday/hugoverse/internal/domain/job"
+)
+
+// MaxLogLines is the number of log lines kept for a job, the lines after
+// it are dropped.
+const MaxLogLines = 1000
+
+// KeepJobs is the number of finished jobs kept for each owner, and
+// KeepJobsFor how long a finished job is kept at most.
+const (
+	KeepJobs    = 100
+	KeepJobsFor = 30 * 24 * time.Hour
+)
+
+// Job is the record of a job, persisted on each change.
+type Job struct {
+	ID     string     `json:"id"`
+	Kind   job.Kind   `json:"kind"`
+	Status job.Status `json:"status"`
+
+	// Owner is the email of the user who submitted the job, the only
+	// one to see it.
+	Owner string `json:"owner,omitempty"`
+
+	// Type and ContentID identify the content the job works on.
+	Type      string `json:"type,omitempty"`
+	ContentID string `json:"content_id,omitempty"`
+
+	// Result is set when the job succeeded, e.g. to the URL of the site.
+	Result string `json:"result,omitempty"`
+	Error  string `json:"error,omitempty"`
+
+	Log []string `json:"log,omitempty"`
+
+	Created  time.Time  `json:"created"`
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+}
+
+// AddLog appends line to the log, once the log is full a last line notes
+// that the rest was dropped.
+func (j *Job) AddLog(line string) {
+	switch {
+	case len(j.Log) < MaxLogLines:
+		j.Log = append(j.Log, line)
+	case len(j.Log) == MaxLogLines:
+		j.Log = append(j.Log, "... log truncated")
+	}
+}
+
+// Clone returns a copy of j safe to use while j changes.
+func (j *Job) Clone() *Job {
+	c := *j
+	c.Log = append([]string(nil), j.Log...)
+	return &c
+}
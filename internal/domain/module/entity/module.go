@@ -65,7 +65,12 @@ func (m *Module) Load() error {
 }
 
 func (m *Module) collect() error {
-	if err := m.collector.CollectGoModules(); err != nil {
+	collect := m.collector.CollectGoModules
+	if len(m.ModuleImports) == 0 {
+		// Nothing to download, so no need for Go.
+		collect = func() error { return m.collector.CollectMainModule(m.Fs, m.WorkingDir) }
+	}
+	if err := collect(); err != nil {
 		return err
 	}
 	m.projMod = &valueobject.ProjectModule{
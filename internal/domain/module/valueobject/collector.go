@@ -1,7 +1,12 @@
 package valueobject
 
 import (
+	"fmt"
+	"path/filepath"
 	"strings"
+
+	"github.com/spf13/afero"
+	"golang.org/x/mod/modfile"
 )
 
 type Collector struct {
@@ -36,6 +41,29 @@ func (c *Collector) CollectGoModules() error {
 	return nil
 }
 
+// CollectMainModule reads the main module from the go.mod in dir on fs,
+// without running Go. It is all a project importing no modules needs, and
+// works for projects that are not on the OS file system.
+func (c *Collector) CollectMainModule(fs afero.Fs, dir string) error {
+	filename := filepath.Join(dir, GoModFilename)
+	b, err := afero.ReadFile(fs, filename)
+	if err != nil {
+		return err
+	}
+	path := modfile.ModulePath(b)
+	if path == "" {
+		return fmt.Errorf("no module path in %s", filename)
+	}
+
+	c.goModules = GoModules{{
+		Path:  path,
+		Main:  true,
+		Dir:   dir,
+		GoMod: filename,
+	}}
+	return nil
+}
+
 func (c *Collector) GetMain() *GoModule {
 	for _, m := range c.goModules {
 		if m.Main {
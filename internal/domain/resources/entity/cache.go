@@ -19,6 +19,8 @@ type Cache struct {
 
 	filecache.Caches
 
+	ImagePool *ImagePool
+
 	CacheImage                  *dynacache.Partition[string, *ResourceImage]
 	CacheResource               *dynacache.Partition[string, resources.Resource]
 	CacheResources              *dynacache.Partition[string, []resources.Resource]
@@ -52,6 +54,8 @@ func (c *Cache) GetOrCreateImageResource(parent *ResourceImage, conf valueobject
 		// read clones the parent to its new name and copies
 		// the content to the destinations.
 		read := func(info filecache.ItemInfo, r io.ReadSeeker) error {
+			c.ImagePool.MarkReused()
+
 			img = parent.clone(nil)
 			targetPath := img.paths
 			targetPath.File = relTarget.File
@@ -87,13 +91,6 @@ func (c *Cache) GetOrCreateImageResource(parent *ResourceImage, conf valueobject
 		}
 
 		// Now look in the file cache.
-
-		// The definition of this counter is not that we have processed that amount
-		// (e.g. resized etc.), it can be fetched from file cache,
-		//  but the count of processed image variations for this site.
-		// TODO
-		//c.pathSpec.ProcessingStats.Incr(&c.pathSpec.ProcessingStats.ProcessedImages)
-
 		_, err := c.Caches.ImageCache().ReadOrCreate(relTargetPath, read, create)
 		if err != nil {
 			return nil, err
@@ -48,7 +48,7 @@ func (c *SassClient) Open() error {
 		c.once.Do(func() {
 			if valueobject.IsDartSassV2() {
 				transpiler, err = godartsass.Start(godartsass.Options{
-					DartSassEmbeddedFilename: valueobject.DartSassBinaryName,
+					DartSassEmbeddedFilename: valueobject.DartSassBinaryName(),
 					LogEventHandler: func(event godartsass.LogEvent) {
 						message := strings.ReplaceAll(event.Message, dartSassStdinPrefix, "")
 						switch event.Type {
@@ -63,7 +63,7 @@ func (c *SassClient) Open() error {
 				})
 				c.transpiler = transpiler
 			} else {
-				panic(fmt.Sprintf("Unexpected Dart Sass version, only supported v2: %s", valueobject.DartSassBinaryName))
+				panic(fmt.Sprintf("Unexpected Dart Sass version, only supported v2: %s", valueobject.DartSassBinaryName()))
 			}
 		})
 
@@ -112,7 +112,7 @@ func (c *SassClient) toCSS(args godartsass.Args, src io.Reader) (godartsass.Resu
 	if err != nil {
 		if err.Error() == "unexpected EOF" {
 			//lint:ignore ST1005 end user message.
-			return res, fmt.Errorf("got unexpected EOF when executing %q. The user running hugo must have read and execute permissions on this program. With execute permissions only, this error is thrown", valueobject.DartSassBinaryName)
+			return res, fmt.Errorf("got unexpected EOF when executing %q. The user running hugo must have read and execute permissions on this program. With execute permissions only, this error is thrown", valueobject.DartSassBinaryName())
 		}
 		return res, herrors.NewFileErrorFromFileInErr(err, c.FsService.AssetsFs(), herrors.OffsetMatcher)
 	}
@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/evanw/esbuild/pkg/api"
+	"github.com/mdfriday/hugoverse/internal/domain/resources"
+	"github.com/mdfriday/hugoverse/internal/domain/resources/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/media"
+	"io"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var cssSourceMappingURLRe = regexp.MustCompile(`/\*# sourceMappingURL=.*\*/\n?`)
+
+// BuildCSS bundles the given CSS Resource with ESBuild, inlining @import
+// and lowering modern syntax for the configured targets.
+func (c *JsClient) BuildCSS(res resources.Resource, opts map[string]any) (resources.Resource, error) {
+	transRes := res.(Transformer)
+	return transRes.Transform(
+		&cssBuildTransformation{c: c, optsm: opts},
+	)
+}
+
+type cssBuildTransformation struct {
+	optsm map[string]any
+	c     *JsClient
+}
+
+func (t *cssBuildTransformation) Key() valueobject.ResourceTransformationKey {
+	return valueobject.NewResourceTransformationKey("cssbuild", t.optsm)
+}
+
+func (t *cssBuildTransformation) Transform(ctx *valueobject.ResourceTransformationCtx) error {
+	ctx.Target.OutMediaType = media.Builtin.CSSType
+
+	cssOpts, err := valueobject.DecodeCSSBuildOptions(t.optsm)
+	if err != nil {
+		return err
+	}
+
+	opts, err := cssOpts.Options()
+	if err != nil {
+		return err
+	}
+
+	if opts.TargetPath != "" {
+		ctx.Target.OutPath = opts.TargetPath
+	} else {
+		ctx.ReplaceOutPathExtension(".css")
+	}
+
+	src, err := io.ReadAll(ctx.Source.From)
+	if err != nil {
+		return err
+	}
+
+	opts.SourceDir = filepath.FromSlash(path.Dir(ctx.SourcePath()))
+	opts.Contents = string(src)
+	opts.MediaType = ctx.Source.InMediaType
+	opts.Stdin = true
+
+	if ctx.DepSvc.DependencyManager() != nil {
+		opts.DependencyManager = ctx.DepSvc.DependencyManager()
+	}
+	opts.StdinSourcePath = ctx.SourcePath()
+
+	result, err := t.c.c.Build(opts)
+	if err != nil {
+		return err
+	}
+
+	var css, sourceMap *api.OutputFile
+	for i, o := range result.OutputFiles {
+		if strings.HasSuffix(o.Path, ".map") {
+			sourceMap = &result.OutputFiles[i]
+		} else {
+			css = &result.OutputFiles[i]
+		}
+	}
+	if css == nil {
+		return fmt.Errorf("css.Build: no output for %q", ctx.SourcePath())
+	}
+
+	content := string(css.Contents)
+	if sourceMap != nil {
+		if opts.SourceMap == "linked" {
+			symPath := path.Base(ctx.Target.OutPath) + ".map"
+			content = cssSourceMappingURLRe.ReplaceAllString(content, "/*# sourceMappingURL="+symPath+" */\n")
+		}
+
+		if err := ctx.PubSvc.PublishContentToTarget(string(sourceMap.Contents), ctx.Target.OutPath+".map"); err != nil {
+			return err
+		}
+	}
+
+	_, err = ctx.Target.To.Write([]byte(content))
+	return err
+}
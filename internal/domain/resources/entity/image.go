@@ -1,13 +1,19 @@
 package entity
 
-import "github.com/mdfriday/hugoverse/internal/domain/resources/valueobject"
+import (
+	"github.com/mdfriday/hugoverse/internal/domain/resources/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/maps"
+)
 
 type Image struct {
 	*valueobject.Filters
+
+	configs *maps.Cache[string, imageConfigResult]
 }
 
 func NewImage() *Image {
 	return &Image{
 		Filters: &valueobject.Filters{},
+		configs: maps.NewCache[string, imageConfigResult](),
 	}
 }
@@ -0,0 +1,152 @@
+package entity
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ImageBudget bounds the image transformations of all the builds sharing
+// it by the number of workers and by the memory the decoded images may
+// take up.
+type ImageBudget struct {
+	mu   sync.Mutex
+	cond *sync.Cond
+
+	workers int
+	running int
+
+	budget uint64
+	inUse  uint64
+}
+
+// NewImageBudget creates a budget running at most workers transformations
+// at a time, with at most memoryBudget bytes of decoded images.
+func NewImageBudget(workers int, memoryBudget uint64) *ImageBudget {
+	if workers < 1 {
+		workers = 1
+	}
+	b := &ImageBudget{
+		workers: workers,
+		budget:  memoryBudget,
+	}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+// run runs f when a worker and weight bytes of the memory budget are
+// available. A weight larger than the budget is capped, so that it runs alone.
+func (b *ImageBudget) run(weight uint64, f func() error) error {
+	if weight > b.budget {
+		weight = b.budget
+	}
+
+	b.mu.Lock()
+	for b.running >= b.workers || b.inUse+weight > b.budget {
+		b.cond.Wait()
+	}
+	b.running++
+	b.inUse += weight
+	b.mu.Unlock()
+
+	defer func() {
+		b.mu.Lock()
+		b.running--
+		b.inUse -= weight
+		b.cond.Broadcast()
+		b.mu.Unlock()
+	}()
+
+	return f()
+}
+
+// ImagePool runs the image transformations of a build within a budget
+// shared with the other builds of the process.
+// Identical in-flight transformations, i.e. with the same target, are
+// run once and the result shared.
+type ImagePool struct {
+	budget *ImageBudget
+
+	mu       sync.Mutex
+	inFlight map[string]*imageCall
+
+	regenerated  atomic.Int64
+	reused       atomic.Int64
+	deduplicated atomic.Int64
+}
+
+type imageCall struct {
+	wg  sync.WaitGroup
+	val any
+	err error
+}
+
+// ImagePoolStats reports how the derivatives of a build were produced.
+type ImagePoolStats struct {
+	// Regenerated is the number of derivatives decoded and processed.
+	Regenerated int64
+	// Reused is the number of derivatives read from the image cache.
+	Reused int64
+	// Deduplicated is the number of requests joining an identical
+	// transformation already in flight.
+	Deduplicated int64
+}
+
+// NewImagePool creates a pool running its transformations within budget.
+func NewImagePool(budget *ImageBudget) *ImagePool {
+	return &ImagePool{
+		budget:   budget,
+		inFlight: make(map[string]*imageCall),
+	}
+}
+
+// Do calls f for key, concurrent calls with the same key wait for and share
+// the result of the first.
+func (p *ImagePool) Do(key string, f func() (any, error)) (any, error) {
+	p.mu.Lock()
+	if c, found := p.inFlight[key]; found {
+		p.mu.Unlock()
+		p.deduplicated.Add(1)
+		c.wg.Wait()
+		return c.val, c.err
+	}
+	c := &imageCall{}
+	c.wg.Add(1)
+	p.inFlight[key] = c
+	p.mu.Unlock()
+
+	defer func() {
+		p.mu.Lock()
+		delete(p.inFlight, key)
+		p.mu.Unlock()
+		c.wg.Done()
+	}()
+
+	c.val, c.err = f()
+
+	return c.val, c.err
+}
+
+// Run runs f when a worker and weight bytes of the memory budget are
+// available. A weight larger than the budget is capped, so that it runs alone.
+func (p *ImagePool) Run(weight uint64, f func() error) error {
+	if err := p.budget.run(weight, f); err != nil {
+		return err
+	}
+	p.regenerated.Add(1)
+
+	return nil
+}
+
+// MarkReused records a derivative read from the image cache.
+func (p *ImagePool) MarkReused() {
+	p.reused.Add(1)
+}
+
+// Stats returns the statistics since the pool was created.
+func (p *ImagePool) Stats() ImagePoolStats {
+	return ImagePoolStats{
+		Regenerated:  p.regenerated.Load(),
+		Reused:       p.reused.Load(),
+		Deduplicated: p.deduplicated.Load(),
+	}
+}
@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestImagePool(t *testing.T) {
+	c := qt.New(t)
+
+	p := NewImagePool(NewImageBudget(4, 100))
+
+	// Identical in-flight keys run once.
+	var calls atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := p.Do("a.png", func() (any, error) {
+				calls.Add(1)
+				<-release
+				return "A", nil
+			})
+			c.Check(err, qt.IsNil)
+			c.Check(v, qt.Equals, "A")
+		}()
+	}
+	for p.Stats().Deduplicated < 4 {
+		time.Sleep(time.Millisecond)
+	}
+	close(release)
+	wg.Wait()
+	c.Assert(calls.Load(), qt.Equals, int32(1))
+
+	// The memory budget limits the concurrent transformations.
+	var running, maxRunning atomic.Int32
+	track := func() error {
+		n := running.Add(1)
+		for {
+			m := maxRunning.Load()
+			if n <= m || maxRunning.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		time.Sleep(5 * time.Millisecond)
+		running.Add(-1)
+		return nil
+	}
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Check(p.Run(40, track), qt.IsNil)
+		}()
+	}
+	wg.Wait()
+	c.Assert(maxRunning.Load() <= 2, qt.IsTrue)
+
+	// Pools sharing a budget are limited together.
+	budget := NewImageBudget(4, 100)
+	pools := []*ImagePool{NewImagePool(budget), NewImagePool(budget)}
+	running.Store(0)
+	maxRunning.Store(0)
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.Check(pools[i%2].Run(40, track), qt.IsNil)
+		}()
+	}
+	wg.Wait()
+	c.Assert(maxRunning.Load() <= 2, qt.IsTrue)
+	c.Assert(pools[0].Stats().Regenerated, qt.Equals, int64(4))
+
+	// Weights above the budget run alone.
+	c.Assert(p.Run(1000, func() error { return nil }), qt.IsNil)
+
+	p.MarkReused()
+	c.Assert(p.Stats(), qt.Equals, ImagePoolStats{Regenerated: 9, Reused: 1, Deduplicated: 4})
+}
@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/domain/resources"
+	"github.com/mdfriday/hugoverse/pkg/identity"
+	"github.com/mdfriday/hugoverse/pkg/images"
+	"github.com/mdfriday/hugoverse/pkg/images/qr"
+	pio "github.com/mdfriday/hugoverse/pkg/io"
+	"github.com/mdfriday/hugoverse/pkg/paths"
+	"image"
+	"image/png"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ImageConfig returns the color model and dimensions of the image at the
+// given path without decoding it.
+// The path is either a http(s) URL, fetched and cached as in resources.GetRemote,
+// or a filename relative to the working dir, e.g. an uploaded image in static.
+func (rs *Resources) ImageConfig(pathname string) (image.Config, error) {
+	if pathname == "" {
+		return image.Config{}, fmt.Errorf("images.Config: path must not be empty")
+	}
+
+	v := rs.Image.configs.GetOrCreate(pathname, func() imageConfigResult {
+		config, err := rs.decodeImageConfig(pathname)
+		return imageConfigResult{config: config, err: err}
+	})
+	return v.config, v.err
+}
+
+type imageConfigResult struct {
+	config image.Config
+	err    error
+}
+
+func (rs *Resources) decodeImageConfig(pathname string) (image.Config, error) {
+	if strings.HasPrefix(pathname, "http://") || strings.HasPrefix(pathname, "https://") {
+		r, err := rs.FromRemote(pathname, nil)
+		if err != nil {
+			return image.Config{}, fmt.Errorf("images.Config: %w", err)
+		}
+		f, err := r.ReadSeekCloser()
+		if err != nil {
+			return image.Config{}, err
+		}
+		defer f.Close()
+
+		config, _, err := images.DecodeConfig(f)
+		if err != nil {
+			return image.Config{}, fmt.Errorf("images.Config: failed to decode %q: %w", pathname, err)
+		}
+		return config, nil
+	}
+
+	wfs := rs.FsService.NewBasePathFs(rs.FsService.Os(), rs.FsService.WorkingDirAbs())
+	f, err := wfs.Open(filepath.Clean(filepath.FromSlash(pathname)))
+	if err != nil {
+		return image.Config{}, fmt.Errorf("images.Config: %w", err)
+	}
+	defer f.Close()
+
+	config, _, err := images.DecodeConfig(f)
+	if err != nil {
+		return image.Config{}, fmt.Errorf("images.Config: failed to decode %q: %w", pathname, err)
+	}
+	return config, nil
+}
+
+// QR encodes text as a QR code and returns it as a PNG image resource,
+// with scale pixels per module, published to targetDir.
+func (rs *Resources) QR(text string, level qr.Level, scale int, targetDir string) (resources.Resource, error) {
+	if text == "" {
+		return nil, fmt.Errorf("images.QR: text must not be empty")
+	}
+
+	code, err := qr.Encode(text, level)
+	if err != nil {
+		return nil, fmt.Errorf("images.QR: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, code.Image(scale)); err != nil {
+		return nil, fmt.Errorf("images.QR: failed to encode image: %w", err)
+	}
+	b := buf.Bytes()
+
+	name := "qr_" + identity.HashString(text, level, scale) + ".png"
+	targetPath := path.Join("/", paths.ToSlashTrimLeading(targetDir), name)
+
+	return rs.GetResourceWithOpener(targetPath, func() (pio.ReadSeekCloser, error) {
+		return pio.NewReadSeekerNoOpCloserFromBytes(b), nil
+	})
+}
@@ -0,0 +1,44 @@
+package entity
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	configVO "github.com/mdfriday/hugoverse/internal/domain/config/valueobject"
+)
+
+func TestImageConfigRemote(t *testing.T) {
+	c := qt.New(t)
+
+	var buf bytes.Buffer
+	c.Assert(png.Encode(&buf, image.NewGray(image.Rect(0, 0, 30, 20))), qt.IsNil)
+
+	hits := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(buf.Bytes())
+	}))
+	defer srv.Close()
+
+	rc, _ := newTestRemoteClient(c, configVO.DefaultSecurityConfig)
+	rs := rc.rs
+	rs.RemoteClient = rc
+	rs.Image = NewImage()
+
+	for i := 0; i < 2; i++ {
+		config, err := rs.ImageConfig(srv.URL + "/logo.png")
+		c.Assert(err, qt.IsNil)
+		c.Assert(config.Width, qt.Equals, 30)
+		c.Assert(config.Height, qt.Equals, 20)
+	}
+	c.Assert(hits, qt.Equals, 1)
+
+	_, err := rs.ImageConfig("")
+	c.Assert(err, qt.IsNotNil)
+}
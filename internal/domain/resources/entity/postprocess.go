@@ -0,0 +1,211 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/domain/resources"
+	"github.com/mdfriday/hugoverse/pkg/maps"
+	"github.com/spf13/afero"
+	"github.com/spf13/cast"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var _ resources.PostPublishedResource = (*PostPublishResource)(nil)
+
+// PostPublishResource stands in for a Resource in the templates until all
+// pages are rendered. Its fields are placeholders, replaced in the published
+// files with the values of the delegate in ApplyPostProcess.
+type PostPublishResource struct {
+	prefix   string
+	delegate resources.Resource
+}
+
+func NewPostPublishResource(id int, r resources.Resource) *PostPublishResource {
+	return &PostPublishResource{
+		prefix:   resources.PostProcessPrefix + "_" + strconv.Itoa(id) + "_",
+		delegate: r,
+	}
+}
+
+func (r *PostPublishResource) field(name string) string {
+	return r.prefix + name + resources.PostProcessSuffix
+}
+
+func (r *PostPublishResource) ResourceType() string {
+	return r.field("ResourceType")
+}
+
+func (r *PostPublishResource) Permalink() string {
+	return r.field("Permalink")
+}
+
+func (r *PostPublishResource) RelPermalink() string {
+	return r.field("RelPermalink")
+}
+
+func (r *PostPublishResource) Name() string {
+	return r.field("Name")
+}
+
+func (r *PostPublishResource) Title() string {
+	return r.field("Title")
+}
+
+func (r *PostPublishResource) Params() maps.Params {
+	return maps.Params{}
+}
+
+func (r *PostPublishResource) Data() any {
+	return map[string]any{
+		"Integrity": r.field("Data.Integrity"),
+	}
+}
+
+func (r *PostPublishResource) MediaType() map[string]any {
+	return map[string]any{
+		"Type":     r.field("MediaType.Type"),
+		"MainType": r.field("MediaType.MainType"),
+		"SubType":  r.field("MediaType.SubType"),
+		"Suffixes": r.field("MediaType.Suffixes"),
+	}
+}
+
+func (r *PostPublishResource) Origin() resources.Resource {
+	return r.delegate
+}
+
+// GetFieldString returns the value of the delegate for the given field
+// placeholder name, e.g. RelPermalink or Data.Integrity.
+// Reading the links triggers the deferred transformation and publishing.
+func (r *PostPublishResource) GetFieldString(field string) (string, bool) {
+	d := r.delegate
+
+	switch field {
+	case "ResourceType":
+		return d.ResourceType(), true
+	case "Permalink", "RelPermalink":
+		l, ok := d.(resources.LinksProvider)
+		if !ok {
+			return "", false
+		}
+		if field == "Permalink" {
+			return l.Permalink(), true
+		}
+		return l.RelPermalink(), true
+	case "Name":
+		return d.Name(), true
+	case "Title":
+		if t, ok := d.(resources.NameTitleProvider); ok {
+			return t.Title(), true
+		}
+		return d.Name(), true
+	case "MediaType.Type":
+		return d.MediaType().Type, true
+	case "MediaType.MainType":
+		return d.MediaType().MainType, true
+	case "MediaType.SubType":
+		return d.MediaType().SubType, true
+	case "MediaType.Suffixes":
+		return strings.Join(d.MediaType().Suffixes(), ","), true
+	}
+
+	if key, found := strings.CutPrefix(field, "Data."); found {
+		v, found := cast.ToStringMap(d.Data())[key]
+		if !found {
+			return "", false
+		}
+		return cast.ToString(v), true
+	}
+
+	return "", false
+}
+
+// PostProcess defers the publishing, and thereby the transformation, of the
+// given Resource until all pages are rendered, e.g. to purge a stylesheet
+// against the build stats.
+func (rs *Resources) PostProcess(r resources.Resource) (resources.PostPublishedResource, error) {
+	if r == nil {
+		return nil, fmt.Errorf("resources.PostProcess: resource is nil")
+	}
+
+	id := rs.Common.Incr.Incr()
+	ppr := NewPostPublishResource(id, r)
+
+	rs.postProcessMu.Lock()
+	rs.PostProcessResources[strconv.Itoa(id)] = ppr
+	rs.postProcessMu.Unlock()
+
+	return ppr, nil
+}
+
+var postProcessPlaceholderRe = regexp.MustCompile(
+	regexp.QuoteMeta(resources.PostProcessPrefix) + `_(\d+)_([\w.]+?)` + regexp.QuoteMeta(resources.PostProcessSuffix))
+
+// ApplyPostProcess replaces the resources.PostProcess placeholders in the
+// published files with the final values. It is called when the build is done.
+func (rs *Resources) ApplyPostProcess() error {
+	rs.postProcessMu.RLock()
+	defer rs.postProcessMu.RUnlock()
+
+	if len(rs.PostProcessResources) == 0 {
+		return nil
+	}
+
+	fs := rs.FsService.PublishFs()
+	for _, filename := range rs.FsService.PostProcessFiles() {
+		b, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return fmt.Errorf("failed to read %q for post processing: %w", filename, err)
+		}
+
+		replaced, err := rs.replacePostProcessPlaceholders(b)
+		if err != nil {
+			return fmt.Errorf("failed to post process %q: %w", filename, err)
+		}
+		if replaced == nil {
+			continue
+		}
+
+		if err := afero.WriteFile(fs, filename, replaced, 0o666); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replacePostProcessPlaceholders returns nil if b holds no placeholders.
+func (rs *Resources) replacePostProcessPlaceholders(b []byte) ([]byte, error) {
+	var (
+		err     error
+		changed bool
+	)
+
+	replaced := postProcessPlaceholderRe.ReplaceAllFunc(b, func(m []byte) []byte {
+		if err != nil {
+			return m
+		}
+		sm := postProcessPlaceholderRe.FindSubmatch(m)
+		id, field := string(sm[1]), string(sm[2])
+
+		r, found := rs.PostProcessResources[id]
+		if !found {
+			err = fmt.Errorf("resource with ID %s not found", id)
+			return m
+		}
+		v, found := r.GetFieldString(field)
+		if !found {
+			err = fmt.Errorf("field %q not found in resource %q", field, r.Origin().Name())
+			return m
+		}
+
+		changed = true
+		return []byte(v)
+	})
+	if err != nil || !changed {
+		return nil, err
+	}
+
+	return replaced, nil
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	configVO "github.com/mdfriday/hugoverse/internal/domain/config/valueobject"
+	"github.com/mdfriday/hugoverse/internal/domain/resources"
+	"github.com/mdfriday/hugoverse/pkg/identity"
+	"github.com/mdfriday/hugoverse/pkg/io"
+)
+
+func TestPostProcess(t *testing.T) {
+	c := qt.New(t)
+
+	rc, _ := newTestRemoteClient(c, configVO.DefaultSecurityConfig)
+	rs := rc.rs
+	rs.Common = &Common{
+		Incr: &identity.IncrementByOne{},
+		PostBuildAssets: &PostBuildAssets{
+			PostProcessResources: make(map[string]resources.PostPublishedResource),
+		},
+	}
+
+	rsb := newResourceBuilder("/css/main.css", func() (io.ReadSeekCloser, error) {
+		return io.NewReadSeekerNoOpCloserFromString("body{}"), nil
+	})
+	rsb.withMediaService(rs.MediaService).withURLService(rs.URLService).
+		withData(map[string]any{"Integrity": "sha256-abc"})
+	r, err := rsb.build()
+	c.Assert(err, qt.IsNil)
+
+	ppr, err := rs.PostProcess(r)
+	c.Assert(err, qt.IsNil)
+	c.Assert(ppr.Name(), qt.Equals, "__h_pp_l1_1_Name__e=")
+
+	data := ppr.Data().(map[string]any)
+	html := `<link href="` + ppr.Name() + `" integrity="` + data["Integrity"].(string) + `">`
+
+	b, err := rs.replacePostProcessPlaceholders([]byte(html))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, `<link href="main.css" integrity="sha256-abc">`)
+
+	b, err = rs.replacePostProcessPlaceholders([]byte(`<p>no placeholders</p>`))
+	c.Assert(err, qt.IsNil)
+	c.Assert(b, qt.IsNil)
+
+	_, err = rs.replacePostProcessPlaceholders([]byte(`__h_pp_l1_42_Name__e=`))
+	c.Assert(err, qt.ErrorMatches, "resource with ID 42 not found")
+}
@@ -0,0 +1,390 @@
+package entity
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/domain/resources"
+	"github.com/mdfriday/hugoverse/pkg/cache/filecache"
+	"github.com/mdfriday/hugoverse/pkg/identity"
+	pio "github.com/mdfriday/hugoverse/pkg/io"
+	"github.com/mdfriday/hugoverse/pkg/maps"
+	"github.com/mdfriday/hugoverse/pkg/media"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+	"io"
+	"mime"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultRemoteMaxBodySize is the default max size of the body of a
+	// remote resource.
+	DefaultRemoteMaxBodySize = 32 << 20
+
+	maxRemoteRedirects = 10
+)
+
+// RemoteClient fetches remote resources, see resources.GetRemote.
+type RemoteClient struct {
+	rs *Resources
+
+	Security  resources.SecurityConfig
+	FileCache *filecache.Cache
+
+	// HTTPClient follows the redirects to the URLs allowed by Security
+	// only.
+	HTTPClient *http.Client
+
+	// Retries is the number of retries on temporary failures, e.g. a 503.
+	Retries int
+	// RetryWait is the wait before the first retry, doubled for every
+	// following retry.
+	RetryWait time.Duration
+
+	// MaxBodySize is the max size of a response body, a larger one fails
+	// the fetch.
+	MaxBodySize int64
+}
+
+func NewRemoteClient(rs *Resources, security resources.SecurityConfig) *RemoteClient {
+	c := &RemoteClient{
+		rs:          rs,
+		Security:    security,
+		FileCache:   rs.Cache.GetResourceCache(),
+		Retries:     3,
+		RetryWait:   500 * time.Millisecond,
+		MaxBodySize: DefaultRemoteMaxBodySize,
+	}
+	c.HTTPClient = &http.Client{
+		Timeout:       30 * time.Second,
+		CheckRedirect: c.checkRedirect,
+	}
+	return c
+}
+
+// redirectError is the error of a redirect not followed, which isn't
+// retried.
+type redirectError struct {
+	error
+}
+
+func (e *redirectError) Unwrap() error {
+	return e.error
+}
+
+func (c *RemoteClient) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRemoteRedirects {
+		return &redirectError{fmt.Errorf("stopped after %d redirects", maxRemoteRedirects)}
+	}
+	if err := c.Security.CheckAllowedHTTPURL(req.URL.String()); err != nil {
+		return &redirectError{err}
+	}
+	return nil
+}
+
+// RemoteOptions are the options accepted by resources.GetRemote.
+type RemoteOptions struct {
+	Method  string
+	Headers map[string]any
+	Body    any
+
+	// Key overrides the cache key, e.g. to cache requests with a changing
+	// auth token once.
+	Key string
+}
+
+func decodeRemoteOptions(m map[string]any) (RemoteOptions, error) {
+	var opts RemoteOptions
+	if err := mapstructure.WeakDecode(maps.CleanConfigStringMap(m), &opts); err != nil {
+		return opts, err
+	}
+	opts.Method = strings.ToUpper(opts.Method)
+	if opts.Method == "" {
+		opts.Method = http.MethodGet
+	}
+	return opts, nil
+}
+
+func (o RemoteOptions) body() ([]byte, error) {
+	switch b := o.Body.(type) {
+	case nil:
+		return nil, nil
+	case []byte:
+		return b, nil
+	default:
+		s, err := cast.ToStringE(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid request body: %w", err)
+		}
+		return []byte(s), nil
+	}
+}
+
+func (o RemoteOptions) header() http.Header {
+	h := make(http.Header)
+	for k, v := range o.Headers {
+		switch vv := v.(type) {
+		case []any, []string:
+			for _, s := range cast.ToStringSlice(vv) {
+				h.Add(k, s)
+			}
+		default:
+			h.Add(k, cast.ToString(vv))
+		}
+	}
+	return h
+}
+
+// RemoteError is returned from FromRemote when the request failed. Data
+// holds the response metadata, if any, e.g. StatusCode.
+type RemoteError struct {
+	error
+	data map[string]any
+}
+
+func (e *RemoteError) Unwrap() error {
+	return e.error
+}
+
+func (e *RemoteError) Data() any {
+	return e.data
+}
+
+var _ resources.ResourceError = (*RemoteError)(nil)
+
+// GetRemote is FromRemote with the error returned as a Resource, to be
+// handled in the templates with .Err.
+func (c *RemoteClient) GetRemote(uri string, options map[string]any) resources.Resource {
+	r, err := c.FromRemote(uri, options)
+	if err != nil {
+		return NewErrorResource(fmt.Errorf("error calling resources.GetRemote: %w", err))
+	}
+	return r
+}
+
+// FromRemote fetches the given http(s) URL and creates a Resource from the
+// response body. Successful responses are cached on disk, with the max age
+// set in the getresource cache config.
+func (c *RemoteClient) FromRemote(uri string, optionsm map[string]any) (resources.Resource, error) {
+	rURL, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse URL for resource %s: %w", uri, err)
+	}
+	if rURL.Scheme != "http" && rURL.Scheme != "https" {
+		return nil, fmt.Errorf("invalid URL scheme %q in %s", rURL.Scheme, uri)
+	}
+
+	opts, err := decodeRemoteOptions(optionsm)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode options for resource %s: %w", uri, err)
+	}
+
+	if err := c.Security.CheckAllowedHTTPURL(uri); err != nil {
+		return nil, err
+	}
+	if err := c.Security.CheckAllowedHTTPMethod(opts.Method); err != nil {
+		return nil, err
+	}
+
+	body, err := opts.body()
+	if err != nil {
+		return nil, err
+	}
+	header := opts.header()
+
+	resourceID := opts.Key
+	if resourceID == "" {
+		resourceID = identity.HashString(uri, opts.Method, header, string(body))
+	} else {
+		resourceID = identity.HashString(resourceID)
+	}
+
+	return c.rs.Cache.GetOrCreateResource("__remote/"+resourceID, func() (resources.Resource, error) {
+		_, b, err := c.FileCache.GetOrCreateBytes(resourceID, func() ([]byte, error) {
+			res, err := c.fetch(rURL, opts.Method, header, body)
+			if err != nil {
+				return nil, err
+			}
+			defer res.Body.Close()
+
+			if res.StatusCode < 200 || res.StatusCode > 299 {
+				return nil, &RemoteError{
+					error: fmt.Errorf("failed to fetch remote resource from '%s': %s", uri, http.StatusText(res.StatusCode)),
+					data:  responseData(res),
+				}
+			}
+
+			if res.ContentLength > c.MaxBodySize {
+				return nil, fmt.Errorf("remote resource %q is larger than %d bytes", uri, c.MaxBodySize)
+			}
+			content, err := io.ReadAll(io.LimitReader(res.Body, c.MaxBodySize+1))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read remote resource %q: %w", uri, err)
+			}
+			if int64(len(content)) > c.MaxBodySize {
+				return nil, fmt.Errorf("remote resource %q is larger than %d bytes", uri, c.MaxBodySize)
+			}
+			res.Body = io.NopCloser(bytes.NewReader(content))
+
+			return httputil.DumpResponse(res, true)
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		res, err := http.ReadResponse(bufio.NewReader(bytes.NewReader(b)), nil)
+		if err != nil {
+			return nil, err
+		}
+		defer res.Body.Close()
+
+		content, err := io.ReadAll(res.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read remote resource %q: %w", uri, err)
+		}
+
+		mediaType := c.resolveMediaType(rURL, res.Header.Get("Content-Type"), content)
+
+		name := path.Base(rURL.Path)
+		if _, params, _ := mime.ParseMediaType(res.Header.Get("Content-Disposition")); params != nil {
+			if filename, ok := params["filename"]; ok {
+				name = filename
+			}
+		}
+		name = strings.TrimSuffix(name, path.Ext(name))
+		if name == "" || name == "." || name == "/" {
+			name = "remote"
+		}
+		targetPath := "/" + name + "_" + resourceID + mediaType.FirstSuffix.FullSuffix
+
+		rsb := newResourceBuilder(targetPath, func() (pio.ReadSeekCloser, error) {
+			return pio.NewReadSeekerNoOpCloserFromBytes(content), nil
+		})
+		rsb.withCache(c.rs.Cache).withMediaService(c.rs.MediaService).
+			withImageService(c.rs.ImageService).withImageProcessor(c.rs.ImageProc).
+			withPublisher(c.rs.Publisher).withURLService(c.rs.URLService).
+			withData(responseData(res))
+
+		return rsb.build()
+	})
+}
+
+// fetch does the request, retrying on network errors and temporary
+// failures.
+func (c *RemoteClient) fetch(u *url.URL, method string, header http.Header, body []byte) (*http.Response, error) {
+	wait := c.RetryWait
+
+	for i := 0; ; i++ {
+		req, err := http.NewRequest(method, u.String(), bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		req.Header = header.Clone()
+		if req.Header.Get("User-Agent") == "" {
+			req.Header.Set("User-Agent", "Hugoverse")
+		}
+
+		res, err := c.HTTPClient.Do(req)
+		if i >= c.Retries || errors.As(err, new(*redirectError)) {
+			return res, err
+		}
+		if err == nil && !isTemporaryHTTPStatus(res.StatusCode) {
+			return res, nil
+		}
+		if res != nil {
+			res.Body.Close()
+		}
+
+		time.Sleep(wait)
+		wait *= 2
+	}
+}
+
+func isTemporaryHTTPStatus(code int) bool {
+	switch code {
+	case http.StatusRequestTimeout,
+		http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+func (c *RemoteClient) resolveMediaType(u *url.URL, contentType string, content []byte) media.Type {
+	if ext := strings.TrimPrefix(path.Ext(u.Path), "."); ext != "" {
+		if mt, _, found := c.rs.MediaService.LookFirstBySuffix(ext); found {
+			return mt
+		}
+	}
+
+	for _, ct := range []string{contentType, http.DetectContentType(content)} {
+		mtStr, _, err := mime.ParseMediaType(ct)
+		if err != nil {
+			continue
+		}
+		if mt, found := c.rs.MediaService.LookByType(mtStr); found {
+			return mt
+		}
+	}
+
+	return media.Builtin.OctetType
+}
+
+func responseData(res *http.Response) map[string]any {
+	return map[string]any{
+		"ContentLength":    res.ContentLength,
+		"ContentType":      res.Header.Get("Content-Type"),
+		"Status":           res.Status,
+		"StatusCode":       res.StatusCode,
+		"TransferEncoding": res.TransferEncoding,
+		"Headers":          res.Header,
+	}
+}
+
+// ErrorResource is returned in place of a Resource when resources.GetRemote
+// fails, so the error can be handled in the template with .Err.
+type ErrorResource struct {
+	resources.ResourceError
+}
+
+// NewErrorResource wraps err, keeping the response data from a RemoteError.
+func NewErrorResource(err error) *ErrorResource {
+	var re *RemoteError
+	if !errors.As(err, &re) {
+		re = &RemoteError{error: err, data: map[string]any{}}
+	} else if re.error != err {
+		re = &RemoteError{error: err, data: re.data}
+	}
+	return &ErrorResource{ResourceError: re}
+}
+
+func (e *ErrorResource) Err() resources.ResourceError {
+	return e.ResourceError
+}
+
+func (e *ErrorResource) Name() string { return "" }
+
+func (e *ErrorResource) ReadSeekCloser() (pio.ReadSeekCloser, error) {
+	return nil, e.ResourceError
+}
+
+func (e *ErrorResource) TargetPath() string { return "" }
+
+func (e *ErrorResource) Content(context.Context) (any, error) {
+	return nil, e.ResourceError
+}
+
+func (e *ErrorResource) ResourceType() string { return "" }
+
+func (e *ErrorResource) MediaType() media.Type { return media.Type{} }
@@ -0,0 +1,163 @@
+package entity
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	configEntity "github.com/mdfriday/hugoverse/internal/domain/config/entity"
+	configVO "github.com/mdfriday/hugoverse/internal/domain/config/valueobject"
+	"github.com/mdfriday/hugoverse/internal/domain/resources"
+	"github.com/mdfriday/hugoverse/pkg/cache/dynacache"
+	"github.com/mdfriday/hugoverse/pkg/cache/filecache"
+	"github.com/mdfriday/hugoverse/pkg/hexec/security"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+	"github.com/spf13/afero"
+)
+
+type testURLService struct{}
+
+func (testURLService) BaseUrl() string { return "https://example.org/" }
+
+func newTestRemoteClient(c *qt.C, sec configVO.SecurityConfig) (*RemoteClient, afero.Fs) {
+	mt, err := configVO.DecodeMediaTypesConfig(configVO.NewDefaultProvider())
+	c.Assert(err, qt.IsNil)
+
+	fs := afero.NewMemMapFs()
+	memCache := dynacache.New(dynacache.Options{Running: true, Log: loggers.NewDefault()})
+	rs := &Resources{
+		Cache: &Cache{
+			Caches: filecache.Caches{
+				"getresource": filecache.NewCache(fs, -1, ""),
+			},
+			CacheResource: dynacache.GetOrCreatePartition[string, resources.Resource](
+				memCache, "/res1", dynacache.OptionsPartition{ClearWhen: dynacache.ClearOnChange, Weight: 40},
+			),
+		},
+		MediaService: configEntity.MediaType{MediaTypeConfig: mt},
+		URLService:   testURLService{},
+	}
+
+	rc := NewRemoteClient(rs, configEntity.Security{SecurityConfig: sec})
+	rc.RetryWait = time.Millisecond
+
+	return rc, fs
+}
+
+func TestFromRemote(t *testing.T) {
+	c := qt.New(t)
+
+	var hits, flaky int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		switch r.URL.Path {
+		case "/products.json":
+			if r.Header.Get("Authorization") != "Bearer token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			io.WriteString(w, `{"name": "`+r.Method+`"}`)
+		case "/avatar":
+			if atomic.AddInt32(&flaky, 1) < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+			io.WriteString(w, "avatar")
+		case "/big":
+			io.WriteString(w, strings.Repeat("a", 2048))
+		case "/moved":
+			http.Redirect(w, r, "http://internal.example/secret", http.StatusFound)
+		case "/moved-here":
+			http.Redirect(w, r, "/avatar", http.StatusFound)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	c.Run("Headers, method and caching", func(c *qt.C) {
+		rc, fs := newTestRemoteClient(c, configVO.DefaultSecurityConfig)
+		opts := map[string]any{
+			"method":  "post",
+			"headers": map[string]any{"Authorization": "Bearer token"},
+		}
+
+		r, err := rc.FromRemote(srv.URL+"/products.json", opts)
+		c.Assert(err, qt.IsNil)
+		c.Assert(r.MediaType().Type, qt.Equals, "application/json")
+		content, err := r.Content(context.Background())
+		c.Assert(err, qt.IsNil)
+		c.Assert(content, qt.Equals, `{"name": "POST"}`)
+		data := r.Data().(map[string]any)
+		c.Assert(data["StatusCode"], qt.Equals, http.StatusOK)
+		c.Assert(data["ContentType"], qt.Equals, "application/json")
+
+		// Served from the file cache in a new client.
+		hitsBefore := atomic.LoadInt32(&hits)
+		rc2, _ := newTestRemoteClient(c, configVO.DefaultSecurityConfig)
+		rc2.FileCache = filecache.NewCache(fs, -1, "")
+		_, err = rc2.FromRemote(srv.URL+"/products.json", opts)
+		c.Assert(err, qt.IsNil)
+		c.Assert(atomic.LoadInt32(&hits), qt.Equals, hitsBefore)
+	})
+
+	c.Run("Retries", func(c *qt.C) {
+		rc, _ := newTestRemoteClient(c, configVO.DefaultSecurityConfig)
+		r, err := rc.FromRemote(srv.URL+"/avatar", nil)
+		c.Assert(err, qt.IsNil)
+		c.Assert(r.MediaType().Type, qt.Equals, "text/plain")
+	})
+
+	c.Run("Error as value", func(c *qt.C) {
+		rc, _ := newTestRemoteClient(c, configVO.DefaultSecurityConfig)
+		r := rc.GetRemote(srv.URL+"/products.json", nil)
+		er, ok := r.(resources.ErrProvider)
+		c.Assert(ok, qt.IsTrue)
+		c.Assert(er.Err(), qt.ErrorMatches, ".*Unauthorized")
+		c.Assert(er.Err().Data().(map[string]any)["StatusCode"], qt.Equals, http.StatusUnauthorized)
+	})
+
+	c.Run("Security", func(c *qt.C) {
+		sec := configVO.DefaultSecurityConfig
+		sec.HTTP.Methods = security.MustNewWhitelist("GET")
+		rc, _ := newTestRemoteClient(c, sec)
+		_, err := rc.FromRemote(srv.URL+"/products.json", map[string]any{"method": "POST"})
+		c.Assert(err, qt.ErrorMatches, `(?s)access denied: "POST".*`)
+
+		sec.HTTP.URLs = security.MustNewWhitelist(`^https://example\.org/`)
+		rc, _ = newTestRemoteClient(c, sec)
+		_, err = rc.FromRemote(srv.URL+"/products.json", nil)
+		c.Assert(err, qt.ErrorMatches, `(?s)access denied.*security\.http\.urls.*`)
+
+		// Every redirect is checked too, and not retried.
+		sec.HTTP.URLs = security.MustNewWhitelist("^" + regexp.QuoteMeta(srv.URL) + "/")
+		rc, _ = newTestRemoteClient(c, sec)
+		hitsBefore := atomic.LoadInt32(&hits)
+		_, err = rc.FromRemote(srv.URL+"/moved", nil)
+		c.Assert(err, qt.ErrorMatches, `(?s).*access denied: "http://internal\.example/secret".*`)
+		c.Assert(atomic.LoadInt32(&hits), qt.Equals, hitsBefore+1)
+		_, err = rc.FromRemote(srv.URL+"/moved-here", nil)
+		c.Assert(err, qt.IsNil)
+	})
+
+	c.Run("Max body size", func(c *qt.C) {
+		rc, _ := newTestRemoteClient(c, configVO.DefaultSecurityConfig)
+		rc.MaxBodySize = 1024
+		_, err := rc.FromRemote(srv.URL+"/big", nil)
+		c.Assert(err, qt.ErrorMatches, `.*is larger than 1024 bytes`)
+
+		rc.MaxBodySize = 2048
+		r, err := rc.FromRemote(srv.URL+"/big", nil)
+		c.Assert(err, qt.IsNil)
+		c.Assert(r, qt.IsNotNil)
+	})
+}
@@ -19,6 +19,7 @@ type resourceBuilder struct {
 
 	openReadSeekCloser io.OpenReadSeekCloser
 	mediaType          media.Type
+	data               map[string]any
 
 	publisher *Publisher
 
@@ -66,6 +67,11 @@ func (rs *resourceBuilder) withMediaService(mediaSvc resources.MediaTypesConfig)
 	return rs
 }
 
+func (rs *resourceBuilder) withData(data map[string]any) *resourceBuilder {
+	rs.data = data
+	return rs
+}
+
 func (rs *resourceBuilder) build() (resources.Resource, error) {
 	if rs.openReadSeekCloser == nil {
 		return nil, errors.New("OpenReadSeekCloser is nil")
@@ -124,6 +130,10 @@ func (rs *resourceBuilder) buildMediaType() error {
 }
 
 func (rs *resourceBuilder) buildResource() (resources.Resource, error) {
+	if rs.data == nil {
+		rs.data = make(map[string]any)
+	}
+
 	gr := &Resource{
 		Staler: &stale.AtomicStaler{},
 		h:      &valueobject.ResourceHash{},
@@ -133,7 +143,7 @@ func (rs *resourceBuilder) buildResource() (resources.Resource, error) {
 
 		paths: rs.resPaths,
 
-		data:              make(map[string]any),
+		data:              rs.data,
 		dependencyManager: identity.NewManager("resource"),
 
 		publisher:   rs.publisher,
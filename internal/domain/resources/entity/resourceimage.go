@@ -289,75 +289,84 @@ func (i *ResourceImage) processActionOptions(action string, options []string) (r
 	return img, nil
 }
 
-// Serialize image processing. The imaging library spins up its own set of Go routines,
-// so there is not much to gain from adding more load to the mix. That
-// can even have negative effect in low resource scenarios.
-// Note that this only effects the non-cached scenario. Once the processed
-// image is written to disk, everything is fast, fast fast.
-const imageProcWorkers = 1
-
-var imageProcSem = make(chan bool, imageProcWorkers)
+// decodedImageWeight estimates the memory used to transform the image,
+// the decoded source and the result, at 4 bytes per pixel.
+func (i *ResourceImage) decodedImageWeight(conf valueobject.ImageConfig) uint64 {
+	const bytesPerPixel = 4
+	w, h := i.Width(), i.Height()
+	return uint64(w*h+max(conf.Width, w)*max(conf.Height, h)) * bytesPerPixel
+}
 
 func (i *ResourceImage) doWithImageConfig(conf valueobject.ImageConfig, f func(src image.Image) (image.Image, error)) (resources.ImageResource, error) {
-	img, err := i.ImageCache.GetOrCreateImageResource(i, conf, func() (*ResourceImage, image.Image, error) {
-		imageProcSem <- true
-		defer func() {
-			<-imageProcSem
-		}()
+	pool := i.ImageCache.ImagePool
+	v, err := pool.Do(i.relTargetPathFromConfig(conf).TargetPath(), func() (any, error) {
+		return i.getOrCreateImageResource(pool, conf, f)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return v.(*ResourceImage), nil
+}
 
-		src, err := i.DecodeImage()
-		if err != nil {
-			return nil, nil, &os.PathError{Op: conf.Action, Path: i.paths.TargetPath(), Err: err}
-		}
+func (i *ResourceImage) getOrCreateImageResource(pool *ImagePool, conf valueobject.ImageConfig, f func(src image.Image) (image.Image, error)) (*ResourceImage, error) {
+	return i.ImageCache.GetOrCreateImageResource(i, conf, func() (ci *ResourceImage, converted image.Image, err error) {
+		err = pool.Run(i.decodedImageWeight(conf), func() error {
+			ci, converted, err = i.transform(conf, f)
+			return err
+		})
+		return
+	})
+}
 
-		converted, err := f(src)
-		if err != nil {
-			return nil, nil, &os.PathError{Op: conf.Action, Path: i.paths.TargetPath(), Err: err}
-		}
+func (i *ResourceImage) transform(conf valueobject.ImageConfig, f func(src image.Image) (image.Image, error)) (*ResourceImage, image.Image, error) {
+	src, err := i.DecodeImage()
+	if err != nil {
+		return nil, nil, &os.PathError{Op: conf.Action, Path: i.paths.TargetPath(), Err: err}
+	}
 
-		hasAlpha := !valueobject.IsOpaque(converted)
-		shouldFill := conf.BgColor != nil && hasAlpha
-		shouldFill = shouldFill || (!valueobject.SupportsTransparency(conf.TargetFormat) && hasAlpha)
-		var bgColor color.Color
+	converted, err := f(src)
+	if err != nil {
+		return nil, nil, &os.PathError{Op: conf.Action, Path: i.paths.TargetPath(), Err: err}
+	}
 
-		if shouldFill {
-			bgColor = conf.BgColor
-			if bgColor == nil {
-				bgColor = i.ImageConfig.BgColor()
-			}
-			tmp := image.NewRGBA(converted.Bounds())
-			draw.Draw(tmp, tmp.Bounds(), image.NewUniform(bgColor), image.Point{}, draw.Src)
-			draw.Draw(tmp, tmp.Bounds(), converted, converted.Bounds().Min, draw.Over)
-			converted = tmp
+	hasAlpha := !valueobject.IsOpaque(converted)
+	shouldFill := conf.BgColor != nil && hasAlpha
+	shouldFill = shouldFill || (!valueobject.SupportsTransparency(conf.TargetFormat) && hasAlpha)
+	var bgColor color.Color
+
+	if shouldFill {
+		bgColor = conf.BgColor
+		if bgColor == nil {
+			bgColor = i.ImageConfig.BgColor()
 		}
+		tmp := image.NewRGBA(converted.Bounds())
+		draw.Draw(tmp, tmp.Bounds(), image.NewUniform(bgColor), image.Point{}, draw.Src)
+		draw.Draw(tmp, tmp.Bounds(), converted, converted.Bounds().Min, draw.Over)
+		converted = tmp
+	}
 
-		if conf.TargetFormat == resources.PNG {
-			// Apply the colour palette from the source
-			if paletted, ok := src.(*image.Paletted); ok {
-				palette := paletted.Palette
-				if bgColor != nil && len(palette) < 256 {
-					palette = images.AddColorToPalette(bgColor, palette)
-				} else if bgColor != nil {
-					images.ReplaceColorInPalette(bgColor, palette)
-				}
-				tmp := image.NewPaletted(converted.Bounds(), palette)
-				draw.FloydSteinberg.Draw(tmp, tmp.Bounds(), converted, converted.Bounds().Min)
-				converted = tmp
+	if conf.TargetFormat == resources.PNG {
+		// Apply the colour palette from the source
+		if paletted, ok := src.(*image.Paletted); ok {
+			palette := paletted.Palette
+			if bgColor != nil && len(palette) < 256 {
+				palette = images.AddColorToPalette(bgColor, palette)
+			} else if bgColor != nil {
+				images.ReplaceColorInPalette(bgColor, palette)
 			}
+			tmp := image.NewPaletted(converted.Bounds(), palette)
+			draw.FloydSteinberg.Draw(tmp, tmp.Bounds(), converted, converted.Bounds().Min)
+			converted = tmp
 		}
+	}
 
-		ci := i.clone(converted)
-		targetPath := i.relTargetPathFromConfig(conf)
-		ci.paths = targetPath
-		ci.ImageFormat = conf.TargetFormat
-		ci.mediaType = valueobject.MediaType(conf.TargetFormat)
+	ci := i.clone(converted)
+	targetPath := i.relTargetPathFromConfig(conf)
+	ci.paths = targetPath
+	ci.ImageFormat = conf.TargetFormat
+	ci.mediaType = valueobject.MediaType(conf.TargetFormat)
 
-		return ci, converted, nil
-	})
-	if err != nil {
-		return nil, err
-	}
-	return img, nil
+	return ci, converted, nil
 }
 
 // DecodeImage decodes the images source into an Image.
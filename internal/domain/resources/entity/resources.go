@@ -37,6 +37,7 @@ type Resources struct {
 	*SassClient
 	*JsClient
 	*BundlerClient
+	*RemoteClient
 }
 
 func (rs *Resources) SetupTemplateClient(tmpl Template) {
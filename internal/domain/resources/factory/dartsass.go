@@ -9,12 +9,12 @@ import (
 )
 
 func newDartSass(exec *hexec.Exec, fs resources.Fs) (*entity.SassClient, error) {
-	valueobject.SetDartSassBinaryName()
-	if valueobject.DartSassBinaryName == "" {
+	binary := valueobject.DartSassBinaryName()
+	if binary == "" {
 		return &entity.SassClient{BinaryFound: false}, fmt.Errorf("no Dart Sass binary found in $PATH")
 	}
 
-	if err := exec.Sec().CheckAllowedExec(valueobject.DartSassBinaryName); err != nil {
+	if err := exec.Sec().CheckAllowedExec(binary); err != nil {
 		return &entity.SassClient{BinaryFound: true, AllowedExec: false}, err
 	}
 
@@ -6,14 +6,22 @@ import (
 	"github.com/mdfriday/hugoverse/internal/domain/resources/valueobject"
 	"github.com/mdfriday/hugoverse/pkg/cache/dynacache"
 	"github.com/mdfriday/hugoverse/pkg/cache/filecache"
+	"github.com/mdfriday/hugoverse/pkg/env"
 	"github.com/mdfriday/hugoverse/pkg/hexec"
 	"github.com/mdfriday/hugoverse/pkg/identity"
 	"github.com/mdfriday/hugoverse/pkg/loggers"
 	"github.com/mdfriday/hugoverse/pkg/resource/jsconfig"
 	"github.com/spf13/afero"
+	"sync"
 	"time"
 )
 
+// imageBudget is shared by the builds of the process, so that concurrent
+// builds together stay within the memory limit.
+var imageBudget = sync.OnceValue(func() *entity.ImageBudget {
+	return entity.NewImageBudget(env.GetNumWorkerMultiplier(), env.GetMemoryLimit())
+})
+
 func NewResources(ws resources.Workspace) (*entity.Resources, error) {
 	c, err := newCache(ws)
 	if err != nil {
@@ -72,6 +80,7 @@ func NewResources(ws resources.Workspace) (*entity.Resources, error) {
 	}
 
 	rs.BundlerClient = entity.NewBundlerClient(rs)
+	rs.RemoteClient = entity.NewRemoteClient(rs, ws)
 
 	return rs, nil
 }
@@ -84,7 +93,8 @@ func newCache(ws resources.Workspace) (*entity.Cache, error) {
 	memoryCache := newMemoryCache()
 
 	return &entity.Cache{
-		Caches: fileCaches,
+		Caches:    fileCaches,
+		ImagePool: entity.NewImagePool(imageBudget()),
 		CacheImage: dynacache.GetOrCreatePartition[string, *entity.ResourceImage](
 			memoryCache,
 			"/imgs",
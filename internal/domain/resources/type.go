@@ -45,6 +45,8 @@ type MinifyConfig interface {
 
 type SecurityConfig interface {
 	ExecAuth() hexec.ExecAuth
+	CheckAllowedHTTPURL(url string) error
+	CheckAllowedHTTPMethod(method string) error
 }
 
 type Fs interface {
@@ -57,6 +59,10 @@ type Fs interface {
 	WorkingDirAbs() string
 	PublishDirAbs() string
 
+	// PostProcessFiles returns the published files with
+	// resources.PostProcess placeholders.
+	PostProcessFiles() []string
+
 	ResolveJSConfigFile(name string) string
 
 	AssetsFsRealFilename(rel string) string
@@ -0,0 +1,104 @@
+package valueobject
+
+import (
+	"fmt"
+	"github.com/evanw/esbuild/pkg/api"
+	"github.com/mdfriday/hugoverse/pkg/paths"
+	"github.com/mitchellh/mapstructure"
+	"regexp"
+	"strings"
+)
+
+var (
+	nameEngine = map[string]api.EngineName{
+		"chrome":  api.EngineChrome,
+		"edge":    api.EngineEdge,
+		"firefox": api.EngineFirefox,
+		"ie":      api.EngineIE,
+		"ios":     api.EngineIOS,
+		"opera":   api.EngineOpera,
+		"safari":  api.EngineSafari,
+	}
+
+	engineRe = regexp.MustCompile(`^([a-z]+)(\d+(?:\.\d+)*)$`)
+
+	// DefaultCSSTargets are the browsers targeted by css.Build if none are
+	// configured, the baseline of browsers released in 2020 and 2021.
+	DefaultCSSTargets = []string{"chrome87", "edge88", "firefox78", "safari14"}
+)
+
+// CSSBuildOptions holds user facing options for the css.Build template function.
+type CSSBuildOptions struct {
+	// If not set, the source path will be used as the target path.
+	TargetPath string
+
+	// Whether to minify to output.
+	Minify bool
+
+	// One of "inline", "external", "linked" or "none".
+	SourceMap string
+
+	SourcesContent bool
+
+	// The browsers to build for, e.g. ["chrome100", "safari15"].
+	// CSS nesting and other modern syntax is lowered, and vendor prefixes
+	// added, as needed by these.
+	// Default is DefaultCSSTargets.
+	Targets []string
+}
+
+// DecodeCSSBuildOptions decodes the given map into CSSBuildOptions.
+func DecodeCSSBuildOptions(m map[string]any) (CSSBuildOptions, error) {
+	opts := CSSBuildOptions{
+		SourcesContent: true,
+	}
+
+	if err := mapstructure.WeakDecode(m, &opts); err != nil {
+		return opts, err
+	}
+
+	if opts.TargetPath != "" {
+		opts.TargetPath = paths.ToSlashTrimLeading(opts.TargetPath)
+	}
+	if len(opts.Targets) == 0 {
+		opts.Targets = DefaultCSSTargets
+	}
+
+	return opts, nil
+}
+
+// Options converts the CSS options into build options.
+func (o CSSBuildOptions) Options() (Options, error) {
+	engines, err := toEngines(o.Targets)
+	if err != nil {
+		return Options{}, err
+	}
+
+	return Options{
+		ExternalOptions: ExternalOptions{
+			TargetPath:     o.TargetPath,
+			Minify:         o.Minify,
+			SourceMap:      o.SourceMap,
+			SourcesContent: o.SourcesContent,
+		},
+		InternalOptions: InternalOptions{
+			Engines: engines,
+		},
+	}, nil
+}
+
+func toEngines(targets []string) ([]api.Engine, error) {
+	var engines []api.Engine
+	for _, t := range targets {
+		m := engineRe.FindStringSubmatch(strings.ToLower(strings.TrimSpace(t)))
+		if m == nil {
+			return nil, fmt.Errorf("invalid CSS target: %q", t)
+		}
+		name, found := nameEngine[m[1]]
+		if !found {
+			return nil, fmt.Errorf("invalid CSS target: %q", t)
+		}
+		engines = append(engines, api.Engine{Name: name, Version: m[2]})
+	}
+	return engines, nil
+}
@@ -0,0 +1,60 @@
+package valueobject
+
+import (
+	"testing"
+
+	"github.com/evanw/esbuild/pkg/api"
+	qt "github.com/frankban/quicktest"
+	configVO "github.com/mdfriday/hugoverse/internal/domain/config/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/media"
+)
+
+func TestCSSBuildOptions(t *testing.T) {
+	c := qt.New(t)
+
+	// Sets up the builtin media types.
+	_, err := configVO.DecodeMediaTypesConfig(configVO.NewDefaultProvider())
+	c.Assert(err, qt.IsNil)
+
+	build := func(c *qt.C, m map[string]any, css string) string {
+		cssOpts, err := DecodeCSSBuildOptions(m)
+		c.Assert(err, qt.IsNil)
+		opts, err := cssOpts.Options()
+		c.Assert(err, qt.IsNil)
+		opts.MediaType = media.Builtin.CSSType
+		opts.Contents = css
+		opts.Stdin = true
+		c.Assert(opts.compile(), qt.IsNil)
+
+		result := api.Build(opts.compiled)
+		c.Assert(result.Errors, qt.HasLen, 0)
+		c.Assert(result.OutputFiles, qt.HasLen, 1)
+		return string(result.OutputFiles[0].Contents)
+	}
+
+	c.Run("Defaults", func(c *qt.C) {
+		opts, err := DecodeCSSBuildOptions(map[string]any{"targetPath": "/css/main.css"})
+		c.Assert(err, qt.IsNil)
+		c.Assert(opts.TargetPath, qt.Equals, "css/main.css")
+		c.Assert(opts.Targets, qt.DeepEquals, DefaultCSSTargets)
+	})
+
+	c.Run("Nesting and prefixes", func(c *qt.C) {
+		out := build(c, map[string]any{"targets": []string{"safari12"}},
+			".a { color: red; & .b { user-select: none; } }")
+		c.Assert(out, qt.Contains, ".a .b {")
+		c.Assert(out, qt.Contains, "-webkit-user-select: none;")
+	})
+
+	c.Run("Minify", func(c *qt.C) {
+		out := build(c, map[string]any{"minify": true, "targets": "chrome120"}, ".a {\n  color: #ff0000;\n}\n")
+		c.Assert(out, qt.Equals, ".a{color:red}\n")
+	})
+
+	c.Run("Invalid target", func(c *qt.C) {
+		opts, err := DecodeCSSBuildOptions(map[string]any{"targets": []string{"netscape4"}})
+		c.Assert(err, qt.IsNil)
+		_, err = opts.Options()
+		c.Assert(err, qt.ErrorMatches, `invalid CSS target: "netscape4"`)
+	})
+}
@@ -6,25 +6,36 @@ import (
 	"github.com/mitchellh/mapstructure"
 	"os"
 	"strings"
+	"sync"
 )
 
-var DartSassBinaryName string
+var (
+	dartSassBinaryName     string
+	dartSassBinaryNameOnce sync.Once
+)
 
-func SetDartSassBinaryName() {
-	DartSassBinaryName = os.Getenv("DART_SASS_BINARY")
-	if DartSassBinaryName == "" {
-		for _, name := range dartSassBinaryNamesV2 {
-			if hexec.InPath(name) {
-				DartSassBinaryName = name
-				break
+// DartSassBinaryName returns the Dart Sass binary to use, set in
+// DART_SASS_BINARY or else found in $PATH, empty if there is none.
+// It is looked up once per process and shared by all builds.
+func DartSassBinaryName() string {
+	dartSassBinaryNameOnce.Do(func() {
+		dartSassBinaryName = os.Getenv("DART_SASS_BINARY")
+		if dartSassBinaryName == "" {
+			for _, name := range dartSassBinaryNamesV2 {
+				if hexec.InPath(name) {
+					dartSassBinaryName = name
+					break
+				}
 			}
-		}
-		if DartSassBinaryName == "" {
-			if hexec.InPath(dartSassBinaryNameV1) {
-				DartSassBinaryName = dartSassBinaryNameV1
+			if dartSassBinaryName == "" {
+				if hexec.InPath(dartSassBinaryNameV1) {
+					dartSassBinaryName = dartSassBinaryNameV1
+				}
 			}
 		}
-	}
+	})
+
+	return dartSassBinaryName
 }
 
 var (
@@ -33,7 +44,7 @@ var (
 )
 
 func IsDartSassV2() bool {
-	return !strings.Contains(DartSassBinaryName, "embedded")
+	return !strings.Contains(DartSassBinaryName(), "embedded")
 }
 
 type DartSassOptions struct {
@@ -190,6 +190,9 @@ type InternalOptions struct {
 
 	StdinSourcePath string
 
+	// The browsers to build CSS for.
+	Engines []api.Engine
+
 	DependencyManager identity.Manager
 
 	Stdin                   bool // Set to true to pass in the entry point as a byte slice.
@@ -246,6 +249,8 @@ func (opts *Options) compile() (err error) {
 		loader = api.LoaderTSX
 	case media.Builtin.JSXType.SubType:
 		loader = api.LoaderJSX
+	case media.Builtin.CSSType.SubType:
+		loader = api.LoaderCSS
 	default:
 		err = fmt.Errorf("unsupported Media Type: %q", opts.MediaType)
 		return
@@ -326,6 +331,7 @@ func (opts *Options) compile() (err error) {
 		AbsWorkingDir: opts.AbsWorkingDir,
 
 		Target:         target,
+		Engines:        opts.Engines,
 		Format:         format,
 		Platform:       platform,
 		Sourcemap:      sourceMap,
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"encoding/json"
+	"github.com/mdfriday/hugoverse/internal/domain/site/valueobject"
+	"github.com/spf13/afero"
+)
+
+const buildStatsFilename = "hugo_stats.json"
+
+// writeBuildStats writes the HTML elements collected while publishing to
+// hugo_stats.json in the project working dir, only rewriting the file when
+// its content changes to avoid triggering file watchers.
+func (s *Site) writeBuildStats() error {
+	if s.Publisher.HTMLCollector == nil {
+		return nil
+	}
+
+	elements := s.Publisher.HTMLCollector.Elements()
+	disableTags, disableClasses, disableIDs := s.ConfigSvc.BuildStatsOptions()
+	if disableTags {
+		elements.Tags = []string{}
+	}
+	if disableClasses {
+		elements.Classes = []string{}
+	}
+	if disableIDs {
+		elements.IDs = []string{}
+	}
+
+	b, err := json.MarshalIndent(valueobject.BuildStats{HTMLElements: elements}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	fs := s.FsSvc.WorkingWritable()
+	if old, err := afero.ReadFile(fs, buildStatsFilename); err == nil && string(old) == string(b) {
+		return nil
+	}
+
+	return afero.WriteFile(fs, buildStatsFilename, b, 0o666)
+}
@@ -0,0 +1,208 @@
+package entity
+
+import (
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/domain/fs"
+	"github.com/mdfriday/hugoverse/pkg/herrors"
+	"github.com/mdfriday/hugoverse/pkg/parser/metadecoders"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Data returns the decoded files in the merged /data directory for the
+// current language, e.g. data/authors/bep.toml is available as
+// .Site.Data.authors.bep.
+func (s *Site) Data() map[string]any {
+	init, ok := s.lazy.data[s.Language.currentLanguage]
+	if ok {
+		if _, err := init.Do(); err != nil {
+			s.Log.Errorf("Data: %v", err)
+		}
+	} else {
+		s.Log.Errorf("Data: no init for %s", s.Language.currentLanguage)
+	}
+
+	return s.data[s.Language.currentLanguage]
+}
+
+type dataFile struct {
+	fi fs.FileMetaInfo
+
+	keyParts []string
+	name     string
+	format   metadecoders.Format
+}
+
+func (s *Site) loadData(lang string) (map[string]any, error) {
+	languages := make(map[string]bool)
+	for _, l := range s.LanguageSvc.LanguageKeys() {
+		languages[l] = true
+	}
+
+	// The data mounts are walked in order of precedence, project first.
+	// A file for the current language overrides its language neutral
+	// counterpart, so those are handled first.
+	var langFiles, neutralFiles []dataFile
+
+	if err := s.FsSvc.WalkData("", fs.WalkCallback{
+		WalkFn: func(path string, info fs.FileMetaInfo) error {
+			if info.IsDir() {
+				return nil
+			}
+
+			df, fileLang, ok := newDataFile(info, languages)
+			if !ok {
+				return nil
+			}
+
+			switch fileLang {
+			case "":
+				neutralFiles = append(neutralFiles, df)
+			case lang:
+				langFiles = append(langFiles, df)
+			}
+
+			return nil
+		},
+	}, fs.WalkwayConfig{}); err != nil {
+		if !herrors.IsNotExist(err) {
+			return nil, err
+		}
+	}
+
+	data := make(map[string]any)
+	for _, df := range append(langFiles, neutralFiles...) {
+		if err := s.handleDataFile(data, df); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+func newDataFile(fi fs.FileMetaInfo, languages map[string]bool) (dataFile, string, bool) {
+	rel, err := fi.RelativeFilename()
+	if err != nil {
+		rel = fi.Name()
+	}
+	rel = filepath.ToSlash(strings.TrimPrefix(rel, string(filepath.Separator)))
+
+	dir, base := filepath.Split(filepath.FromSlash(rel))
+	ext := filepath.Ext(base)
+	format := metadecoders.FormatFromString(strings.TrimPrefix(ext, "."))
+	if format == "" {
+		return dataFile{}, "", false
+	}
+
+	name := strings.TrimSuffix(base, ext)
+	var lang string
+	if langExt := filepath.Ext(name); langExt != "" && languages[langExt[1:]] {
+		lang = langExt[1:]
+		name = strings.TrimSuffix(name, langExt)
+	}
+
+	var keyParts []string
+	for _, part := range strings.Split(filepath.ToSlash(dir), "/") {
+		if part != "" {
+			keyParts = append(keyParts, part)
+		}
+	}
+
+	return dataFile{fi: fi, keyParts: keyParts, name: name, format: format}, lang, true
+}
+
+// handleDataFile adds the decoded file content to data following Hugo's
+// rules: a file with higher precedence wins, maps with lower precedence are
+// merged in key by key, everything else is only used if not already set.
+func (s *Site) handleDataFile(data map[string]any, df dataFile) error {
+	v, err := s.decodeDataFile(df)
+	if err != nil {
+		return err
+	}
+
+	current := data
+	for _, key := range df.keyParts {
+		if _, ok := current[key]; !ok {
+			current[key] = make(map[string]any)
+		}
+		m, ok := current[key].(map[string]any)
+		if !ok {
+			s.Log.Warnf("Data for key %q in path %q is overridden by higher precedence data already in the data tree", key, df.fi.FileName())
+			return nil
+		}
+		current = m
+	}
+
+	higherPrecedentData := current[df.name]
+
+	switch vv := v.(type) {
+	case nil:
+	case map[string]any:
+		switch higher := higherPrecedentData.(type) {
+		case nil:
+			current[df.name] = vv
+		case map[string]any:
+			for key, value := range vv {
+				if _, exists := higher[key]; exists {
+					s.Log.Warnf("Data for key %q in path %q is overridden by higher precedence data already in the data tree", key, df.fi.FileName())
+					continue
+				}
+				higher[key] = value
+			}
+		default:
+			s.Log.Warnf("Data in path %q is overridden by higher precedence data already in the data tree", df.fi.FileName())
+		}
+	default:
+		if higherPrecedentData == nil {
+			current[df.name] = vv
+		} else {
+			s.Log.Warnf("Data in path %q is overridden by higher precedence data already in the data tree", df.fi.FileName())
+		}
+	}
+
+	return nil
+}
+
+func (s *Site) decodeDataFile(df dataFile) (any, error) {
+	f, err := df.fi.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open data file %q: %w", df.fi.FileName(), err)
+	}
+	defer f.Close()
+
+	content, err := io.ReadAll(f)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := metadecoders.Default.Unmarshal(content, df.format)
+	if err != nil {
+		return nil, dataFileError(fmt.Errorf("failed to decode data file: %w", err), df)
+	}
+
+	if records, ok := v.([][]string); ok {
+		// Keep the data tree in the same shape as the other formats.
+		rows := make([]any, len(records))
+		for i, r := range records {
+			row := make([]any, len(r))
+			for j, c := range r {
+				row[j] = c
+			}
+			rows[i] = row
+		}
+		return rows, nil
+	}
+
+	return v, nil
+}
+
+func dataFileError(inerr error, df dataFile) error {
+	f, err := df.fi.Open()
+	if err != nil {
+		return inerr
+	}
+	defer f.Close()
+
+	return herrors.NewFileErrorFromName(inerr, df.fi.FileName()).UpdateContent(f, nil)
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"context"
+	"github.com/spf13/afero"
+	"strings"
+)
+
+// renderDeferred executes the templates.Defer blocks and replaces their
+// placeholders in the published files.
+func (s *Site) renderDeferred() error {
+	outputs, err := s.Template.ExecuteDeferred(context.Background())
+	if err != nil || len(outputs) == 0 {
+		return err
+	}
+
+	oldnew := make([]string, 0, len(outputs)*2)
+	for placeholder, output := range outputs {
+		oldnew = append(oldnew, placeholder, output)
+	}
+	replacer := strings.NewReplacer(oldnew...)
+
+	fs := s.Publisher.Fs
+	for _, filename := range s.FsSvc.PostProcessFiles() {
+		b, err := afero.ReadFile(fs, filename)
+		if err != nil {
+			return err
+		}
+
+		content := string(b)
+		replaced := replacer.Replace(content)
+		if replaced == content {
+			continue
+		}
+
+		if err := afero.WriteFile(fs, filename, []byte(replaced), 0o666); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
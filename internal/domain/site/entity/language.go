@@ -24,6 +24,12 @@ func (l *Language) CurrentLanguageIndex() int {
 	return curr
 }
 
+// UseDefaultLanguage makes the default language the current one, for
+// using the site outside a build, e.g. in archetypes.
+func (l *Language) UseDefaultLanguage() {
+	l.currentLanguage = l.LangSvc.DefaultLanguage()
+}
+
 func (l *Language) setup() error {
 	// TODO: make it configurable from config timeZone field
 	l.currentLocation = time.UTC
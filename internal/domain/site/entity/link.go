@@ -6,6 +6,9 @@ import (
 )
 
 func (p *Page) Permalink() string {
+	if p.NoLink() {
+		return ""
+	}
 	if p.PageIdentity().PageLanguage() == p.langSvc.DefaultLanguage() {
 		return p.BaseURL.WithPathNoTrailingSlash + paths.PathEscape(p.PageOutput.TargetFilePath())
 	}
@@ -15,6 +18,9 @@ func (p *Page) Permalink() string {
 }
 
 func (p *Page) RelPermalink() string {
+	if p.NoLink() {
+		return ""
+	}
 	if p.PageIdentity().PageLanguage() == p.langSvc.DefaultLanguage() {
 		return p.BaseURL.WithPathNoTrailingSlash + paths.PathEscape(p.PageOutput.TargetFilePath())
 	}
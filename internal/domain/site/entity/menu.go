@@ -0,0 +1,150 @@
+package entity
+
+import (
+	"github.com/mdfriday/hugoverse/internal/domain/site/valueobject"
+	"html/template"
+)
+
+// assembleMenus collects the menu entries for the current language from the
+// sections (sectionPagesMenu), the site config and the page front matter,
+// in that order, so that front matter wins over config and config wins over
+// the generated section entries.
+func (s *Site) assembleMenus() valueobject.Menus {
+	var entries []*valueobject.MenuEntry
+
+	entries = append(entries, s.sectionPagesMenuEntries()...)
+	entries = append(entries, s.configMenuEntries()...)
+	entries = append(entries, s.pageMenuEntries()...)
+
+	return valueobject.AssembleMenus(entries)
+}
+
+func (s *Site) sectionPagesMenuEntries() []*valueobject.MenuEntry {
+	name := s.ConfigSvc.SectionPagesMenu()
+	if name == "" || s.home == nil {
+		return nil
+	}
+
+	var entries []*valueobject.MenuEntry
+	for _, p := range s.Sections() {
+		me := &valueobject.MenuEntry{
+			MenuConfig: valueobject.MenuConfig{Identifier: p.Section()},
+			Menu:       name,
+		}
+		me.SetPageValues(p, p.PageWeight())
+		me.ConfiguredURL = me.URL
+		entries = append(entries, me)
+	}
+
+	return entries
+}
+
+func (s *Site) configMenuEntries() []*valueobject.MenuEntry {
+	var entries []*valueobject.MenuEntry
+
+	for name, menu := range s.ConfigSvc.Menus(s.Language.currentLanguage) {
+		for _, entry := range menu {
+			me := &valueobject.MenuEntry{
+				MenuConfig: valueobject.MenuConfig{
+					Identifier: entry.Identifier(),
+					Parent:     entry.Parent(),
+					Name:       entry.Name(),
+					Pre:        template.HTML(entry.Pre()),
+					Post:       template.HTML(entry.Post()),
+					URL:        entry.URL(),
+					PageRef:    entry.PageRef(),
+					Weight:     entry.Weight(),
+					Title:      entry.Title(),
+					Params:     entry.Params(),
+				},
+				Menu:          name,
+				ConfiguredURL: entry.URL(),
+			}
+
+			if me.PageRef != "" {
+				p, err := s.GetPage(me.PageRef)
+				if err != nil {
+					s.Log.Warnf("Menu %q: failed to resolve pageRef %q: %v", name, me.PageRef, err)
+				} else if p != nil {
+					me.SetPageValues(p, p.PageWeight())
+				}
+			}
+
+			entries = append(entries, me)
+		}
+	}
+
+	return entries
+}
+
+func (s *Site) pageMenuEntries() []*valueobject.MenuEntry {
+	var entries []*valueobject.MenuEntry
+
+	for _, cp := range s.ContentSvc.GlobalPages(s.CurrentLanguageIndex()) {
+		params := cp.Params()
+		pm, found := params["menus"]
+		if !found {
+			pm, found = params["menu"]
+		}
+		if !found {
+			continue
+		}
+
+		pes, err := valueobject.DecodePageMenus(pm)
+		if err != nil {
+			s.Log.Errorf("%s: %v", cp.Paths().Path(), err)
+			continue
+		}
+		if len(pes) == 0 {
+			continue
+		}
+
+		p, err := s.sitePage(cp)
+		if err != nil {
+			s.Log.Errorf("%s: %v", cp.Paths().Path(), err)
+			continue
+		}
+
+		for _, me := range pes {
+			me.SetPageValues(p, p.PageWeight())
+			entries = append(entries, me)
+		}
+	}
+
+	return entries
+}
+
+// IsMenuCurrent returns whether the menu entry inme in the menu menuID
+// points to this page.
+func (p *Page) IsMenuCurrent(menuID string, inme *valueobject.MenuEntry) bool {
+	if inme == nil || (inme.Menu != "" && inme.Menu != menuID) {
+		return false
+	}
+
+	u := inme.RelPermalink()
+	if u == "" {
+		return false
+	}
+
+	return u == p.RelPermalink() || u == p.Permalink()
+}
+
+// HasMenuCurrent returns whether this page is the section page of the
+// menu entry me, or is pointed to by any of its descendants.
+func (p *Page) HasMenuCurrent(menuID string, me *valueobject.MenuEntry) bool {
+	if me == nil {
+		return false
+	}
+
+	if me.Page != nil && me.Page.IsSection() && me.Page.IsAncestor(p) {
+		return true
+	}
+
+	for _, child := range me.Children {
+		if p.IsMenuCurrent(menuID, child) || p.HasMenuCurrent(menuID, child) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -1,11 +1,12 @@
 package entity
 
 import (
-	"fmt"
+	contenthubVO "github.com/mdfriday/hugoverse/internal/domain/contenthub/valueobject"
 	"github.com/mdfriday/hugoverse/internal/domain/site"
 	"github.com/mdfriday/hugoverse/internal/domain/site/valueobject"
 	"github.com/mdfriday/hugoverse/pkg/compare"
 	"sort"
+	"strings"
 )
 
 type Navigation struct {
@@ -45,22 +46,37 @@ func (t OrderedTaxonomy) getOneOPage() *WeightedPage {
 	if len(t) == 0 {
 		return nil
 	}
-	return t[0].WeightedPages.Page()
+	return t[0].WeightedPages[0]
+}
+
+// Reverse reverses the order of the entries in this taxonomy.
+func (t OrderedTaxonomy) Reverse() OrderedTaxonomy {
+	r := make(OrderedTaxonomy, len(t))
+	for i, e := range t {
+		r[len(t)-1-i] = e
+	}
+	return r
 }
 
 // WeightedPages is a list of Pages with their corresponding (and relative) weight
 // [{Weight: 30, Page: *1}, {Weight: 40, Page: *2}]
 type WeightedPages []*WeightedPage
 
-// Page will return the Page (of Kind taxonomyList) that represents this set
-// of pages. This method will panic if p is empty, as that should never happen.
-func (p WeightedPages) Page() *WeightedPage {
+// Page will return the Page (of Kind term) that represents this set
+// of pages, nil if p is empty.
+func (p WeightedPages) Page() *Page {
 	if len(p) == 0 {
-		_ = fmt.Errorf("page called on empty WeightedPages")
 		return nil
 	}
 
-	return p[0]
+	wp := p[0]
+	tp, err := wp.Site.sitePage(wp.Owner())
+	if err != nil {
+		wp.Site.Log.Errorf("term page for %q: %v", wp.Paths().Path(), err)
+		return nil
+	}
+
+	return tp
 }
 
 func (p WeightedPages) Pages() []*WeightedPage {
@@ -79,7 +95,10 @@ func (p WeightedPages) Count() int {
 func (p WeightedPages) Len() int      { return len(p) }
 func (p WeightedPages) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
 func (p WeightedPages) Less(i, j int) bool {
-	return p[i].Weight() <= p[j].Weight()
+	if p[i].Weight() == p[j].Weight() {
+		return compare.LessStrings(p[i].Title(), p[j].Title())
+	}
+	return contenthubVO.LessWeight(p[i].Weight(), p[j].Weight())
 }
 
 // OrderedTaxonomyEntry is similar to an element of a Taxonomy, but with the key embedded (as name)
@@ -99,6 +118,51 @@ func (ie OrderedTaxonomyEntry) Term() string {
 	return ie.Name
 }
 
+// Get returns the weighted pages for the given term.
+func (i Taxonomy) Get(key string) WeightedPages {
+	return i[strings.ToLower(key)]
+}
+
+// Count returns the number of pages for the given term.
+func (i Taxonomy) Count(key string) int {
+	return len(i.Get(key))
+}
+
+// Alphabetical returns an ordered taxonomy sorted by term name.
+func (i Taxonomy) Alphabetical() OrderedTaxonomy {
+	name := func(i1, i2 *OrderedTaxonomyEntry) bool {
+		return compare.LessStrings(i1.Name, i2.Name)
+	}
+
+	ia := i.TaxonomyArray()
+	oiBy(name).Sort(ia)
+	return ia
+}
+
+// ByWeight returns an ordered taxonomy sorted by the weight set in the
+// term pages' front matter, e.g. content/tags/go/_index.md.
+// Terms with the same weight are sorted alphabetical.
+func (i Taxonomy) ByWeight() OrderedTaxonomy {
+	weights := make(map[string]int, len(i))
+	for k, v := range i {
+		if len(v) > 0 {
+			weights[k] = v[0].Owner().PageWeight()
+		}
+	}
+
+	weight := func(i1, i2 *OrderedTaxonomyEntry) bool {
+		w1, w2 := weights[i1.Name], weights[i2.Name]
+		if w1 == w2 {
+			return compare.LessStrings(i1.Name, i2.Name)
+		}
+		return contenthubVO.LessWeight(w1, w2)
+	}
+
+	ia := i.TaxonomyArray()
+	oiBy(weight).Sort(ia)
+	return ia
+}
+
 // ByCount returns an ordered taxonomy sorted by # of pages per key.
 // If taxonomies have the same # of pages, sort them alphabetical
 func (i Taxonomy) ByCount() OrderedTaxonomy {
@@ -0,0 +1,108 @@
+package entity
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/contenthub"
+	"github.com/mdfriday/hugoverse/internal/domain/site"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+// termPage is a page with a title and a weight of its own, e.g. a term.
+type termPage struct {
+	contenthub.Page
+	title  string
+	weight int
+}
+
+func (p *termPage) Title() string   { return p.title }
+func (p *termPage) PageWeight() int { return p.weight }
+
+func (p *termPage) PageOutputs() ([]contenthub.PageOutput, error) {
+	return []contenthub.PageOutput{nil}, nil
+}
+
+// termWeight is the weight of a page within a term.
+type termWeight struct {
+	page, owner contenthub.Page
+	weight      int
+}
+
+func (w *termWeight) Weight() int            { return w.weight }
+func (w *termWeight) Ordinal() int           { return 0 }
+func (w *termWeight) Page() contenthub.Page  { return w.page }
+func (w *termWeight) Owner() contenthub.Page { return w.owner }
+
+// noSources is a content service with pages without resources.
+type noSources struct {
+	site.ContentService
+}
+
+func (noSources) GetPageSources(contenthub.Page) ([]contenthub.PageSource, error) {
+	return nil, nil
+}
+
+func TestTaxonomy(t *testing.T) {
+	c := qt.New(t)
+
+	s := &Site{ContentSvc: noSources{}, Log: loggers.NewDefault()}
+	weighted := func(owner contenthub.Page, title string, weight int) *WeightedPage {
+		p := &termPage{title: title}
+		return &WeightedPage{
+			Page:              &Page{Page: p, Site: s},
+			OrdinalWeightPage: &termWeight{page: p, owner: owner, weight: weight},
+		}
+	}
+
+	// The terms without a weight set in their front matter go last.
+	gopher := &termPage{title: "gopher", weight: 2}
+	hugo := &termPage{title: "hugo", weight: 1}
+	apple := &termPage{title: "apple"}
+	zebra := &termPage{title: "zebra"}
+	tags := Taxonomy{
+		"gopher": {weighted(gopher, "Go", 0)},
+		"hugo":   {weighted(hugo, "Themes", 0), weighted(hugo, "Sites", 0)},
+		"apple":  {weighted(apple, "Pie", 0)},
+		"zebra":  {weighted(zebra, "Stripes", 0), weighted(zebra, "Zoo", 0), weighted(zebra, "Africa", 0)},
+	}
+
+	names := func(ot OrderedTaxonomy) []string {
+		var names []string
+		for _, e := range ot {
+			names = append(names, e.Term())
+		}
+		return names
+	}
+
+	c.Assert(names(tags.ByWeight()), qt.DeepEquals, []string{"hugo", "gopher", "apple", "zebra"})
+	c.Assert(names(tags.ByWeight().Reverse()), qt.DeepEquals, []string{"zebra", "apple", "gopher", "hugo"})
+	c.Assert(names(tags.Alphabetical()), qt.DeepEquals, []string{"apple", "gopher", "hugo", "zebra"})
+	c.Assert(names(tags.ByCount()), qt.DeepEquals, []string{"zebra", "hugo", "apple", "gopher"})
+	c.Assert(tags.Count("Hugo"), qt.Equals, 2)
+
+	c.Run("WeightedPages", func(c *qt.C) {
+		// The pages of a term are sorted by their weight in it, the ones
+		// without last, then by title.
+		pages := WeightedPages{
+			weighted(hugo, "Zoo", 0),
+			weighted(hugo, "Sites", 20),
+			weighted(hugo, "Blogs", 0),
+			weighted(hugo, "Themes", 10),
+			weighted(hugo, "Docs", 10),
+		}
+		pages.Sort()
+
+		var titles []string
+		for _, p := range pages {
+			titles = append(titles, p.Title())
+		}
+		c.Assert(titles, qt.DeepEquals, []string{"Docs", "Themes", "Sites", "Blogs", "Zoo"})
+
+		// The page of the pages is the term page.
+		tp := pages.Page()
+		c.Assert(tp, qt.IsNotNil)
+		c.Assert(tp.Page, qt.Equals, contenthub.Page(hugo))
+		c.Assert(WeightedPages{}.Page(), qt.IsNil)
+	})
+}
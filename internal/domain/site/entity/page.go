@@ -56,8 +56,12 @@ func (p *Page) processResources(pageSources []contenthub.PageSource) error {
 }
 
 func (p *Page) render() error {
-	if err := p.renderResources(); err != nil {
-		return err
+	// Resources not published here are still published on demand,
+	// e.g. when their RelPermalink is used in a template.
+	if p.ShouldPublishResources() {
+		if err := p.renderResources(); err != nil {
+			return err
+		}
 	}
 
 	if err := p.renderPage(); err != nil {
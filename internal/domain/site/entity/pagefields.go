@@ -5,7 +5,6 @@ import (
 	"github.com/mdfriday/hugoverse/internal/domain/site/valueobject"
 	"github.com/mdfriday/hugoverse/pkg/maps"
 	"github.com/mdfriday/hugoverse/pkg/media"
-	"strings"
 	"time"
 )
 
@@ -75,8 +74,17 @@ func (p *Page) PublishDate() time.Time {
 	return p.Page.PublishDate()
 }
 
+// Lastmod follows Hugo's default order: the Git commit date when
+// enableGitInfo is set, then lastmod and date from front matter.
 func (p *Page) Lastmod() time.Time {
-	return time.Now()
+	if gi := p.GitInfo(); gi != nil {
+		return gi.CommitDate
+	}
+	if lastmod := p.Page.PageLastmod(); !lastmod.IsZero() {
+		return lastmod
+	}
+
+	return p.Date()
 }
 
 func (p *Page) ExpiryDate() time.Time {
@@ -190,9 +198,11 @@ func (p *Page) IsAncestor(other any) bool {
 	return p.Page.IsAncestor(op.Page)
 }
 
-func (p *Page) GitInfo() valueobject.GitInfo {
-	if p.git == nil {
-		return valueobject.GitInfo{}
+// GitInfo returns the last commit of the page's source file,
+// nil if enableGitInfo is off or the file is not version controlled.
+func (p *Page) GitInfo() *valueobject.GitInfo {
+	if p.git == nil || p.Page.PageFile() == nil {
+		return nil
 	}
 
 	return p.git.GetInfo(p.Page.PageFile().Filename())
@@ -222,23 +232,6 @@ func (p *Page) Description() string {
 	return p.Page.Description()
 }
 
-func (p *Page) IsMenuCurrent(menuID string, me *valueobject.MenuEntry) bool {
-	ps := p.Paths()
-	container := ps.Container()
-	switch container {
-	case "":
-		if me.URL == "/" {
-			return true
-		}
-	default:
-		if strings.Contains(me.URL, container) {
-			return true
-		}
-	}
-
-	return false
-}
-
 func (p *Page) MediaType() media.Type {
 	return p.PageOutput.TargetFormat().MediaType
 }
@@ -4,18 +4,28 @@ import (
 	"bytes"
 	"errors"
 	"github.com/mdfriday/hugoverse/internal/domain/site"
+	"github.com/mdfriday/hugoverse/internal/domain/site/valueobject"
 	"github.com/mdfriday/hugoverse/pkg/helpers"
 	"github.com/spf13/afero"
 	"io"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 type Publisher struct {
 	Fs afero.Fs
+
+	// HTMLCollector collects the elements used in the published HTML files
+	// when the build stats are enabled, nil otherwise.
+	HTMLCollector *valueobject.HTMLElementsCollector
 }
 
 func (p *Publisher) PublishSource(src *bytes.Buffer, filename ...string) error {
+	if p.HTMLCollector != nil && isHTMLFile(filename...) {
+		p.HTMLCollector.Collect(src.Bytes())
+	}
+
 	fw, err := helpers.OpenFilesForWriting(p.Fs, filename...)
 	if err != nil {
 		return err
@@ -66,6 +76,15 @@ func (p *Publisher) Publish(d site.Descriptor) error {
 	return err
 }
 
+func isHTMLFile(filenames ...string) bool {
+	for _, filename := range filenames {
+		if strings.EqualFold(filepath.Ext(filename), ".html") {
+			return true
+		}
+	}
+	return false
+}
+
 // OpenFileForWriting opens or creates the given file. If the target directory
 // does not exist, it gets created.
 func OpenFileForWriting(fs afero.Fs, filename string) (afero.File, error) {
@@ -17,6 +17,7 @@ type Site struct {
 	TranslationSvc site.TranslationService
 	ResourcesSvc   site.ResourceService
 	LanguageSvc    site.LanguageService
+	FsSvc          site.FsService
 	Sitemap        site.SitemapService
 
 	GitSvc *valueobject.GitMap
@@ -43,6 +44,7 @@ type Site struct {
 
 	// Lazily loaded site dependencies
 	lazy *siteInit
+	data map[string]map[string]any
 }
 
 func (s *Site) Build(t site.Template) error {
@@ -62,7 +64,13 @@ func (s *Site) Build(t site.Template) error {
 		}
 	}
 
-	return nil
+	// The build stats are written first, so the deferred templates can
+	// use them, e.g. to purge CSS.
+	if err := s.writeBuildStats(); err != nil {
+		return err
+	}
+
+	return s.renderDeferred()
 }
 
 func (s *Site) setup() error {
@@ -106,6 +114,12 @@ func (s *Site) renderPages() error {
 	go render.startRenderPages()
 
 	if err := s.ContentSvc.WalkPages(s.Language.CurrentLanguageIndex(), func(p contenthub.Page) error {
+		if !p.ShouldRender() {
+			// Headless pages, or pages with build.render set to never or link,
+			// are still available via .Site.GetPage, but never written to disk.
+			return nil
+		}
+
 		sitePage := &Page{
 			resSvc:    s.ResourcesSvc,
 			tmplSvc:   s.Template,
@@ -12,6 +12,7 @@ type siteInit struct {
 	prevNextInSection *lazy.Init
 	menus             map[string]*lazy.Init
 	taxonomies        *lazy.Init
+	data              map[string]*lazy.Init
 }
 
 func (init *siteInit) Reset() {
@@ -21,28 +22,17 @@ func (init *siteInit) Reset() {
 		init.menus[k].Reset()
 	}
 	init.taxonomies.Reset()
+	for k := range init.data {
+		init.data[k].Reset()
+	}
 }
 
 func (s *Site) PrepareLazyLoads() {
 	initMenu := func() (any, error) {
 		menus := valueobject.NewEmptyMenus()
 
-		menusConf := s.ConfigSvc.Menus()
-		for name, menu := range menusConf {
-			if menus[name] == nil {
-				menus[name] = valueobject.Menu{}
-			}
-
-			for _, entry := range menu {
-				menus[name] = menus[name].Add(&valueobject.MenuEntry{
-					MenuConfig: valueobject.MenuConfig{
-						Name:   entry.Name(),
-						URL:    entry.URL(),
-						Weight: entry.Weight(),
-					},
-					Menu: name,
-				})
-			}
+		for name, menu := range s.assembleMenus() {
+			menus[name] = menu
 		}
 
 		lp, err := s.GetPage(valueobject.ReservedLinksFile)
@@ -99,11 +89,23 @@ func (s *Site) PrepareLazyLoads() {
 
 	s.lazy = &siteInit{
 		menus: map[string]*lazy.Init{},
+		data:  map[string]*lazy.Init{},
 	}
+	s.data = make(map[string]map[string]any)
 
 	var init lazy.Init
 	for _, lang := range s.LanguageSvc.LanguageKeys() {
 		s.lazy.menus[lang] = init.Branch(initMenu)
+
+		lang := lang
+		s.lazy.data[lang] = init.Branch(func() (any, error) {
+			data, err := s.loadData(lang)
+			if err != nil {
+				return nil, err
+			}
+			s.data[lang] = data
+			return data, nil
+		})
 	}
 
 	s.lazy.taxonomies = init.Branch(func() (any, error) {
@@ -135,6 +137,12 @@ func (s *Site) PrepareLazyLoads() {
 			return nil, err
 		}
 
+		for _, tax := range s.Navigation.taxonomies {
+			for _, weightedPages := range tax {
+				weightedPages.Sort()
+			}
+		}
+
 		return s.Navigation.taxonomies, nil
 	})
 }
@@ -1,20 +1,29 @@
 package factory
 
 import (
-	"github.com/bep/gitmap"
 	"github.com/mdfriday/hugoverse/internal/domain/site"
 	"github.com/mdfriday/hugoverse/internal/domain/site/entity"
 	"github.com/mdfriday/hugoverse/internal/domain/site/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/gitmap"
 	"github.com/mdfriday/hugoverse/pkg/loggers"
 )
 
 func New(services site.Services) *entity.Site {
 	log := loggers.NewDefault()
 
-	git, err := newGitInfo(services)
-	if err != nil {
-		log.Errorf("failed to create git repo: %s", err)
-		git = nil
+	var git *valueobject.GitMap
+	if services.IsGitInfoEnabled() {
+		var err error
+		git, err = newGitInfo(services)
+		if err != nil {
+			log.Errorf("failed to read git info: %s", err)
+			git = nil
+		}
+	}
+
+	publisher := &entity.Publisher{Fs: services.Publish()}
+	if services.IsBuildStatsEnabled() {
+		publisher.HTMLCollector = valueobject.NewHTMLElementsCollector()
 	}
 
 	s := &entity.Site{
@@ -23,13 +32,14 @@ func New(services site.Services) *entity.Site {
 		TranslationSvc: services,
 		ResourcesSvc:   services,
 		LanguageSvc:    services,
+		FsSvc:          services,
 		Sitemap:        services,
 
 		GitSvc: git,
 
 		Template: nil,
 
-		Publisher: &entity.Publisher{Fs: services.Publish()},
+		Publisher: publisher,
 
 		Title:    services.SiteTitle(),
 		Author:   valueobject.NewAuthor("Hugoverse", "support@gohugo.net"), // TODO: Make configurable
@@ -64,9 +74,8 @@ func newGitInfo(conf site.FsService) (*valueobject.GitMap, error) {
 	workingDir := conf.WorkingDir()
 
 	gitRepo, err := gitmap.Map(gitmap.Options{
-		Repository:        workingDir,
-		Revision:          "",
-		GetGitCommandFunc: nil,
+		Repository: workingDir,
+		Revision:   "",
 	})
 	if err != nil {
 		return nil, err
@@ -4,9 +4,11 @@ import (
 	"bytes"
 	"context"
 	"github.com/mdfriday/hugoverse/internal/domain/contenthub"
+	"github.com/mdfriday/hugoverse/internal/domain/fs"
 	"github.com/mdfriday/hugoverse/internal/domain/resources"
 	"github.com/mdfriday/hugoverse/internal/domain/template"
 	pio "github.com/mdfriday/hugoverse/pkg/io"
+	"github.com/mdfriday/hugoverse/pkg/maps"
 	"github.com/mdfriday/hugoverse/pkg/output"
 	"github.com/spf13/afero"
 	"golang.org/x/text/collate"
@@ -33,13 +35,24 @@ type SitemapService interface {
 type ConfigService interface {
 	ConfigParams() map[string]any
 	SiteTitle() string
-	Menus() map[string][]Menu
+	Menus(lang string) map[string][]Menu
+	SectionPagesMenu() string
+	IsGitInfoEnabled() bool
+	IsBuildStatsEnabled() bool
+	BuildStatsOptions() (disableTags, disableClasses, disableIDs bool)
 }
 
 type Menu interface {
+	Identifier() string
+	Parent() string
 	Name() string
+	Pre() string
+	Post() string
 	URL() string
+	PageRef() string
 	Weight() int
+	Title() string
+	Params() maps.Params
 }
 
 type LanguageService interface {
@@ -72,7 +85,10 @@ type ResourceService interface {
 
 type FsService interface {
 	Publish() afero.Fs
+	WorkingWritable() afero.Fs
+	PostProcessFiles() []string
 	WorkingDir() string
+	WalkData(start string, cb fs.WalkCallback, conf fs.WalkwayConfig) error
 }
 
 type URLService interface {
@@ -99,6 +115,7 @@ type Template interface {
 	MarkReady() error
 	LookupLayout(names []string) (template.Preparer, bool, error)
 	ExecuteWithContext(ctx context.Context, t template.Preparer, wr io.Writer, data any) error
+	ExecuteDeferred(ctx context.Context) (map[string]string, error)
 }
 
 type ContentSpec interface {
@@ -1,7 +1,7 @@
 package valueobject
 
 import (
-	"github.com/bep/gitmap"
+	"github.com/mdfriday/hugoverse/pkg/gitmap"
 	"path/filepath"
 	"strings"
 	"time"
@@ -15,18 +15,20 @@ type GitInfo struct {
 	AbbreviatedHash string `json:"abbreviatedHash"`
 	// The commit message's subject/title line.
 	Subject string `json:"subject"`
-	// The author name, respecting .mailmap.
+	// The author name.
 	AuthorName string `json:"authorName"`
-	// The author email address, respecting .mailmap.
+	// The author email address.
 	AuthorEmail string `json:"authorEmail"`
 	// The author date.
 	AuthorDate time.Time `json:"authorDate"`
 	// The commit date.
 	CommitDate time.Time `json:"commitDate"`
+	// The commit message body.
+	Body string `json:"body"`
 }
 
-func NewGitInfo(info gitmap.GitInfo) GitInfo {
-	return GitInfo{
+func NewGitInfo(info gitmap.GitInfo) *GitInfo {
+	return &GitInfo{
 		Hash:            info.Hash,
 		AbbreviatedHash: info.AbbreviatedHash,
 		Subject:         info.Subject,
@@ -34,6 +36,7 @@ func NewGitInfo(info gitmap.GitInfo) GitInfo {
 		AuthorEmail:     info.AuthorEmail,
 		AuthorDate:      info.AuthorDate,
 		CommitDate:      info.CommitDate,
+		Body:            info.Body,
 	}
 }
 
@@ -42,12 +45,14 @@ type GitMap struct {
 	Repo       *gitmap.GitRepo
 }
 
-func (g *GitMap) GetInfo(filename string) GitInfo {
+// GetInfo returns the last commit for the given absolute filename,
+// or nil if the file is not tracked in the repository.
+func (g *GitMap) GetInfo(filename string) *GitInfo {
 	name := strings.TrimPrefix(filepath.ToSlash(filename), g.ContentDir)
 	name = strings.TrimPrefix(name, "/")
 	gi, found := g.Repo.Files[name]
 	if !found {
-		return GitInfo{}
+		return nil
 	}
 	return NewGitInfo(*gi)
 }
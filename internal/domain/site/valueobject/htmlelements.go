@@ -0,0 +1,224 @@
+package valueobject
+
+import (
+	"bytes"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// HTMLElements holds the tags, classes and ids used in the published HTML.
+type HTMLElements struct {
+	Tags    []string `json:"tags"`
+	Classes []string `json:"classes"`
+	IDs     []string `json:"ids"`
+}
+
+// BuildStats is written to hugo_stats.json when the build is done, to be
+// used to e.g. purge unused CSS.
+type BuildStats struct {
+	HTMLElements HTMLElements `json:"htmlElements"`
+}
+
+// HTMLElementsCollector collects the HTML elements from the published files.
+// It is safe for concurrent use.
+type HTMLElementsCollector struct {
+	mu sync.Mutex
+
+	tags    map[string]bool
+	classes map[string]bool
+	ids     map[string]bool
+}
+
+func NewHTMLElementsCollector() *HTMLElementsCollector {
+	return &HTMLElementsCollector{
+		tags:    make(map[string]bool),
+		classes: make(map[string]bool),
+		ids:     make(map[string]bool),
+	}
+}
+
+// skipContentTags are the elements we don't look into, the content is
+// either not HTML or, for pre, most likely code examples.
+var skipContentTags = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"textarea": true,
+	"pre":      true,
+}
+
+// Collect collects the elements in the given HTML document or fragment.
+func (c *HTMLElementsCollector) Collect(b []byte) {
+	var (
+		tags, classes, ids []string
+		lower              []byte
+	)
+
+	for i := 0; i < len(b); {
+		start := bytes.IndexByte(b[i:], '<')
+		if start == -1 {
+			break
+		}
+		i += start + 1
+
+		if bytes.HasPrefix(b[i:], []byte("!--")) {
+			end := bytes.Index(b[i:], []byte("-->"))
+			if end == -1 {
+				break
+			}
+			i += end + 3
+			continue
+		}
+
+		if i >= len(b) || !isASCIILetter(b[i]) {
+			// End tag, doctype, processing instruction or a stray '<'.
+			continue
+		}
+
+		var el element
+		i = el.parse(b, i)
+		tags = append(tags, el.tag)
+		classes = append(classes, el.classes...)
+		if el.id != "" {
+			ids = append(ids, el.id)
+		}
+
+		if skipContentTags[el.tag] {
+			if lower == nil {
+				lower = asciiLower(b)
+			}
+			end := bytes.Index(lower[i:], []byte("</"+el.tag))
+			if end == -1 {
+				break
+			}
+			i += end
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, t := range tags {
+		c.tags[t] = true
+	}
+	for _, cl := range classes {
+		c.classes[cl] = true
+	}
+	for _, id := range ids {
+		c.ids[id] = true
+	}
+}
+
+// Elements returns the collected elements, sorted.
+func (c *HTMLElementsCollector) Elements() HTMLElements {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return HTMLElements{
+		Tags:    sortedKeys(c.tags),
+		Classes: sortedKeys(c.classes),
+		IDs:     sortedKeys(c.ids),
+	}
+}
+
+type element struct {
+	tag     string
+	id      string
+	classes []string
+}
+
+// parse parses the start tag beginning with its name at b[i] and returns the
+// position after the closing '>'.
+func (el *element) parse(b []byte, i int) int {
+	start := i
+	for i < len(b) && !isSpace(b[i]) && b[i] != '>' && b[i] != '/' {
+		i++
+	}
+	el.tag = strings.ToLower(string(b[start:i]))
+
+	for i < len(b) {
+		for i < len(b) && (isSpace(b[i]) || b[i] == '/') {
+			i++
+		}
+		if i >= len(b) {
+			break
+		}
+		if b[i] == '>' {
+			return i + 1
+		}
+
+		nameStart := i
+		for i < len(b) && !isSpace(b[i]) && b[i] != '=' && b[i] != '>' && b[i] != '/' {
+			i++
+		}
+		name := strings.ToLower(string(b[nameStart:i]))
+
+		for i < len(b) && isSpace(b[i]) {
+			i++
+		}
+		if i >= len(b) || b[i] != '=' {
+			continue
+		}
+		i++
+		for i < len(b) && isSpace(b[i]) {
+			i++
+		}
+		if i >= len(b) {
+			break
+		}
+
+		var value string
+		if q := b[i]; q == '"' || q == '\'' {
+			end := bytes.IndexByte(b[i+1:], q)
+			if end == -1 {
+				return len(b)
+			}
+			value = string(b[i+1 : i+1+end])
+			i += end + 2
+		} else {
+			valueStart := i
+			for i < len(b) && !isSpace(b[i]) && b[i] != '>' {
+				i++
+			}
+			value = string(b[valueStart:i])
+		}
+
+		switch name {
+		case "id":
+			el.id = strings.TrimSpace(value)
+		case "class":
+			el.classes = append(el.classes, strings.Fields(value)...)
+		}
+	}
+
+	return i
+}
+
+// asciiLower is bytes.ToLower for ASCII only, keeping the byte offsets.
+func asciiLower(b []byte) []byte {
+	lower := make([]byte, len(b))
+	for i, c := range b {
+		if c >= 'A' && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		lower[i] = c
+	}
+	return lower
+}
+
+func isASCIILetter(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f'
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
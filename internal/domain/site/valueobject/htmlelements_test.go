@@ -0,0 +1,27 @@
+package valueobject
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestHTMLElementsCollector(t *testing.T) {
+	c := qt.New(t)
+
+	collector := NewHTMLElementsCollector()
+	collector.Collect([]byte(`<!DOCTYPE html>
+<html><head><style>.not-a { color: red }</style></head>
+<body class="home  dark">
+<!-- <div class="commented"> -->
+<DIV id=main Class='wrap'><a href="/" class="btn">Home</a></DIV>
+<pre class="chroma"><code><span class="k">func</span></code></pre>
+<img src="a.png" alt="a > b" />
+</body></html>`))
+	collector.Collect([]byte(`<nav id="menu" class="btn nav"></nav>`))
+
+	el := collector.Elements()
+	c.Assert(el.Tags, qt.DeepEquals, []string{"a", "body", "div", "head", "html", "img", "nav", "pre", "style"})
+	c.Assert(el.Classes, qt.DeepEquals, []string{"btn", "chroma", "dark", "home", "nav", "wrap"})
+	c.Assert(el.IDs, qt.DeepEquals, []string{"main", "menu"})
+}
@@ -1,8 +1,11 @@
 package valueobject
 
 import (
+	"fmt"
 	"github.com/mdfriday/hugoverse/pkg/compare"
 	"github.com/mdfriday/hugoverse/pkg/maps"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
 	"html/template"
 	"sort"
 )
@@ -22,8 +25,56 @@ type MenuEntry struct {
 	// Child entries.
 	Children Menu
 
-	// TODO, removed
-	Page *MenuEntry
+	// The page this menu entry links to, if any.
+	Page MenuPage
+}
+
+// MenuPage is the page side of a menu entry.
+type MenuPage interface {
+	RelPermalink() string
+	LinkTitle() string
+	IsSection() bool
+	IsAncestor(other any) bool
+}
+
+// RelPermalink returns the page's relative permalink, or the configured
+// URL if this entry has no page.
+func (m *MenuEntry) RelPermalink() string {
+	if m.Page != nil {
+		return m.Page.RelPermalink()
+	}
+	return m.URL
+}
+
+// IsEqual returns whether the two menu entries represent the same menu entry.
+func (m *MenuEntry) IsEqual(inme *MenuEntry) bool {
+	return m.IsSameResource(inme) && m.Name == inme.Name
+}
+
+// IsSameResource returns whether the two menu entries points to the same
+// resource (URL).
+func (m *MenuEntry) IsSameResource(inme *MenuEntry) bool {
+	if m == nil || inme == nil {
+		return false
+	}
+	murl, inmeurl := m.RelPermalink(), inme.RelPermalink()
+	return murl != "" && inmeurl != "" && murl == inmeurl
+}
+
+// SetPageValues fills in the name, title, weight and URL from the page p
+// where not already set.
+func (m *MenuEntry) SetPageValues(p MenuPage, weight int) {
+	m.Page = p
+	if m.Name == "" {
+		m.Name = p.LinkTitle()
+	}
+	if m.Title == "" {
+		m.Title = p.LinkTitle()
+	}
+	if m.Weight == 0 {
+		m.Weight = weight
+	}
+	m.URL = p.RelPermalink()
 }
 
 // HasChildren returns whether this menu item has any children.
@@ -131,6 +182,92 @@ func (m Menus) HasSubMenu(me *MenuEntry) bool {
 	return false
 }
 
+type menuKey struct {
+	menu string
+	key  string
+}
+
+// AssembleMenus builds the menu trees from a flat list of entries, where
+// entries with a parent are added as children of the entry with that
+// identifier or name in the same menu. An entry defined more than once
+// overrides the earlier ones. Entries with an unknown parent are dropped.
+func AssembleMenus(entries []*MenuEntry) Menus {
+	var keys []menuKey
+	flat := make(map[menuKey]*MenuEntry)
+
+	for _, me := range entries {
+		k := menuKey{menu: me.Menu, key: me.KeyName()}
+		if _, found := flat[k]; !found {
+			keys = append(keys, k)
+		}
+		flat[k] = me
+	}
+
+	menus := make(Menus)
+	for _, k := range keys {
+		me := flat[k]
+		if me.Parent == "" {
+			menus[k.menu] = menus[k.menu].Add(me)
+			continue
+		}
+		if parent, found := flat[menuKey{menu: k.menu, key: me.Parent}]; found {
+			parent.Children = parent.Children.Add(me)
+		}
+	}
+
+	return menus
+}
+
+// DecodePageMenus decodes the menu setup in page front matter, which is
+// either a menu name, a list of menu names or a map of menu names to
+// entry configurations, e.g.:
+//
+//	menu: main
+//	menu: [main, footer]
+//	menu:
+//	  main:
+//	    parent: docs
+//	    weight: 20
+func DecodePageMenus(menus any) (Menu, error) {
+	var entries Menu
+
+	switch v := menus.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		entries = append(entries, &MenuEntry{Menu: v})
+		return entries, nil
+	case []string:
+		for _, name := range v {
+			entries = append(entries, &MenuEntry{Menu: name})
+		}
+		return entries, nil
+	case []any:
+		for _, name := range v {
+			entries = append(entries, &MenuEntry{Menu: cast.ToString(name)})
+		}
+		return entries, nil
+	}
+
+	m, err := maps.ToStringMapE(menus)
+	if err != nil {
+		return nil, fmt.Errorf("unable to process menus for page: %w", err)
+	}
+
+	for name, mc := range m {
+		me := &MenuEntry{Menu: name}
+		if mc != nil {
+			if err := mapstructure.WeakDecode(mc, &me.MenuConfig); err != nil {
+				return nil, fmt.Errorf("unable to process menu %q for page: %w", name, err)
+			}
+			maps.PrepareParams(me.Params)
+		}
+		entries = append(entries, me)
+	}
+
+	return entries, nil
+}
+
 // MenuConfig holds the configuration for a menu.
 type MenuConfig struct {
 	Identifier string
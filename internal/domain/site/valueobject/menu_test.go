@@ -0,0 +1,53 @@
+package valueobject
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestAssembleMenus(t *testing.T) {
+	c := qt.New(t)
+
+	menus := AssembleMenus([]*MenuEntry{
+		{Menu: "main", MenuConfig: MenuConfig{Identifier: "docs", Name: "Docs", Weight: 20}},
+		{Menu: "main", MenuConfig: MenuConfig{Name: "Blog", Weight: 10}},
+		{Menu: "main", MenuConfig: MenuConfig{Name: "Install", Parent: "docs", Weight: 2}},
+		{Menu: "main", MenuConfig: MenuConfig{Name: "Intro", Parent: "docs", Weight: 1}},
+		{Menu: "main", MenuConfig: MenuConfig{Name: "Orphan", Parent: "nope"}},
+		{Menu: "footer", MenuConfig: MenuConfig{Name: "About"}},
+		// Overrides the first Blog entry.
+		{Menu: "main", MenuConfig: MenuConfig{Name: "Blog", Weight: 30}},
+	})
+
+	main := menus["main"]
+	c.Assert(main, qt.HasLen, 2)
+	c.Assert(main[0].Name, qt.Equals, "Docs")
+	c.Assert(main[1].Weight, qt.Equals, 30)
+	c.Assert(main[0].HasChildren(), qt.IsTrue)
+	c.Assert(main[0].Children[0].Name, qt.Equals, "Intro")
+	c.Assert(main[0].Children[1].Name, qt.Equals, "Install")
+	c.Assert(menus["footer"], qt.HasLen, 1)
+}
+
+func TestDecodePageMenus(t *testing.T) {
+	c := qt.New(t)
+
+	entries, err := DecodePageMenus("main")
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 1)
+	c.Assert(entries[0].Menu, qt.Equals, "main")
+
+	entries, err = DecodePageMenus([]any{"main", "footer"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 2)
+
+	entries, err = DecodePageMenus(map[string]any{
+		"main": map[string]any{"parent": "docs", "weight": "20", "params": map[string]any{"Icon": "book"}},
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(entries, qt.HasLen, 1)
+	c.Assert(entries[0].Parent, qt.Equals, "docs")
+	c.Assert(entries[0].Weight, qt.Equals, 20)
+	c.Assert(entries[0].Params["icon"], qt.Equals, "book")
+}
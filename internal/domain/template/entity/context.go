@@ -10,6 +10,7 @@ import (
 	texttemplate "github.com/mdfriday/hugoverse/pkg/template/texttemplate"
 	"github.com/mdfriday/hugoverse/pkg/template/texttemplate/parse"
 	"github.com/mitchellh/mapstructure"
+	"sync/atomic"
 )
 
 type Context struct {
@@ -27,14 +28,21 @@ type Context struct {
 
 	// Store away the return node in partials.
 	returnNode *parse.CommandNode
+
+	// The bodies of the templates.Defer blocks, by template name.
+	DeferNodes map[string]*parse.ListNode
+	// deferredIdx numbers the templates.Defer blocks.
+	deferredIdx *atomic.Int64
 }
 
-func newTemplateContext(t *valueobject.State, lookupFn func(name string) *valueobject.State) *Context {
+func newTemplateContext(t *valueobject.State, lookupFn func(name string) *valueobject.State, deferredIdx *atomic.Int64) *Context {
 	return &Context{
 		t:                t,
 		lookupFn:         lookupFn,
 		visited:          make(map[string]bool),
 		TemplateNotFound: make(map[string]bool),
+		DeferNodes:       make(map[string]*parse.ListNode),
+		deferredIdx:      deferredIdx,
 	}
 }
 
@@ -53,12 +61,12 @@ func getParseTree(templ template.Preparer) *parse.Tree {
 	return templ.(*htmltemplate.Template).Tree
 }
 
-func ApplyTemplateTransformers(t *valueobject.State, lookupFn func(name string) *valueobject.State) (*Context, error) {
+func ApplyTemplateTransformers(t *valueobject.State, lookupFn func(name string) *valueobject.State, deferredIdx *atomic.Int64) (*Context, error) {
 	if t == nil {
 		return nil, errors.New("expected template, but none provided")
 	}
 
-	c := newTemplateContext(t, lookupFn)
+	c := newTemplateContext(t, lookupFn, deferredIdx)
 	tree := getParseTree(t.Preparer)
 
 	_, err := c.applyTransformations(tree.Root)
@@ -86,6 +94,10 @@ func (c *Context) applyTransformations(n parse.Node) (bool, error) {
 	case *parse.IfNode:
 		c.applyTransformationsToNodes(x.Pipe, x.List, x.ElseList)
 	case *parse.WithNode:
+		if cmd, chain, ok := isWithDefer(x); ok {
+			c.handleDefer(x, cmd, chain)
+			break
+		}
 		c.applyTransformationsToNodes(x.Pipe, x.List, x.ElseList)
 	case *parse.RangeNode:
 		c.applyTransformationsToNodes(x.Pipe, x.List, x.ElseList)
@@ -0,0 +1,137 @@
+package entity
+
+import (
+	"context"
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
+	tplfuncs "github.com/mdfriday/hugoverse/pkg/template/funcs/templates"
+	htmltemplate "github.com/mdfriday/hugoverse/pkg/template/htmltemplate"
+	texttemplate "github.com/mdfriday/hugoverse/pkg/template/texttemplate"
+	"github.com/mdfriday/hugoverse/pkg/template/texttemplate/parse"
+	"github.com/spf13/cast"
+	"strconv"
+)
+
+// isWithDefer reports whether the with block is a templates.Defer block and
+// returns the templates.Defer command.
+func isWithDefer(n *parse.WithNode) (*parse.CommandNode, *parse.ChainNode, bool) {
+	if n.Pipe == nil || len(n.Pipe.Cmds) != 1 {
+		return nil, nil, false
+	}
+
+	cmd := n.Pipe.Cmds[0]
+	if len(cmd.Args) == 1 {
+		// {{ with (templates.Defer ...) }}
+		if p, ok := cmd.Args[0].(*parse.PipeNode); ok && len(p.Cmds) == 1 {
+			cmd = p.Cmds[0]
+		}
+	}
+	if len(cmd.Args) == 0 {
+		return nil, nil, false
+	}
+
+	chain, ok := cmd.Args[0].(*parse.ChainNode)
+	if !ok || len(chain.Field) != 1 || chain.Field[0] != "Defer" {
+		return nil, nil, false
+	}
+	if id, ok := chain.Node.(*parse.IdentifierNode); !ok || id.Ident != "templates" {
+		return nil, nil, false
+	}
+
+	return cmd, chain, true
+}
+
+// handleDefer moves the body of a templates.Defer block to a template of its
+// own, executed when the build is done, and rewrites the block to
+//
+//	{{ with (templates.DoDefer "<template name>" ...) }}{{ . }}{{ end }}
+//
+// which writes a placeholder replaced with the output of that template.
+func (c *Context) handleDefer(n *parse.WithNode, cmd *parse.CommandNode, chain *parse.ChainNode) {
+	name := tplfuncs.DeferredTemplatePrefix + strconv.FormatInt(c.deferredIdx.Add(1), 10)
+
+	c.applyTransformationsToNodes(n.List, n.ElseList)
+	c.DeferNodes[name] = n.List
+
+	chain.Field = []string{"DoDefer"}
+	cmd.Args = append([]parse.Node{chain, &parse.StringNode{
+		NodeType: parse.NodeString,
+		Quoted:   strconv.Quote(name),
+		Text:     name,
+	}}, cmd.Args[1:]...)
+
+	n.List = &parse.ListNode{
+		NodeType: parse.NodeList,
+		Nodes: []parse.Node{
+			&parse.ActionNode{
+				NodeType: parse.NodeAction,
+				Pipe: &parse.PipeNode{
+					NodeType: parse.NodePipe,
+					Cmds: []*parse.CommandNode{
+						{NodeType: parse.NodeCommand, Args: []parse.Node{&parse.DotNode{}}},
+					},
+				},
+			},
+		},
+	}
+	n.ElseList = nil
+}
+
+// addDeferred adds the deferred blocks found in ts as templates in the same
+// template set.
+func (t *Parser) addDeferred(ns *Namespace, ts *valueobject.State, deferred map[string]*parse.ListNode) error {
+	for name, list := range deferred {
+		tree := &parse.Tree{Name: name, Root: list}
+
+		var (
+			templ valueobject.State
+			err   error
+		)
+		switch x := unwrap(ts.Preparer).(type) {
+		case *texttemplate.Template:
+			templ.Preparer, err = x.AddParseTree(name, tree)
+		case *htmltemplate.Template:
+			templ.Preparer, err = x.AddParseTree(name, tree)
+		default:
+			err = fmt.Errorf("unsupported template type %T", x)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to add deferred template for %q: %w", ts.Name(), err)
+		}
+
+		ns.addTemplate(name, valueobject.NewTemplateState(templ.Preparer, valueobject.TemplateInfo{
+			Name:   name,
+			IsText: ts.IsText(),
+		}, nil))
+	}
+
+	return nil
+}
+
+func (t *Template) DeferredExecutions() *tplfuncs.Deferred {
+	return t.Deferred
+}
+
+// ExecuteDeferred executes the templates.Defer blocks registered during the
+// build and returns their output by placeholder.
+func (t *Template) ExecuteDeferred(ctx context.Context) (map[string]string, error) {
+	executions := t.Deferred.Drain()
+	if len(executions) == 0 {
+		return nil, nil
+	}
+
+	m := make(map[string]string, len(executions))
+	for _, e := range executions {
+		_, res, err := t.Execute(ctx, e.TemplateName, e.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute deferred template %q: %w", e.TemplateName, err)
+		}
+		s, err := cast.ToStringE(res)
+		if err != nil {
+			return nil, err
+		}
+		m[e.Placeholder] = s
+	}
+
+	return m, nil
+}
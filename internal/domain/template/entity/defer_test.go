@@ -0,0 +1,57 @@
+package entity
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
+	tplfuncs "github.com/mdfriday/hugoverse/pkg/template/funcs/templates"
+	texttemplate "github.com/mdfriday/hugoverse/pkg/template/texttemplate"
+)
+
+func TestDefer(t *testing.T) {
+	c := qt.New(t)
+
+	deferred := tplfuncs.NewDeferred()
+	templates := tplfuncs.New(deferred)
+	funcs := map[string]any{
+		"templates": func() *tplfuncs.Namespace { return templates },
+		"dict": func(k1 string, v1 any, k2 string, v2 any) map[string]any {
+			return map[string]any{k1: v1, k2: v2}
+		},
+	}
+
+	p := &Parser{
+		PrototypeText: texttemplate.New("").Funcs(funcs),
+		Ast:           &AstTransformer{TransformNotFound: make(map[string]*valueobject.State)},
+		RWMutex:       &sync.RWMutex{},
+	}
+	ns := &Namespace{StateMap: &valueobject.StateMap{Templates: make(map[string]*valueobject.State)}}
+
+	ts, err := p.Parse(valueobject.TemplateInfo{
+		Name:     "index.txt",
+		Template: `a{{ $v := "outer" }}{{ with (templates.Defer (dict "key" "k" "data" "D")) }}[{{ . }}]{{ else }}never{{ end }}b`,
+		IsText:   true,
+	})
+	c.Assert(err, qt.IsNil)
+	c.Assert(p.Transform(ns, ts), qt.IsNil)
+
+	var b strings.Builder
+	for i := 0; i < 2; i++ {
+		c.Assert(ts.Preparer.(*texttemplate.Template).Execute(&b, nil), qt.IsNil)
+	}
+
+	executions := deferred.Drain()
+	c.Assert(executions, qt.HasLen, 1)
+	e := executions[0]
+	c.Assert(b.String(), qt.Equals, "a"+e.Placeholder+"b"+"a"+e.Placeholder+"b")
+	c.Assert(e.Data, qt.Equals, "D")
+
+	dt, found := ns.findTemplate(e.TemplateName)
+	c.Assert(found, qt.IsTrue)
+	b.Reset()
+	c.Assert(dt.Preparer.(*texttemplate.Template).Execute(&b, e.Data), qt.IsNil)
+	c.Assert(b.String(), qt.Equals, "[D]")
+}
@@ -26,6 +26,26 @@ func (t *Namespace) findTemplate(name string) (*valueobject.State, bool) {
 	return state, found
 }
 
+// findTemplateInfo finds the source info of the named template,
+// which may be the base template of another one.
+func (t *Namespace) findTemplateInfo(name string) valueobject.TemplateInfo {
+	t.Mu.RLock()
+	defer t.Mu.RUnlock()
+
+	if state, found := t.Templates[name]; found && !state.Info.IsZero() {
+		return state.Info
+	}
+	for _, state := range t.Templates {
+		if state.Info.Name == name {
+			return state.Info
+		}
+		if state.BaseInfo.Name == name {
+			return state.BaseInfo
+		}
+	}
+	return valueobject.TemplateInfo{}
+}
+
 func (t *Namespace) newTemplateLookup(in *valueobject.State) func(name string) *valueobject.State {
 	return func(name string) *valueobject.State {
 		if templ, found := t.Templates[name]; found {
@@ -139,9 +139,9 @@ func (t *Parser) createPrototypes() error {
 }
 
 func (t *Parser) Transform(ns *Namespace, ts *valueobject.State) error {
-	_, err := t.Ast.applyTemplateTransformers(ns.newTemplateLookup, ts)
+	c, err := t.Ast.applyTemplateTransformers(ns.newTemplateLookup, ts)
 	if err != nil {
 		return err
 	}
-	return nil
+	return t.addDeferred(ns, ts, c.DeferNodes)
 }
@@ -4,10 +4,14 @@ import (
 	"context"
 	"fmt"
 	"github.com/mdfriday/hugoverse/internal/domain/template"
+	"github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
 	bp "github.com/mdfriday/hugoverse/pkg/bufferpool"
+	"github.com/mdfriday/hugoverse/pkg/identity"
 	texttemplate "github.com/mdfriday/hugoverse/pkg/template/texttemplate"
+	"github.com/mdfriday/hugoverse/pkg/template/texttemplate/parse"
 	htemplate "html/template"
 	"io"
+	"strings"
 )
 
 func (t *Template) Execute(ctx context.Context, name string, data any) (tmpl string, res any, err error) {
@@ -63,6 +67,141 @@ func (t *Template) Execute(ctx context.Context, name string, data any) (tmpl str
 	return templ.Name(), result, nil
 }
 
+// TemplateIdentity returns an identity of the named template that changes
+// with its source and the sources of the partials and templates it
+// includes, transitively, e.g. to key persisted partialCached output. It's
+// not found if a partial is included by a name only known when executed.
+func (t *Template) TemplateIdentity(name string) (string, bool) {
+	var sources []any
+	if !t.collectSources(name, make(map[string]bool), &sources) {
+		return "", false
+	}
+
+	return identity.HashString(sources...), true
+}
+
+func (t *Template) collectSources(name string, seen map[string]bool, sources *[]any) bool {
+	templ, found := t.Main.findTemplate(name)
+	if !found {
+		templ, found = t.Main.findTemplate(name + ".html")
+	}
+	if !found || templ.Info.Template == "" {
+		return false
+	}
+	if seen[templ.Name()] {
+		return true
+	}
+	seen[templ.Name()] = true
+	*sources = append(*sources, templ.Name(), templ.Info.Template, templ.BaseInfo.Template)
+
+	partials, templates, ok := includes(getParseTree(templ.Preparer).Root)
+	if !ok {
+		return false
+	}
+	for _, p := range partials {
+		if !strings.HasPrefix(p, "partials/") {
+			p = "partials/" + p
+		}
+		if !t.collectSources(p, seen, sources) {
+			return false
+		}
+	}
+	for _, n := range templates {
+		// Templates defined within the source are part of it already.
+		if _, found := t.Main.findTemplate(n); found && !t.collectSources(n, seen, sources) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// includes returns the names of the partials and the templates included
+// in the tree below node, false if a partial name is not a constant.
+func includes(node parse.Node) (partials, templates []string, ok bool) {
+	ok = true
+	var walk func(n parse.Node)
+	walk = func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Nodes {
+				walk(c)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe)
+		case *parse.IfNode:
+			walk(&n.BranchNode)
+		case *parse.RangeNode:
+			walk(&n.BranchNode)
+		case *parse.WithNode:
+			walk(&n.BranchNode)
+		case *parse.BranchNode:
+			walk(n.Pipe)
+			walk(n.List)
+			walk(n.ElseList)
+		case *parse.TemplateNode:
+			templates = append(templates, n.Name)
+			walk(n.Pipe)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, c := range n.Cmds {
+				walk(c)
+			}
+		case *parse.CommandNode:
+			if isPartialCall(n) {
+				if len(n.Args) < 2 {
+					return
+				}
+				name, isConst := n.Args[1].(*parse.StringNode)
+				if !isConst {
+					ok = false
+					return
+				}
+				partials = append(partials, name.Text)
+			}
+			for _, a := range n.Args {
+				walk(a)
+			}
+		case *parse.ChainNode:
+			walk(n.Node)
+		}
+	}
+	walk(node)
+
+	return partials, templates, ok
+}
+
+// isPartialCall reports whether cmd calls partial, partialCached or their
+// partials namespace functions.
+func isPartialCall(cmd *parse.CommandNode) bool {
+	if len(cmd.Args) == 0 {
+		return false
+	}
+	switch f := cmd.Args[0].(type) {
+	case *parse.IdentifierNode:
+		return f.Ident == "partial" || f.Ident == "partialCached"
+	case *parse.ChainNode:
+		id, ok := f.Node.(*parse.IdentifierNode)
+		return ok && id.Ident == "partials" && len(f.Field) == 1 &&
+			(f.Field[0] == "Include" || f.Field[0] == "IncludeCached")
+	}
+	return false
+}
+
+// ExecuteWithContext executes the template, adding the failing template source
+// and the data context type to the error.
+func (t *Template) ExecuteWithContext(ctx context.Context, templ template.Preparer, wr io.Writer, data any) error {
+	if err := t.Executor.ExecuteWithContext(ctx, templ, wr, data); err != nil {
+		return valueobject.NewExecuteError(err, data, t.Main.findTemplateInfo)
+	}
+	return nil
+}
+
 // contextWrapper makes room for a return value in a partial invocation.
 type contextWrapper struct {
 	Arg    any
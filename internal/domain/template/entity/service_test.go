@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
+	texttemplate "github.com/mdfriday/hugoverse/pkg/template/texttemplate"
+)
+
+func TestTemplateIdentity(t *testing.T) {
+	c := qt.New(t)
+
+	partial := func(name string, data ...any) string { return "" }
+	p := &Parser{
+		PrototypeText: texttemplate.New("").Funcs(map[string]any{
+			"partial":       partial,
+			"partialCached": partial,
+		}),
+		RWMutex: &sync.RWMutex{},
+	}
+	tmpl := &Template{Main: &Namespace{StateMap: &valueobject.StateMap{Templates: make(map[string]*valueobject.State)}}}
+	add := func(name, source string) {
+		ts, err := p.Parse(valueobject.TemplateInfo{Name: name, Template: source, IsText: true})
+		c.Assert(err, qt.IsNil)
+		tmpl.Main.addTemplate(name, ts)
+	}
+
+	add("partials/nav.html", `<nav>{{ range . }}{{ partial "item.html" . }}{{ end }}</nav>`)
+	add("partials/item.html", `<a>{{ . }}</a>{{ partialCached "nav.html" . }}`)
+	add("partials/dynamic.html", `{{ partial (printf "%s.html" .) . }}`)
+
+	id, found := tmpl.TemplateIdentity("partials/nav.html")
+	c.Assert(found, qt.IsTrue)
+	same, _ := tmpl.TemplateIdentity("partials/nav")
+	c.Assert(same, qt.Equals, id)
+
+	// Changing a nested partial changes the identity.
+	add("partials/item.html", `<b>{{ . }}</b>`)
+	changed, found := tmpl.TemplateIdentity("partials/nav.html")
+	c.Assert(found, qt.IsTrue)
+	c.Assert(changed, qt.Not(qt.Equals), id)
+
+	_, found = tmpl.TemplateIdentity("partials/dynamic.html")
+	c.Assert(found, qt.IsFalse)
+	_, found = tmpl.TemplateIdentity("partials/missing.html")
+	c.Assert(found, qt.IsFalse)
+}
@@ -1,8 +1,10 @@
 package entity
 
 import (
+	"fmt"
 	"github.com/mdfriday/hugoverse/internal/domain/template"
 	"github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
+	"sort"
 	"strings"
 )
 
@@ -10,6 +12,83 @@ type Shortcode struct {
 	// shortcodes maps shortcode name to template variants
 	// (language, output format etc.) of that shortcode.
 	shortcodes map[string]*shortcodeTemplates
+
+	// schemas maps shortcode name to its parameter schema.
+	schemas map[string]shortcodeSchema
+}
+
+type shortcodeSchema struct {
+	*valueobject.ShortcodeSchema
+	embedded bool
+}
+
+func (t *Shortcode) addSchema(name string, schema *valueobject.ShortcodeSchema, embedded bool) {
+	if existing, found := t.schemas[name]; found && !existing.embedded && embedded {
+		return
+	}
+	t.schemas[name] = shortcodeSchema{ShortcodeSchema: schema, embedded: embedded}
+}
+
+// schema returns the schema of the named shortcode. An embedded schema
+// does not apply to a user template overriding the embedded shortcode.
+func (t *Shortcode) schema(name string) (*valueobject.ShortcodeSchema, bool) {
+	s, found := t.schemas[name]
+	if !found {
+		return nil, false
+	}
+	if s.embedded {
+		if templs, found := t.shortcodes[name]; found && !templs.isInternal() {
+			return nil, false
+		}
+	}
+	return s.ShortcodeSchema, true
+}
+
+// ValidateShortcode validates the parameters of a shortcode invocation
+// against the shortcode's schema, if any, see valueobject.ShortcodeSchema.Validate.
+func (t *Shortcode) ValidateShortcode(name string, params any) (any, error) {
+	if t == nil {
+		return params, nil
+	}
+	schema, found := t.schema(name)
+	if !found {
+		return params, nil
+	}
+	validated, err := schema.Validate(params)
+	if err != nil {
+		return nil, fmt.Errorf("shortcode %q: %w", name, err)
+	}
+	return validated, nil
+}
+
+// Shortcodes lists the shortcodes available to the content, sorted by name.
+func (t *Shortcode) Shortcodes() []valueobject.ShortcodeInfo {
+	if t == nil {
+		return nil
+	}
+
+	var infos []valueobject.ShortcodeInfo
+	for name, templs := range t.shortcodes {
+		if strings.Contains(name, "__") || len(templs.variants) == 0 {
+			// Internal helpers, e.g. 1__h_simple_assets.
+			continue
+		}
+		info := valueobject.ShortcodeInfo{
+			Name:     name,
+			Inner:    templs.variants[0].ts.ParseInfo().Inner(),
+			Embedded: templs.isInternal(),
+		}
+		if schema, found := t.schema(name); found {
+			info.Schema = schema
+		}
+		infos = append(infos, info)
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos
 }
 
 func (t *Shortcode) LookupVariant(name string, variants template.Variants) (template.Preparer, bool, bool) {
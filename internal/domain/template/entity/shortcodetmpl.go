@@ -22,6 +22,16 @@ type shortcodeTemplates struct {
 	variants []shortcodeVariant
 }
 
+// isInternal reports whether the shortcode is not overridden by the user.
+func (s *shortcodeTemplates) isInternal() bool {
+	for _, v := range s.variants {
+		if !isInternal(v.ts.Name()) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *shortcodeTemplates) indexOf(variants []string) int {
 L:
 	for i, v1 := range s.variants {
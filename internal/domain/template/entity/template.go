@@ -8,7 +8,9 @@ import (
 	"github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
 	"github.com/mdfriday/hugoverse/pkg/herrors"
 	"github.com/mdfriday/hugoverse/pkg/loggers"
+	tplfuncs "github.com/mdfriday/hugoverse/pkg/template/funcs/templates"
 	texttemplate "github.com/mdfriday/hugoverse/pkg/template/texttemplate"
+	"io"
 	iofs "io/fs"
 	"path/filepath"
 	"strings"
@@ -31,6 +33,10 @@ type Template struct {
 
 	LayoutTemplateCache   map[string]valueobject.LayoutCacheEntry
 	layoutTemplateCacheMu sync.RWMutex
+
+	// Deferred holds the templates.Defer blocks waiting for the build to be
+	// done.
+	Deferred *tplfuncs.Deferred
 }
 
 func (t *Template) MarkReady() error {
@@ -120,6 +126,10 @@ func (t *Template) LoadEmbedded() error {
 		name := strings.TrimPrefix(filepath.ToSlash(path), "embedded/templates/")
 		templateName := name
 
+		if scName, ok := valueobject.ShortcodeSchemaName(name); ok {
+			return t.addShortcodeSchema(scName, name, templb, true)
+		}
+
 		// For the render hooks and the server templates it does not make sense to preserve the
 		// double _internal double book-keeping,
 		// just add it if its now provided by the user.
@@ -160,6 +170,10 @@ func (t *Template) LoadTemplates() error {
 
 		name := strings.TrimPrefix(filepath.ToSlash(path), "/")
 
+		if scName, ok := valueobject.ShortcodeSchemaName(name); ok {
+			return t.addShortcodeSchemaFileInfo(scName, name, fi)
+		}
+
 		if err := t.addTemplateFileInfo(name, fi); err != nil {
 			return err
 		}
@@ -189,6 +203,34 @@ func (t *Template) addTemplateFileInfo(name string, fim fs.FileMetaInfo) error {
 	return t.addTemplate(tinfo.Name, tinfo)
 }
 
+func (t *Template) addShortcodeSchemaFileInfo(scName, name string, fim fs.FileMetaInfo) error {
+	f, err := fim.Open()
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	b, err := io.ReadAll(f)
+	if err != nil {
+		return err
+	}
+
+	if err := t.addShortcodeSchema(scName, name, b, false); err != nil {
+		return herrors.NewFileErrorFromName(err, fim.FileName())
+	}
+	return nil
+}
+
+func (t *Template) addShortcodeSchema(scName, name string, b []byte, embedded bool) error {
+	schema, err := valueobject.LoadShortcodeSchema(name, b)
+	if err != nil {
+		return err
+	}
+
+	t.getShortcode().addSchema(scName, schema, embedded)
+
+	return nil
+}
+
 func (t *Template) addTemplateContent(name, tpl string) error {
 	tinfo, err := valueobject.LoadTemplateContent(name, tpl)
 	if err != nil {
@@ -273,6 +315,7 @@ func (t *Template) getShortcode() *Shortcode {
 	t.shortcodeOnce.Do(func() {
 		t.Shortcode = &Shortcode{
 			shortcodes: map[string]*shortcodeTemplates{},
+			schemas:    map[string]shortcodeSchema{},
 		}
 	})
 	return t.Shortcode
@@ -1,6 +1,9 @@
 package entity
 
-import "github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
+import (
+	"github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
+	"sync/atomic"
+)
 
 type templateLookupFunc func(in *valueobject.State) func(name string) *valueobject.State
 
@@ -8,10 +11,14 @@ type AstTransformer struct {
 	// Holds name and source of template definitions not found during the first
 	// AST transformation pass.
 	TransformNotFound map[string]*valueobject.State
+
+	// deferredIdx numbers the templates.Defer blocks of the template
+	// service.
+	deferredIdx atomic.Int64
 }
 
 func (t *AstTransformer) applyTemplateTransformers(lookup templateLookupFunc, ts *valueobject.State) (*Context, error) {
-	c, err := ApplyTemplateTransformers(ts, lookup(ts))
+	c, err := ApplyTemplateTransformers(ts, lookup(ts), &t.deferredIdx)
 	if err != nil {
 		return nil, err
 	}
@@ -28,7 +35,7 @@ func (t *AstTransformer) post(lu templateLookupFunc) error {
 		lookup := lu(source)
 		templ := lookup(name)
 		if templ != nil {
-			_, err := ApplyTemplateTransformers(templ, lookup)
+			_, err := ApplyTemplateTransformers(templ, lookup, &t.deferredIdx)
 			if err != nil {
 				return err
 			}
@@ -5,6 +5,7 @@ import (
 	"github.com/mdfriday/hugoverse/internal/domain/template/entity"
 	"github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
 	"github.com/mdfriday/hugoverse/pkg/loggers"
+	"github.com/mdfriday/hugoverse/pkg/template/funcs/templates"
 	htmltemplate "github.com/mdfriday/hugoverse/pkg/template/htmltemplate"
 	texttemplate "github.com/mdfriday/hugoverse/pkg/template/texttemplate"
 	"reflect"
@@ -23,6 +24,7 @@ func newBuilder() *builder {
 		tmpl: &entity.Template{
 			Log:                 loggers.NewDefault(),
 			LayoutTemplateCache: make(map[string]valueobject.LayoutCacheEntry),
+			Deferred:            templates.NewDeferred(),
 		},
 	}
 }
@@ -68,16 +70,16 @@ func (b *builder) withCfs(cfs template.CustomizedFunctions) *builder {
 }
 
 func (b *builder) buildFunctions() *builder {
-	valueobject.ResetTemplateFuncsNamespaceRegistry()
-	valueobject.RegisterNamespaces()
-	valueobject.RegisterCallbackNamespaces(b.tmpl.Execute)
-	valueobject.RegisterExtendedNamespaces(b.cfs)
-	valueobject.RegisterLookerNamespaces(b.cfs, b.tmpl.Lookup)
+	reg := &valueobject.TemplateFuncsNamespaceRegistry{}
+	valueobject.RegisterNamespaces(reg)
+	valueobject.RegisterCallbackNamespaces(reg, b.tmpl, b.cfs)
+	valueobject.RegisterExtendedNamespaces(reg, b.cfs)
+	valueobject.RegisterLookerNamespaces(reg, b.cfs, b.tmpl.Lookup)
 
 	funcs := htmltemplate.FuncMap{}
 
 	// Merge the namespace funcs
-	for _, nsf := range valueobject.TemplateFuncsNamespaceRegistry {
+	for _, nsf := range reg.Namespaces() {
 		ns := nsf()
 		if _, exists := funcs[ns.Name]; exists {
 			continue
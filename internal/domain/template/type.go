@@ -5,14 +5,17 @@ import (
 	"github.com/mdfriday/hugoverse/internal/domain/fs"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/collections"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/compare"
+	"github.com/mdfriday/hugoverse/pkg/template/funcs/css"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/hugo"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/image"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/js"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/lang"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/os"
+	"github.com/mdfriday/hugoverse/pkg/template/funcs/partials"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/resource"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/site"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/strings"
+	"github.com/mdfriday/hugoverse/pkg/template/funcs/templates"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/transform"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/urls"
 	template "github.com/mdfriday/hugoverse/pkg/template/texttemplate"
@@ -41,6 +44,14 @@ type Template interface {
 	Lookup
 }
 
+// Callback is implemented by the template service for the namespaces
+// calling back into it, e.g. partials.
+type Callback interface {
+	Execute(ctx context.Context, name string, data any) (tmpl string, res any, err error)
+	TemplateIdentity(name string) (string, bool)
+	DeferredExecutions() *templates.Deferred
+}
+
 type Executor interface {
 	ExecuteWithContext(ctx context.Context, t Preparer, wr io.Writer, data any) error
 }
@@ -77,9 +88,12 @@ type CustomizedFunctions interface {
 	strings.Title
 	resource.Resource
 	js.Client
+	css.Client
 	image.Image
+	image.Resources
 	os.Os
 	site.Service
 	hugo.Info
 	lang.Translator
+	partials.Cache
 }
@@ -0,0 +1,61 @@
+package valueobject
+
+import (
+	"fmt"
+	"github.com/mdfriday/hugoverse/pkg/herrors"
+	pio "github.com/mdfriday/hugoverse/pkg/io"
+	"github.com/mdfriday/hugoverse/pkg/text"
+)
+
+// ExecuteError is returned when a template fails to execute.
+// It adds the failing template and the type of its data context
+// to the file error pointing into the template source, which it wraps.
+type ExecuteError struct {
+	err herrors.FileError
+
+	// Template is the name of the template where the error happened,
+	// e.g. a partial called from the layout.
+	Template string
+
+	// DataType is the type of the data context of the executed template.
+	DataType string
+}
+
+func (e *ExecuteError) Error() string {
+	return e.err.Error()
+}
+
+func (e *ExecuteError) Unwrap() error {
+	return e.err
+}
+
+// NewExecuteError wraps err with the position extracted from the error
+// message and the surrounding lines of the failing template source.
+// The lookup resolves the template info for the failing template name,
+// it may return a zero TemplateInfo if not found.
+func NewExecuteError(err error, data any, lookup func(name string) TemplateInfo) *ExecuteError {
+	name, lno, col := herrors.ExtractTemplatePosition(err)
+	info := lookup(name)
+
+	filename := name
+	if info.Fi != nil {
+		filename = info.Fi.FileName()
+	}
+
+	fe := herrors.NewFileErrorFromPos(err, text.Position{
+		Filename:     filename,
+		LineNumber:   lno,
+		ColumnNumber: col,
+	})
+	if !info.IsZero() {
+		f := pio.NewReadSeekerNoOpCloserFromString(info.Template)
+		defer f.Close()
+		fe = fe.UpdateContent(f, nil)
+	}
+
+	return &ExecuteError{
+		err:      fe,
+		Template: name,
+		DataType: fmt.Sprintf("%T", data),
+	}
+}
@@ -0,0 +1,38 @@
+package valueobject
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"text/template"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/pkg/herrors"
+)
+
+func TestNewExecuteError(t *testing.T) {
+	c := qt.New(t)
+
+	src := "line 1\nline 2\n{{ .Title.Foo }}\nline 4"
+	templ := template.Must(template.New("_default/single.html").Parse(src))
+	err := templ.Execute(io.Discard, map[string]any{"Title": "t"})
+	c.Assert(err, qt.IsNotNil)
+
+	data := map[string]any{}
+	ee := NewExecuteError(err, data, func(name string) TemplateInfo {
+		c.Assert(name, qt.Equals, "_default/single.html")
+		return TemplateInfo{Name: name, Template: src}
+	})
+
+	c.Assert(ee.Template, qt.Equals, "_default/single.html")
+	c.Assert(ee.DataType, qt.Equals, "map[string]interface {}")
+	c.Assert(errors.Is(ee, err), qt.IsTrue)
+
+	// The file error is there once, below ExecuteError.
+	fes := herrors.UnwrapFileErrorsWithErrorContext(fmt.Errorf("failed to render pages: %w", ee))
+	c.Assert(fes, qt.HasLen, 1)
+	c.Assert(fes[0].Position().LineNumber, qt.Equals, 3)
+	c.Assert(fes[0].ErrorContext().Lines, qt.DeepEquals, []string{"line 1", "line 2", "{{ .Title.Foo }}", "line 4"})
+	c.Assert(fes[0].ErrorContext().LinesPos, qt.Equals, 2)
+}
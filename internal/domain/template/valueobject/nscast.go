@@ -5,7 +5,7 @@ import (
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/cast"
 )
 
-func registerCast() {
+func registerCast(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := cast.New()
 
@@ -38,5 +38,5 @@ func registerCast() {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
@@ -8,7 +8,7 @@ import (
 
 const nsCollections = "collections"
 
-func registerCollections(timezone compare.TimeZone,
+func registerCollections(reg *TemplateFuncsNamespaceRegistry, timezone compare.TimeZone,
 	looker collections.FuncLooker, lang collections.Language) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := collections.New(timezone, looker, lang)
@@ -192,5 +192,5 @@ func registerCollections(timezone compare.TimeZone,
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
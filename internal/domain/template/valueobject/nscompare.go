@@ -7,7 +7,7 @@ import (
 
 const nsCompare = "compare"
 
-func registerCompare(timezone compare.TimeZone) {
+func registerCompare(reg *TemplateFuncsNamespaceRegistry, timezone compare.TimeZone) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := compare.New(timezone.Location(), false)
 
@@ -68,5 +68,5 @@ func registerCompare(timezone compare.TimeZone) {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
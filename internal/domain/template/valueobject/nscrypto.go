@@ -7,7 +7,7 @@ import (
 
 const nsCrypto = "crypto"
 
-func registerCrypto() {
+func registerCrypto(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := crypto.New()
 
@@ -55,5 +55,5 @@ func registerCrypto() {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
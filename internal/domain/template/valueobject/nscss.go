@@ -2,14 +2,20 @@ package valueobject
 
 import (
 	"context"
+	"github.com/mdfriday/hugoverse/pkg/template/funcs/css"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/resource"
 )
 
 const nsCss = "css"
 
-func registerCss(res resource.Resource) {
+type cssClient interface {
+	css.Client
+	resource.Resource
+}
+
+func registerCss(reg *TemplateFuncsNamespaceRegistry, client cssClient) {
 	f := func() *TemplateFuncsNamespace {
-		ctx, err := resource.New(res)
+		ctx, err := css.New(client, client)
 		if err != nil {
 			// TODO(bep) no panic.
 			panic(err)
@@ -25,8 +31,13 @@ func registerCss(res resource.Resource) {
 			[][2]string{},
 		)
 
+		ns.AddMethodMapping(ctx.Build,
+			nil,
+			[][2]string{},
+		)
+
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
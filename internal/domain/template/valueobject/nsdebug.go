@@ -0,0 +1,36 @@
+package valueobject
+
+import (
+	"context"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+	"github.com/mdfriday/hugoverse/pkg/template/funcs/debug"
+)
+
+const nsDebug = "debug"
+
+func registerDebug(reg *TemplateFuncsNamespaceRegistry) {
+	f := func() *TemplateFuncsNamespace {
+		ctx := debug.New(loggers.NewDefault())
+
+		ns := &TemplateFuncsNamespace{
+			Name:    nsDebug,
+			Context: func(cctx context.Context, args ...any) (any, error) { return ctx, nil },
+		}
+
+		ns.AddMethodMapping(ctx.Dump,
+			nil,
+			[][2]string{
+				{`{{ debug.Dump (dict "a" 1) }}`, "{\n  \"a\": 1\n}"},
+			},
+		)
+
+		ns.AddMethodMapping(ctx.Timer,
+			nil,
+			[][2]string{},
+		)
+
+		return ns
+	}
+
+	reg.Add(f)
+}
@@ -7,7 +7,7 @@ import (
 
 const nsDiagram = "diagrams"
 
-func registerDiagram() {
+func registerDiagram(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := diagrams.New()
 
@@ -23,8 +23,29 @@ func registerDiagram() {
 			},
 		)
 
+		ns.AddMethodMapping(ctx.Sequence,
+			[]string{"sequence"},
+			[][2]string{
+				{`{{ (diagrams.Sequence "Alice -> Bob: Hello").Width }}`, `185`},
+			},
+		)
+
+		ns.AddMethodMapping(ctx.Flowchart,
+			[]string{"flowchart"},
+			[][2]string{
+				{`{{ (diagrams.Flowchart "a -> b").Height }}`, `142`},
+			},
+		)
+
+		ns.AddMethodMapping(ctx.Dot,
+			[]string{"dot"},
+			[][2]string{
+				{`{{ (diagrams.Dot "digraph { a -> b }").Height }}`, `142`},
+			},
+		)
+
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
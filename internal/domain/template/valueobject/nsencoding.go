@@ -7,7 +7,7 @@ import (
 
 const nsEncoding = "encoding"
 
-func registerEncoding() {
+func registerEncoding(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := encoding.New()
 
@@ -42,5 +42,5 @@ func registerEncoding() {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
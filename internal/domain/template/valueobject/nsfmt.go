@@ -7,7 +7,7 @@ import (
 
 const nsFmt = "fmt"
 
-func registerFmt() {
+func registerFmt(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := fmt.New()
 
@@ -67,5 +67,5 @@ func registerFmt() {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
@@ -7,7 +7,7 @@ import (
 
 const nsHugo = "hugo"
 
-func registerHugo(info hugo.Info) {
+func registerHugo(reg *TemplateFuncsNamespaceRegistry, info hugo.Info) {
 	f := func() *TemplateFuncsNamespace {
 		h := hugo.New(info)
 
@@ -21,5 +21,5 @@ func registerHugo(info hugo.Info) {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
@@ -7,9 +7,9 @@ import (
 
 const nsImage = "images"
 
-func registerImages(img image.Image) {
+func registerImages(reg *TemplateFuncsNamespaceRegistry, img image.Image, rs image.Resources) {
 	f := func() *TemplateFuncsNamespace {
-		ctx, err := image.New(img)
+		ctx, err := image.New(img, rs)
 		if err != nil {
 			// TODO(bep) no panic.
 			panic(err)
@@ -20,8 +20,18 @@ func registerImages(img image.Image) {
 			Context: func(cctx context.Context, args ...any) (any, error) { return ctx, nil },
 		}
 
+		ns.AddMethodMapping(ctx.Config,
+			nil,
+			[][2]string{},
+		)
+
+		ns.AddMethodMapping(ctx.QR,
+			nil,
+			[][2]string{},
+		)
+
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
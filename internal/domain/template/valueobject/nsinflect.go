@@ -7,7 +7,7 @@ import (
 
 const nsInflect = "inflect"
 
-func registerInflect() {
+func registerInflect(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := inflect.New()
 
@@ -43,5 +43,5 @@ func registerInflect() {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
@@ -7,7 +7,7 @@ import (
 
 const nsJs = "js"
 
-func registerJs(client js.Client) {
+func registerJs(reg *TemplateFuncsNamespaceRegistry, client js.Client) {
 	f := func() *TemplateFuncsNamespace {
 		ctx, err := js.New(client)
 		if err != nil {
@@ -23,5 +23,5 @@ func registerJs(client js.Client) {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
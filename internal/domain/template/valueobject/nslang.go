@@ -7,7 +7,7 @@ import (
 
 const nsLang = "lang"
 
-func registerLang(translator lang.Translator) {
+func registerLang(reg *TemplateFuncsNamespaceRegistry, translator lang.Translator) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := lang.New(translator)
 
@@ -64,5 +64,5 @@ func registerLang(translator lang.Translator) {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
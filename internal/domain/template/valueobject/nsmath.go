@@ -7,7 +7,7 @@ import (
 
 const nsMath = "math"
 
-func registerMath() {
+func registerMath(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := math.New()
 
@@ -131,5 +131,5 @@ func registerMath() {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
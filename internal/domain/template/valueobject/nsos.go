@@ -7,7 +7,7 @@ import (
 
 const nsOs = "os"
 
-func registerOs(ws os.Os) {
+func registerOs(reg *TemplateFuncsNamespaceRegistry, ws os.Os) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := os.New(ws)
 
@@ -45,5 +45,5 @@ func registerOs(ws os.Os) {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
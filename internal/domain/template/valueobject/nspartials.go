@@ -15,14 +15,15 @@ package valueobject
 
 import (
 	"context"
+	"github.com/mdfriday/hugoverse/pkg/cache/filecache"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/partials"
 )
 
 const nsPartials = "partials"
 
-func registerPartials(cb func(ctx context.Context, name string, data any) (tmpl string, res any, err error)) {
+func registerPartials(reg *TemplateFuncsNamespaceRegistry, cb partials.TemplateExecutor, id partials.TemplateIdentity, store *filecache.Cache) {
 	f := func() *TemplateFuncsNamespace {
-		ctx := partials.New(cb)
+		ctx := partials.New(cb, id, store)
 
 		ns := &TemplateFuncsNamespace{
 			Name:    nsPartials,
@@ -36,6 +37,11 @@ func registerPartials(cb func(ctx context.Context, name string, data any) (tmpl
 			},
 		)
 
+		ns.AddMethodMapping(ctx.IncludeCached,
+			[]string{"partialCached"},
+			[][2]string{},
+		)
+
 		// TODO(bep) we need the return to be a valid identifier, but
 		// should consider another way of adding it.
 		ns.AddMethodMapping(func() string { return "" },
@@ -46,5 +52,5 @@ func registerPartials(cb func(ctx context.Context, name string, data any) (tmpl
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
@@ -9,7 +9,7 @@ import (
 
 const nsPath = "path"
 
-func registerPath() {
+func registerPath(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := path.New()
 
@@ -42,5 +42,5 @@ func registerPath() {
 
 		return ns
 	}
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
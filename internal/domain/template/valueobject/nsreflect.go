@@ -7,7 +7,7 @@ import (
 
 const nsReflect = "reflect"
 
-func registerReflect() {
+func registerReflect(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := reflect.New()
 
@@ -33,5 +33,5 @@ func registerReflect() {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
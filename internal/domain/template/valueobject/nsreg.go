@@ -1,54 +1,62 @@
 package valueobject
 
 import (
-	"context"
 	"github.com/mdfriday/hugoverse/internal/domain/template"
 	"github.com/mdfriday/hugoverse/pkg/template/funcs/collections"
+	"github.com/mdfriday/hugoverse/pkg/template/funcs/partials"
 )
 
-var TemplateFuncsNamespaceRegistry []func() *TemplateFuncsNamespace
+// TemplateFuncsNamespaceRegistry collects the template funcs namespaces
+// of one template service, so that services created at the same time,
+// e.g. by parallel builds, do not share them.
+type TemplateFuncsNamespaceRegistry struct {
+	namespaces []func() *TemplateFuncsNamespace
+}
 
-func ResetTemplateFuncsNamespaceRegistry() {
-	TemplateFuncsNamespaceRegistry = nil
+func (reg *TemplateFuncsNamespaceRegistry) Add(ns func() *TemplateFuncsNamespace) {
+	reg.namespaces = append(reg.namespaces, ns)
 }
 
-func AddTemplateFuncsNamespace(ns func() *TemplateFuncsNamespace) {
-	TemplateFuncsNamespaceRegistry = append(TemplateFuncsNamespaceRegistry, ns)
+// Namespaces returns the namespaces in the order they were added.
+func (reg *TemplateFuncsNamespaceRegistry) Namespaces() []func() *TemplateFuncsNamespace {
+	return reg.namespaces
 }
 
-func RegisterNamespaces() {
-	registerCast()
-	registerFmt()
-	registerSafe()
-	registerCrypto()
-	registerPath()
-	registerInflect()
-	registerDiagram()
-	registerReflect()
-	registerMath()
-	registerEncoding()
-	registerTime()
+func RegisterNamespaces(reg *TemplateFuncsNamespaceRegistry) {
+	registerCast(reg)
+	registerFmt(reg)
+	registerSafe(reg)
+	registerCrypto(reg)
+	registerPath(reg)
+	registerInflect(reg)
+	registerDiagram(reg)
+	registerReflect(reg)
+	registerMath(reg)
+	registerEncoding(reg)
+	registerTime(reg)
+	registerDebug(reg)
 }
 
-func RegisterCallbackNamespaces(cb func(ctx context.Context, name string, data any) (tmpl string, res any, err error)) {
-	registerPartials(cb)
+func RegisterCallbackNamespaces(reg *TemplateFuncsNamespaceRegistry, cb template.Callback, cache partials.Cache) {
+	registerPartials(reg, cb.Execute, cb.TemplateIdentity, cache.PartialsCache())
+	registerTemplates(reg, cb.DeferredExecutions())
 }
 
-func RegisterExtendedNamespaces(functions template.CustomizedFunctions) {
-	registerLang(functions)
-	registerCompare(functions)
-	registerTransform(functions)
-	registerUrls(functions, functions)
-	registerStrings(functions)
-	registerResources(functions)
-	registerImages(functions)
-	registerCss(functions)
-	registerJs(functions)
-	registerOs(functions)
-	registerSite(functions)
-	registerHugo(functions)
+func RegisterExtendedNamespaces(reg *TemplateFuncsNamespaceRegistry, functions template.CustomizedFunctions) {
+	registerLang(reg, functions)
+	registerCompare(reg, functions)
+	registerTransform(reg, functions)
+	registerUrls(reg, functions, functions)
+	registerStrings(reg, functions)
+	registerResources(reg, functions)
+	registerImages(reg, functions, functions)
+	registerCss(reg, functions)
+	registerJs(reg, functions)
+	registerOs(reg, functions)
+	registerSite(reg, functions)
+	registerHugo(reg, functions)
 }
 
-func RegisterLookerNamespaces(functions template.CustomizedFunctions, looker collections.FuncLooker) {
-	registerCollections(functions, looker, functions)
+func RegisterLookerNamespaces(reg *TemplateFuncsNamespaceRegistry, functions template.CustomizedFunctions, looker collections.FuncLooker) {
+	registerCollections(reg, functions, looker, functions)
 }
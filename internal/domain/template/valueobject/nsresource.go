@@ -7,7 +7,7 @@ import (
 
 const nsResources = "resources"
 
-func registerResources(res resource.Resource) {
+func registerResources(reg *TemplateFuncsNamespaceRegistry, res resource.Resource) {
 	f := func() *TemplateFuncsNamespace {
 		ctx, err := resource.New(res)
 		if err != nil {
@@ -25,6 +25,11 @@ func registerResources(res resource.Resource) {
 			[][2]string{},
 		)
 
+		ns.AddMethodMapping(ctx.GetRemote,
+			nil,
+			[][2]string{},
+		)
+
 		ns.AddMethodMapping(ctx.Minify,
 			[]string{"minify"},
 			[][2]string{},
@@ -40,8 +45,13 @@ func registerResources(res resource.Resource) {
 			[][2]string{},
 		)
 
+		ns.AddMethodMapping(ctx.PostProcess,
+			nil,
+			[][2]string{},
+		)
+
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
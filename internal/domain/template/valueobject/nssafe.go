@@ -7,7 +7,7 @@ import (
 
 const nsSafe = "safe"
 
-func registerSafe() {
+func registerSafe(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := safe.New()
 
@@ -58,5 +58,5 @@ func registerSafe() {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
@@ -7,7 +7,7 @@ import (
 
 const nsSite = "site"
 
-func registerSite(svc site.Service) {
+func registerSite(reg *TemplateFuncsNamespaceRegistry, svc site.Service) {
 	f := func() *TemplateFuncsNamespace {
 		s := site.New(svc)
 
@@ -23,5 +23,5 @@ func registerSite(svc site.Service) {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
@@ -7,7 +7,7 @@ import (
 
 const nsStrings = "strings"
 
-func registerStrings(ts strings.Title) {
+func registerStrings(reg *TemplateFuncsNamespaceRegistry, ts strings.Title) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := strings.New(ts)
 
@@ -237,5 +237,5 @@ func registerStrings(ts strings.Title) {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
@@ -0,0 +1,33 @@
+package valueobject
+
+import (
+	"context"
+	"github.com/mdfriday/hugoverse/pkg/template/funcs/templates"
+)
+
+const nsTemplates = "templates"
+
+func registerTemplates(reg *TemplateFuncsNamespaceRegistry, deferred *templates.Deferred) {
+	f := func() *TemplateFuncsNamespace {
+		ctx := templates.New(deferred)
+
+		ns := &TemplateFuncsNamespace{
+			Name:    nsTemplates,
+			Context: func(cctx context.Context, args ...any) (any, error) { return ctx, nil },
+		}
+
+		ns.AddMethodMapping(ctx.Defer,
+			nil,
+			[][2]string{},
+		)
+
+		ns.AddMethodMapping(ctx.DoDefer,
+			nil,
+			[][2]string{},
+		)
+
+		return ns
+	}
+
+	reg.Add(f)
+}
@@ -11,7 +11,7 @@ import (
 
 const nsTime = "time"
 
-func registerTime() {
+func registerTime(reg *TemplateFuncsNamespaceRegistry) {
 	f := func() *TemplateFuncsNamespace {
 		trans := translators.GetTranslator("en") //TODO, make it more extensible
 		formatter := htime.NewTimeFormatter(trans)
@@ -79,5 +79,5 @@ func registerTime() {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
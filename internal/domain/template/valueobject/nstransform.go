@@ -7,7 +7,7 @@ import (
 
 const nsTransform = "transform"
 
-func registerTransform(markdown transform.Markdown) {
+func registerTransform(reg *TemplateFuncsNamespaceRegistry, markdown transform.Markdown) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := transform.New(markdown)
 
@@ -110,5 +110,5 @@ func registerTransform(markdown transform.Markdown) {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
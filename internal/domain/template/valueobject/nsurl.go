@@ -7,7 +7,7 @@ import (
 
 const nsUrls = "urls"
 
-func registerUrls(url urls.URL, ref urls.RefSource) {
+func registerUrls(reg *TemplateFuncsNamespaceRegistry, url urls.URL, ref urls.RefSource) {
 	f := func() *TemplateFuncsNamespace {
 		ctx := urls.New(url, ref)
 
@@ -56,5 +56,5 @@ func registerUrls(url urls.URL, ref urls.RefSource) {
 		return ns
 	}
 
-	AddTemplateFuncsNamespace(f)
+	reg.Add(f)
 }
@@ -0,0 +1,238 @@
+package valueobject
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/mdfriday/hugoverse/pkg/parser/metadecoders"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+)
+
+// ShortcodeSchemaSuffix marks a shortcode schema, a sidecar file declaring
+// the parameters of the shortcode template next to it, e.g.
+// shortcodes/youtube.schema.yaml for shortcodes/youtube.html.
+const ShortcodeSchemaSuffix = ".schema"
+
+// Parameter types of a shortcode schema.
+const (
+	ShortcodeParamString = "string"
+	ShortcodeParamInt    = "int"
+	ShortcodeParamFloat  = "float"
+	ShortcodeParamBool   = "bool"
+	ShortcodeParamAny    = "any"
+)
+
+// ShortcodeSchema declares the parameters of a shortcode.
+type ShortcodeSchema struct {
+	Description string `json:"description,omitempty"`
+
+	// Params in the order of the positional parameters.
+	Params []ShortcodeParam `json:"params"`
+
+	// AdditionalParams allows parameters not declared in Params.
+	AdditionalParams bool `json:"additionalParams,omitempty"`
+}
+
+// ShortcodeParam declares a shortcode parameter.
+type ShortcodeParam struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Required    bool   `json:"required,omitempty"`
+	Default     any    `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// ShortcodeInfo describes a shortcode available to the content.
+type ShortcodeInfo struct {
+	Name string `json:"name"`
+
+	// Inner is set when the shortcode takes inner content and needs a
+	// closing tag.
+	Inner bool `json:"inner"`
+
+	// Embedded is set for the shortcodes shipped with Hugoverse.
+	Embedded bool `json:"embedded"`
+
+	Schema *ShortcodeSchema `json:"schema,omitempty"`
+}
+
+// ShortcodeSchemaName returns the shortcode name for a schema file path
+// relative to the layouts, e.g. shortcodes/youtube.schema.yaml => youtube.
+func ShortcodeSchemaName(name string) (string, bool) {
+	name = strings.TrimPrefix(name, InternalPathPrefix)
+	if !strings.HasPrefix(name, ShortcodesPathPrefix) {
+		return "", false
+	}
+	name = strings.TrimPrefix(name, ShortcodesPathPrefix)
+	base := strings.TrimSuffix(name, path.Ext(name))
+	if !strings.HasSuffix(base, ShortcodeSchemaSuffix) {
+		return "", false
+	}
+	if metadecoders.FormatFromString(name) == "" {
+		return "", false
+	}
+
+	return strings.TrimSuffix(base, ShortcodeSchemaSuffix), true
+}
+
+// LoadShortcodeSchema decodes the schema in the given file, the format
+// is taken from its extension.
+func LoadShortcodeSchema(filename string, b []byte) (*ShortcodeSchema, error) {
+	m, err := metadecoders.Default.UnmarshalToMap(b, metadecoders.FormatFromString(filename))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode shortcode schema %q: %w", filename, err)
+	}
+
+	s := &ShortcodeSchema{}
+	if err := mapstructure.WeakDecode(m, s); err != nil {
+		return nil, fmt.Errorf("failed to decode shortcode schema %q: %w", filename, err)
+	}
+
+	for i, p := range s.Params {
+		if p.Name == "" {
+			return nil, fmt.Errorf("shortcode schema %q: parameter %d has no name", filename, i)
+		}
+		p.Type = strings.ToLower(p.Type)
+		switch p.Type {
+		case "":
+			p.Type = ShortcodeParamAny
+		case ShortcodeParamString, ShortcodeParamInt, ShortcodeParamFloat, ShortcodeParamBool, ShortcodeParamAny:
+		default:
+			return nil, fmt.Errorf("shortcode schema %q: parameter %q has unknown type %q", filename, p.Name, p.Type)
+		}
+		if p.Default != nil {
+			if p.Default, err = p.convert(p.Default); err != nil {
+				return nil, fmt.Errorf("shortcode schema %q: %w", filename, err)
+			}
+		}
+		s.Params[i] = p
+	}
+
+	return s, nil
+}
+
+// Validate checks the parameters of a shortcode invocation, a map of named
+// or a slice of positional parameters, against the schema.
+// It returns the parameters converted to the declared types, with the
+// defaults of the missing ones added.
+// An invocation without parameters gets no defaults, so that it stays
+// neither named nor positional, see .IsNamedParams.
+func (s *ShortcodeSchema) Validate(params any) (any, error) {
+	switch v := params.(type) {
+	case nil:
+		for _, p := range s.Params {
+			if p.Required {
+				return nil, fmt.Errorf("missing required parameter %q", p.Name)
+			}
+		}
+		return nil, nil
+	case map[string]any:
+		return s.validateNamed(v)
+	case []any:
+		return s.validatePositional(v)
+	default:
+		return nil, fmt.Errorf("invalid parameters type %T", params)
+	}
+}
+
+func (s *ShortcodeSchema) validateNamed(params map[string]any) (any, error) {
+	for name := range params {
+		if _, found := s.param(name); !found && !s.AdditionalParams {
+			return nil, fmt.Errorf("unknown parameter %q", name)
+		}
+	}
+
+	validated := make(map[string]any, len(params))
+	for name, v := range params {
+		validated[name] = v
+	}
+
+	for _, p := range s.Params {
+		v, found := validated[p.Name]
+		if !found {
+			if p.Required {
+				return nil, fmt.Errorf("missing required parameter %q", p.Name)
+			}
+			if p.Default != nil {
+				validated[p.Name] = p.Default
+			}
+			continue
+		}
+		cv, err := p.convert(v)
+		if err != nil {
+			return nil, err
+		}
+		validated[p.Name] = cv
+	}
+
+	return validated, nil
+}
+
+func (s *ShortcodeSchema) validatePositional(params []any) (any, error) {
+	if len(params) > len(s.Params) && !s.AdditionalParams {
+		return nil, fmt.Errorf("too many parameters, got %d, expected at most %d", len(params), len(s.Params))
+	}
+
+	validated := make([]any, len(params))
+	copy(validated, params)
+
+	for i, p := range s.Params {
+		if i < len(params) {
+			cv, err := p.convert(params[i])
+			if err != nil {
+				return nil, err
+			}
+			validated[i] = cv
+			continue
+		}
+		if p.Required {
+			return nil, fmt.Errorf("missing required parameter %q at position %d", p.Name, i)
+		}
+	}
+
+	// Fill in the trailing defaults as long as the positions line up.
+	for i := len(params); i < len(s.Params) && s.Params[i].Default != nil; i++ {
+		validated = append(validated, s.Params[i].Default)
+	}
+
+	return validated, nil
+}
+
+func (s *ShortcodeSchema) param(name string) (ShortcodeParam, bool) {
+	for _, p := range s.Params {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return ShortcodeParam{}, false
+}
+
+func (p ShortcodeParam) convert(v any) (any, error) {
+	var (
+		cv  any
+		err error
+	)
+	switch p.Type {
+	case ShortcodeParamString:
+		cv, err = cast.ToStringE(v)
+	case ShortcodeParamInt:
+		if f, ok := v.(float64); ok && f != float64(int(f)) {
+			err = fmt.Errorf("%v is not an integer", f)
+			break
+		}
+		cv, err = cast.ToIntE(v)
+	case ShortcodeParamFloat:
+		cv, err = cast.ToFloat64E(v)
+	case ShortcodeParamBool:
+		cv, err = cast.ToBoolE(v)
+	default:
+		cv = v
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parameter %q: expected %s, got %T %v", p.Name, p.Type, v, v)
+	}
+
+	return cv, nil
+}
@@ -0,0 +1,74 @@
+package valueobject
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestShortcodeSchemaName(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		name   string
+		expect string
+		ok     bool
+	}{
+		{"shortcodes/youtube.schema.yaml", "youtube", true},
+		{"_internal/shortcodes/figure.schema.toml", "figure", true},
+		{"shortcodes/youtube.html", "", false},
+		{"shortcodes/youtube.schema.html", "", false},
+		{"partials/youtube.schema.yaml", "", false},
+	} {
+		name, ok := ShortcodeSchemaName(test.name)
+		c.Assert(ok, qt.Equals, test.ok, qt.Commentf(test.name))
+		c.Assert(name, qt.Equals, test.expect)
+	}
+}
+
+func TestShortcodeSchemaValidate(t *testing.T) {
+	c := qt.New(t)
+
+	schema, err := LoadShortcodeSchema("video.schema.yaml", []byte(`
+params:
+  - name: id
+    type: string
+    required: true
+  - name: width
+    type: int
+    default: 640
+  - name: autoplay
+    type: bool
+`))
+	c.Assert(err, qt.IsNil)
+
+	v, err := schema.Validate(map[string]any{"id": 42, "autoplay": "true"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.DeepEquals, map[string]any{"id": "42", "width": 640, "autoplay": true})
+
+	v, err = schema.Validate([]any{"abc", "320"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.DeepEquals, []any{"abc", 320})
+
+	v, err = schema.Validate([]any{"abc"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.DeepEquals, []any{"abc", 640})
+
+	_, err = schema.Validate(nil)
+	c.Assert(err, qt.ErrorMatches, `missing required parameter "id"`)
+
+	// No defaults for invocations without parameters.
+	optional := &ShortcodeSchema{Params: schema.Params[1:]}
+	v, err = optional.Validate(nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.IsNil)
+	_, err = schema.Validate(map[string]any{"id": "a", "height": 2})
+	c.Assert(err, qt.ErrorMatches, `unknown parameter "height"`)
+	_, err = schema.Validate(map[string]any{"id": "a", "width": 1.5})
+	c.Assert(err, qt.ErrorMatches, `parameter "width": expected int, got float64 1.5`)
+	_, err = schema.Validate([]any{"a", 1, true, "x"})
+	c.Assert(err, qt.ErrorMatches, `too many parameters.*`)
+
+	_, err = LoadShortcodeSchema("bad.schema.json", []byte(`{"params": [{"name": "a", "type": "date"}]}`))
+	c.Assert(err, qt.ErrorMatches, `.*unknown type "date"`)
+}
@@ -0,0 +1,90 @@
+package admin
+
+import (
+	"bytes"
+	"html/template"
+)
+
+// BuildError is the view model of the build error explorer.
+type BuildError struct {
+	Error string
+
+	// Template and DataType are set for template execution errors.
+	Template string
+	DataType string
+
+	Files []BuildErrorFile
+}
+
+// BuildErrorFile is a source file the error points into.
+type BuildErrorFile struct {
+	Filename     string
+	LineNumber   int
+	ColumnNumber int
+
+	Lines []BuildErrorLine
+}
+
+// BuildErrorLine is a source line surrounding the error.
+type BuildErrorLine struct {
+	Number  int
+	Text    string
+	IsError bool
+}
+
+// BuildErrorExplorer returns the standalone page showing why a preview build failed.
+func (v *View) BuildErrorExplorer(e BuildError) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	tmpl := template.Must(template.New("buildError").Parse(buildErrorHTML))
+	if err := tmpl.Execute(buf, e); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+const buildErrorHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8" />
+<title>Preview: Build Error</title>
+<style type="text/css">
+    body { font-family: system-ui, -apple-system, "Segoe UI", Roboto, sans-serif; font-size: 14px; background-color: #272a36; color: #f8f8f2; }
+    main { max-width: 100ch; padding: 2ch; margin: auto; }
+    hr { margin-bottom: 1rem; border: none; height: 1px; background-color: #3d3d3d; }
+    pre, code { white-space: pre-wrap; word-wrap: break-word; font-family: SFMono-Regular, Menlo, Monaco, Consolas, "Liberation Mono", monospace; }
+    .error pre { line-height: 1.5; color: #ff5555; }
+    .meta td { padding-right: 2ch; }
+    .meta td:first-child { color: #7c7c7c; }
+    .filename { color: #eef78a; font-size: 0.9rem; line-height: 1.5; }
+    .source { border-collapse: collapse; width: 100%; background-color: #1e1f29; }
+    .source td { padding: 0 1ch; vertical-align: top; }
+    .source .lno { color: #6272a4; text-align: right; user-select: none; width: 1%; }
+    .source .hl { background-color: #44475a; }
+    a { color: #0594cb; text-decoration: none; }
+    a:hover { color: #ccc; }
+</style>
+</head>
+<body>
+<main>
+    <div class="error"><pre>{{ .Error }}</pre></div>
+    {{ if .Template }}
+    <table class="meta">
+        <tr><td>Template</td><td><code>{{ .Template }}</code></td></tr>
+        <tr><td>Data context</td><td><code>{{ .DataType }}</code></td></tr>
+    </table>
+    {{ end }}
+    <hr />
+    {{ range .Files }}
+    <code class="filename">{{ printf "%s:%d:%d" .Filename .LineNumber .ColumnNumber }}:</code>
+    <table class="source">
+        {{ range .Lines }}
+        <tr{{ if .IsError }} class="hl"{{ end }}><td class="lno"><pre>{{ .Number }}</pre></td><td><pre>{{ .Text }}</pre></td></tr>
+        {{ end }}
+    </table>
+    <hr />
+    {{ end }}
+    <a href="">Reload Page</a>
+</main>
+</body>
+</html>
+`
@@ -2,17 +2,20 @@ package admin
 
 import (
 	"bytes"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/ratelimit"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/record/analytics"
 	"html/template"
 )
 
-// Dashboard returns the AdminView view with analytics dashboard
-func (v *View) Dashboard() ([]byte, error) {
+// Dashboard returns the AdminView view with analytics dashboard, and the
+// clients throttled lately
+func (v *View) Dashboard(throttled []ratelimit.Throttled) ([]byte, error) {
 	buf := &bytes.Buffer{}
 	data, err := analytics.ChartData()
 	if err != nil {
 		return nil, err
 	}
+	data["throttled"] = throttled
 
 	tmpl := template.Must(template.New("analytics").Parse(analyticsHTML))
 	err = tmpl.Execute(buf, data)
@@ -193,6 +193,30 @@ var analyticsHTML = `
     </script>
 </div>
 </div>
+{{ with .throttled }}
+<div class="card">
+<div class="card-content">
+    <div class="card-title">Throttled Clients</div>
+    <table class="striped">
+        <thead>
+            <tr><th>Client</th><th>Budget</th><th>Refused</th><th>First</th><th>Last</th><th>Until</th></tr>
+        </thead>
+        <tbody>
+        {{ range . }}
+            <tr>
+                <td><code>{{ .Client }}</code></td>
+                <td>{{ .Budget }}</td>
+                <td>{{ .Count }}</td>
+                <td>{{ .First.UTC.Format "2006-01-02 15:04:05" }}</td>
+                <td>{{ .Last.UTC.Format "2006-01-02 15:04:05" }}</td>
+                <td>{{ .Until.UTC.Format "2006-01-02 15:04:05" }}</td>
+            </tr>
+        {{ end }}
+        </tbody>
+    </table>
+</div>
+</div>
+{{ end }}
 </div>
 `
 
@@ -12,6 +12,16 @@ import (
 
 const managerHTML = `
 <div class="card editor">
+	{{ if .Errors }}
+	<div class="card-panel red lighten-4 field-errors">
+		<b>Please correct the fields below.</b>
+		<ul>
+		{{ range $field, $msgs := .Errors }}{{ range $msgs }}
+			<li>{{ $field }} {{ . }}</li>
+		{{ end }}{{ end }}
+		</ul>
+	</div>
+	{{ end }}
     <form method="post" action="/admin/edit" enctype="multipart/form-data">
 		<input type="hidden" name="uuid" value="{{.UUID}}"/>
 		<input type="hidden" name="id" value="{{.ID}}"/>
@@ -22,6 +32,23 @@ const managerHTML = `
 	</form>
 	<script>
 		$(function() {
+			// show the errors of the fields inline, after their input
+			var fieldErrors = {{ .Errors }};
+			$.each(fieldErrors || {}, function(field, msgs) {
+				var $input = $('form [name="' + field + '"], form [name^="' + field + '."]').first();
+				if ($input.length === 0) {
+					return;
+				}
+				$input.addClass('invalid');
+				var $msg = $('<span class="helper-text red-text field-error"></span>').text(field + ' ' + msgs.join(', '));
+				var $field = $input.closest('.input-field');
+				if ($field.length) {
+					$field.append($msg);
+				} else {
+					$input.after($msg);
+				}
+			});
+
 			// remove all bad chars from all inputs in the form, except file fields
 			$('form input:not([type=file]), form textarea').on('blur', function(e) {
 				var val = e.target.value;
@@ -119,10 +146,18 @@ type manager struct {
 	Kind   string
 	Slug   string
 	Editor template.HTML
+	// Errors are the messages of the fields failing validation, by field.
+	Errors map[string][]string
 }
 
 // Manage ...
 func Manage(e editor.Editable, typeName string) ([]byte, error) {
+	return ManageInvalid(e, typeName, nil)
+}
+
+// ManageInvalid is Manage for content failing validation, showing the
+// messages of errs, by field, inline in the editor.
+func ManageInvalid(e editor.Editable, typeName string, errs map[string][]string) ([]byte, error) {
 	v, err := e.MarshalEditor()
 	if err != nil {
 		return nil, fmt.Errorf("couldn't marshal editor for content %s. %s", typeName, err.Error())
@@ -150,6 +185,7 @@ func Manage(e editor.Editable, typeName string) ([]byte, error) {
 		Status: st.ItemStatus(),
 		Slug:   s.ItemSlug(),
 		Editor: template.HTML(v),
+		Errors: errs,
 	}
 
 	// execute html template into buffer for func return val
@@ -0,0 +1,426 @@
+package database
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mdfriday/hugoverse/pkg/db"
+)
+
+// BackupVersion is the version of the archives written by Backup.
+const BackupVersion = 1
+
+const (
+	manifestName = "manifest.json"
+	storeName    = "system.db"
+	searchDir    = "Search"
+	usersDir     = "users"
+	uploadsDir   = "uploads"
+	schemasDir   = "schemas"
+)
+
+// Manifest describes a backup archive, and is its last entry.
+type Manifest struct {
+	Version int       `json:"version"`
+	Created time.Time `json:"created"`
+
+	// Users are the data dirs of the users whose stores are archived.
+	Users []string `json:"users"`
+
+	// Uploads is the number of uploaded files archived.
+	Uploads int `json:"uploads"`
+
+	// Schemas is the number of content type schema files archived.
+	Schemas int `json:"schemas"`
+}
+
+// Backup writes a gzipped tar archive of the admin store, every user store,
+// the content type schemas and the uploads in uploadDir to w, while the
+// server keeps serving. Each store is archived as of a read transaction of
+// its own, taken when it is its turn to be written and ended once it is:
+// every store is consistent in itself, but a write to two stores between
+// their snapshots may be in the archive for one of them only.
+//
+// The search indexes are left out. They hold nothing the stores don't, and
+// bleve writes them in place with no snapshot to copy from, so restoring
+// rebuilds them from the restored stores instead.
+func (d *Database) Backup(w io.Writer, uploadDir string) (*Manifest, error) {
+	users, err := d.UserDirs()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{
+		Version: BackupVersion,
+		Created: time.Now().UTC(),
+		Users:   users,
+	}
+
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	if err := writeStore(tw, storeName, d.adminStore, m.Created); err != nil {
+		return nil, err
+	}
+	for _, ud := range users {
+		us, err := d.openUserStore(ud)
+		if err != nil {
+			return nil, fmt.Errorf("open store of user %s: %w", ud, err)
+		}
+		if err := writeStore(tw, path.Join(usersDir, ud, storeName), us, m.Created); err != nil {
+			return nil, err
+		}
+	}
+
+	if m.Schemas, err = writeDir(tw, filepath.Join(d.dataDir, schemasDir), schemasDir); err != nil {
+		return nil, err
+	}
+	if m.Uploads, err = writeDir(tw, uploadDir, uploadsDir); err != nil {
+		return nil, err
+	}
+
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    manifestName,
+		Mode:    0600,
+		Size:    int64(len(b)),
+		ModTime: m.Created,
+	}); err != nil {
+		return nil, err
+	}
+	if _, err := tw.Write(b); err != nil {
+		return nil, err
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gw.Close(); err != nil {
+		return nil, err
+	}
+
+	return m, nil
+}
+
+// writeStore writes a snapshot of s to tw as name.
+func writeStore(tw *tar.Writer, name string, s *db.Store, modTime time.Time) error {
+	snap, err := s.Snapshot()
+	if err != nil {
+		return err
+	}
+	defer snap.Close()
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    snap.Size(),
+		ModTime: modTime,
+	}); err != nil {
+		return err
+	}
+	if _, err := snap.WriteTo(tw); err != nil {
+		return fmt.Errorf("write %s: %w", name, err)
+	}
+	return nil
+}
+
+// writeDir writes the files in dir to tw under prefix, and returns their
+// number.
+func writeDir(tw *tar.Writer, dir, prefix string) (int, error) {
+	count := 0
+	err := filepath.WalkDir(dir, func(p string, e fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && p == dir {
+			return filepath.SkipDir
+		}
+		if err != nil {
+			return err
+		}
+		if !e.Type().IsRegular() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Open(p)
+		if errors.Is(err, fs.ErrNotExist) {
+			// Deleted since the walk began.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil {
+			return err
+		}
+		if err := tw.WriteHeader(&tar.Header{
+			Name:    path.Join(prefix, filepath.ToSlash(rel)),
+			Mode:    0644,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		}); err != nil {
+			return err
+		}
+		if _, err := io.CopyN(tw, f, info.Size()); err != nil {
+			return fmt.Errorf("write %s: %w", path.Join(prefix, filepath.ToSlash(rel)), err)
+		}
+
+		count++
+		return nil
+	})
+
+	return count, err
+}
+
+// UserDirs returns the data dirs of the users with a store.
+func (d *Database) UserDirs() ([]string, error) {
+	return userDirs(d.dataDir)
+}
+
+func userDirs(dataDir string) ([]string, error) {
+	entries, err := os.ReadDir(dataDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []string
+	for _, e := range entries {
+		if !e.IsDir() || !isUserDir(e.Name()) {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dataDir, e.Name(), storeName)); err == nil {
+			users = append(users, e.Name())
+		}
+	}
+	return users, nil
+}
+
+// isUserDir reports whether name is a user data dir, the md5 of an email.
+func isUserDir(name string) bool {
+	b, err := hex.DecodeString(name)
+	return err == nil && len(b) == 16 && strings.ToLower(name) == name
+}
+
+// Restore replaces the admin store, the user stores, the content type
+// schemas and the uploads in dataDir and uploadDir with those of the
+// archive read from r, once it is read in full and every store in it is
+// checked. The replaced data and the stale search indexes are moved to a
+// pre-restore dir in dataDir, returned with the manifest. Should a move
+// fail, those done are undone, leaving the data as it was. No store of
+// dataDir may be open.
+func Restore(dataDir, uploadDir string, r io.Reader) (*Manifest, string, error) {
+	staging, err := os.MkdirTemp(dataDir, ".restore-")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.RemoveAll(staging)
+
+	m, files, err := extract(staging, r)
+	if err != nil {
+		return nil, "", fmt.Errorf("read archive: %w", err)
+	}
+	if err := m.check(staging, files); err != nil {
+		return nil, "", fmt.Errorf("invalid archive: %w", err)
+	}
+
+	current, err := userDirs(dataDir)
+	if err != nil {
+		return nil, "", err
+	}
+
+	aside, err := os.MkdirTemp(dataDir, fmt.Sprintf(".pre-restore-%d-", time.Now().Unix()))
+	if err != nil {
+		return nil, "", err
+	}
+
+	moves := [][2]string{
+		{filepath.Join(dataDir, storeName), filepath.Join(aside, storeName)},
+		{filepath.Join(dataDir, searchDir), filepath.Join(aside, searchDir)},
+		{filepath.Join(dataDir, schemasDir), filepath.Join(aside, schemasDir)},
+		{uploadDir, filepath.Join(aside, uploadsDir)},
+	}
+	for _, ud := range current {
+		moves = append(moves, [2]string{filepath.Join(dataDir, ud), filepath.Join(aside, ud)})
+	}
+	moves = append(moves,
+		[2]string{filepath.Join(staging, storeName), filepath.Join(dataDir, storeName)},
+		[2]string{filepath.Join(staging, schemasDir), filepath.Join(dataDir, schemasDir)},
+		[2]string{filepath.Join(staging, uploadsDir), uploadDir},
+	)
+	for _, ud := range m.Users {
+		moves = append(moves, [2]string{filepath.Join(staging, usersDir, ud), filepath.Join(dataDir, ud)})
+	}
+
+	var done [][2]string
+	for _, mv := range moves {
+		err := rename(mv[0], mv[1])
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			if uerr := undo(done); uerr != nil {
+				return nil, aside, fmt.Errorf("restore stopped half way, the previous data is in %s: %w", aside, errors.Join(err, uerr))
+			}
+			_ = os.Remove(aside)
+			return nil, "", fmt.Errorf("restore failed, the previous data is back in place: %w", err)
+		}
+		done = append(done, mv)
+	}
+
+	return m, aside, nil
+}
+
+// rename is os.Rename, replaced in tests.
+var rename = os.Rename
+
+// undo moves back what moves moved, the last first.
+func undo(moves [][2]string) error {
+	var errs []error
+	for i := len(moves) - 1; i >= 0; i-- {
+		if err := rename(moves[i][1], moves[i][0]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// extract extracts the archive read from r to dir, returning its manifest
+// and the number of files in it by top dir, e.g. uploads.
+func extract(dir string, r io.Reader) (*Manifest, map[string]int, error) {
+	gr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer gr.Close()
+
+	var m *Manifest
+	files := make(map[string]int)
+	tr := tar.NewReader(gr)
+	for {
+		h, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if h.Typeflag == tar.TypeDir {
+			continue
+		}
+		if h.Typeflag != tar.TypeReg || !archived(h.Name) {
+			return nil, nil, fmt.Errorf("unexpected entry %q", h.Name)
+		}
+
+		if h.Name == manifestName {
+			m = &Manifest{}
+			if err := json.NewDecoder(tr).Decode(m); err != nil {
+				return nil, nil, fmt.Errorf("decode manifest: %w", err)
+			}
+			continue
+		}
+
+		name := filepath.Join(dir, filepath.FromSlash(h.Name))
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			return nil, nil, err
+		}
+		f, err := os.OpenFile(name, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err != nil {
+			return nil, nil, err
+		}
+		_, err = io.Copy(f, tr)
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if top, _, ok := strings.Cut(h.Name, "/"); ok {
+			files[top]++
+		}
+	}
+
+	if m == nil {
+		return nil, nil, errors.New("no manifest")
+	}
+	return m, files, nil
+}
+
+// archived reports whether name is the name of an entry Backup writes.
+func archived(name string) bool {
+	if !fs.ValidPath(name) {
+		return false
+	}
+	switch parts := strings.Split(name, "/"); {
+	case name == manifestName || name == storeName:
+		return true
+	case parts[0] == usersDir:
+		return len(parts) == 3 && isUserDir(parts[1]) && parts[2] == storeName
+	case parts[0] == uploadsDir:
+		return len(parts) > 1
+	case parts[0] == schemasDir:
+		return len(parts) == 2
+	}
+	return false
+}
+
+func (m *Manifest) check(dir string, files map[string]int) error {
+	if m.Version < 1 || m.Version > BackupVersion {
+		return fmt.Errorf("unsupported version %d", m.Version)
+	}
+	if files[uploadsDir] != m.Uploads {
+		return fmt.Errorf("%d uploads, the manifest lists %d", files[uploadsDir], m.Uploads)
+	}
+	if files[schemasDir] != m.Schemas {
+		return fmt.Errorf("%d schemas, the manifest lists %d", files[schemasDir], m.Schemas)
+	}
+
+	if err := checkStore(dir, storeName); err != nil {
+		return err
+	}
+
+	archivedUsers, err := os.ReadDir(filepath.Join(dir, usersDir))
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if len(archivedUsers) != len(m.Users) {
+		return fmt.Errorf("%d user stores, the manifest lists %d", len(archivedUsers), len(m.Users))
+	}
+	for _, ud := range m.Users {
+		if !isUserDir(ud) {
+			return fmt.Errorf("invalid user dir %q", ud)
+		}
+		if err := checkStore(dir, path.Join(usersDir, ud, storeName)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkStore checks the store extracted to name in dir.
+func checkStore(dir, name string) error {
+	p := filepath.Join(dir, filepath.FromSlash(name))
+	if _, err := os.Stat(p); errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no %s", name)
+	}
+	return db.Check(p)
+}
@@ -0,0 +1,183 @@
+package database
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/pkg/db"
+)
+
+func TestBackupRestore(t *testing.T) {
+	c := qt.New(t)
+
+	// open opens the data in dir with a job of id in the admin store.
+	open := func(dir, ud string) *Database {
+		d, err := New(dir)
+		c.Assert(err, qt.IsNil)
+		c.Assert(d.StartAdminDatabase(nil), qt.IsNil)
+		c.Assert(d.StartUserDir(ud), qt.IsNil)
+		return d
+	}
+	job := func(dir, ud, id string) []byte {
+		d := open(dir, ud)
+		defer d.Close()
+		b, err := d.GetJob(id)
+		c.Assert(err, qt.IsNil)
+		return b
+	}
+	ud := hashEmailMD5("a@example.org")
+
+	src := t.TempDir()
+	uploads := filepath.Join(t.TempDir(), "uploads")
+	d := open(src, ud)
+	c.Assert(d.PutJob("j1", []byte(`{"id":"j1"}`)), qt.IsNil)
+	c.Assert(d.NewUpload("1", "a.png", []byte(`{"name":"a.png"}`)), qt.IsNil)
+	for name, content := range map[string]string{
+		filepath.Join(uploads, "2024", "10", "a.png"): "png",
+		filepath.Join(src, schemasDir, "Note.json"):   "{}",
+	} {
+		c.Assert(os.MkdirAll(filepath.Dir(name), 0o755), qt.IsNil)
+		c.Assert(os.WriteFile(name, []byte(content), 0o644), qt.IsNil)
+	}
+
+	var archive bytes.Buffer
+	m, err := d.Backup(&archive, uploads)
+	c.Assert(err, qt.IsNil)
+	c.Assert(m.Users, qt.DeepEquals, []string{ud})
+	c.Assert(m.Uploads, qt.Equals, 1)
+	c.Assert(m.Schemas, qt.Equals, 1)
+	d.Close()
+
+	// Restore over other data, kept aside.
+	dst := t.TempDir()
+	dstUploads := filepath.Join(t.TempDir(), "uploads")
+	other := hashEmailMD5("b@example.org")
+	d = open(dst, other)
+	c.Assert(d.PutJob("old", []byte(`{"id":"old"}`)), qt.IsNil)
+	d.Close()
+
+	m, aside, err := Restore(dst, dstUploads, bytes.NewReader(archive.Bytes()))
+	c.Assert(err, qt.IsNil)
+	c.Assert(m.Users, qt.DeepEquals, []string{ud})
+	c.Assert(string(job(dst, ud, "j1")), qt.Equals, `{"id":"j1"}`)
+	c.Assert(job(dst, ud, "old"), qt.IsNil)
+	d = open(dst, ud)
+	upload, err := d.GetUpload("1")
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(upload), qt.Equals, `{"name":"a.png"}`)
+	d.Close()
+	for name, content := range map[string]string{
+		filepath.Join(dstUploads, "2024", "10", "a.png"): "png",
+		filepath.Join(dst, schemasDir, "Note.json"):      "{}",
+	} {
+		b, err := os.ReadFile(name)
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(b), qt.Equals, content)
+	}
+	_, err = os.Stat(filepath.Join(aside, other, storeName))
+	c.Assert(err, qt.IsNil)
+	_, err = os.Stat(filepath.Join(dst, other))
+	c.Assert(errors.Is(err, os.ErrNotExist), qt.IsTrue)
+
+	// A failed move undoes the others.
+	c.Assert(os.WriteFile(filepath.Join(dstUploads, "kept.txt"), []byte("kept"), 0o644), qt.IsNil)
+	moved := 0
+	rename = func(from, to string) error {
+		if moved++; moved == 6 {
+			return errors.New("disk full")
+		}
+		return os.Rename(from, to)
+	}
+	defer func() { rename = os.Rename }()
+	_, aside, err = Restore(dst, dstUploads, bytes.NewReader(archive.Bytes()))
+	c.Assert(err, qt.ErrorMatches, "restore failed, the previous data is back in place: disk full")
+	c.Assert(aside, qt.Equals, "")
+	rename = os.Rename
+	c.Assert(string(job(dst, ud, "j1")), qt.Equals, `{"id":"j1"}`)
+	b, err := os.ReadFile(filepath.Join(dstUploads, "kept.txt"))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(b), qt.Equals, "kept")
+	entries, err := os.ReadDir(dst)
+	c.Assert(err, qt.IsNil)
+	var asides int
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".pre-restore-") {
+			asides++
+		}
+	}
+	c.Assert(asides, qt.Equals, 1)
+}
+
+func TestRestoreRejects(t *testing.T) {
+	c := qt.New(t)
+
+	type entry struct {
+		name, body string
+		typ        byte
+	}
+	archive := func(entries ...entry) *bytes.Buffer {
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		for _, e := range entries {
+			h := &tar.Header{Name: e.name, Mode: 0o644, Size: int64(len(e.body)), Typeflag: e.typ}
+			if e.typ == tar.TypeSymlink {
+				h.Size = 0
+				h.Linkname = "/etc/passwd"
+			}
+			c.Assert(tw.WriteHeader(h), qt.IsNil)
+			_, err := tw.Write([]byte(e.body))
+			c.Assert(err, qt.IsNil)
+		}
+		c.Assert(tw.Close(), qt.IsNil)
+		c.Assert(gw.Close(), qt.IsNil)
+		return &buf
+	}
+	manifest := func(body string) entry {
+		return entry{name: manifestName, body: body, typ: tar.TypeReg}
+	}
+	storeDir := t.TempDir()
+	s, err := db.NewStore(storeDir, nil)
+	c.Assert(err, qt.IsNil)
+	c.Assert(s.Close(), qt.IsNil)
+	b, err := os.ReadFile(filepath.Join(storeDir, storeName))
+	c.Assert(err, qt.IsNil)
+	store := func(name string) entry {
+		return entry{name: name, body: string(b), typ: tar.TypeReg}
+	}
+	ud := hashEmailMD5("a@example.org")
+
+	for _, test := range []struct {
+		entries []entry
+		err     string
+	}{
+		{[]entry{{name: "../system.db", typ: tar.TypeReg}}, `read archive: unexpected entry "../system.db"`},
+		{[]entry{{name: "users/bob/system.db", typ: tar.TypeReg}}, `read archive: unexpected entry "users/bob/system.db"`},
+		{[]entry{{name: "uploads/a.png", typ: tar.TypeSymlink}}, `read archive: unexpected entry "uploads/a.png"`},
+		{[]entry{{name: "notes.txt", typ: tar.TypeReg}}, `read archive: unexpected entry "notes.txt"`},
+		{[]entry{{name: "uploads/a.png", body: "png", typ: tar.TypeReg}}, "read archive: no manifest"},
+		{[]entry{manifest(`{"version":2}`)}, "invalid archive: unsupported version 2"},
+		{[]entry{manifest(`{"version":1,"uploads":1}`)}, "invalid archive: 0 uploads, the manifest lists 1"},
+		{[]entry{manifest(`{"version":1}`)}, "invalid archive: no system.db"},
+		{[]entry{store(storeName), manifest(`{"version":1,"users":["` + ud + `"]}`)}, "invalid archive: 0 user stores, the manifest lists 1"},
+		{[]entry{store(storeName), store("users/" + ud + "/system.db"), manifest(`{"version":1,"users":["0123456789abcdef0123456789abcdef"]}`)}, "invalid archive: no users/0123456789abcdef0123456789abcdef/system.db"},
+		{[]entry{store(storeName), store("users/" + ud + "/system.db"), manifest(`{"version":1,"users":["..` + `"]}`)}, `invalid archive: invalid user dir ".."`},
+		{[]entry{{name: storeName, body: "not a store", typ: tar.TypeReg}, manifest(`{"version":1}`)}, "invalid archive: .*"},
+	} {
+		dir := t.TempDir()
+		_, _, err := Restore(dir, filepath.Join(dir, "uploads"), archive(test.entries...))
+		c.Assert(err, qt.ErrorMatches, test.err, qt.Commentf("%v", test.entries))
+
+		// Nothing was touched.
+		entries, err := os.ReadDir(dir)
+		c.Assert(err, qt.IsNil)
+		c.Assert(entries, qt.HasLen, 0)
+	}
+}
@@ -0,0 +1,156 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/pkg/db"
+)
+
+// store is what the content writes need of a db.Store, or of a db.Tx to
+// have several of them applied at once.
+type store interface {
+	Get(item db.Item) ([]byte, error)
+	Set(item db.Item) error
+	Delete(item db.Item) error
+	NextSequence(item db.BucketItem) (uint64, error)
+	SetIndex(item db.KeyValue) error
+	RemoveIndex(slug string) error
+	CheckSlugForDuplicate(slug string) (string, error)
+}
+
+// contentTx is the repository.Tx of Batch.
+type contentTx struct {
+	s store
+}
+
+func (t *contentTx) GetContent(namespace string, id string) ([]byte, error) {
+	return getContent(t.s, namespace, id)
+}
+
+func (t *contentTx) PutContent(ci any, data []byte) error {
+	return putContent(t.s, ci, data)
+}
+
+func (t *contentTx) NewContent(ci any, data []byte) error {
+	return newContent(t.s, ci, data)
+}
+
+func (t *contentTx) DeleteContent(namespace string, id string, slug string, hash string) error {
+	return deleteContent(t.s, namespace, id, slug, hash)
+}
+
+func (t *contentTx) NextContentId(ns string) (uint64, error) {
+	return t.s.NextSequence(&item{bucket: ns})
+}
+
+func (t *contentTx) CheckSlugForDuplicate(namespace string, slug string) (string, error) {
+	return t.s.CheckSlugForDuplicate(slug)
+}
+
+func getContent(s store, namespace string, id string) ([]byte, error) {
+	return s.Get(
+		&item{
+			bucket: namespace,
+			key:    id,
+		})
+}
+
+func deleteContent(s store, namespace string, id string, slug string, hash string) error {
+	if err := s.Delete(&item{bucket: namespace, key: id}); err != nil {
+		return err
+	}
+
+	// The slugs of pending items are indexed with those of their type.
+	contentType, _, _ := strings.Cut(namespace, ItemBucketPrefix)
+	if err := s.Delete(&item{bucket: bucketNameWithIndex(contentType), key: slug}); err != nil {
+		return err
+	}
+
+	if err := s.RemoveIndex(slug); err != nil {
+		return err
+	}
+
+	if hash != "" {
+		if err := s.RemoveIndex(fmt.Sprintf("%s:%s", namespace, hash)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func putContent(s store, ci any, data []byte) error {
+	cii, ok := ci.(content.Identifiable)
+	if !ok {
+		return errors.New("invalid content type")
+	}
+	id := cii.ItemID()
+	ns := cii.ItemName()
+
+	cis, ok := ci.(content.Statusable)
+	if !ok {
+		return errors.New("invalid content type")
+	}
+	status := cis.ItemStatus()
+
+	bucket := ns
+	if !(status == content.Public || status == "") {
+		bucket = fmt.Sprintf("%s%s", ns, bucketNameWithPrefix(string(status)))
+	}
+
+	if err := s.Set(
+		&item{
+			bucket: bucket,
+			key:    strconv.FormatInt(int64(id), 10),
+			value:  data,
+		}); err != nil {
+		return err
+	}
+
+	ciSlug, ok := ci.(content.Sluggable)
+	if ok {
+		if err := s.Set(
+			&item{
+				bucket: bucketNameWithIndex(ns),
+				key:    ciSlug.ItemSlug(),
+				value:  data,
+			}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func newContent(s store, ci any, data []byte) error {
+	if err := putContent(s, ci, data); err != nil {
+		return err
+	}
+
+	cii, ok := ci.(content.Identifiable)
+	if !ok {
+		return errors.New("invalid content type")
+	}
+	id := cii.ItemID()
+	ns := cii.ItemName()
+
+	ciSlug, ok := ci.(content.Sluggable)
+	if !ok {
+		return errors.New("invalid content type")
+	}
+	if err := s.SetIndex(newKeyValueItem(ciSlug.ItemSlug(), fmt.Sprintf("%s:%d", ns, id))); err != nil {
+		return err
+	}
+	ciHash, ok := ci.(content.Hashable)
+	if ok {
+		if err := s.SetIndex(newKeyValueItem(fmt.Sprintf("%s:%s", ns, ciHash.ItemHash()), fmt.Sprintf("%d", id))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/repository"
 	"github.com/mdfriday/hugoverse/pkg/db"
 	"github.com/mdfriday/hugoverse/pkg/loggers"
 	"path"
@@ -15,7 +16,7 @@ import (
 var (
 	adminOriginBuckets = []string{
 		"__config", "__users",
-		"__contentIndex",
+		"__contentIndex", "__jobs",
 	}
 
 	userBuckets = []string{
@@ -61,9 +62,12 @@ func (d *Database) RegisterContentBuckets(contentTypeNames []string) {
 }
 
 func (d *Database) Close() {
-	if d.userStore != nil {
-		d.userStore.Close()
+	// The user stores are shared through the cache of db, close them all
+	// rather than leave closed ones there.
+	if err := db.CloseUserStores(); err != nil {
+		d.log.Errorln("Couldn't close user stores.", err)
 	}
+	d.userStore = nil
 	if d.adminStore != nil {
 		d.adminStore.Close()
 	}
@@ -92,33 +96,11 @@ func (d *Database) ContentByPrefix(namespace, prefix string) ([][]byte, error) {
 }
 
 func (d *Database) GetContent(namespace string, id string) ([]byte, error) {
-	return d.getStore(namespace).Get(
-		&item{
-			bucket: namespace,
-			key:    id,
-		})
+	return getContent(d.getStore(namespace), namespace, id)
 }
 
 func (d *Database) DeleteContent(namespace string, id string, slug string, hash string) error {
-	if err := d.getStore(namespace).Delete(&item{bucket: namespace, key: id}); err != nil {
-		return err
-	}
-
-	if err := d.getStore(namespace).Delete(&item{bucket: bucketNameWithIndex(namespace), key: slug}); err != nil {
-		return err
-	}
-
-	if err := d.getStore(namespace).RemoveIndex(slug); err != nil {
-		return err
-	}
-
-	if hash != "" {
-		if err := d.getStore(namespace).RemoveIndex(fmt.Sprintf("%s:%s", namespace, hash)); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return deleteContent(d.getStore(namespace), namespace, id, slug, hash)
 }
 
 func (d *Database) PutContent(ci any, data []byte) error {
@@ -126,71 +108,15 @@ func (d *Database) PutContent(ci any, data []byte) error {
 	if !ok {
 		return errors.New("invalid content type")
 	}
-	id := cii.ItemID()
-	ns := cii.ItemName()
-
-	cis, ok := ci.(content.Statusable)
-	if !ok {
-		return errors.New("invalid content type")
-	}
-	status := cis.ItemStatus()
-
-	bucket := ns
-	if !(status == content.Public || status == "") {
-		bucket = fmt.Sprintf("%s%s", ns, bucketNameWithPrefix(string(status)))
-	}
-
-	if err := d.getStore(ns).Set(
-		&item{
-			bucket: bucket,
-			key:    strconv.FormatInt(int64(id), 10),
-			value:  data,
-		}); err != nil {
-		return err
-	}
-
-	ciSlug, ok := ci.(content.Sluggable)
-	if ok {
-		if err := d.getStore(ns).Set(
-			&item{
-				bucket: bucketNameWithIndex(ns),
-				key:    ciSlug.ItemSlug(),
-				value:  data,
-			}); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return putContent(d.getStore(cii.ItemName()), ci, data)
 }
 
 func (d *Database) NewContent(ci any, data []byte) error {
-	if err := d.PutContent(ci, data); err != nil {
-		return err
-	}
-
 	cii, ok := ci.(content.Identifiable)
 	if !ok {
 		return errors.New("invalid content type")
 	}
-	id := cii.ItemID()
-	ns := cii.ItemName()
-
-	ciSlug, ok := ci.(content.Sluggable)
-	if !ok {
-		return errors.New("invalid content type")
-	}
-	if err := d.getStore(ns).SetIndex(newKeyValueItem(ciSlug.ItemSlug(), fmt.Sprintf("%s:%d", ns, id))); err != nil {
-		return err
-	}
-	ciHash, ok := ci.(content.Hashable)
-	if ok {
-		if err := d.getStore(ns).SetIndex(newKeyValueItem(fmt.Sprintf("%s:%s", ns, ciHash.ItemHash()), fmt.Sprintf("%d", id))); err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return newContent(d.getStore(cii.ItemName()), ci, data)
 }
 
 func (d *Database) NextContentId(ns string) (uint64, error) {
@@ -250,6 +176,14 @@ func keyBit8Uint64(sid string) (string, error) {
 	return string(b), err
 }
 
+// Batch runs fn with the content writes to the user store in a single
+// transaction, committed if fn returns nil and rolled back otherwise.
+func (d *Database) Batch(fn func(tx repository.Tx) error) error {
+	return d.userStore.Update(func(tx *db.Tx) error {
+		return fn(&contentTx{s: tx})
+	})
+}
+
 func (d *Database) CheckSlugForDuplicate(namespace string, slug string) (string, error) {
 	return d.getStore(namespace).CheckSlugForDuplicate(slug)
 }
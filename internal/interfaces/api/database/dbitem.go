@@ -40,6 +40,14 @@ func newUserItem(email string, user []byte) *item {
 	}
 }
 
+func newJobItem(id string, data []byte) *item {
+	return &item{
+		bucket: bucketNameWithPrefix("jobs"),
+		key:    id,
+		value:  data,
+	}
+}
+
 func newKeyValueItem(key, value string) *item {
 	return &item{
 		key:   key,
@@ -28,12 +28,12 @@ func (d *Database) Open(next http.HandlerFunc) http.HandlerFunc {
 }
 
 func (d *Database) StartUserDatabase(email string) error {
-	var buckets []string
-	buckets = append(buckets, d.contentBuckets...)
-	buckets = append(buckets, userBuckets...)
+	return d.StartUserDir(hashEmailMD5(email))
+}
 
-	ud := hashEmailMD5(email)
-	s, err := db.OpenUserStore(ud, d.dataDir, buckets)
+// StartUserDir starts the database of the user with the data dir ud.
+func (d *Database) StartUserDir(ud string) error {
+	s, err := d.openUserStore(ud)
 	if err != nil {
 		return err
 	}
@@ -46,6 +46,31 @@ func (d *Database) StartUserDatabase(email string) error {
 	return nil
 }
 
+// UserDatabase returns a copy of d on the database of the user with
+// email, left as it is when other users' requests start theirs, e.g. for
+// the jobs of the user.
+func (d *Database) UserDatabase(email string) (*Database, error) {
+	ud := hashEmailMD5(email)
+	s, err := d.openUserStore(ud)
+	if err != nil {
+		return nil, err
+	}
+
+	u := *d
+	u.userStore = s
+	u.userDir = ud
+
+	return &u, nil
+}
+
+func (d *Database) openUserStore(ud string) (*db.Store, error) {
+	var buckets []string
+	buckets = append(buckets, d.contentBuckets...)
+	buckets = append(buckets, userBuckets...)
+
+	return db.OpenUserStore(ud, d.dataDir, buckets)
+}
+
 func hashEmailMD5(email string) string {
 	hash := md5.New()
 	hash.Write([]byte(email))
@@ -0,0 +1,17 @@
+package database
+
+func (d *Database) PutJob(id string, data []byte) error {
+	return d.adminStore.Set(newJobItem(id, data))
+}
+
+func (d *Database) GetJob(id string) ([]byte, error) {
+	return d.adminStore.Get(newJobItem(id, nil))
+}
+
+func (d *Database) AllJobs() ([][]byte, error) {
+	return d.adminStore.ContentAll(bucketNameWithPrefix("jobs")), nil
+}
+
+func (d *Database) DeleteJob(id string) error {
+	return d.adminStore.Delete(newJobItem(id, nil))
+}
@@ -3,39 +3,81 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	templateVO "github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/admin"
 	"github.com/mdfriday/hugoverse/pkg/herrors"
+	"github.com/mdfriday/hugoverse/pkg/validate"
 	"net/http"
 )
 
-func (s *Handler) handlerError(res http.ResponseWriter, req *http.Request, err error) {
-	var fe herrors.FileError
-	if errors.As(err, &fe) {
-		res.WriteHeader(http.StatusBadRequest)
+// validationError responds with the fields of an item failing validation,
+// e.g. {"errors":[{"field":"title","rule":"required","message":"is required"}]},
+// or with a 500 if err isn't a validation error.
+func (s *Handler) validationError(res http.ResponseWriter, err error) {
+	var errs validate.Errors
+	if !errors.As(err, &errs) {
+		s.log.Errorf("Error validating content: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-		jsonBytes, err := json.Marshal(fe.Error())
-		if err != nil {
-			s.log.Errorf("Error marshalling token when handling error: %v", err)
-			res.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+	j, err := json.Marshal(map[string]validate.Errors{"errors": errs})
+	if err != nil {
+		s.log.Errorf("Error marshalling validation errors: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
 
-		j, err := s.res.FmtJSON(jsonBytes)
-		if err != nil {
-			s.log.Errorf("Error formatting JSON when handling error: %v", err)
-			res.WriteHeader(http.StatusInternalServerError)
-			return
-		}
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(http.StatusUnprocessableEntity)
+	if _, err := res.Write(j); err != nil {
+		s.log.Errorf("Error writing response: %v", err)
+	}
+}
 
-		s.res.Json(res, j)
+// handlerBuildError serves the build error explorer, showing the failing
+// template source around the error position.
+func (s *Handler) handlerBuildError(res http.ResponseWriter, err error) {
+	e := admin.BuildError{Error: err.Error()}
 
+	var ee *templateVO.ExecuteError
+	if errors.As(err, &ee) {
+		e.Template = ee.Template
+		e.DataType = ee.DataType
+	}
+
+	for _, fe := range herrors.UnwrapFileErrorsWithErrorContext(err) {
+		ectx := fe.ErrorContext()
+		if len(ectx.Lines) == 0 {
+			continue
+		}
+		pos := fe.Position()
+		f := admin.BuildErrorFile{
+			Filename:     pos.Filename,
+			LineNumber:   pos.LineNumber,
+			ColumnNumber: pos.ColumnNumber,
+		}
+		first := pos.LineNumber - ectx.LinesPos
+		for i, line := range ectx.Lines {
+			f.Lines = append(f.Lines, admin.BuildErrorLine{
+				Number:  first + i,
+				Text:    line,
+				IsError: i == ectx.LinesPos,
+			})
+		}
+		e.Files = append(e.Files, f)
+	}
+
+	b, err := s.adminView.BuildErrorExplorer(e)
+	if err != nil {
+		s.log.Errorf("Error rendering build error explorer: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
+	res.Header().Set("Content-Type", "text/html; charset=utf-8")
 	res.WriteHeader(http.StatusInternalServerError)
-	_, err = res.Write([]byte(err.Error()))
-	if err != nil {
+	if _, err := res.Write(b); err != nil {
 		s.log.Errorf("Error writing response: %v", err)
 	}
-
-	return
 }
@@ -0,0 +1,41 @@
+package handler
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"text/template"
+
+	qt "github.com/frankban/quicktest"
+	templateVO "github.com/mdfriday/hugoverse/internal/domain/template/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/admin"
+	"github.com/mdfriday/hugoverse/pkg/herrors"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+func TestHandlerBuildError(t *testing.T) {
+	c := qt.New(t)
+
+	src := "<h1>{{ .Title }}</h1>\n{{ .Params.Foo }}\n"
+	templ := template.Must(template.New("_default/single.html").Parse(src))
+	err := templ.Execute(io.Discard, map[string]any{"Title": "t", "Params": "p"})
+	c.Assert(err, qt.IsNotNil)
+
+	err = templateVO.NewExecuteError(err, map[string]any{}, func(name string) templateVO.TemplateInfo {
+		return templateVO.TemplateInfo{Name: name, Template: src}
+	})
+	err = fmt.Errorf("failed to render pages: %w", herrors.ImproveIfNilPointer(err))
+
+	s := &Handler{log: loggers.NewDefault(), adminView: &admin.View{}}
+	res := httptest.NewRecorder()
+	s.handlerBuildError(res, err)
+
+	c.Assert(res.Code, qt.Equals, http.StatusInternalServerError)
+	body := res.Body.String()
+	c.Assert(strings.Count(body, `<table class="source">`), qt.Equals, 1)
+	c.Assert(body, qt.Contains, "_default/single.html:2:")
+	c.Assert(body, qt.Contains, "<code>map[string]interface {}</code>")
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/base64"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/admin"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/ratelimit"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/token"
 	"log"
 	"net/http"
@@ -23,7 +24,7 @@ func (s *Handler) refreshAdminFlag(req *http.Request) {
 
 func (s *Handler) AdminHandler(res http.ResponseWriter, req *http.Request) {
 	s.refreshAdminFlag(req)
-	view, err := s.adminView.Dashboard()
+	view, err := s.adminView.Dashboard(s.limiter.Throttled())
 	if err != nil {
 		s.log.Errorf("Error rendering admin view: %v", err)
 		res.WriteHeader(http.StatusInternalServerError)
@@ -153,12 +154,20 @@ func (s *Handler) LoginHandler(res http.ResponseWriter, req *http.Request) {
 		email := strings.ToLower(req.FormValue("email"))
 		pwd := req.FormValue("password")
 
+		if wait, locked := s.limiter.LoginLocked(req, email); locked {
+			s.log.Errorf("Login of %s locked out for %s", email, wait)
+			ratelimit.TooManyRequests(res, wait)
+			return
+		}
+
 		err = s.adminApp.ValidateUser(email, pwd)
 		if err != nil {
 			s.log.Errorf("Error validating user: %v", err)
+			s.limiter.LoginFailed(req, email)
 			http.Redirect(res, req, req.URL.String(), http.StatusFound)
 			return
 		}
+		s.limiter.LoginSucceeded(req, email)
 
 		// create new token
 		nt, exp, err := token.New(email)
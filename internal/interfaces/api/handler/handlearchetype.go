@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/mdfriday/hugoverse/internal/application"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"net/http"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+type newSitePost struct {
+	ID   string `json:"id"`
+	Path string `json:"path"`
+}
+
+// NewContentHandler creates posts for a site from the archetype of kind,
+// e.g. /api/content/new?type=Site&id=1&kind=posts&path=posts/hello.md.
+// Files of bundle archetypes other than the content files are skipped.
+func (s *Handler) NewContentHandler(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	id := q.Get("id")
+	t := q.Get("type")
+	kind := q.Get("kind")
+	contentPath := q.Get("path")
+
+	if t == "" || id == "" || contentPath == "" {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	// The path is relative to the content directory, the kind to the
+	// archetypes one, neither may reach out of it.
+	if !filepath.IsLocal(strings.TrimPrefix(contentPath, "/")) || (kind != "" && !filepath.IsLocal(kind)) {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	pt, ok := s.contentApp.GetContentCreator(t)
+	if !ok {
+		res.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	if _, ok = pt().(content.Buildable); !ok {
+		s.log.Println("[Response] error: Type", t, "does not implement item.Buildable or embed item.Item.")
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	target, clean, err := s.contentApp.ArchetypeTarget(id)
+	if err != nil {
+		s.log.Errorf("Error writing archetypes of site %s: %v", id, err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer clean()
+
+	files, err := application.NewContentWithTarget(target, kind, contentPath)
+	if err != nil {
+		s.log.Errorf("Error creating content from archetype %q: %v", kind, err)
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var data []json.RawMessage
+	for _, f := range files {
+		if ext := path.Ext(f.Path); ext != ".md" && ext != ".markdown" {
+			continue
+		}
+
+		postID, err := s.contentApp.NewSitePost(id, f.Path, f.Content)
+		if err != nil {
+			s.log.Errorf("Error creating post %s: %v", f.Path, err)
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		b, err := json.Marshal(newSitePost{ID: postID, Path: f.Path})
+		if err != nil {
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		data = append(data, b)
+	}
+
+	j, err := s.res.FmtJSON(data...)
+	if err != nil {
+		s.log.Errorf("Error formatting JSON: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.res.Json(res, j)
+}
@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/ratelimit"
+	"io"
+	"net/http"
+	"time"
+)
+
+// BackupHandler streams a gzipped tar archive of all the CMS data, to be
+// restored with hugov restore. As it holds the data of every user, it is
+// guarded by the backup credentials of the admin config rather than a
+// user token, and is forbidden until they are set. Failed attempts lock
+// the client out as failed logins do.
+func (s *Handler) BackupHandler(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	user, password := s.adminApp.BackupCredentials()
+	if user == "" || password == "" {
+		res.WriteHeader(http.StatusForbidden)
+		return
+	}
+	u, p, ok := req.BasicAuth()
+	if wait, locked := s.limiter.LoginLocked(req, u); locked {
+		s.log.Errorf("Backup by %s locked out for %s", u, wait)
+		ratelimit.TooManyRequests(res, wait)
+		return
+	}
+	if !ok ||
+		subtle.ConstantTimeCompare([]byte(u), []byte(user)) != 1 ||
+		subtle.ConstantTimeCompare([]byte(p), []byte(password)) != 1 {
+		s.limiter.LoginFailed(req, u)
+		res.Header().Set("WWW-Authenticate", `Basic realm="backup"`)
+		res.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	s.limiter.LoginSucceeded(req, u)
+
+	res.Header().Set("Content-Type", "application/gzip")
+	res.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="hugoverse-%d.tar.gz"`, time.Now().Unix()))
+	res.Header().Set("Cache-Control", "no-store")
+
+	w := &writeCounter{w: res}
+	m, err := s.db.Backup(w, s.uploadDir)
+	if err != nil {
+		s.log.Errorf("Error backing up: %v", err)
+		// Once the archive started the status is sent, the client is left
+		// with a truncated archive that fails to restore.
+		if w.n == 0 {
+			res.Header().Del("Content-Disposition")
+			res.WriteHeader(http.StatusInternalServerError)
+		}
+		return
+	}
+
+	s.log.Printf("Backed up %d user stores and %d uploads", len(m.Users), m.Uploads)
+}
+
+type writeCounter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *writeCounter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
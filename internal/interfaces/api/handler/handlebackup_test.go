@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/application"
+	adminFactory "github.com/mdfriday/hugoverse/internal/domain/admin/factory"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/admin"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/ratelimit"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+func TestBackupHandler(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := database.New(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	ct := application.NewContentServer(d)
+	d.RegisterContentBuckets(ct.AllContentTypeNames())
+	c.Assert(d.StartAdminDatabase(ct.AllAdminTypeNames()), qt.IsNil)
+	defer d.Close()
+	a, err := adminFactory.NewAdmin(d)
+	c.Assert(err, qt.IsNil)
+
+	log := loggers.NewDefault()
+	s := &Handler{log: log, res: NewResponse(&admin.View{}), db: d, uploadDir: t.TempDir(),
+		contentApp: ct, adminApp: a, limiter: ratelimit.New(log, a)}
+	get := func(user, password string) int {
+		req := httptest.NewRequest(http.MethodGet, "/admin/backup", nil)
+		req.SetBasicAuth(user, password)
+		res := httptest.NewRecorder()
+		s.BackupHandler(res, req)
+		return res.Code
+	}
+
+	c.Assert(get("ops", "secret"), qt.Equals, http.StatusForbidden)
+
+	a.Conf.BackupBasicAuthUser = "ops"
+	a.Conf.BackupBasicAuthPassword = "secret"
+	a.Conf.LoginMaxFailures = 2
+	c.Assert(get("ops", "secret"), qt.Equals, http.StatusOK)
+
+	// Failed attempts lock the client out, the right credentials too.
+	c.Assert(get("ops", "guess"), qt.Equals, http.StatusUnauthorized)
+	c.Assert(get("ops", "guess"), qt.Equals, http.StatusUnauthorized)
+	c.Assert(get("ops", "secret"), qt.Equals, http.StatusTooManyRequests)
+}
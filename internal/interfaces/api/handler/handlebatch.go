@@ -0,0 +1,416 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	contentEntity "github.com/mdfriday/hugoverse/internal/domain/content/entity"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/editor"
+	"github.com/mdfriday/hugoverse/pkg/timestamp"
+	"maps"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+const (
+	// maxBatchLine is the max size of an op of a batch.
+	maxBatchLine = 4 << 20
+	// maxBatchOps is the max number of ops of a batch.
+	maxBatchOps = 10000
+)
+
+// batchLine is an op of a batch, one JSON object to a line, e.g.
+// {"op":"create","type":"Post","data":{"title":"Hello","tags":["a","b"]}}
+type batchLine struct {
+	Op     valueobject.BatchOpKind `json:"op"`
+	Type   string                  `json:"type"`
+	ID     string                  `json:"id"`
+	Status string                  `json:"status"`
+	Data   map[string]any          `json:"data"`
+}
+
+// BatchContentHandler applies the NDJSON ops of create, update, delete and
+// approve posted to /api/contents/batch, and responds with the result of
+// each. With atomic=true, either all ops are applied or none is, e.g.
+// /api/contents/batch?atomic=true
+func (s *Handler) BatchContentHandler(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	atomic := req.URL.Query().Get("atomic") == "true"
+
+	var ops []*valueobject.BatchOp
+	sc := bufio.NewScanner(req.Body)
+	sc.Buffer(make([]byte, 64*1024), maxBatchLine)
+	for n := 1; sc.Scan(); n++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		if len(ops) == maxBatchOps {
+			http.Error(res, fmt.Sprintf("more than %d ops", maxBatchOps), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		op, err := parseBatchLine(sc.Bytes())
+		if err != nil {
+			http.Error(res, fmt.Sprintf("line %d: %v", n, err), http.StatusBadRequest)
+			return
+		}
+		ops = append(ops, op)
+	}
+	if err := sc.Err(); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Ops failing the hooks or the rules of their type are left out of the
+	// batch.
+	var results []*valueobject.BatchResult
+	var accepted []*valueobject.BatchOp
+	var at []int
+	hooks := make([]*batchHooks, len(ops))
+	v := s.contentApp.NewValidator()
+	for i, op := range ops {
+		h, err := s.beforeBatchOp(req, v, op)
+		if err == nil {
+			err = setBatchOp(v, op)
+		}
+		if err != nil {
+			r := &valueobject.BatchResult{Index: i, Op: op.Op, Type: op.Type, ID: op.ID, Error: err.Error()}
+			errors.As(err, &r.Errors)
+			results = append(results, r)
+			continue
+		}
+		hooks[i] = h
+		accepted = append(accepted, op)
+		at = append(at, i)
+	}
+
+	applied := 0
+	if !atomic || len(results) == 0 {
+		for j, r := range s.contentApp.Batch(accepted, atomic) {
+			r.Index = at[j]
+			results = append(results, r)
+			if r.OK() {
+				applied++
+				s.afterBatchOp(accepted[j], r, hooks[r.Index])
+			}
+		}
+	} else {
+		for j, op := range accepted {
+			results = append(results, &valueobject.BatchResult{
+				Index: at[j], Op: op.Op, Type: op.Type, ID: op.ID,
+				Error: contentEntity.ErrBatchNotApplied.Error(),
+			})
+		}
+	}
+
+	if applied > 0 {
+		if err := s.adminApp.InvalidateCache(); err != nil {
+			s.log.Errorf("Error invalidating cache: %s", err)
+		}
+	}
+
+	ordered := make([]*valueobject.BatchResult, len(results))
+	for _, r := range results {
+		ordered[r.Index] = r
+	}
+	data := make([]json.RawMessage, len(ordered))
+	for i, r := range ordered {
+		b, err := json.Marshal(r)
+		if err != nil {
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		data[i] = b
+	}
+
+	j, err := json.Marshal(map[string][]json.RawMessage{"data": data})
+	if err != nil {
+		s.log.Errorf("Error marshalling response to JSON: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	if atomic && applied < len(ops) {
+		res.Header().Set("Content-Type", "application/json")
+		res.WriteHeader(http.StatusUnprocessableEntity)
+		if _, err := res.Write(j); err != nil {
+			s.log.Errorf("Error writing response: %v", err)
+		}
+		return
+	}
+
+	s.res.Json(res, j)
+}
+
+func parseBatchLine(line []byte) (*valueobject.BatchOp, error) {
+	var l batchLine
+	if err := json.Unmarshal(line, &l); err != nil {
+		return nil, err
+	}
+
+	values := make(url.Values)
+	for k, v := range l.Data {
+		switch v := v.(type) {
+		case []any:
+			for _, e := range v {
+				s, err := batchValue(e)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", k, err)
+				}
+				values.Add(k, s)
+			}
+		default:
+			s, err := batchValue(v)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			values.Set(k, s)
+		}
+	}
+
+	op := &valueobject.BatchOp{
+		Op:     l.Op,
+		Type:   l.Type,
+		ID:     l.ID,
+		Status: l.Status,
+		Values: values,
+	}
+	return op, op.Check()
+}
+
+func batchValue(v any) (string, error) {
+	switch v := v.(type) {
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case nil:
+		return "", nil
+	}
+	return "", fmt.Errorf("unsupported value %v", v)
+}
+
+// batchHooks are the hooks of an op and the request they run with, for
+// the after hooks to run once the op is applied.
+type batchHooks struct {
+	hook content.Hookable
+	r    *http.Request
+}
+
+// beforeBatchOp runs the before hooks of an op as the handler of a single
+// item does, ContentHandler for a create op, DeleteContentHandler for a
+// delete op and ApproveContentHandler for an approve op, and returns the
+// hooks to run after it. A create op is also checked against the rules of
+// its type, and its status set to public only if the type is Trustable.
+// Update ops, which run no hooks, are checked against the rules and by
+// the Update of the stored item as ContentHandler does, with the values
+// they set merged onto the stored item. The rules across items are checked
+// by v against the ops accepted before too.
+func (s *Handler) beforeBatchOp(req *http.Request, v *contentEntity.Validator, op *valueobject.BatchOp) (*batchHooks, error) {
+	ts := timestamp.Now()
+	if op.Op == valueobject.BatchUpdate {
+		op.Values.Set("updated", ts)
+		if err := v.ValidateUpdate(op.Type, op.ID, op.Status, op.Values); err != nil {
+			return nil, err
+		}
+		return nil, s.updateBatchOp(req, v, op)
+	}
+
+	p, ok := s.contentApp.GetContentCreator(op.Type)
+	if !ok {
+		return nil, fmt.Errorf("invalid content type %q", op.Type)
+	}
+	post := p()
+
+	hook, ok := post.(content.Hookable)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not hookable", op.Type)
+	}
+
+	r := req.Clone(req.Context())
+	r.PostForm = op.Values
+	r.Form = op.Values
+	w := &discardResponse{header: make(http.Header)}
+	h := &batchHooks{hook: hook, r: r}
+
+	switch op.Op {
+	case valueobject.BatchDelete, valueobject.BatchApprove:
+		status := op.Status
+		if op.Op == valueobject.BatchApprove {
+			status = string(content.Pending)
+		}
+		data, err := s.contentApp.GetContent(op.Type, op.ID, status)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			return nil, fmt.Errorf("%s %s not found", op.Type, op.ID)
+		}
+		if err := json.Unmarshal(data, post); err != nil {
+			return nil, err
+		}
+		op.Values.Set("id", op.ID)
+		op.Values.Set("type", op.Type)
+		op.Values.Set("status", status)
+
+		if op.Op == valueobject.BatchDelete {
+			if err := hook.BeforeAdminDelete(w, r); err != nil {
+				return nil, err
+			}
+			return h, hook.BeforeDelete(w, r)
+		}
+
+		m, ok := post.(editor.Mergeable)
+		if !ok {
+			return nil, fmt.Errorf("type %s is not mergeable", op.Type)
+		}
+		if err := hook.BeforeApprove(w, r); err != nil {
+			return nil, err
+		}
+		if err := m.Approve(w, r); err != nil {
+			return nil, err
+		}
+		return h, hook.BeforeSave(w, r)
+	}
+
+	ext, ok := post.(content.Createable)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not createable", op.Type)
+	}
+
+	op.Values.Set("timestamp", ts)
+	op.Values.Set("updated", ts)
+
+	if err := hook.BeforeAPICreate(w, r); err != nil {
+		return nil, err
+	}
+	if err := v.Validate(op.Type, "-1", r.PostForm); err != nil {
+		return nil, err
+	}
+	if err := ext.Create(w, r); err != nil {
+		return nil, err
+	}
+	if err := hook.BeforeSave(w, r); err != nil {
+		return nil, err
+	}
+	op.Values = r.PostForm
+
+	op.Status = string(content.Pending)
+	if trusted, ok := post.(content.Trustable); ok {
+		if err := trusted.AutoApprove(w, r); err != nil {
+			return nil, err
+		}
+		op.Status = string(content.Public)
+	}
+
+	return h, nil
+}
+
+// updateBatchOp runs the Update of the stored item of an update op, if its
+// type is Updateable, with the values of the op merged onto those of the
+// item. The values Update sets are set on the op.
+func (s *Handler) updateBatchOp(req *http.Request, v *contentEntity.Validator, op *valueobject.BatchOp) error {
+	p, ok := s.contentApp.GetContentCreator(op.Type)
+	if !ok {
+		return fmt.Errorf("invalid content type %q", op.Type)
+	}
+	ep := p()
+	update, ok := ep.(content.Updateable)
+	if !ok {
+		return nil
+	}
+
+	data, err := s.contentApp.GetContent(op.Type, op.ID, op.Status)
+	if err != nil {
+		return err
+	}
+	if data == nil {
+		return fmt.Errorf("%s %s not found", op.Type, op.ID)
+	}
+	if err := json.Unmarshal(data, ep); err != nil {
+		return err
+	}
+
+	merged, err := v.Merged(op.Type, op.ID, op.Status, op.Values)
+	if err != nil {
+		return err
+	}
+	before := maps.Clone(merged)
+
+	r := req.Clone(req.Context())
+	r.PostForm = merged
+	r.Form = merged
+	if err := update.Update(&discardResponse{header: make(http.Header)}, r); err != nil {
+		return err
+	}
+	for k, vs := range r.PostForm {
+		if !slices.Equal(vs, before[k]) {
+			op.Values[k] = vs
+		}
+	}
+	return nil
+}
+
+// setBatchOp sets the values of an accepted op in v, for the ops after it
+// to be validated against.
+func setBatchOp(v *contentEntity.Validator, op *valueobject.BatchOp) error {
+	switch op.Op {
+	case valueobject.BatchCreate:
+		return v.Set(op.Type, "", op.Status, op.Values)
+	case valueobject.BatchUpdate:
+		return v.Set(op.Type, op.ID, op.Status, op.Values)
+	case valueobject.BatchDelete:
+		v.Delete(op.Type, op.ID, op.Status)
+	}
+	return nil
+}
+
+// afterBatchOp runs the after hooks of an op applied with result, logging
+// their errors as the handlers of a single item do, the op being done.
+func (s *Handler) afterBatchOp(op *valueobject.BatchOp, result *valueobject.BatchResult, h *batchHooks) {
+	if h == nil {
+		return
+	}
+
+	w := &discardResponse{header: make(http.Header)}
+	var afters []func(http.ResponseWriter, *http.Request) error
+	switch op.Op {
+	case valueobject.BatchCreate:
+		afters = []func(http.ResponseWriter, *http.Request) error{h.hook.AfterSave, h.hook.AfterAPICreate}
+	case valueobject.BatchDelete:
+		afters = []func(http.ResponseWriter, *http.Request) error{h.hook.AfterDelete, h.hook.AfterAdminDelete}
+	case valueobject.BatchApprove:
+		afters = []func(http.ResponseWriter, *http.Request) error{h.hook.AfterApprove, h.hook.AfterSave}
+	}
+
+	// Set the target in the context for the hooks to get the saved item.
+	r := h.r.WithContext(context.WithValue(h.r.Context(), "target", fmt.Sprintf("%s:%s", op.Type, result.ID)))
+	for _, after := range afters {
+		if err := after(w, r); err != nil {
+			s.log.Errorf("Error running after hook of batch op %d, %s %s %s: %v", result.Index, op.Op, op.Type, result.ID, err)
+			return
+		}
+	}
+}
+
+// discardResponse is the response of the hooks run for the ops of a batch,
+// which have no response of their own.
+type discardResponse struct {
+	header http.Header
+}
+
+func (d *discardResponse) Header() http.Header         { return d.header }
+func (d *discardResponse) Write(b []byte) (int, error) { return len(b), nil }
+func (d *discardResponse) WriteHeader(int)             {}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/application"
+	adminFactory "github.com/mdfriday/hugoverse/internal/domain/admin/factory"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/admin"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+// note is a Post recording the hooks run on it, which refuses to be deleted
+// when titled keep.
+type note struct {
+	valueobject.Post
+}
+
+var noteHooks []string
+
+func (n *note) record(hook string) error {
+	noteHooks = append(noteHooks, hook+" "+n.Title)
+	return nil
+}
+
+func (n *note) BeforeDelete(http.ResponseWriter, *http.Request) error {
+	if n.Title == "keep" {
+		return errors.New("kept")
+	}
+	return n.record("BeforeDelete")
+}
+
+func (n *note) AfterDelete(http.ResponseWriter, *http.Request) error {
+	return n.record("AfterDelete")
+}
+
+func (n *note) BeforeApprove(http.ResponseWriter, *http.Request) error {
+	return n.record("BeforeApprove")
+}
+
+func (n *note) AfterApprove(http.ResponseWriter, *http.Request) error {
+	return n.record("AfterApprove")
+}
+
+func TestBatchContentHandler(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := database.New(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	ct := application.NewContentServer(d)
+	ct.UserTypes["Note"] = func() interface{} { return new(note) }
+	d.RegisterContentBuckets(ct.AllContentTypeNames())
+	c.Assert(d.StartAdminDatabase(ct.AllAdminTypeNames()), qt.IsNil)
+	c.Assert(d.StartUserDir("batch"), qt.IsNil)
+	defer d.Close()
+	a, err := adminFactory.NewAdmin(d)
+	c.Assert(err, qt.IsNil)
+
+	s := &Handler{log: loggers.NewDefault(), res: NewResponse(&admin.View{}), contentApp: ct, adminApp: a}
+	post := func(query, body string) (*httptest.ResponseRecorder, []*valueobject.BatchResult) {
+		req := httptest.NewRequest(http.MethodPost, "/api/contents/batch"+query, strings.NewReader(body))
+		res := httptest.NewRecorder()
+		s.BatchContentHandler(res, req)
+		var results struct {
+			Data []*valueobject.BatchResult `json:"data"`
+		}
+		if res.Code == http.StatusOK || res.Code == http.StatusUnprocessableEntity {
+			c.Assert(json.Unmarshal(res.Body.Bytes(), &results), qt.IsNil)
+		}
+		return res, results.Data
+	}
+
+	c.Run("Limits", func(c *qt.C) {
+		res, _ := post("", strings.Repeat(`{"op":"delete","type":"Note","id":"1"}`+"\n", maxBatchOps+1))
+		c.Assert(res.Code, qt.Equals, http.StatusRequestEntityTooLarge)
+		c.Assert(res.Body.String(), qt.Contains, "more than 10000 ops")
+
+		res, _ = post("", `{"op":"create","type":"Note","data":{"title":"`+strings.Repeat("a", maxBatchLine)+`"}}`)
+		c.Assert(res.Code, qt.Equals, http.StatusBadRequest)
+
+		res, _ = post("", `{"op":"delete","type":"Note","id":"1"}`+"\n\n"+`{"op":`)
+		c.Assert(res.Code, qt.Equals, http.StatusBadRequest)
+		c.Assert(res.Body.String(), qt.Contains, "line 3: ")
+
+		res, _ = post("", `{"op":"move","type":"Note","id":"1"}`)
+		c.Assert(res.Code, qt.Equals, http.StatusBadRequest)
+		c.Assert(res.Body.String(), qt.Contains, "line 1: ")
+	})
+
+	// Notes are Trustable, so pending ones are only made bypassing the hooks.
+	var ops []*valueobject.BatchOp
+	for _, title := range []string{"a", "keep", "b"} {
+		ops = append(ops, &valueobject.BatchOp{Op: valueobject.BatchCreate, Type: "Note", Status: string(content.Pending),
+			Values: url.Values{"title": {title}, "content": {title}}})
+	}
+	results := ct.Batch(ops, true)
+	for i, r := range results {
+		c.Assert(r.OK(), qt.IsTrue, qt.Commentf("%d: %s", i, r.Error))
+	}
+	a1, keep, b := results[0].ID, results[1].ID, results[2].ID
+
+	c.Run("Atomic", func(c *qt.C) {
+		res, results := post("?atomic=true", strings.Join([]string{
+			`{"op":"create","type":"Note","data":{"title":"c","content":"c"}}`,
+			`{"op":"delete","type":"Note","id":"` + keep + `","status":"pending"}`,
+		}, "\n"))
+		c.Assert(res.Code, qt.Equals, http.StatusUnprocessableEntity)
+		c.Assert(results, qt.HasLen, 2)
+		c.Assert(results[0].OK(), qt.IsFalse)
+		c.Assert(results[1].Error, qt.Equals, "kept")
+		c.Assert(results[0].ID, qt.Equals, "")
+	})
+
+	c.Run("Hooks", func(c *qt.C) {
+		noteHooks = nil
+		res, results := post("", strings.Join([]string{
+			`{"op":"delete","type":"Note","id":"` + a1 + `","status":"pending"}`,
+			`{"op":"delete","type":"Note","id":"` + keep + `","status":"pending"}`,
+			`{"op":"approve","type":"Note","id":"` + b + `"}`,
+		}, "\n"))
+		c.Assert(res.Code, qt.Equals, http.StatusOK)
+		c.Assert(results[0].OK(), qt.IsTrue, qt.Commentf("%s", results[0].Error))
+		c.Assert(results[1].Error, qt.Equals, "kept")
+		c.Assert(results[2].OK(), qt.IsTrue, qt.Commentf("%s", results[2].Error))
+		c.Assert(noteHooks, qt.DeepEquals, []string{
+			"BeforeDelete a", "BeforeApprove b",
+			"AfterDelete a", "AfterApprove b",
+		})
+
+		for _, test := range []struct {
+			id, status string
+			found      bool
+		}{
+			{a1, string(content.Pending), false},
+			{keep, string(content.Pending), true},
+			{b, string(content.Pending), false},
+			{results[2].ID, string(content.Public), true},
+		} {
+			data, err := ct.GetContent("Note", test.id, test.status)
+			c.Assert(err, qt.IsNil)
+			c.Assert(data != nil, qt.Equals, test.found, qt.Commentf("%v", test))
+		}
+	})
+
+	c.Run("Update", func(c *qt.C) {
+		results := ct.Batch([]*valueobject.BatchOp{{Op: valueobject.BatchCreate, Type: "SiteMenu",
+			Values: url.Values{"site": {"/api/content?type=Site&id=1"}, "name": {"main"},
+				"entry_names": {"home", "blog"}, "entry_urls": {"/", "/blog/"}}}}, true)
+		c.Assert(results[0].OK(), qt.IsTrue, qt.Commentf("%s", results[0].Error))
+		menu := results[0].ID
+
+		// The entries are checked merged onto the stored menu.
+		res, results := post("", strings.Join([]string{
+			`{"op":"update","type":"SiteMenu","id":"` + menu + `","data":{"entry_parents":["blog","home"]}}`,
+			`{"op":"update","type":"SiteMenu","id":"` + menu + `","data":{"entry_parents":["","nav"]}}`,
+			`{"op":"update","type":"SiteMenu","id":"` + menu + `","data":{"entry_parents":["","home"]}}`,
+		}, "\n"))
+		c.Assert(res.Code, qt.Equals, http.StatusOK)
+		c.Assert(results[0].Error, qt.Contains, "is within itself")
+		c.Assert(results[1].Error, qt.Contains, `the parent of "blog", "nav", is not an entry`)
+		c.Assert(results[2].OK(), qt.IsTrue, qt.Commentf("%s", results[2].Error))
+	})
+}
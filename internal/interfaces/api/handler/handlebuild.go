@@ -1,12 +1,16 @@
 package handler
 
 import (
-	"github.com/mdfriday/hugoverse/internal/application"
+	"context"
 	"github.com/mdfriday/hugoverse/internal/domain/content"
+	contentEntity "github.com/mdfriday/hugoverse/internal/domain/content/entity"
+	"github.com/mdfriday/hugoverse/internal/domain/job"
 	"log"
 	"net/http"
 )
 
+// BuildContentHandler writes the project of the site and enqueues a job
+// building it, responding with the job.
 func (s *Handler) BuildContentHandler(res http.ResponseWriter, req *http.Request) {
 	q := req.URL.Query()
 	id := q.Get("id")
@@ -32,19 +36,20 @@ func (s *Handler) BuildContentHandler(res http.ResponseWriter, req *http.Request
 		return
 	}
 
-	t, err := s.contentApp.BuildTarget(t, id, status)
+	target, err := s.contentApp.BuildTarget(t, id, status)
 	if err != nil {
 		s.log.Errorf("Error building: %v", err)
 		res.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	err = application.GenerateStaticSiteWithTarget(t)
-	if err != nil {
-		s.log.Errorf("Error building: %v", err)
-		res.WriteHeader(http.StatusInternalServerError)
-		return
-	}
+	s.enqueue(res, req, job.KindBuild, t, id, func(ctx context.Context, log job.Logger, _ func() (*contentEntity.Content, error)) (string, error) {
+		log.Printf("Building site %s in %s", id, target)
+		if err := buildSite(ctx, target, log); err != nil {
+			return "", err
+		}
+		log.Printf("Built site %s", id)
 
-	s.res.Json(res, []byte("ok"))
+		return "", nil
+	})
 }
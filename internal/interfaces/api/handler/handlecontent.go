@@ -16,6 +16,35 @@ import (
 	"strings"
 )
 
+// apiTermsHandler responds with the terms of a taxonomy used by the contents
+// of type t, e.g. /api/contents?type=Post&taxonomy=tags
+func (s *Handler) apiTermsHandler(res http.ResponseWriter, t, taxonomy string) {
+	terms, err := s.contentApp.Terms(t, taxonomy)
+	if err != nil {
+		s.log.Errorf("[Terms] error collecting %s for %s: %v", taxonomy, t, err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var result []json.RawMessage
+	for _, term := range terms {
+		b, err := json.Marshal(term)
+		if err != nil {
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		result = append(result, b)
+	}
+
+	j, err := s.res.FmtJSON(result...)
+	if err != nil {
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.res.Json(res, j)
+}
+
 func (s *Handler) ApiContentsHandler(res http.ResponseWriter, req *http.Request) {
 	q := req.URL.Query()
 	t := q.Get("type")
@@ -34,6 +63,11 @@ func (s *Handler) ApiContentsHandler(res http.ResponseWriter, req *http.Request)
 		return
 	}
 
+	if taxonomy := q.Get("taxonomy"); taxonomy != "" {
+		s.apiTermsHandler(res, t, taxonomy)
+		return
+	}
+
 	count, err := query.Count(req)
 	if err != nil {
 		res.WriteHeader(http.StatusInternalServerError)
@@ -316,6 +350,11 @@ func (s *Handler) postContent(res http.ResponseWriter, req *http.Request) {
 		return
 	}
 
+	if err := s.contentApp.Validate(t, cid, req.PostForm); err != nil {
+		s.validationError(res, err)
+		return
+	}
+
 	err = ext.Create(res, req)
 	if err != nil {
 		s.log.Errorf("Error calling Accept: %v", err)
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/application"
+	adminFactory "github.com/mdfriday/hugoverse/internal/domain/admin/factory"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/admin"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+func TestApiContentsHandlerTerms(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := database.New(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	ct := application.NewContentServer(d)
+	d.RegisterContentBuckets(ct.AllContentTypeNames())
+	c.Assert(d.StartAdminDatabase(ct.AllAdminTypeNames()), qt.IsNil)
+	c.Assert(d.StartUserDir("terms"), qt.IsNil)
+	defer d.Close()
+	a, err := adminFactory.NewAdmin(d)
+	c.Assert(err, qt.IsNil)
+
+	results := ct.Batch([]*valueobject.BatchOp{
+		{Op: valueobject.BatchCreate, Type: "Post", Values: url.Values{"title": {"a"}, "params": {"tags: [Go, hugo]"}}},
+		{Op: valueobject.BatchCreate, Type: "Post", Values: url.Values{"title": {"b"}, "params": {"tags: [go, ' ']\ncategories: news"}}},
+		{Op: valueobject.BatchCreate, Type: "Post", Values: url.Values{"title": {"c"}}},
+		{Op: valueobject.BatchCreate, Type: "Post", Status: "pending", Values: url.Values{"title": {"d"}, "params": {"tags: [draft]"}}},
+		{Op: valueobject.BatchCreate, Type: "Site", Values: url.Values{"title": {"Blog"}}},
+	}, true)
+	for i, r := range results {
+		c.Assert(r.OK(), qt.IsTrue, qt.Commentf("%d: %s", i, r.Error))
+	}
+
+	s := &Handler{log: loggers.NewDefault(), res: NewResponse(&admin.View{}), contentApp: ct, adminApp: a}
+	terms := func(query string) (int, []valueobject.Term) {
+		req := httptest.NewRequest(http.MethodGet, "/api/contents?"+query, nil)
+		res := httptest.NewRecorder()
+		s.ApiContentsHandler(res, req)
+
+		var result struct {
+			Data []valueobject.Term `json:"data"`
+		}
+		if res.Code == http.StatusOK {
+			c.Assert(json.Unmarshal(res.Body.Bytes(), &result), qt.IsNil)
+		}
+		return res.Code, result.Data
+	}
+
+	// The terms of the public posts are counted case-insensitively, the
+	// first spelling kept.
+	code, data := terms("type=Post&taxonomy=tags")
+	c.Assert(code, qt.Equals, http.StatusOK)
+	c.Assert(data, qt.DeepEquals, []valueobject.Term{{Name: "Go", Count: 2}, {Name: "hugo", Count: 1}})
+
+	code, data = terms("type=Post&taxonomy=Categories")
+	c.Assert(code, qt.Equals, http.StatusOK)
+	c.Assert(data, qt.DeepEquals, []valueobject.Term{{Name: "news", Count: 1}})
+
+	code, data = terms("type=Post&taxonomy=series")
+	c.Assert(code, qt.Equals, http.StatusOK)
+	c.Assert(data, qt.HasLen, 0)
+
+	// Types without terms have none.
+	code, data = terms("type=Site&taxonomy=tags")
+	c.Assert(code, qt.Equals, http.StatusOK)
+	c.Assert(data, qt.HasLen, 0)
+
+	code, _ = terms("type=Nope&taxonomy=tags")
+	c.Assert(code, qt.Equals, http.StatusNotFound)
+}
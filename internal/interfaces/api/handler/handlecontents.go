@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
 	"github.com/mdfriday/hugoverse/pkg/db"
 	"github.com/mdfriday/hugoverse/pkg/editor"
 	"log"
@@ -351,11 +352,15 @@ func (s *Handler) ContentsHandler(res http.ResponseWriter, req *http.Request) {
 			New ` + t + `
 		</a>`
 
-	if _, ok := pt.(content.CSVFormattable); ok {
+	formats := []valueobject.ExportFormat{valueobject.ExportCSV, valueobject.ExportNDJSON}
+	if t == "Post" {
+		formats = append(formats, valueobject.ExportHugo)
+	}
+	for _, f := range formats {
 		btn += `<br/>
-				<a href="/admin/contents/export?type=` + t + `&format=csv" class="green darken-4 btn export-post waves-effect waves-light">
+				<a href="/admin/contents/export?type=` + t + `&format=` + string(f) + `&status=` + status + `" class="green darken-4 btn export-post waves-effect waves-light">
 					<i class="material-icons left">system_update_alt</i>
-					CSV
+					` + strings.ToUpper(string(f)) + `
 				</a>`
 	}
 
@@ -1,15 +1,21 @@
 package handler
 
 import (
-	"encoding/json"
+	"context"
+	"fmt"
 	"github.com/mdfriday/hugoverse/internal/application"
 	"github.com/mdfriday/hugoverse/internal/domain/content"
+	contentEntity "github.com/mdfriday/hugoverse/internal/domain/content/entity"
 	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/domain/job"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/form"
 	"log"
 	"net/http"
 )
 
+// DeployContentHandler applies the domain and enqueues a job building the
+// site, unless it has a working directory of its own, and deploying it,
+// responding with the job. The job result is the URL of the site.
 func (s *Handler) DeployContentHandler(res http.ResponseWriter, req *http.Request) {
 	q := req.URL.Query()
 	id := q.Get("id")
@@ -63,6 +69,12 @@ func (s *Handler) DeployContentHandler(res http.ResponseWriter, req *http.Reques
 		return
 	}
 
+	if hostName != "Netlify" {
+		s.log.Errorf("Error: Netlify only supported for now")
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
 	var target string
 
 	sc, err := s.contentApp.GetContentObject(t, id)
@@ -76,20 +88,14 @@ func (s *Handler) DeployContentHandler(res http.ResponseWriter, req *http.Reques
 		target = site.WorkingDir
 	}
 
-	if target == "" {
+	build := target == ""
+	if build {
 		target, err = s.contentApp.BuildTarget(t, id, status)
 		if err != nil {
 			s.log.Errorf("Error building: %v", err)
 			res.WriteHeader(http.StatusInternalServerError)
 			return
 		}
-
-		err = application.GenerateStaticSiteWithTarget(target)
-		if err != nil {
-			s.log.Errorf("Error building site %s for deployment with error : %v", id, err)
-			res.WriteHeader(http.StatusInternalServerError)
-			return
-		}
 	}
 
 	sd, err := s.contentApp.GetDeployment(d, hostName)
@@ -99,37 +105,30 @@ func (s *Handler) DeployContentHandler(res http.ResponseWriter, req *http.Reques
 		return
 	}
 
-	if hostName != "Netlify" {
-		s.log.Errorf("Error: Netlify only supported for now")
-		res.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	err = application.DeployToNetlify(target, sd, d, hostToken)
-	if err != nil {
-		s.log.Errorf("Error building: %v", err)
-		res.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	if err := s.contentApp.UpdateContentObject(sd); err != nil {
-		s.log.Errorf("Error updating deployment: %v", err)
-		res.WriteHeader(http.StatusInternalServerError)
-		return
-	}
+	s.enqueue(res, req, job.KindDeploy, t, id, func(ctx context.Context, log job.Logger, userContent func() (*contentEntity.Content, error)) (string, error) {
+		if build {
+			log.Printf("Building site %s in %s", id, target)
+			if err := buildSite(ctx, target, log); err != nil {
+				return "", err
+			}
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+		}
 
-	jsonBytes, err := json.Marshal("https://" + d.FullDomain())
-	if err != nil {
-		s.log.Errorf("Error marshalling token: %v", err)
-		return
-	}
+		log.Printf("Deploying site %s to %s", id, d.FullDomain())
+		if err := application.DeployToNetlify(target, sd, d, hostToken); err != nil {
+			return "", fmt.Errorf("deploy: %w", err)
+		}
 
-	j, err := s.res.FmtJSON(jsonBytes)
-	if err != nil {
-		s.log.Errorf("Error formatting JSON: %v", err)
-		res.WriteHeader(http.StatusInternalServerError)
-		return
-	}
+		contentApp, err := userContent()
+		if err != nil {
+			return "", err
+		}
+		if err := contentApp.UpdateContentObject(sd); err != nil {
+			return "", fmt.Errorf("update deployment: %w", err)
+		}
 
-	res.WriteHeader(http.StatusOK)
-	s.res.Json(res, j)
+		return "https://" + d.FullDomain(), nil
+	})
 }
@@ -3,14 +3,17 @@ package handler
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"github.com/gorilla/schema"
 	"github.com/mdfriday/hugoverse/internal/domain/content"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/admin"
 	"github.com/mdfriday/hugoverse/pkg/editor"
 	"github.com/mdfriday/hugoverse/pkg/timestamp"
+	"github.com/mdfriday/hugoverse/pkg/validate"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
 )
 
@@ -66,17 +69,7 @@ func (s *Handler) EditHandler(res http.ResponseWriter, req *http.Request) {
 			item.SetItemID(-1)
 		}
 
-		sel, ok := post.(content.RefSelectable)
-		if ok {
-			selContentTypes := sel.SelectContentTypes()
-			data := make(map[string][][]byte)
-
-			for _, ct := range selContentTypes {
-				data[ct] = s.db.AllContent(ct)
-			}
-
-			sel.SetSelectData(data)
-		}
+		s.setSelectData(post)
 
 		m, err := admin.Manage(post.(editor.Editable), t)
 		if err != nil {
@@ -160,6 +153,12 @@ func (s *Handler) EditHandler(res http.ResponseWriter, req *http.Request) {
 			res.WriteHeader(http.StatusBadRequest)
 			return
 		}
+
+		if err := s.contentApp.Validate(pt, cid, req.PostForm); err != nil {
+			s.editInvalid(res, req, pt, err)
+			return
+		}
+
 		err = ext.Create(res, req)
 		if err != nil {
 			s.log.Errorf("[Create] error calling Create:", err)
@@ -253,6 +252,70 @@ func (s *Handler) EditHandler(res http.ResponseWriter, req *http.Request) {
 	}
 }
 
+// setSelectData gives post the items of the types it refers to, if it is
+// content.RefSelectable.
+func (s *Handler) setSelectData(post any) {
+	sel, ok := post.(content.RefSelectable)
+	if !ok {
+		return
+	}
+
+	data := make(map[string][][]byte)
+	for _, ct := range sel.SelectContentTypes() {
+		data[ct] = s.db.AllContent(ct)
+	}
+	sel.SetSelectData(data)
+}
+
+// editInvalid serves the editor again with the values posted for an item
+// of type t, and the fields in err failing validation.
+func (s *Handler) editInvalid(res http.ResponseWriter, req *http.Request, t string, err error) {
+	var errs validate.Errors
+	if !errors.As(err, &errs) {
+		s.log.Errorf("Error validating content: %v", err)
+		if err := s.res.err500(res); err != nil {
+			s.log.Errorf("Error response err 500: %s", err)
+		}
+		return
+	}
+
+	values := make(url.Values, len(req.PostForm))
+	for k, v := range req.PostForm {
+		values[k] = append([]string(nil), v...)
+	}
+	post, err := s.contentApp.DecodeContent(t, values)
+	if err != nil {
+		s.log.Errorf("Error decoding invalid content: %v", err)
+		if err := s.res.err400(res); err != nil {
+			s.log.Errorf("Error response err 400: %s", err)
+		}
+		return
+	}
+	s.setSelectData(post)
+
+	m, err := admin.ManageInvalid(post.(editor.Editable), t, errs.Fields())
+	if err != nil {
+		s.log.Errorf("Error rendering admin view: %v", err)
+		if err := s.res.err500(res); err != nil {
+			s.log.Errorf("Error response err 500: %s", err)
+		}
+		return
+	}
+
+	adminView, err := s.adminView.SubView(m)
+	if err != nil {
+		s.log.Errorf("Error rendering admin view: %v", err)
+		if err := s.res.err500(res); err != nil {
+			s.log.Errorf("Error response err 500: %s", err)
+		}
+		return
+	}
+
+	res.Header().Set("Content-Type", "text/html")
+	res.WriteHeader(http.StatusUnprocessableEntity)
+	res.Write(adminView)
+}
+
 func (s *Handler) DeleteHandler(res http.ResponseWriter, req *http.Request) {
 	if req.Method != http.MethodPost {
 		res.WriteHeader(http.StatusMethodNotAllowed)
@@ -493,7 +556,7 @@ func (s *Handler) ApproveContentHandler(res http.ResponseWriter, req *http.Reque
 	}
 
 	// set the target in the context so user can get saved value from db in hook
-	ctx := context.WithValue(req.Context(), "target", fmt.Sprintf("%s:%d", t, id))
+	ctx := context.WithValue(req.Context(), "target", fmt.Sprintf("%s:%s", t, id))
 	req = req.WithContext(ctx)
 
 	err = hook.AfterSave(res, req)
@@ -511,6 +574,6 @@ func (s *Handler) ApproveContentHandler(res http.ResponseWriter, req *http.Reque
 
 	// redirect to the new approved content's editor
 	redir := req.URL.Scheme + req.URL.Host + strings.TrimSuffix(req.URL.Path, "/approve")
-	redir += fmt.Sprintf("?type=%s&id=%d", t, id)
+	redir += fmt.Sprintf("?type=%s&id=%s", t, id)
 	http.Redirect(res, req, redir, http.StatusFound)
 }
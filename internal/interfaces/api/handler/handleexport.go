@@ -0,0 +1,112 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/tidwall/sjson"
+	"net/http"
+	"time"
+)
+
+// ApiExportContentHandler streams the contents of a user type as CSV,
+// NDJSON or a gzipped tar of a Hugo project, filtered by site, status and
+// creation date, e.g.
+// /api/contents/export?type=Post&format=hugo&site=1&since=2024-01-01
+// Hidden items are left out and omitted fields removed, as in
+// /api/contents.
+func (s *Handler) ApiExportContentHandler(res http.ResponseWriter, req *http.Request) {
+	s.export(res, req, s.contentApp.AllContentTypes(), func(ci any, data []byte) ([]byte, error) {
+		if h, ok := ci.(content.Hideable); ok {
+			if err := h.Hide(res, req); !errors.Is(err, content.ErrAllowHiddenItem) {
+				return nil, nil
+			}
+		}
+
+		om, ok := ci.(content.Omittable)
+		if !ok {
+			return data, nil
+		}
+		fields, err := om.Omit(res, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range fields {
+			if data, err = sjson.DeleteBytes(data, f); err != nil {
+				return nil, err
+			}
+		}
+		return data, nil
+	})
+}
+
+// ExportContentHandler is ApiExportContentHandler for the admin, which
+// exports any type, the admin ones too, as stored.
+func (s *Handler) ExportContentHandler(res http.ResponseWriter, req *http.Request) {
+	s.export(res, req, s.contentApp.AllTypes(), nil)
+}
+
+func (s *Handler) export(res http.ResponseWriter, req *http.Request, types map[string]content.Creator,
+	item func(ci any, data []byte) ([]byte, error)) {
+	if req.Method != http.MethodGet {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	t := q.Get("type")
+	if _, ok := types[t]; !ok {
+		res.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	format := valueobject.ExportFormat(q.Get("format"))
+	if format == "" {
+		format = valueobject.ExportCSV
+	}
+
+	f := valueobject.ExportFilter{
+		Site:   q.Get("site"),
+		Status: content.Status(q.Get("status")),
+		Item:   item,
+	}
+	var err error
+	if f.Since, err = parseExportDate(q.Get("since")); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if f.Until, err = parseExportDate(q.Get("until")); err != nil {
+		http.Error(res, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res.Header().Set("Content-Type", format.ContentType())
+	res.Header().Set("Content-Disposition",
+		fmt.Sprintf(`attachment; filename="%s-%d.%s"`, t, time.Now().Unix(), format.Ext()))
+	res.Header().Set("Cache-Control", "no-store")
+
+	w := &writeCounter{w: res}
+	n, err := s.contentApp.Export(w, t, format, f)
+	if err != nil {
+		s.log.Errorf("Error exporting %s as %s: %v", t, format, err)
+		if w.n == 0 {
+			res.Header().Del("Content-Disposition")
+			http.Error(res, err.Error(), http.StatusBadRequest)
+		}
+		return
+	}
+
+	s.log.Printf("Exported %d %s as %s", n, t, format)
+}
+
+// parseExportDate parses a date, e.g. 2024-01-31, or a time in RFC 3339.
+func parseExportDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
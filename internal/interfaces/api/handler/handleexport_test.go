@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/application"
+	adminFactory "github.com/mdfriday/hugoverse/internal/domain/admin/factory"
+	"github.com/mdfriday/hugoverse/internal/domain/content"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/admin"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+// member is a type hiding the members not listed, and omitting their
+// email unless asked by the admin.
+type member struct {
+	valueobject.Item
+
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Listed bool   `json:"listed"`
+}
+
+func (m *member) Hide(http.ResponseWriter, *http.Request) error {
+	if m.Listed {
+		return content.ErrAllowHiddenItem
+	}
+	return nil
+}
+
+func (m *member) Omit(http.ResponseWriter, *http.Request) ([]string, error) {
+	return []string{"email"}, nil
+}
+
+func TestExportContentHandler(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := database.New(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	ct := application.NewContentServer(d)
+	ct.UserTypes["Member"] = func() interface{} { return new(member) }
+	d.RegisterContentBuckets(ct.AllContentTypeNames())
+	c.Assert(d.StartAdminDatabase(ct.AllAdminTypeNames()), qt.IsNil)
+	c.Assert(d.StartUserDir("export"), qt.IsNil)
+	defer d.Close()
+	a, err := adminFactory.NewAdmin(d)
+	c.Assert(err, qt.IsNil)
+
+	results := ct.Batch([]*valueobject.BatchOp{
+		{Op: valueobject.BatchCreate, Type: "Member", Values: url.Values{"name": {"ann"}, "email": {"ann@example.org"}, "listed": {"true"}}},
+		{Op: valueobject.BatchCreate, Type: "Member", Values: url.Values{"name": {"bob"}, "email": {"bob@example.org"}}},
+	}, true)
+	for i, r := range results {
+		c.Assert(r.OK(), qt.IsTrue, qt.Commentf("%d: %s", i, r.Error))
+	}
+
+	s := &Handler{log: loggers.NewDefault(), res: NewResponse(&admin.View{}), contentApp: ct, adminApp: a}
+	get := func(h http.HandlerFunc, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/contents/export?"+query, nil)
+		res := httptest.NewRecorder()
+		h(res, req)
+		return res
+	}
+
+	c.Run("API", func(c *qt.C) {
+		res := get(s.ApiExportContentHandler, "type=Member&format=csv")
+		c.Assert(res.Code, qt.Equals, http.StatusOK)
+		body := res.Body.String()
+		c.Assert(body, qt.Contains, "ann")
+		c.Assert(body, qt.Not(qt.Contains), "bob")
+		c.Assert(body, qt.Not(qt.Contains), "ann@example.org")
+
+		res = get(s.ApiExportContentHandler, "type=Member&format=ndjson")
+		c.Assert(res.Code, qt.Equals, http.StatusOK)
+		c.Assert(res.Body.String(), qt.Contains, `"name":"ann"`)
+		c.Assert(res.Body.String(), qt.Not(qt.Contains), "email")
+
+		// The admin types are not content.
+		for _, t := range ct.AllAdminTypeNames() {
+			c.Assert(get(s.ApiExportContentHandler, "type="+t).Code, qt.Equals, http.StatusNotFound, qt.Commentf(t))
+		}
+	})
+
+	c.Run("Admin", func(c *qt.C) {
+		res := get(s.ExportContentHandler, "type=Member&format=ndjson")
+		c.Assert(res.Code, qt.Equals, http.StatusOK)
+		c.Assert(res.Body.String(), qt.Contains, `"email":"bob@example.org"`)
+
+		for _, t := range ct.AllAdminTypeNames() {
+			c.Assert(get(s.ExportContentHandler, "type="+t).Code, qt.Equals, http.StatusOK, qt.Commentf(t))
+		}
+	})
+}
@@ -0,0 +1,69 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/ratelimit"
+	"io"
+	"net/http"
+)
+
+// maxGraphQLBody bounds the body of a GraphQL POST request.
+const maxGraphQLBody = 1 << 20
+
+// GraphQLHandler runs a query of the GraphQL API, sent as JSON in the body
+// of a POST, e.g. {"query":"{ post(id: 1) { title } }"}, or in the query
+// string of a GET, e.g. /api/graphql?query={post(id:1){title}}. A query
+// persisted before may be sent by hash only, in the persistedQuery
+// extension. Queries of page trees spend the build budget too.
+func (s *Handler) GraphQLHandler(res http.ResponseWriter, req *http.Request) {
+	r := &valueobject.GraphQLRequest{}
+
+	switch req.Method {
+	case http.MethodGet:
+		q := req.URL.Query()
+		r.Query = q.Get("query")
+		r.OperationName = q.Get("operationName")
+		for name, v := range map[string]any{"variables": &r.Variables, "extensions": &r.Extensions} {
+			if q.Get(name) == "" {
+				continue
+			}
+			if err := json.Unmarshal([]byte(q.Get(name)), v); err != nil {
+				http.Error(res, "invalid "+name+": "+err.Error(), http.StatusBadRequest)
+				return
+			}
+		}
+	case http.MethodPost:
+		b, err := io.ReadAll(io.LimitReader(req.Body, maxGraphQLBody))
+		if err != nil {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err := json.Unmarshal(b, r); err != nil {
+			http.Error(res, "invalid request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	default:
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	// Page trees are built from the projects of their sites.
+	if s.graphQL.QueriesPageTree(r) {
+		if wait, ok := s.limiter.Allow(ratelimit.Build, req); !ok {
+			ratelimit.TooManyRequests(res, wait)
+			return
+		}
+	}
+
+	result := s.graphQL.Execute(res, req, r)
+
+	j, err := json.Marshal(result)
+	if err != nil {
+		s.log.Errorf("Error marshalling GraphQL result: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.res.Json(res, j)
+}
@@ -0,0 +1,250 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/mdfriday/hugoverse/internal/application"
+	contentEntity "github.com/mdfriday/hugoverse/internal/domain/content/entity"
+	"github.com/mdfriday/hugoverse/internal/domain/job"
+	jobEntity "github.com/mdfriday/hugoverse/internal/domain/job/entity"
+	"github.com/mdfriday/hugoverse/internal/domain/job/valueobject"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/token"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+const jobsPath = "/api/jobs"
+
+// jobTask is the task of a job. userContent opens the content of the user
+// who submitted the job, apart from the user database the requests start,
+// when the task gets to write to it: the database of an idle user is
+// closed while the job may still be queued.
+type jobTask func(ctx context.Context, log job.Logger, userContent func() (*contentEntity.Content, error)) (string, error)
+
+// JobsHandler serves the build, preview and deploy jobs of the user:
+//
+//	GET  /api/jobs              lists the jobs, the most recent first
+//	GET  /api/jobs/{id}         returns the job with its log
+//	GET  /api/jobs/{id}/log     streams the log as server-sent events
+//	POST /api/jobs/{id}/cancel  cancels the job
+//
+// The jobs of other users are not found.
+func (s *Handler) JobsHandler(res http.ResponseWriter, req *http.Request) {
+	// The status changes while the job runs, never serve it from a cache.
+	res.Header().Del("ETag")
+	res.Header().Set("Cache-Control", "no-store")
+
+	owner, err := token.GetEmail(req)
+	if err != nil {
+		res.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	rest := strings.Trim(strings.TrimPrefix(req.URL.Path, jobsPath), "/")
+	if rest == "" {
+		if req.Method != http.MethodGet {
+			res.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		s.listJobs(res, owner)
+		return
+	}
+
+	id, action, _ := strings.Cut(rest, "/")
+	switch {
+	case action == "" && req.Method == http.MethodGet:
+		j, err := s.ownJob(owner, id)
+		if err != nil {
+			s.jobError(res, id, err)
+			return
+		}
+		s.jobJSON(res, http.StatusOK, j)
+	case action == "log" && req.Method == http.MethodGet:
+		s.streamJobLog(res, req, owner, id)
+	case action == "cancel" && req.Method == http.MethodPost:
+		if _, err := s.ownJob(owner, id); err != nil {
+			s.jobError(res, id, err)
+			return
+		}
+		j, err := s.jobs.Cancel(id)
+		if err != nil {
+			s.jobError(res, id, err)
+			return
+		}
+		s.jobJSON(res, http.StatusOK, j)
+	case action == "" || action == "log" || action == "cancel":
+		res.WriteHeader(http.StatusMethodNotAllowed)
+	default:
+		res.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// ownJob returns the job id if owner submitted it.
+func (s *Handler) ownJob(owner, id string) (*valueobject.Job, error) {
+	j, err := s.jobs.Job(id)
+	if err != nil {
+		return nil, err
+	}
+	if j.Owner != owner {
+		return nil, jobEntity.ErrJobNotFound
+	}
+	return j, nil
+}
+
+// enqueue adds a job running task on the content id of type t on behalf
+// of the user of req, and responds with it for the client to follow at
+// /api/jobs/{id}.
+func (s *Handler) enqueue(res http.ResponseWriter, req *http.Request, kind job.Kind, t, id string, task jobTask) {
+	owner, err := token.GetEmail(req)
+	if err != nil {
+		res.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	j, err := s.jobs.Enqueue(owner, kind, t, id, func(ctx context.Context, log job.Logger) (string, error) {
+		return task(ctx, log, func() (*contentEntity.Content, error) {
+			d, err := s.db.UserDatabase(owner)
+			if err != nil {
+				return nil, fmt.Errorf("open user database: %w", err)
+			}
+			return s.contentApp.ForRepo(d), nil
+		})
+	})
+	if err != nil {
+		s.log.Errorf("Error enqueueing %s job: %v", kind, err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Location", jobsPath+"/"+j.ID)
+	s.jobJSON(res, http.StatusAccepted, j)
+}
+
+// buildSite builds the site in target for a job, writing the build log to
+// the log of the job and stopping once ctx is done.
+func buildSite(ctx context.Context, target string, log job.Logger) error {
+	b, err := application.NewBuilder(application.BuildOptions{
+		WorkingDir: target,
+		Logger:     loggers.NewPrintfLogger(log.Printf),
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.BuildContext(ctx)
+}
+
+func (s *Handler) listJobs(res http.ResponseWriter, owner string) {
+	jobs, err := s.jobs.Jobs(owner)
+	if err != nil {
+		s.log.Errorf("Error listing jobs: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var data []json.RawMessage
+	for _, j := range jobs {
+		b, err := json.Marshal(j)
+		if err != nil {
+			s.log.Errorf("Error marshalling job %s: %v", j.ID, err)
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		data = append(data, b)
+	}
+
+	j, err := s.res.FmtJSON(data...)
+	if err != nil {
+		s.log.Errorf("Error formatting JSON: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.res.Json(res, j)
+}
+
+// streamJobLog sends each log line as a log event with the line number
+// as its id, so a reconnecting client resumes after the Last-Event-ID,
+// and ends with a done event holding the finished job.
+func (s *Handler) streamJobLog(res http.ResponseWriter, req *http.Request, owner, id string) {
+	from := 0
+	if last := req.Header.Get("Last-Event-ID"); last != "" {
+		n, err := strconv.Atoi(last)
+		if err != nil || n < 0 {
+			res.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		from = n + 1
+	}
+
+	// Fail before the stream starts if there is no such job.
+	if _, err := s.ownJob(owner, id); err != nil {
+		s.jobError(res, id, err)
+		return
+	}
+
+	rc := http.NewResponseController(res)
+	res.Header().Set("Content-Type", "text/event-stream")
+	res.Header().Set("X-Accel-Buffering", "no")
+	res.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		s.log.Errorf("Error streaming job %s: %v", id, err)
+		return
+	}
+
+	j, err := s.jobs.Follow(req.Context(), id, from, func(n int, line string) error {
+		// A line break in the data starts another data field.
+		data := strings.ReplaceAll(line, "\n", "\ndata: ")
+		if _, err := fmt.Fprintf(res, "id: %d\nevent: log\ndata: %s\n\n", n, data); err != nil {
+			return err
+		}
+		return rc.Flush()
+	})
+	if err != nil {
+		// The client went away.
+		return
+	}
+
+	b, err := json.Marshal(j)
+	if err != nil {
+		s.log.Errorf("Error marshalling job %s: %v", id, err)
+		return
+	}
+	if _, err := fmt.Fprintf(res, "event: done\ndata: %s\n\n", b); err == nil {
+		_ = rc.Flush()
+	}
+}
+
+func (s *Handler) jobJSON(res http.ResponseWriter, status int, j *valueobject.Job) {
+	b, err := json.Marshal(j)
+	if err != nil {
+		s.log.Errorf("Error marshalling job %s: %v", j.ID, err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	data, err := s.res.FmtJSON(b)
+	if err != nil {
+		s.log.Errorf("Error formatting JSON: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	res.Header().Set("Content-Type", "application/json")
+	res.WriteHeader(status)
+	s.res.Json(res, data)
+}
+
+func (s *Handler) jobError(res http.ResponseWriter, id string, err error) {
+	if errors.Is(err, jobEntity.ErrJobNotFound) {
+		res.WriteHeader(http.StatusNotFound)
+		return
+	}
+	s.log.Errorf("Error getting job %s: %v", id, err)
+	res.WriteHeader(http.StatusInternalServerError)
+}
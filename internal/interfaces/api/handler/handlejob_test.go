@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/domain/job"
+	jobEntity "github.com/mdfriday/hugoverse/internal/domain/job/entity"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/admin"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/token"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+	"github.com/nilslice/jwt"
+)
+
+type memJobs struct {
+	mu   sync.Mutex
+	jobs map[string][]byte
+}
+
+func (m *memJobs) PutJob(id string, data []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.jobs[id] = data
+	return nil
+}
+
+func (m *memJobs) GetJob(id string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.jobs[id], nil
+}
+
+func (m *memJobs) AllJobs() ([][]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var all [][]byte
+	for _, d := range m.jobs {
+		all = append(all, d)
+	}
+	return all, nil
+}
+
+func (m *memJobs) DeleteJob(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.jobs, id)
+	return nil
+}
+
+func TestJobsHandlerOwner(t *testing.T) {
+	c := qt.New(t)
+
+	jwt.Secret([]byte("secret"))
+	q := &jobEntity.Queue{Repo: &memJobs{jobs: make(map[string][]byte)}}
+	q.Start(1)
+	defer q.Close()
+
+	release := make(chan struct{})
+	defer close(release)
+	j, err := q.Enqueue("alice@example.org", job.KindBuild, "Site", "1", func(ctx context.Context, log job.Logger) (string, error) {
+		<-release
+		return "", nil
+	})
+	c.Assert(err, qt.IsNil)
+
+	s := &Handler{log: loggers.NewDefault(), res: NewResponse(&admin.View{}), jobs: q}
+	serve := func(method, path, email string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		tok, _, err := token.New(email)
+		c.Assert(err, qt.IsNil)
+		req.Header.Set("Authorization", "Bearer "+tok)
+		res := httptest.NewRecorder()
+		s.JobsHandler(res, req)
+		return res
+	}
+
+	res := serve(http.MethodGet, jobsPath+"/"+j.ID, "alice@example.org")
+	c.Assert(res.Code, qt.Equals, http.StatusOK)
+	c.Assert(res.Body.String(), qt.Contains, j.ID)
+	res = serve(http.MethodGet, jobsPath, "alice@example.org")
+	c.Assert(res.Body.String(), qt.Contains, j.ID)
+
+	// Other users don't see the job, nor cancel it.
+	res = serve(http.MethodGet, jobsPath, "bob@example.org")
+	c.Assert(res.Code, qt.Equals, http.StatusOK)
+	c.Assert(res.Body.String(), qt.Not(qt.Contains), j.ID)
+	for _, test := range []struct{ method, path string }{
+		{http.MethodGet, jobsPath + "/" + j.ID},
+		{http.MethodGet, jobsPath + "/" + j.ID + "/log"},
+		{http.MethodPost, jobsPath + "/" + j.ID + "/cancel"},
+	} {
+		res := serve(test.method, test.path, "bob@example.org")
+		c.Assert(res.Code, qt.Equals, http.StatusNotFound, qt.Commentf("%v", test))
+	}
+	current, err := q.Job(j.ID)
+	c.Assert(err, qt.IsNil)
+	c.Assert(current.Status.Done(), qt.IsFalse)
+
+	req := httptest.NewRequest(http.MethodGet, jobsPath, nil)
+	res = httptest.NewRecorder()
+	s.JobsHandler(res, req)
+	c.Assert(res.Code, qt.Equals, http.StatusUnauthorized)
+}
@@ -0,0 +1,487 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+
+	contentEntity "github.com/mdfriday/hugoverse/internal/domain/content/entity"
+	contentVO "github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	jobVO "github.com/mdfriday/hugoverse/internal/domain/job/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/openapi"
+)
+
+// OpenAPIHandler responds with the OpenAPI document of /api, generated from
+// the content types registered, for clients to be generated from.
+func (s *Handler) OpenAPIHandler(res http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		res.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	j, err := json.Marshal(OpenAPI(s.contentApp))
+	if err != nil {
+		s.log.Errorf("Error marshalling OpenAPI document: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.res.Json(res, j)
+}
+
+// apiDoc builds the OpenAPI document of /api.
+type apiDoc struct {
+	doc *openapi.Document
+	// types are the names of the content types.
+	types map[string]bool
+	// items is any item of the content types.
+	items *openapi.Schema
+}
+
+// OpenAPI returns the OpenAPI document of /api, with a schema by content
+// type of contentApp.
+func OpenAPI(contentApp *contentEntity.Content) *openapi.Document {
+	d := &apiDoc{doc: &openapi.Document{
+		OpenAPI: openapi.Version,
+		Info: openapi.Info{
+			Title:       "Hugoverse API",
+			Description: "The content, search, build, deploy and upload API of the CMS.",
+			Version:     "1",
+		},
+		Paths: make(map[string]*openapi.PathItem),
+		Components: openapi.Components{
+			Schemas: contentApp.Schemas(),
+			SecuritySchemes: map[string]*openapi.SecurityScheme{
+				"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+				"cookie": {Type: "apiKey", In: "cookie", Name: "_token"},
+			},
+		},
+	}}
+
+	var types []string
+	d.types = make(map[string]bool)
+	for name := range d.doc.Components.Schemas {
+		types = append(types, name)
+		d.types[name] = true
+	}
+	sort.Strings(types)
+	d.items = &openapi.Schema{}
+	for _, name := range types {
+		d.items.OneOf = append(d.items.OneOf, openapi.Ref(name))
+	}
+
+	d.contentPaths()
+	d.sitePaths()
+	d.jobPaths()
+	d.userPaths()
+	return d.doc
+}
+
+// component returns the schema of the API named name, a reference to it
+// unless a content type takes its name.
+func (d *apiDoc) component(name string, s *openapi.Schema) *openapi.Schema {
+	if d.types[name] {
+		return s
+	}
+	d.doc.Components.Schemas[name] = s
+	return openapi.Ref(name)
+}
+
+var security = []openapi.SecurityRequirement{{"bearer": {}}, {"cookie": {}}}
+
+func (d *apiDoc) path(path string) *openapi.PathItem {
+	p, ok := d.doc.Paths[path]
+	if !ok {
+		p = &openapi.PathItem{}
+		d.doc.Paths[path] = p
+	}
+	return p
+}
+
+func param(name, description string, required bool) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "query", Description: description, Required: required, Schema: openapi.String("")}
+}
+
+func enumParam(name, description string, values ...string) *openapi.Parameter {
+	p := param(name, description, false)
+	p.Schema.Enum = values
+	return p
+}
+
+func intParam(name, description string, def int) *openapi.Parameter {
+	return &openapi.Parameter{Name: name, In: "query", Description: description,
+		Schema: &openapi.Schema{Type: "integer", Default: def}}
+}
+
+// target are the parameters of the item an operation works on, e.g. a site.
+func target(what string) []*openapi.Parameter {
+	return []*openapi.Parameter{
+		param("type", "The content type of the "+what+", e.g. Site.", true),
+		param("id", "The id of the "+what+".", true),
+		enumParam("status", "The status of the "+what+", public if not set.", "public", "pending"),
+	}
+}
+
+func body(mediaType string, s *openapi.Schema) map[string]*openapi.MediaType {
+	return map[string]*openapi.MediaType{mediaType: {Schema: s}}
+}
+
+// listOf is the schema of the JSON responses of the API, {"data": [items]}.
+func listOf(items *openapi.Schema) map[string]*openapi.MediaType {
+	return body("application/json", openapi.Object(map[string]*openapi.Schema{"data": openapi.Array(items)}))
+}
+
+// responses are the responses of an operation by status code, with the
+// description of the error responses.
+func responses(ok string, r *openapi.Response, errs ...string) map[string]*openapi.Response {
+	m := map[string]*openapi.Response{ok: r}
+	// Every operation is rate limited.
+	for _, code := range append(errs, "429") {
+		m[code] = &openapi.Response{Description: errorDescriptions[code]}
+	}
+	return m
+}
+
+var errorDescriptions = map[string]string{
+	"400": "The request is missing parameters or has invalid ones.",
+	"401": "The request has no valid token.",
+	"404": "There is no such content type or item.",
+	"405": "The method is not allowed.",
+	"409": "The resource exists already, or is in use.",
+	"413": "The request is too large.",
+	"422": "The request can't be applied.",
+	"429": "The client sent too many requests, or failed to log in too many times. Retry after the seconds in the Retry-After header.",
+}
+
+func (d *apiDoc) contentPaths() {
+	count := intParam("count", "The number of items in a page, -1 for all.", 10)
+	offset := intParam("offset", "The page, a multiplier of count.", 0)
+	order := enumParam("order", "The order of the items by creation time, desc if not set.", "desc", "asc")
+	id := openapi.Object(map[string]*openapi.Schema{
+		"id":     openapi.String(""),
+		"type":   openapi.String(""),
+		"status": openapi.String(""),
+	})
+
+	d.path("/api/contents").Get = &openapi.Operation{
+		OperationID: "listContents",
+		Summary:     "Lists the public items of a content type, or the terms of a taxonomy of its items.",
+		Tags:        []string{"content"},
+		Parameters: []*openapi.Parameter{
+			param("type", "The content type, e.g. Post.", true),
+			param("taxonomy", "A taxonomy, e.g. tags, to list its terms instead.", false),
+			count, offset, order,
+		},
+		Responses: responses("200", &openapi.Response{Description: "The items.", Content: listOf(d.items)}, "400", "401", "404"),
+		Security:  security,
+	}
+
+	batchResult := d.component("BatchResult", openapi.SchemaOf(reflect.TypeOf(contentVO.BatchResult{})))
+	d.path("/api/contents/batch").Post = &openapi.Operation{
+		OperationID: "batchContents",
+		Summary:     "Applies ops creating, updating, deleting and approving items.",
+		Tags:        []string{"content"},
+		Parameters: []*openapi.Parameter{
+			enumParam("atomic", "Whether either all ops are applied or none is.", "true", "false"),
+		},
+		RequestBody: &openapi.RequestBody{
+			Description: `An op a line, e.g. {"op":"create","type":"Post","data":{"title":"Hello"}}`,
+			Required:    true,
+			Content:     body("application/x-ndjson", openapi.String("")),
+		},
+		Responses: responses("200", &openapi.Response{Description: "The result of each op, in order.", Content: listOf(batchResult)},
+			"400", "401", "405", "413", "422"),
+		Security: security,
+	}
+
+	d.path("/api/contents/export").Get = &openapi.Operation{
+		OperationID: "exportContents",
+		Summary:     "Exports the items of a content type.",
+		Tags:        []string{"content"},
+		Parameters: []*openapi.Parameter{
+			param("type", "The content type, e.g. Post.", true),
+			enumParam("format", "The format of the export.", string(contentVO.ExportCSV), string(contentVO.ExportNDJSON), string(contentVO.ExportHugo)),
+			param("site", "The id of a site, to export only its items.", false),
+			enumParam("status", "The status of the items, public if not set.", "public", "pending"),
+			{Name: "since", In: "query", Description: "Exports the items created since, e.g. 2024-01-31.", Schema: openapi.String("date")},
+			{Name: "until", In: "query", Description: "Exports the items created before.", Schema: openapi.String("date")},
+		},
+		Responses: responses("200", &openapi.Response{Description: "The export, as an attachment.", Content: map[string]*openapi.MediaType{
+			contentVO.ExportCSV.ContentType():    {Schema: openapi.String("")},
+			contentVO.ExportNDJSON.ContentType(): {Schema: openapi.String("")},
+			contentVO.ExportHugo.ContentType():   {Schema: openapi.String("binary")},
+		}}, "400", "401", "404", "405"),
+		Security: security,
+	}
+
+	d.path("/api/content").Get = &openapi.Operation{
+		OperationID: "getContent",
+		Summary:     "Gets an item.",
+		Tags:        []string{"content"},
+		Parameters:  target("item"),
+		Responses:   responses("200", &openapi.Response{Description: "The item.", Content: listOf(d.items)}, "400", "401", "404"),
+		Security:    security,
+	}
+	d.path("/api/content").Post = &openapi.Operation{
+		OperationID: "createContent",
+		Summary:     "Creates an item, pending approval unless its type trusts the client.",
+		Tags:        []string{"content"},
+		Parameters:  []*openapi.Parameter{param("type", "The content type, e.g. Post.", true)},
+		RequestBody: &openapi.RequestBody{
+			Description: "The fields of the item, as in the schema of its type.",
+			Required:    true,
+			Content: map[string]*openapi.MediaType{
+				"multipart/form-data":               {Schema: d.items},
+				"application/x-www-form-urlencoded": {Schema: d.items},
+				"application/json":                  {Schema: d.items},
+			},
+		},
+		Responses: responses("200", &openapi.Response{Description: "The id and status of the item.", Content: listOf(id)}, "400", "401", "404", "405"),
+		Security:  security,
+	}
+
+	d.path("/api/content/delete").Post = &openapi.Operation{
+		OperationID: "deleteContent",
+		Summary:     "Deletes an item, or rejects a pending one.",
+		Tags:        []string{"content"},
+		Parameters: []*openapi.Parameter{
+			enumParam("reject", "Whether the item is pending and rejected.", "true", "false"),
+		},
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: body("application/x-www-form-urlencoded", &openapi.Schema{
+				Type: "object",
+				Properties: map[string]*openapi.Schema{
+					"type":   openapi.String(""),
+					"id":     openapi.String(""),
+					"status": openapi.String(""),
+				},
+				Required: []string{"type", "id"},
+			}),
+		},
+		Responses: responses("200", &openapi.Response{Description: "The id and status of the item deleted.", Content: listOf(id)}, "400", "401", "404", "405"),
+		Security:  security,
+	}
+
+	d.path("/api/content/new").Post = &openapi.Operation{
+		OperationID: "newContent",
+		Summary:     "Creates the posts of a site from an archetype of its theme.",
+		Tags:        []string{"content"},
+		Parameters: append(target("site")[:2],
+			param("kind", "The archetype, e.g. posts, the default one if not set.", false),
+			param("path", "The path of the content in the site, e.g. posts/hello.md.", true),
+		),
+		Responses: responses("200", &openapi.Response{Description: "The site posts created.", Content: listOf(openapi.SchemaOf(reflect.TypeOf(newSitePost{})))},
+			"400", "401", "404", "405"),
+		Security: security,
+	}
+
+	d.path("/api/hash").Get = &openapi.Operation{
+		OperationID: "getContentByHash",
+		Summary:     "Finds an item by the hash of its fields.",
+		Tags:        []string{"content"},
+		Parameters: []*openapi.Parameter{
+			param("type", "The content type, e.g. Language.", true),
+			param("hash", "The hash of the item.", true),
+			enumParam("status", "The status of the item, public if not set.", "public", "pending"),
+		},
+		Responses: responses("200", &openapi.Response{Description: "The id of the item.", Content: listOf(id)}, "400", "401", "404", "422"),
+		Security:  security,
+	}
+
+	d.path("/api/search").Get = &openapi.Operation{
+		OperationID: "searchContents",
+		Summary:     "Searches the items of a content type.",
+		Tags:        []string{"search"},
+		Parameters: []*openapi.Parameter{
+			param("type", "The content type, e.g. Post.", true),
+			param("q", "The query.", true),
+			count, offset,
+		},
+		Responses: responses("200", &openapi.Response{Description: "The items found.", Content: listOf(d.items)}, "400", "401", "404"),
+		Security:  security,
+	}
+	d.path("/api/search2").Get = &openapi.Operation{
+		OperationID: "searchIndexExample",
+		Summary:     "Runs the example indexing and term query of the search index.",
+		Tags:        []string{"search"},
+		Responses:   responses("200", &openapi.Response{Description: "The query ran."}, "401", "404"),
+		Security:    security,
+		Deprecated:  true,
+	}
+
+	graphQLRequest := d.component("GraphQLRequest", openapi.SchemaOf(reflect.TypeOf(contentVO.GraphQLRequest{})))
+	graphQLResult := d.component("GraphQLResult", openapi.Object(map[string]*openapi.Schema{
+		"data": {Type: "object"},
+		"errors": openapi.Array(openapi.Object(map[string]*openapi.Schema{
+			"message":    openapi.String(""),
+			"locations":  openapi.Array(&openapi.Schema{Type: "object"}),
+			"path":       openapi.Array(&openapi.Schema{}),
+			"extensions": {Type: "object"},
+		})),
+	}))
+	result := &openapi.Response{Description: "The result of the query.", Content: body("application/json", graphQLResult)}
+	d.path("/api/graphql").Get = &openapi.Operation{
+		OperationID: "queryGraphQL",
+		Summary:     "Runs a query of the GraphQL API.",
+		Tags:        []string{"content"},
+		Parameters: []*openapi.Parameter{
+			param("query", "The query, e.g. { post(id: 1) { title } }.", false),
+			param("operationName", "The operation of the query to run.", false),
+			param("variables", "The variables of the query, as JSON.", false),
+			param("extensions", `The extensions, as JSON, e.g. {"persistedQuery":{"version":1,"sha256Hash":"..."}}.`, false),
+		},
+		Responses: responses("200", result, "400", "401"),
+		Security:  security,
+	}
+	d.path("/api/graphql").Post = &openapi.Operation{
+		OperationID: "postGraphQL",
+		Summary:     "Runs a query of the GraphQL API.",
+		Tags:        []string{"content"},
+		RequestBody: &openapi.RequestBody{Required: true, Content: body("application/json", graphQLRequest)},
+		Responses:   responses("200", result, "400", "401"),
+		Security:    security,
+	}
+
+	d.path("/api/openapi.json").Get = &openapi.Operation{
+		OperationID: "getOpenAPI",
+		Summary:     "Gets this document.",
+		Responses: responses("200", &openapi.Response{Description: "The OpenAPI document of the API.",
+			Content: body("application/json", &openapi.Schema{Type: "object"})}, "405"),
+	}
+}
+
+func (d *apiDoc) sitePaths() {
+	job := d.component("Job", openapi.SchemaOf(reflect.TypeOf(jobVO.Job{})))
+	accepted := &openapi.Response{Description: "The job enqueued, at the URL of the Location header.", Content: body("application/json", job)}
+
+	d.path("/api/build").Post = &openapi.Operation{
+		OperationID: "buildSite",
+		Summary:     "Builds a site.",
+		Tags:        []string{"build"},
+		Parameters:  target("site"),
+		Responses:   responses("202", accepted, "400", "401", "404"),
+		Security:    security,
+	}
+	d.path("/api/preview").Post = &openapi.Operation{
+		OperationID: "previewSite",
+		Summary:     "Builds a site and deploys it to a preview domain, the result of the job.",
+		Tags:        []string{"build"},
+		Parameters:  target("site"),
+		Responses:   responses("202", accepted, "400", "401", "404"),
+		Security:    security,
+	}
+	d.path("/api/deploy").Post = &openapi.Operation{
+		OperationID: "deploySite",
+		Summary:     "Deploys a site to a domain, building it first if needed.",
+		Tags:        []string{"build"},
+		Parameters:  target("site"),
+		RequestBody: &openapi.RequestBody{
+			Required: true,
+			Content: body("multipart/form-data", &openapi.Schema{
+				Type: "object",
+				Properties: map[string]*openapi.Schema{
+					"domain":     {Type: "string", Description: "The root domain, e.g. example.org."},
+					"host_name":  {Type: "string", Enum: []string{"Netlify"}},
+					"host_token": {Type: "string", Description: "The token of the host."},
+				},
+				Required: []string{"domain", "host_name", "host_token"},
+			}),
+		},
+		Responses: responses("202", accepted, "400", "401", "404", "409"),
+		Security:  security,
+	}
+
+	d.path("/api/shortcodes").Get = &openapi.Operation{
+		OperationID: "listShortcodes",
+		Summary:     "Lists the shortcodes of a site, with the schemas of their parameters.",
+		Tags:        []string{"build"},
+		Parameters:  []*openapi.Parameter{param("id", "The id of the site.", true)},
+		Responses:   responses("200", &openapi.Response{Description: "The shortcodes.", Content: listOf(&openapi.Schema{Type: "object"})}, "400", "401"),
+		Security:    security,
+	}
+
+	d.path("/api/uploads/{path}").Get = &openapi.Operation{
+		OperationID: "getUpload",
+		Summary:     "Gets an uploaded file.",
+		Tags:        []string{"upload"},
+		Parameters: []*openapi.Parameter{
+			{Name: "path", In: "path", Description: "The path of the file, e.g. 2024/01/logo.png.", Required: true, Schema: openapi.String("")},
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "The file.", Content: body("application/octet-stream", openapi.String("binary"))},
+			"404": {Description: "There is no such file."},
+		},
+	}
+}
+
+func (d *apiDoc) jobPaths() {
+	job := d.component("Job", openapi.SchemaOf(reflect.TypeOf(jobVO.Job{})))
+	jobID := &openapi.Parameter{Name: "id", In: "path", Description: "The id of the job.", Required: true, Schema: openapi.String("")}
+	found := &openapi.Response{Description: "The job.", Content: body("application/json", job)}
+
+	d.path("/api/jobs").Get = &openapi.Operation{
+		OperationID: "listJobs",
+		Summary:     "Lists the build, preview and deploy jobs, the most recent first.",
+		Tags:        []string{"build"},
+		Responses:   responses("200", &openapi.Response{Description: "The jobs.", Content: listOf(job)}, "401", "405"),
+		Security:    security,
+	}
+
+	d.path("/api/jobs/{id}").Parameters = []*openapi.Parameter{jobID}
+	d.path("/api/jobs/{id}").Get = &openapi.Operation{
+		OperationID: "getJob",
+		Summary:     "Gets a job with its log.",
+		Tags:        []string{"build"},
+		Responses:   responses("200", found, "401", "404", "405"),
+		Security:    security,
+	}
+	d.path("/api/jobs/{id}/log").Parameters = []*openapi.Parameter{jobID}
+	d.path("/api/jobs/{id}/log").Get = &openapi.Operation{
+		OperationID: "streamJobLog",
+		Summary:     "Streams the log of a job until it ends.",
+		Tags:        []string{"build"},
+		Responses: responses("200", &openapi.Response{Description: "The lines of the log, as server-sent events.",
+			Content: body("text/event-stream", openapi.String(""))}, "401", "404", "405"),
+		Security: security,
+	}
+	d.path("/api/jobs/{id}/cancel").Parameters = []*openapi.Parameter{jobID}
+	d.path("/api/jobs/{id}/cancel").Post = &openapi.Operation{
+		OperationID: "cancelJob",
+		Summary:     "Cancels a job.",
+		Tags:        []string{"build"},
+		Responses:   responses("200", found, "401", "404", "405"),
+		Security:    security,
+	}
+}
+
+func (d *apiDoc) userPaths() {
+	credentials := &openapi.RequestBody{
+		Required: true,
+		Content: body("application/x-www-form-urlencoded", &openapi.Schema{
+			Type: "object",
+			Properties: map[string]*openapi.Schema{
+				"email":    openapi.String("email"),
+				"password": openapi.String("password"),
+			},
+			Required: []string{"email", "password"},
+		}),
+	}
+	token := &openapi.Response{Description: "The token of the user, for the bearer scheme.", Content: listOf(openapi.String(""))}
+
+	d.path("/api/user").Post = &openapi.Operation{
+		OperationID: "registerUser",
+		Summary:     "Registers a user.",
+		Tags:        []string{"user"},
+		RequestBody: credentials,
+		Responses:   responses("201", token, "405", "409"),
+	}
+	d.path("/api/login").Post = &openapi.Operation{
+		OperationID: "login",
+		Summary:     "Logs a user in.",
+		Tags:        []string{"user"},
+		RequestBody: credentials,
+		Responses:   responses("201", token, "401", "405"),
+	}
+}
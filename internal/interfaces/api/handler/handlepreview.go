@@ -1,11 +1,14 @@
 package handler
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"github.com/mdfriday/hugoverse/internal/application"
 	"github.com/mdfriday/hugoverse/internal/domain/content"
+	contentEntity "github.com/mdfriday/hugoverse/internal/domain/content/entity"
 	"github.com/mdfriday/hugoverse/internal/domain/content/valueobject"
+	"github.com/mdfriday/hugoverse/internal/domain/job"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/form"
 	"github.com/mdfriday/hugoverse/pkg/fs/static"
 	"github.com/mdfriday/hugoverse/pkg/rand"
@@ -15,6 +18,9 @@ import (
 	"path"
 )
 
+// PreviewContentHandler writes the project of the site and enqueues a job
+// building it and deploying it to a preview domain, responding with the
+// job. The job result is the URL of the preview.
 func (s *Handler) PreviewContentHandler(res http.ResponseWriter, req *http.Request) {
 	q := req.URL.Query()
 	id := q.Get("id")
@@ -47,68 +53,56 @@ func (s *Handler) PreviewContentHandler(res http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	t, err = s.contentApp.BuildTarget(t, id, status)
+	target, err := s.contentApp.BuildTarget(t, id, status)
 	if err != nil {
 		s.log.Errorf("Error building: %v", err)
 		res.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	err = application.GenerateStaticSiteWithTarget(t)
-	if err != nil {
-		s.log.Errorf("Error preview site %s for deployment with error : %v", id, err)
-		s.handlerError(res, req, err)
-		return
-	}
-
-	d := &valueobject.Domain{
-		Root:  "app.mdfriday.com",
-		Sub:   fmt.Sprintf("%s-%s", "mdf", rand.ShortString(6)),
-		Owner: "MDFriday",
-	}
-
-	preview, err := s.contentApp.NewPreview(d)
-	if err != nil {
-		s.log.Errorf("Error new preview: %v", err)
-		res.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	sd := &valueobject.Deployment{
-		SiteName: preview.SiteName,
-		HostName: preview.HostName,
-		Status:   preview.Status,
-	}
-
-	err = application.DeployToNetlify(t, sd, d, s.adminApp.Netlify.Token())
-	if err != nil {
-		s.log.Errorf("Error building: %v", err)
-		res.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	preview.SiteID = sd.SiteID
-	if err := s.contentApp.UpdateContentObject(preview); err != nil {
-		s.log.Errorf("Error updating preview: %v", err)
-		res.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	jsonBytes, err := json.Marshal("https://" + d.FullDomain())
-	if err != nil {
-		s.log.Errorf("Error marshalling token: %v", err)
-		return
-	}
-
-	j, err := s.res.FmtJSON(jsonBytes)
-	if err != nil {
-		s.log.Errorf("Error formatting JSON: %v", err)
-		res.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-
-	res.WriteHeader(http.StatusOK)
-	s.res.Json(res, j)
+	s.enqueue(res, req, job.KindPreview, t, id, func(ctx context.Context, log job.Logger, userContent func() (*contentEntity.Content, error)) (string, error) {
+		log.Printf("Building site %s in %s", id, target)
+		if err := buildSite(ctx, target, log); err != nil {
+			return "", err
+		}
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		contentApp, err := userContent()
+		if err != nil {
+			return "", err
+		}
+
+		d := &valueobject.Domain{
+			Root:  "app.mdfriday.com",
+			Sub:   fmt.Sprintf("%s-%s", "mdf", rand.ShortString(6)),
+			Owner: "MDFriday",
+		}
+
+		preview, err := contentApp.NewPreview(d)
+		if err != nil {
+			return "", fmt.Errorf("new preview: %w", err)
+		}
+
+		sd := &valueobject.Deployment{
+			SiteName: preview.SiteName,
+			HostName: preview.HostName,
+			Status:   preview.Status,
+		}
+
+		log.Printf("Deploying preview to %s", d.FullDomain())
+		if err := application.DeployToNetlify(target, sd, d, s.adminApp.Netlify.Token()); err != nil {
+			return "", fmt.Errorf("deploy preview: %w", err)
+		}
+
+		preview.SiteID = sd.SiteID
+		if err := contentApp.UpdateContentObject(preview); err != nil {
+			return "", fmt.Errorf("update preview: %w", err)
+		}
+
+		return "https://" + d.FullDomain(), nil
+	})
 }
 
 func (s *Handler) PreviewContentHandlerLocal(res http.ResponseWriter, req *http.Request) {
@@ -146,7 +140,7 @@ func (s *Handler) PreviewContentHandlerLocal(res http.ResponseWriter, req *http.
 	err = application.GenerateStaticSiteWithTarget(t)
 	if err != nil {
 		s.log.Errorf("Error building site %s for preview with error : %v", id, err)
-		res.WriteHeader(http.StatusInternalServerError)
+		s.handlerBuildError(res, err)
 		return
 	}
 
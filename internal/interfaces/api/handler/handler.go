@@ -4,9 +4,11 @@ import (
 	"github.com/mdfriday/hugoverse/internal/application"
 	adminEntity "github.com/mdfriday/hugoverse/internal/domain/admin/entity"
 	contentEntity "github.com/mdfriday/hugoverse/internal/domain/content/entity"
+	jobEntity "github.com/mdfriday/hugoverse/internal/domain/job/entity"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/admin"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/auth"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/ratelimit"
 	"github.com/mdfriday/hugoverse/pkg/loggers"
 	"html/template"
 )
@@ -19,14 +21,19 @@ type Handler struct {
 
 	db         *database.Database
 	contentApp *contentEntity.Content
+	graphQL    *contentEntity.GraphQL
 	adminApp   *adminEntity.Admin
 	adminView  *admin.View
+	jobs       *jobEntity.Queue
+	limiter    *ratelimit.Limiter
 
 	auth *auth.Auth
 }
 
 func New(log loggers.Logger, db *database.Database,
-	contentApp *contentEntity.Content, adminApp *adminEntity.Admin) *Handler {
+	contentApp *contentEntity.Content, graphQL *contentEntity.GraphQL,
+	adminApp *adminEntity.Admin, jobs *jobEntity.Queue,
+	limiter *ratelimit.Limiter) *Handler {
 
 	adminView := &admin.View{
 		Logo:       adminApp.Name(),
@@ -44,8 +51,11 @@ func New(log loggers.Logger, db *database.Database,
 
 		db:         db,
 		contentApp: contentApp,
+		graphQL:    graphQL,
 		adminApp:   adminApp,
 		adminView:  adminView,
+		jobs:       jobs,
+		limiter:    limiter,
 
 		auth: &auth.Auth{},
 	}
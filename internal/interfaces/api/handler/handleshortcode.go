@@ -0,0 +1,52 @@
+package handler
+
+import (
+	"encoding/json"
+	"github.com/mdfriday/hugoverse/internal/application"
+	"net/http"
+)
+
+// ShortcodesHandler lists the shortcodes available to a site, with their
+// parameter schemas, for the editor's insert shortcode dialog.
+func (s *Handler) ShortcodesHandler(res http.ResponseWriter, req *http.Request) {
+	id := req.URL.Query().Get("id")
+	if id == "" {
+		res.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	target, clean, err := s.contentApp.ConfigTarget(id)
+	if err != nil {
+		s.log.Errorf("Error writing config of site %s: %v", id, err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	defer clean()
+
+	shortcodes, err := application.ShortcodesWithTarget(target)
+	if err != nil {
+		s.log.Errorf("Error loading shortcodes of site %s: %v", id, err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var data []json.RawMessage
+	for _, sc := range shortcodes {
+		b, err := json.Marshal(sc)
+		if err != nil {
+			s.log.Errorf("Error marshalling shortcode %s: %v", sc.Name, err)
+			res.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		data = append(data, b)
+	}
+
+	j, err := s.res.FmtJSON(data...)
+	if err != nil {
+		s.log.Errorf("Error formatting JSON: %v", err)
+		res.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	s.res.Json(res, j)
+}
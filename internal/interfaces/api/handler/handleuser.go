@@ -2,6 +2,7 @@ package handler
 
 import (
 	"encoding/json"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/ratelimit"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/token"
 	"log"
 	"net/http"
@@ -79,12 +80,20 @@ func (s *Handler) UserLoginHandler(res http.ResponseWriter, req *http.Request) {
 		email := strings.ToLower(req.FormValue("email"))
 		pwd := req.FormValue("password")
 
+		if wait, locked := s.limiter.LoginLocked(req, email); locked {
+			s.log.Errorf("Login of %s locked out for %s", email, wait)
+			ratelimit.TooManyRequests(res, wait)
+			return
+		}
+
 		err = s.adminApp.ValidateUser(email, pwd)
 		if err != nil {
 			s.log.Errorf("Error validating user: %v", err)
+			s.limiter.LoginFailed(req, email)
 			res.WriteHeader(http.StatusUnauthorized)
 			return
 		}
+		s.limiter.LoginSucceeded(req, email)
 
 		nt, _, err := token.New(email)
 		if err != nil {
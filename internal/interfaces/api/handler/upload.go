@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/mdfriday/hugoverse/internal/domain/admin"
+	"github.com/mdfriday/hugoverse/pkg/images"
 	"github.com/mdfriday/hugoverse/pkg/timestamp"
+	"image"
 	"io"
 	"mime/multipart"
 	"net/http"
@@ -103,21 +105,31 @@ func (s *Handler) StoreFiles(req *http.Request) (map[string]string, error) {
 
 		// add upload information to db
 		go func() {
-			s.storeFileInfo(size, filename, urlPath, fds)
+			s.storeFileInfo(size, filename, absPath, urlPath, fds)
 		}()
 	}
 
 	return urlPaths, nil
 }
 
-func (s *Handler) storeFileInfo(size int64, filename, urlPath string, fds []*multipart.FileHeader) {
+func (s *Handler) storeFileInfo(size int64, filename, absPath, urlPath string, fds []*multipart.FileHeader) {
+	contentType := fds[0].Header.Get("Content-Type")
 	data := url.Values{
 		"name":           []string{filename},
 		"path":           []string{urlPath},
-		"content_type":   []string{fds[0].Header.Get("Content-Type")},
+		"content_type":   []string{contentType},
 		"content_length": []string{fmt.Sprintf("%d", size)},
 	}
 
+	if strings.HasPrefix(contentType, "image/") && contentType != "image/svg+xml" {
+		if config, err := probeImageConfig(absPath); err != nil {
+			s.log.Warnf("Failed to read the dimensions of uploaded image %s: %v", filename, err)
+		} else {
+			data.Set("width", fmt.Sprintf("%d", config.Width))
+			data.Set("height", fmt.Sprintf("%d", config.Height))
+		}
+	}
+
 	s.log.Debugln("storeFileInfo: ", filename, urlPath, fmt.Sprintf("%d", size))
 
 	if err := s.adminApp.NewUpload(data); err != nil {
@@ -125,6 +137,17 @@ func (s *Handler) storeFileInfo(size int64, filename, urlPath string, fds []*mul
 	}
 }
 
+func probeImageConfig(filename string) (image.Config, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return image.Config{}, err
+	}
+	defer f.Close()
+
+	config, _, err := images.DecodeConfig(f)
+	return config, err
+}
+
 func (s *Handler) deleteUploadFromDisk(id string) error {
 	// get data on file
 	data, err := s.adminApp.GetUpload(id)
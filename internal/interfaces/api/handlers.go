@@ -3,37 +3,71 @@ package api
 import (
 	"fmt"
 	"github.com/mdfriday/hugoverse/internal/application"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/ratelimit"
 	"net/http"
 )
 
 func (s *Server) registerContentHandler() {
 	s.mux.HandleFunc("/api/contents", s.wrapContentHandler(s.handler.ApiContentsHandler))
+	s.mux.HandleFunc("/api/contents/batch", s.wrapBuildHandler(s.handler.BatchContentHandler))
+	s.mux.HandleFunc("/api/contents/export", s.wrapStreamHandler(
+		s.limit.Limit(ratelimit.Build, s.handler.ApiExportContentHandler)))
 	s.mux.HandleFunc("/api/content", s.wrapContentHandler(
 		s.content.Handle(s.handler.ContentHandler)))
 	s.mux.HandleFunc("/api/content/delete", s.wrapContentHandler(
 		s.content.Handle(s.handler.DeleteContentHandler)))
+	s.mux.HandleFunc("/api/content/new", s.wrapBuildHandler(s.handler.NewContentHandler))
 
 	s.mux.HandleFunc("/api/hash", s.wrapContentHandler(s.handler.HashHandler))
 
 	s.mux.HandleFunc("/api/search", s.wrapContentHandler(s.handler.SearchContentHandler))
 	s.mux.HandleFunc("/api/search2", s.wrapContentHandler(s.handler.SearchContentHandler2))
 
-	s.mux.HandleFunc("/api/preview", s.wrapContentHandler(s.handler.PreviewContentHandler))
-	s.mux.HandleFunc("/api/build", s.wrapContentHandler(s.handler.BuildContentHandler))
-	s.mux.HandleFunc("/api/deploy", s.wrapContentHandler(s.handler.DeployContentHandler))
+	s.mux.HandleFunc("/api/preview", s.wrapBuildHandler(s.handler.PreviewContentHandler))
+	s.mux.HandleFunc("/api/build", s.wrapBuildHandler(s.handler.BuildContentHandler))
+	s.mux.HandleFunc("/api/deploy", s.wrapBuildHandler(s.handler.DeployContentHandler))
+	s.mux.HandleFunc("/api/jobs", s.wrapStreamHandler(s.handler.JobsHandler))
+	s.mux.HandleFunc("/api/jobs/", s.wrapStreamHandler(s.handler.JobsHandler))
+
+	s.mux.HandleFunc("/api/shortcodes", s.wrapContentHandler(s.handler.ShortcodesHandler))
+
+	s.mux.HandleFunc("/api/graphql", s.wrapContentHandler(s.handler.GraphQLHandler))
+
+	// Served without a token, for clients to be generated from.
+	s.mux.HandleFunc("/api/openapi.json", s.record.Collect(s.cors.Handle(
+		s.limit.Limit(ratelimit.API, s.handler.OpenAPIHandler))))
 }
 
 func (s *Server) wrapContentHandler(handler http.HandlerFunc) http.HandlerFunc {
 	return s.record.Collect(
 		s.cors.Handle(
-			s.comp.Gzip(
+			s.limit.Limit(ratelimit.API,
+				s.comp.Gzip(
+					s.db.Open(
+						s.auth.Check(handler))))))
+}
+
+// wrapBuildHandler is wrapContentHandler spending the build budget too, once
+// authenticated, for builds, batches and exports are costly.
+func (s *Server) wrapBuildHandler(handler http.HandlerFunc) http.HandlerFunc {
+	return s.wrapContentHandler(s.limit.Limit(ratelimit.Build, handler))
+}
+
+// wrapStreamHandler is wrapContentHandler without the compression, which
+// would buffer the events of a stream.
+func (s *Server) wrapStreamHandler(handler http.HandlerFunc) http.HandlerFunc {
+	return s.record.Collect(
+		s.cors.Handle(
+			s.limit.Limit(ratelimit.API,
 				s.db.Open(
 					s.auth.Check(handler)))))
 }
 
 func (s *Server) registerUserHandler() {
-	s.mux.HandleFunc("/api/user", s.record.Collect(s.cors.Handle(s.content.Handle(s.handler.UserRegisterHandler))))
-	s.mux.HandleFunc("/api/login", s.record.Collect(s.cors.Handle(s.content.Handle(s.handler.UserLoginHandler))))
+	s.mux.HandleFunc("/api/user", s.record.Collect(s.cors.Handle(
+		s.limit.Limit(ratelimit.API, s.content.Handle(s.handler.UserRegisterHandler)))))
+	s.mux.HandleFunc("/api/login", s.record.Collect(s.cors.Handle(
+		s.limit.Limit(ratelimit.API, s.content.Handle(s.handler.UserLoginHandler)))))
 }
 
 func (s *Server) wrapAdminHandler(handler http.HandlerFunc) http.HandlerFunc {
@@ -51,6 +85,7 @@ func (s *Server) registerAdminHandler() {
 
 	s.mux.HandleFunc("/admin/contents", s.wrapAdminHandler(s.handler.ContentsHandler))
 	s.mux.HandleFunc("/admin/contents/search", s.wrapAdminHandler(s.handler.SearchHandler))
+	s.mux.HandleFunc("/admin/contents/export", s.wrapAdminHandler(s.handler.ExportContentHandler))
 
 	s.mux.HandleFunc("/admin/edit", s.wrapAdminHandler(s.handler.EditHandler))
 	s.mux.HandleFunc("/admin/edit/delete", s.wrapAdminHandler(s.handler.DeleteHandler))
@@ -63,6 +98,10 @@ func (s *Server) registerAdminHandler() {
 
 	s.mux.HandleFunc("/admin/init", s.handler.InitHandler)
 
+	// Guarded by the backup credentials, and compressed already.
+	s.mux.HandleFunc("/admin/backup", s.record.Collect(
+		s.limit.Limit(ratelimit.Build, s.handler.BackupHandler)))
+
 	s.mux.Handle("/admin/static/", s.cache.Control(
 		http.FileServer(adminStaticDir())))
 
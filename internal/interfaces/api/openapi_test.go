@@ -0,0 +1,90 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/internal/application"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/cache"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/cors"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/form"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/handler"
+	"github.com/mdfriday/hugoverse/pkg/openapi"
+)
+
+// routes records the patterns registered on a mux.
+type routes struct {
+	*http.ServeMux
+	patterns []string
+}
+
+func (r *routes) Handle(pattern string, h http.Handler) {
+	r.patterns = append(r.patterns, pattern)
+	r.ServeMux.Handle(pattern, h)
+}
+
+func (r *routes) HandleFunc(pattern string, h func(http.ResponseWriter, *http.Request)) {
+	r.patterns = append(r.patterns, pattern)
+	r.ServeMux.HandleFunc(pattern, h)
+}
+
+// described reports whether the route pattern is a path of doc, or a path
+// below it if it's a subtree, e.g. /api/jobs/.
+func described(doc *openapi.Document, pattern string) bool {
+	if !strings.HasSuffix(pattern, "/") {
+		return doc.Paths[pattern] != nil
+	}
+	for p := range doc.Paths {
+		if strings.HasPrefix(p, pattern) && len(p) > len(pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+func TestOpenAPI(t *testing.T) {
+	c := qt.New(t)
+
+	d, err := database.New(t.TempDir())
+	c.Assert(err, qt.IsNil)
+	defer d.Close()
+	contentApp := application.NewContentServer(d)
+
+	doc := handler.OpenAPI(contentApp)
+	for _, name := range contentApp.AllContentTypeNames() {
+		c.Assert(doc.Components.Schemas[name], qt.Not(qt.IsNil), qt.Commentf(name))
+	}
+
+	sitePost := doc.Components.Schemas["SitePost"]
+	c.Assert(sitePost.Properties["title"], qt.IsNil)
+	c.Assert(sitePost.Properties["path"], qt.DeepEquals, openapi.String(""))
+	c.Assert(sitePost.Properties["id"], qt.DeepEquals, &openapi.Schema{Type: "integer", Format: "int64"})
+	c.Assert(sitePost.Properties["site"].Description, qt.Equals, "A reference to a Site, e.g. /api/content?type=Site&id=1")
+
+	r := &routes{ServeMux: http.NewServeMux()}
+	ch := cache.New(nil, nil)
+	s := &Server{mux: r, content: &form.Content{}, cache: ch, cors: cors.New(nil, nil, ch)}
+	s.registerHandler()
+
+	for _, pattern := range r.patterns {
+		if strings.HasPrefix(pattern, "/api/") {
+			c.Check(described(doc, pattern), qt.IsTrue, qt.Commentf("route %s is not described", pattern))
+		}
+	}
+	// Nor is a path described without a route.
+	for p := range doc.Paths {
+		_, pattern := r.Handler(&http.Request{Method: http.MethodGet, URL: &url.URL{Path: p}})
+		c.Check(pattern, qt.Not(qt.Equals), "", qt.Commentf("path %s has no route", p))
+	}
+
+	b, err := json.Marshal(doc)
+	c.Assert(err, qt.IsNil)
+	var m map[string]any
+	c.Assert(json.Unmarshal(b, &m), qt.IsNil)
+	c.Assert(m["openapi"], qt.Equals, openapi.Version)
+}
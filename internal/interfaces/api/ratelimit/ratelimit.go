@@ -0,0 +1,367 @@
+// Package ratelimit limits the requests of the clients of the API with
+// token buckets, by IP address, user and API key, and locks logins out
+// after failed attempts.
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/token"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+type Controller interface {
+	RateLimitDisabled() bool
+	RateLimit() int
+	BuildRateLimit() int
+	LoginMaxFailures() int
+	LoginLockout() time.Duration
+	TrustedProxies() []netip.Prefix
+}
+
+// Budget is a budget of requests of a client, on its own bucket.
+type Budget string
+
+const (
+	// API is the budget of the requests to the API, a minute.
+	API Budget = "api"
+	// Build is the budget of builds, previews, deploys, batches, exports
+	// and queries of page trees, an hour, on top of API.
+	Build Budget = "build"
+	// Login is the budget of failed logins, before a lockout.
+	Login Budget = "login"
+)
+
+const (
+	// sweepEvery is how often idle buckets are dropped.
+	sweepEvery = time.Minute
+	// keepThrottled is how long throttled clients are shown.
+	keepThrottled = 24 * time.Hour
+)
+
+// Throttled is a client throttled on a budget, e.g. ip:10.0.0.1 on api.
+type Throttled struct {
+	Client string
+	Budget Budget
+	// Count is the requests refused.
+	Count int
+	First time.Time
+	Last  time.Time
+	// Until is when the client may send requests again.
+	Until time.Time
+}
+
+// bucket is a token bucket, refilled of a token every period/limit up to
+// limit.
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// lockout is the failed logins of a client.
+type lockout struct {
+	failures int
+	last     time.Time
+	until    time.Time
+}
+
+type Limiter struct {
+	adminApp Controller
+	log      loggers.Logger
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lockouts  map[string]*lockout
+	throttled map[string]*Throttled
+	swept     time.Time
+
+	now func() time.Time
+}
+
+func New(log loggers.Logger, adminApp Controller) *Limiter {
+	return &Limiter{
+		adminApp:  adminApp,
+		log:       log,
+		buckets:   make(map[string]*bucket),
+		lockouts:  make(map[string]*lockout),
+		throttled: make(map[string]*Throttled),
+		now:       time.Now,
+	}
+}
+
+// Limit wraps a HandlerFunc to respond 429 Too Many Requests, with the
+// seconds to wait in Retry-After, once a client of the request is out of
+// budget b.
+func (l *Limiter) Limit(b Budget, next http.HandlerFunc) http.HandlerFunc {
+	return http.HandlerFunc(func(res http.ResponseWriter, req *http.Request) {
+		if wait, ok := l.Allow(b, req); !ok {
+			TooManyRequests(res, wait)
+			return
+		}
+
+		next.ServeHTTP(res, req)
+	})
+}
+
+// Allow spends a request of req from budget b, or returns the wait until
+// the clients of req are in budget again, for handlers spending a budget
+// on some of their requests only.
+func (l *Limiter) Allow(b Budget, req *http.Request) (time.Duration, bool) {
+	if l.adminApp.RateLimitDisabled() {
+		return 0, true
+	}
+
+	limit, period := l.budget(b)
+	wait, ok := l.take(b, l.Clients(req), limit, period)
+	if !ok {
+		l.log.Printf("Throttled %s on %s for %s", req.RemoteAddr, b, wait)
+	}
+	return wait, ok
+}
+
+func (l *Limiter) budget(b Budget) (int, time.Duration) {
+	if b == Build {
+		return l.adminApp.BuildRateLimit(), time.Hour
+	}
+	return l.adminApp.RateLimit(), time.Minute
+}
+
+// TooManyRequests responds 429 Too Many Requests, to retry after wait.
+func TooManyRequests(res http.ResponseWriter, wait time.Duration) {
+	res.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+	http.Error(res, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
+
+// Clients returns the clients sending req: its IP address and, if it has a
+// valid token, its user and its API key, the token of its Authorization
+// header, e.g. ip:10.0.0.1, user:me@example.com and key:8c5b0a6e13a1.
+func (l *Limiter) Clients(req *http.Request) []string {
+	clients := []string{"ip:" + l.remoteIP(req)}
+
+	email, err := token.GetEmail(req)
+	if err != nil || email == "" {
+		return clients
+	}
+	clients = append(clients, "user:"+email)
+
+	if key := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer "); key != "" {
+		h := sha256.Sum256([]byte(key))
+		clients = append(clients, "key:"+hex.EncodeToString(h[:6]))
+	}
+	return clients
+}
+
+// remoteIP returns the IP address of the client of req, the peer of the
+// connection unless it is a trusted proxy. From a trusted proxy, it is the
+// last address of X-Forwarded-For which isn't of a trusted proxy, the
+// first if all are, or X-Real-IP if there is no X-Forwarded-For.
+func (l *Limiter) remoteIP(req *http.Request) string {
+	peer := req.RemoteAddr
+	if host, _, err := net.SplitHostPort(req.RemoteAddr); err == nil {
+		peer = host
+	}
+	trusted := l.adminApp.TrustedProxies()
+	ip, err := netip.ParseAddr(peer)
+	if err != nil || !isTrusted(trusted, ip) {
+		return peer
+	}
+
+	var hops []string
+	for _, h := range req.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(h, ",")...)
+	}
+	if len(hops) == 0 {
+		if xr, err := netip.ParseAddr(strings.TrimSpace(req.Header.Get("X-Real-IP"))); err == nil {
+			return xr.Unmap().String()
+		}
+		return peer
+	}
+
+	client := ip
+	for i := len(hops) - 1; i >= 0 && isTrusted(trusted, client); i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		client = hop.Unmap()
+	}
+	return client.String()
+}
+
+func isTrusted(trusted []netip.Prefix, ip netip.Addr) bool {
+	ip = ip.Unmap()
+	for _, p := range trusted {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// take takes a token of budget b from the bucket of each of clients, if
+// all have one, or returns the wait until they do.
+func (l *Limiter) take(b Budget, clients []string, limit int, period time.Duration) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	every := period / time.Duration(limit)
+	var wait time.Duration
+	var out []string
+	buckets := make([]*bucket, len(clients))
+	for i, c := range clients {
+		bk, ok := l.buckets[string(b)+" "+c]
+		if !ok {
+			bk = &bucket{tokens: float64(limit), last: now}
+			l.buckets[string(b)+" "+c] = bk
+		}
+		bk.tokens = math.Min(float64(limit), bk.tokens+float64(now.Sub(bk.last))/float64(every))
+		bk.last = now
+		buckets[i] = bk
+
+		if bk.tokens < 1 {
+			out = append(out, c)
+			if w := time.Duration((1 - bk.tokens) * float64(every)); w > wait {
+				wait = w
+			}
+		}
+	}
+
+	if len(out) > 0 {
+		for _, c := range out {
+			l.throttle(b, c, now, now.Add(wait))
+		}
+		return wait, false
+	}
+	for _, bk := range buckets {
+		bk.tokens--
+	}
+	return 0, true
+}
+
+func (l *Limiter) throttle(b Budget, client string, now, until time.Time) {
+	key := string(b) + " " + client
+	t, ok := l.throttled[key]
+	if !ok {
+		t = &Throttled{Client: client, Budget: b, First: now}
+		l.throttled[key] = t
+	}
+	t.Count++
+	t.Last = now
+	t.Until = until
+}
+
+// LoginLocked returns the wait until the IP address of req and the account
+// of email may log in again, if either is locked out.
+func (l *Limiter) LoginLocked(req *http.Request, email string) (time.Duration, bool) {
+	if l.adminApp.RateLimitDisabled() {
+		return 0, false
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	var wait time.Duration
+	for _, c := range l.loginClients(req, email) {
+		if lo, ok := l.lockouts[c]; ok && lo.until.After(now) {
+			l.throttle(Login, c, now, lo.until)
+			wait = max(wait, lo.until.Sub(now))
+		}
+	}
+	return wait, wait > 0
+}
+
+// LoginFailed counts a failed login from the IP address of req to the
+// account of email, locking both out after too many in a row.
+func (l *Limiter) LoginFailed(req *http.Request, email string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	window := l.adminApp.LoginLockout()
+	for _, c := range l.loginClients(req, email) {
+		lo, ok := l.lockouts[c]
+		if !ok || now.Sub(lo.last) > window {
+			lo = &lockout{}
+			l.lockouts[c] = lo
+		}
+		lo.failures++
+		lo.last = now
+		if lo.failures >= l.adminApp.LoginMaxFailures() {
+			lo.failures = 0
+			lo.until = now.Add(window)
+			l.log.Printf("Locked %s out of logins until %s", c, lo.until.Format(time.RFC3339))
+		}
+	}
+}
+
+// LoginSucceeded clears the failed logins to the account of email.
+func (l *Limiter) LoginSucceeded(req *http.Request, email string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.lockouts, "user:"+email)
+}
+
+func (l *Limiter) loginClients(req *http.Request, email string) []string {
+	clients := []string{"ip:" + l.remoteIP(req)}
+	if email != "" {
+		clients = append(clients, "user:"+email)
+	}
+	return clients
+}
+
+// Throttled returns the clients throttled in the last day, the most recent
+// first.
+func (l *Limiter) Throttled() []Throttled {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.sweep(l.now())
+	var ts []Throttled
+	for _, t := range l.throttled {
+		ts = append(ts, *t)
+	}
+	sort.Slice(ts, func(i, j int) bool { return ts[i].Last.After(ts[j].Last) })
+	return ts
+}
+
+// sweep drops the buckets refilled, the lockouts over and the clients
+// throttled a while ago, once in a while.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < sweepEvery {
+		return
+	}
+	l.swept = now
+
+	for key, bk := range l.buckets {
+		b, _, _ := strings.Cut(key, " ")
+		if _, period := l.budget(Budget(b)); now.Sub(bk.last) > period {
+			delete(l.buckets, key)
+		}
+	}
+	window := l.adminApp.LoginLockout()
+	for c, lo := range l.lockouts {
+		if now.After(lo.until) && now.Sub(lo.last) > window {
+			delete(l.lockouts, c)
+		}
+	}
+	for key, t := range l.throttled {
+		if now.Sub(t.Last) > keepThrottled {
+			delete(l.throttled, key)
+		}
+	}
+}
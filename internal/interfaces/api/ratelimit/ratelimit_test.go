@@ -0,0 +1,150 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	adminEntity "github.com/mdfriday/hugoverse/internal/domain/admin/entity"
+	adminVO "github.com/mdfriday/hugoverse/internal/domain/admin/valueobject"
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+type conf struct {
+	disabled bool
+	proxies  string
+}
+
+func (c conf) RateLimitDisabled() bool     { return c.disabled }
+func (c conf) RateLimit() int              { return 2 }
+func (c conf) BuildRateLimit() int         { return 1 }
+func (c conf) LoginMaxFailures() int       { return 2 }
+func (c conf) LoginLockout() time.Duration { return time.Minute }
+
+func (c conf) TrustedProxies() []netip.Prefix {
+	return (&adminEntity.Throttle{Conf: &adminVO.Config{TrustedProxies: c.proxies}}).TrustedProxies()
+}
+
+func newLimiter(disabled bool) (*Limiter, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	l := New(loggers.NewDefault(), conf{disabled: disabled})
+	l.now = func() time.Time { return now }
+	return l, &now
+}
+
+func serve(h http.HandlerFunc, addr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/api/contents", nil)
+	req.RemoteAddr = addr
+	res := httptest.NewRecorder()
+	h(res, req)
+	return res
+}
+
+func TestLimit(t *testing.T) {
+	c := qt.New(t)
+
+	l, now := newLimiter(false)
+	ok := func(res http.ResponseWriter, req *http.Request) {}
+	h := l.Limit(API, ok)
+
+	c.Assert(serve(h, "10.0.0.1:1234").Code, qt.Equals, http.StatusOK)
+	c.Assert(serve(h, "10.0.0.1:1234").Code, qt.Equals, http.StatusOK)
+	res := serve(h, "10.0.0.1:1234")
+	c.Assert(res.Code, qt.Equals, http.StatusTooManyRequests)
+	c.Assert(res.Header().Get("Retry-After"), qt.Equals, "30")
+
+	// Other clients have their own bucket.
+	c.Assert(serve(h, "10.0.0.2:1234").Code, qt.Equals, http.StatusOK)
+
+	throttled := l.Throttled()
+	c.Assert(throttled, qt.HasLen, 1)
+	c.Assert(throttled[0].Client, qt.Equals, "ip:10.0.0.1")
+	c.Assert(throttled[0].Budget, qt.Equals, API)
+
+	// A token every 30s.
+	*now = now.Add(30 * time.Second)
+	c.Assert(serve(h, "10.0.0.1:1234").Code, qt.Equals, http.StatusOK)
+	c.Assert(serve(h, "10.0.0.1:1234").Code, qt.Equals, http.StatusTooManyRequests)
+
+	// The build budget is apart from the api one.
+	b := l.Limit(Build, ok)
+	c.Assert(serve(b, "10.0.0.3:1234").Code, qt.Equals, http.StatusOK)
+	res = serve(b, "10.0.0.3:1234")
+	c.Assert(res.Code, qt.Equals, http.StatusTooManyRequests)
+	c.Assert(res.Header().Get("Retry-After"), qt.Equals, "3600")
+	req := httptest.NewRequest(http.MethodPost, "/api/graphql", nil)
+	req.RemoteAddr = "10.0.0.3:1234"
+	wait, allowed := l.Allow(Build, req)
+	c.Assert(allowed, qt.IsFalse)
+	c.Assert(wait, qt.Equals, time.Hour)
+
+	l, _ = newLimiter(true)
+	h = l.Limit(API, ok)
+	for i := 0; i < 5; i++ {
+		c.Assert(serve(h, "10.0.0.1:1234").Code, qt.Equals, http.StatusOK)
+	}
+}
+
+func TestLoginLockout(t *testing.T) {
+	c := qt.New(t)
+
+	l, now := newLimiter(false)
+	req := httptest.NewRequest(http.MethodPost, "/api/login", nil)
+	req.RemoteAddr = "10.0.0.1:1234"
+	other := httptest.NewRequest(http.MethodPost, "/api/login", nil)
+	other.RemoteAddr = "10.0.0.2:1234"
+
+	l.LoginFailed(req, "me@example.com")
+	_, locked := l.LoginLocked(req, "me@example.com")
+	c.Assert(locked, qt.IsFalse)
+
+	l.LoginFailed(req, "me@example.com")
+	wait, locked := l.LoginLocked(req, "me@example.com")
+	c.Assert(locked, qt.IsTrue)
+	c.Assert(wait, qt.Equals, time.Minute)
+
+	// The account is locked from anywhere, and the address for any account.
+	_, locked = l.LoginLocked(other, "me@example.com")
+	c.Assert(locked, qt.IsTrue)
+	_, locked = l.LoginLocked(req, "you@example.com")
+	c.Assert(locked, qt.IsTrue)
+	_, locked = l.LoginLocked(other, "you@example.com")
+	c.Assert(locked, qt.IsFalse)
+
+	*now = now.Add(time.Minute)
+	_, locked = l.LoginLocked(req, "me@example.com")
+	c.Assert(locked, qt.IsFalse)
+}
+
+func TestRemoteIP(t *testing.T) {
+	c := qt.New(t)
+
+	l := New(loggers.NewDefault(), conf{proxies: "10.0.0.0/8, 192.168.1.1,bad"})
+	for _, test := range []struct {
+		remote string
+		header http.Header
+		ip     string
+	}{
+		{"203.0.113.1:1234", nil, "203.0.113.1"},
+		// Only trusted proxies name the client.
+		{"203.0.113.1:1234", http.Header{"X-Forwarded-For": {"198.51.100.1"}}, "203.0.113.1"},
+		{"192.168.1.2:1234", http.Header{"X-Real-Ip": {"198.51.100.1"}}, "192.168.1.2"},
+		{"192.168.1.1:1234", http.Header{"X-Real-Ip": {"198.51.100.1"}}, "198.51.100.1"},
+		{"10.1.2.3:1234", http.Header{"X-Forwarded-For": {"198.51.100.1"}, "X-Real-Ip": {"198.51.100.2"}}, "198.51.100.1"},
+		// The last hop which isn't a trusted proxy is the client.
+		{"10.1.2.3:1234", http.Header{"X-Forwarded-For": {"6.6.6.6, 198.51.100.1, 10.0.0.2", "192.168.1.1"}}, "198.51.100.1"},
+		{"10.1.2.3:1234", http.Header{"X-Forwarded-For": {"10.0.0.3, 10.0.0.2"}}, "10.0.0.3"},
+		{"10.1.2.3:1234", http.Header{"X-Forwarded-For": {"198.51.100.1, junk, 10.0.0.2"}}, "10.0.0.2"},
+		{"[::ffff:10.1.2.3]:1234", http.Header{"X-Forwarded-For": {"::ffff:198.51.100.1"}}, "198.51.100.1"},
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/api/contents", nil)
+		req.RemoteAddr = test.remote
+		for k, vs := range test.header {
+			req.Header[k] = vs
+		}
+		c.Assert(l.remoteIP(req), qt.Equals, test.ip, qt.Commentf("%v", test))
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"github.com/mdfriday/hugoverse/internal/application"
 	"github.com/mdfriday/hugoverse/internal/domain/admin/entity"
 	"github.com/mdfriday/hugoverse/internal/domain/admin/factory"
+	jobEntity "github.com/mdfriday/hugoverse/internal/domain/job/entity"
+	jobFact "github.com/mdfriday/hugoverse/internal/domain/job/factory"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/auth"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/cache"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/compression"
@@ -12,6 +14,7 @@ import (
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/database"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/form"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/handler"
+	"github.com/mdfriday/hugoverse/internal/interfaces/api/ratelimit"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/record"
 	"github.com/mdfriday/hugoverse/internal/interfaces/api/tls"
 	"github.com/mdfriday/hugoverse/pkg/loggers"
@@ -35,8 +38,15 @@ const (
 	PROD
 )
 
+// router is where a Server registers its handlers, an http.ServeMux.
+type router interface {
+	http.Handler
+	Handle(pattern string, handler http.Handler)
+	HandleFunc(pattern string, handler func(http.ResponseWriter, *http.Request))
+}
+
 type Server struct {
-	mux *http.ServeMux
+	mux router
 	Log loggers.Logger
 
 	Bind         string
@@ -46,6 +56,7 @@ type Server struct {
 
 	db       *database.Database
 	adminApp *entity.Admin
+	jobs     *jobEntity.Queue
 
 	tls *tls.Tls
 
@@ -55,6 +66,7 @@ type Server struct {
 	cache   *cache.Cache
 	cors    *cors.Cors
 	auth    *auth.Auth
+	limit   *ratelimit.Limiter
 
 	handler *handler.Handler
 }
@@ -98,15 +110,27 @@ func NewServer(options ...func(s *Server) error) (*Server, error) {
 	}
 	s.adminApp = server
 
+	jobs, err := jobFact.NewQueue(s.db)
+	if err != nil {
+		return nil, err
+	}
+	s.jobs = jobs
+
 	s.comp = compression.New(s.Log, s.adminApp)
 	s.cache = cache.New(s.Log, s.adminApp)
 	s.cors = cors.New(s.Log, s.adminApp, s.cache)
+	s.limit = ratelimit.New(s.Log, s.adminApp)
 
 	s.record.Start()
 
 	s.tls = tls.NewTls(s, s.adminApp, application.TLSDir())
 
-	s.handler = handler.New(s.Log, s.db, contentApp, s.adminApp)
+	graphQL, err := application.NewGraphQL(contentApp)
+	if err != nil {
+		return nil, err
+	}
+
+	s.handler = handler.New(s.Log, s.db, contentApp, graphQL, s.adminApp, s.jobs, s.limit)
 
 	s.registerHandler()
 
@@ -116,6 +140,7 @@ func NewServer(options ...func(s *Server) error) (*Server, error) {
 }
 
 func (s *Server) Close() {
+	s.jobs.Close()
 	s.db.Close()
 	s.record.Close()
 }
@@ -0,0 +1,174 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mdfriday/hugoverse/internal/application"
+	"github.com/mdfriday/hugoverse/pkg/log"
+)
+
+type backupCmd struct {
+	parent   *flag.FlagSet
+	cmd      *flag.FlagSet
+	out      *string
+	url      *string
+	user     *string
+	password *string
+}
+
+func NewBackupCmd(parent *flag.FlagSet) (*backupCmd, error) {
+	nCmd := &backupCmd{
+		parent: parent,
+	}
+
+	nCmd.cmd = flag.NewFlagSet("backup", flag.ExitOnError)
+	nCmd.out = nCmd.cmd.String("out", "",
+		fmt.Sprintln("[optional] the archive to write, default is `hugoverse-<unix time>.tar.gz`"))
+	nCmd.url = nCmd.cmd.String("url", "",
+		fmt.Sprintln("[optional] back up the running server at url, e.g. `http://localhost:1314`, instead of the stopped one"))
+	nCmd.user = nCmd.cmd.String("user", "",
+		fmt.Sprintln("[optional] the backup user set in the admin config, with --url"))
+	nCmd.password = nCmd.cmd.String("password", os.Getenv("HUGOVERSE_BACKUP_PASSWORD"),
+		fmt.Sprintln("[optional] the backup password set in the admin config, with --url, default is $HUGOVERSE_BACKUP_PASSWORD"))
+
+	err := nCmd.cmd.Parse(parent.Args()[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return nCmd, nil
+}
+
+func (oc *backupCmd) Usage() {
+	oc.cmd.Usage()
+}
+
+func (oc *backupCmd) Run() error {
+	l := log.NewStdLogger()
+
+	out := *oc.out
+	if out == "" {
+		out = fmt.Sprintf("hugoverse-%d.tar.gz", time.Now().Unix())
+	}
+	f, err := os.OpenFile(out, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		l.Fatalf("failed to create archive: %v", err)
+		return err
+	}
+
+	if *oc.url != "" {
+		err = oc.download(f)
+	} else {
+		err = oc.backup(f)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		_ = os.Remove(out)
+		l.Fatalf("failed to back up: %v", err)
+		return err
+	}
+
+	fmt.Printf("Backup written to %s\n", out)
+	return nil
+}
+
+func (oc *backupCmd) backup(w io.Writer) error {
+	m, err := application.Backup(w)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Backed up %d user stores and %d uploads\n", len(m.Users), m.Uploads)
+	return nil
+}
+
+func (oc *backupCmd) download(w io.Writer) error {
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(*oc.url, "/")+"/admin/backup", nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(*oc.user, *oc.password)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusForbidden:
+		return fmt.Errorf("set the backup credentials in the admin config first")
+	case http.StatusUnauthorized:
+		return fmt.Errorf("wrong backup user or password")
+	default:
+		return fmt.Errorf("server responded %s", resp.Status)
+	}
+
+	_, err = io.Copy(w, resp.Body)
+	return err
+}
+
+type restoreCmd struct {
+	parent *flag.FlagSet
+	cmd    *flag.FlagSet
+}
+
+func NewRestoreCmd(parent *flag.FlagSet) (*restoreCmd, error) {
+	nCmd := &restoreCmd{
+		parent: parent,
+	}
+
+	nCmd.cmd = flag.NewFlagSet("restore", flag.ExitOnError)
+	nCmd.cmd.Usage = func() {
+		fmt.Println("Usage:\n  hugov restore <archive>")
+		fmt.Println("\nReplaces the data of the stopped server with the archive written by hugov backup,")
+		fmt.Println("once it is checked, and rebuilds the search indexes. The replaced data is kept aside.")
+		fmt.Println("\nExample:")
+		fmt.Println("  hugov restore hugoverse-1700000000.tar.gz")
+	}
+	err := nCmd.cmd.Parse(parent.Args()[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return nCmd, nil
+}
+
+func (oc *restoreCmd) Usage() {
+	oc.cmd.Usage()
+}
+
+func (oc *restoreCmd) Run() error {
+	l := log.NewStdLogger()
+
+	if oc.cmd.NArg() != 1 {
+		oc.Usage()
+		return fmt.Errorf("please specify the archive to restore")
+	}
+
+	f, err := os.Open(oc.cmd.Arg(0))
+	if err != nil {
+		l.Fatalf("failed to open archive: %v", err)
+		return err
+	}
+	defer f.Close()
+
+	m, indexed, aside, err := application.Restore(f)
+	if err != nil {
+		l.Fatalf("failed to restore: %v", err)
+		return err
+	}
+
+	fmt.Printf("Restored the backup of %s: %d user stores and %d uploads, %d items indexed\n",
+		m.Created.Format(time.RFC3339), len(m.Users), m.Uploads, indexed)
+	fmt.Printf("The replaced data is in %s\n", aside)
+	return nil
+}
@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/application"
+	"github.com/mdfriday/hugoverse/pkg/log"
+)
+
+type gcCmd struct {
+	parent *flag.FlagSet
+	cmd    *flag.FlagSet
+	images *bool
+}
+
+func NewGCCmd(parent *flag.FlagSet) (*gcCmd, error) {
+	nCmd := &gcCmd{
+		parent: parent,
+	}
+
+	nCmd.cmd = flag.NewFlagSet("gc", flag.ExitOnError)
+	nCmd.images = nCmd.cmd.Bool("images", false,
+		fmt.Sprintln("[optional] remove the unused entries from the image cache, default is `false`"))
+
+	err := nCmd.cmd.Parse(parent.Args()[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return nCmd, nil
+}
+
+func (oc *gcCmd) Usage() {
+	oc.cmd.Usage()
+}
+
+func (oc *gcCmd) Run() error {
+	l := log.NewStdLogger()
+
+	if !*oc.images {
+		oc.Usage()
+		return errors.New("please specify what to collect, e.g. --images")
+	}
+
+	count, err := application.GCImages()
+	if err != nil {
+		l.Fatalf("failed to collect unused images: %v", err)
+		return err
+	}
+	fmt.Printf("Removed %d unused files from the image cache\n", count)
+
+	return nil
+}
@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"github.com/mdfriday/hugoverse/internal/application"
+	"github.com/mdfriday/hugoverse/pkg/log"
+)
+
+type newCmd struct {
+	parent *flag.FlagSet
+	cmd    *flag.FlagSet
+}
+
+func NewNewCmd(parent *flag.FlagSet) (*newCmd, error) {
+	nCmd := &newCmd{
+		parent: parent,
+	}
+
+	nCmd.cmd = flag.NewFlagSet("new", flag.ExitOnError)
+	nCmd.cmd.Usage = func() {
+		fmt.Println("Usage:\n  hugov new <kind> <path>")
+		fmt.Println("\nCreates the content at path, relative to the content directory,")
+		fmt.Println("from the archetype of kind, e.g. archetypes/posts.md or the bundle archetypes/posts/.")
+		fmt.Println("\nExample:")
+		fmt.Println("  hugov new posts posts/my-first-post.md")
+	}
+	err := nCmd.cmd.Parse(parent.Args()[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	return nCmd, nil
+}
+
+func (oc *newCmd) Usage() {
+	oc.cmd.Usage()
+}
+
+func (oc *newCmd) Run() error {
+	l := log.NewStdLogger()
+
+	if oc.cmd.NArg() != 2 {
+		oc.Usage()
+		return errors.New("please specify the archetype kind and the content path")
+	}
+
+	filenames, err := application.NewContent(oc.cmd.Arg(0), oc.cmd.Arg(1))
+	if err != nil {
+		l.Fatalf("failed to create content: %v", err)
+		return err
+	}
+	for _, filename := range filenames {
+		fmt.Printf("Content %q created\n", filename)
+	}
+
+	return nil
+}
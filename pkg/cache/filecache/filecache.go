@@ -81,6 +81,11 @@ func NewCache(fs afero.Fs, maxAge time.Duration, pruneAllRootDir string) *Cache
 	}
 }
 
+// Enabled reports whether entries are kept, i.e. the max age is not 0.
+func (c *Cache) Enabled() bool {
+	return c.maxAge != 0
+}
+
 // lockedFile is a file with a lock that is released on Close.
 type lockedFile struct {
 	afero.File
@@ -358,6 +363,11 @@ func (f Caches) GetResourceCache() *Cache {
 	return f[cache.KeyGetResource]
 }
 
+// PartialsCache gets the file cache for the output of partialCached.
+func (f Caches) PartialsCache() *Cache {
+	return f[cache.KeyPartials]
+}
+
 // AssetsCache gets the file cache for assets (processed resources, SCSS etc.).
 func (f Caches) AssetsCache() *Cache {
 	return f[cache.KeyAssets]
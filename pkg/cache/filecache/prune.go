@@ -0,0 +1,110 @@
+package filecache
+
+import (
+	"io"
+	"os"
+
+	"github.com/mdfriday/hugoverse/pkg/herrors"
+	"github.com/spf13/afero"
+)
+
+// Prune removes expired and unused items from this cache.
+// The last one requires a full build so the cache usage can be tracked.
+// Note that we operate directly on the filesystem here, so this is not
+// thread safe.
+func (c *Cache) Prune(force bool) (int, error) {
+	return c.prune(force, false)
+}
+
+// PruneUnused removes the expired items and the items not used since the
+// cache was created, all of them when none was used, e.g. after a full
+// build with no images.
+func (c *Cache) PruneUnused() (int, error) {
+	return c.prune(false, true)
+}
+
+func (c *Cache) prune(force, unused bool) (int, error) {
+	if c.pruneAllRootDir != "" {
+		return c.pruneRootDir(force)
+	}
+	if err := c.init(); err != nil {
+		return 0, err
+	}
+
+	c.nlocker.seenMu.RLock()
+	defer c.nlocker.seenMu.RUnlock()
+
+	counter := 0
+
+	err := afero.Walk(c.Fs, "", func(name string, info os.FileInfo, err error) error {
+		if info == nil {
+			return nil
+		}
+
+		name = cleanID(name)
+
+		if info.IsDir() {
+			f, err := c.Fs.Open(name)
+			if err != nil {
+				// This cache dir may not exist.
+				return nil
+			}
+			_, err = f.Readdirnames(1)
+			f.Close()
+			if err == io.EOF {
+				// Empty dir.
+				if name == "." {
+					// The cache root, keep it even if empty.
+					err = nil
+				} else {
+					err = c.Fs.Remove(name)
+				}
+			}
+
+			if err != nil && !herrors.IsNotExist(err) {
+				return err
+			}
+
+			return nil
+		}
+
+		shouldRemove := force || c.isExpired(info.ModTime())
+
+		if !shouldRemove && (unused || len(c.nlocker.seen) > 0) {
+			// Remove it if it's not been touched/used in the last build.
+			_, seen := c.nlocker.seen[name]
+			shouldRemove = !seen
+		}
+
+		if shouldRemove {
+			err := c.Fs.Remove(name)
+			if err == nil {
+				counter++
+			}
+
+			if err != nil && !herrors.IsNotExist(err) {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return counter, err
+}
+
+func (c *Cache) pruneRootDir(force bool) (int, error) {
+	info, err := c.Fs.Stat(c.pruneAllRootDir)
+	if err != nil {
+		if herrors.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	if !force && !c.isExpired(info.ModTime()) {
+		return 0, nil
+	}
+
+	return 1, c.Fs.RemoveAll(c.pruneAllRootDir)
+}
@@ -0,0 +1,54 @@
+package filecache
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/spf13/afero"
+)
+
+func TestPrune(t *testing.T) {
+	c := qt.New(t)
+
+	fs := afero.NewMemMapFs()
+	writeFile := func(name string) {
+		c.Assert(afero.WriteFile(fs, name, []byte(name), 0o666), qt.IsNil)
+	}
+	writeFile("a/used.png")
+	writeFile("a/unused.png")
+	writeFile("b/unused.png")
+
+	cache := NewCache(fs, -1, "")
+	_, err := cache.ReadOrCreate("a/used.png",
+		func(info ItemInfo, r io.ReadSeeker) error { return nil },
+		func(info ItemInfo, w io.WriteCloser) error { return w.Close() },
+	)
+	c.Assert(err, qt.IsNil)
+
+	count, err := cache.Prune(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(count, qt.Equals, 2)
+	c.Assert(cache.GetString("a/used.png"), qt.Equals, "a/used.png")
+	c.Assert(cache.GetString("a/unused.png"), qt.Equals, "")
+
+	// Nothing seen, only the expired entries go.
+	cache = NewCache(fs, time.Hour, "")
+	count, err = cache.Prune(false)
+	c.Assert(err, qt.IsNil)
+	c.Assert(count, qt.Equals, 0)
+
+	count, err = cache.PruneUnused()
+	c.Assert(err, qt.IsNil)
+	c.Assert(count, qt.Equals, 1)
+	exists, _ := afero.Exists(fs, "a/used.png")
+	c.Assert(exists, qt.IsFalse)
+
+	writeFile("a/used.png")
+	count, err = cache.Prune(true)
+	c.Assert(err, qt.IsNil)
+	c.Assert(count, qt.Equals, 1)
+	exists, _ = afero.Exists(fs, "a/used.png")
+	c.Assert(exists, qt.IsFalse)
+}
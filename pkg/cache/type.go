@@ -7,4 +7,5 @@ const (
 	KeyAssets      = "assets"
 	KeyModules     = "modules"
 	KeyGetResource = "getresource"
+	KeyPartials    = "partials"
 )
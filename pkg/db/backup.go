@@ -0,0 +1,80 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrInUse is returned by Check when another process holds the database.
+var ErrInUse = errors.New("database is in use by another process")
+
+// Snapshot is a consistent, read-only view of a Store, taken while the
+// Store stays open for reads and writes. It must be closed.
+type Snapshot struct {
+	tx *bolt.Tx
+}
+
+// Snapshot takes a Snapshot of the store.
+func (s *Store) Snapshot() (*Snapshot, error) {
+	tx, err := s.db.Begin(false)
+	if err != nil {
+		return nil, err
+	}
+	return &Snapshot{tx: tx}, nil
+}
+
+// Size returns the size of the database file written by WriteTo.
+func (s *Snapshot) Size() int64 {
+	return s.tx.Size()
+}
+
+// WriteTo writes the database file as of the snapshot to w.
+func (s *Snapshot) WriteTo(w io.Writer) (int64, error) {
+	return s.tx.WriteTo(w)
+}
+
+func (s *Snapshot) Close() error {
+	return s.tx.Rollback()
+}
+
+// Check opens the database file at path read-only and reads all of it,
+// returning ErrInUse if another process has it open.
+func Check(path string) (err error) {
+	b, err := bolt.Open(path, 0666, &bolt.Options{ReadOnly: true, Timeout: time.Second})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return ErrInUse
+	}
+	if err != nil {
+		return err
+	}
+	defer b.Close()
+
+	// Bolt panics on corrupt pages. Unlike tx.Check, which does so in a
+	// goroutine of its own, walking the buckets here lets us recover.
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("%s is corrupt: %v", path, p)
+		}
+	}()
+
+	return b.View(func(tx *bolt.Tx) error {
+		return tx.ForEach(func(name []byte, b *bolt.Bucket) error {
+			return walk(b)
+		})
+	})
+}
+
+func walk(b *bolt.Bucket) error {
+	return b.ForEach(func(k, v []byte) error {
+		if v == nil {
+			if nested := b.Bucket(k); nested != nil {
+				return walk(nested)
+			}
+		}
+		return nil
+	})
+}
@@ -1,6 +1,7 @@
 package db
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path"
@@ -53,6 +54,19 @@ func OpenUserStore(userID string, dataDir string, contentTypes []string) (*Store
 	return cs.Store, nil
 }
 
+// CloseUserStores closes all open user stores.
+func CloseUserStores() error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	var errs []error
+	for userID, cs := range cache {
+		errs = append(errs, cs.close())
+		delete(cache, userID)
+	}
+	return errors.Join(errs...)
+}
+
 func ensureDirExists(dir string) error {
 	if _, err := os.Stat(dir); os.IsNotExist(err) {
 		err := os.MkdirAll(dir, 0755)
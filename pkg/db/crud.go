@@ -1,7 +1,6 @@
 package db
 
 import (
-	"bytes"
 	bolt "go.etcd.io/bbolt"
 )
 
@@ -29,71 +28,27 @@ func All(item Item) ([][]byte, error) {
 }
 
 func (s *Store) Get(item Item) ([]byte, error) {
-	val := &bytes.Buffer{}
+	var value []byte
 	err := s.db.View(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(item.Bucket()))
-		if b == nil {
-			return bolt.ErrBucketNotFound
-		}
-
-		obj := b.Get([]byte(item.Key()))
-
-		_, err := val.Write(obj)
-		if err != nil {
-			return err
-		}
-
-		return nil
+		var err error
+		value, err = (&Tx{tx: tx}).Get(item)
+		return err
 	})
 	if err != nil {
 		return nil, err
 	}
 
-	if val.Bytes() == nil {
-		return nil, nil
-	}
-
-	return val.Bytes(), nil
+	return value, nil
 }
 
 func (s *Store) Set(item Item) error {
-	err := s.db.Update(func(tx *bolt.Tx) error {
-		bucket, err := tx.CreateBucketIfNotExists([]byte(item.Bucket()))
-		if err != nil {
-			return err
-		}
-
-		err = bucket.Put([]byte(item.Key()), item.Value())
-		if err != nil {
-			return err
-		}
-
-		return nil
+	return s.Update(func(tx *Tx) error {
+		return tx.Set(item)
 	})
-	if err != nil {
-		return err
-	}
-
-	return nil
 }
 
 func (s *Store) Delete(item Item) error {
-	err := s.db.Update(func(tx *bolt.Tx) error {
-		b := tx.Bucket([]byte(item.Bucket()))
-		if b == nil {
-			return bolt.ErrBucketNotFound
-		}
-
-		err := b.Delete([]byte(item.Key()))
-		if err != nil {
-			return err
-		}
-
-		return nil
+	return s.Update(func(tx *Tx) error {
+		return tx.Delete(item)
 	})
-	if err != nil {
-		return err
-	}
-
-	return nil
 }
@@ -1,22 +1,11 @@
 package db
 
-import bolt "go.etcd.io/bbolt"
-
 func (s *Store) NextSequence(item BucketItem) (uint64, error) {
 	var id uint64
-	err := s.db.Update(func(tx *bolt.Tx) error {
-		items := tx.Bucket([]byte(item.Bucket()))
-		if items == nil {
-			return bolt.ErrBucketNotFound
-		}
-
+	err := s.Update(func(tx *Tx) error {
 		var err error
-		id, err = items.NextSequence()
-		if err != nil {
-			return err
-		}
-
-		return nil
+		id, err = tx.NextSequence(item)
+		return err
 	})
 	if err != nil {
 		return 0, err
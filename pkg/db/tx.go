@@ -0,0 +1,98 @@
+package db
+
+import (
+	"fmt"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+const contentIndexBucket = "__contentIndex"
+
+// Tx is a read-write transaction of a Store, for several writes to be
+// applied at once, or not at all.
+type Tx struct {
+	tx *bolt.Tx
+}
+
+// Update runs fn in a read-write transaction, committed if fn returns nil
+// and rolled back otherwise.
+func (s *Store) Update(fn func(tx *Tx) error) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return fn(&Tx{tx: tx})
+	})
+}
+
+func (t *Tx) Get(item Item) ([]byte, error) {
+	b := t.tx.Bucket([]byte(item.Bucket()))
+	if b == nil {
+		return nil, bolt.ErrBucketNotFound
+	}
+
+	v := b.Get([]byte(item.Key()))
+	if v == nil {
+		return nil, nil
+	}
+	// v is only valid for the life of the transaction.
+	return append([]byte(nil), v...), nil
+}
+
+func (t *Tx) Set(item Item) error {
+	b, err := t.tx.CreateBucketIfNotExists([]byte(item.Bucket()))
+	if err != nil {
+		return err
+	}
+
+	return b.Put([]byte(item.Key()), item.Value())
+}
+
+func (t *Tx) Delete(item Item) error {
+	b := t.tx.Bucket([]byte(item.Bucket()))
+	if b == nil {
+		return bolt.ErrBucketNotFound
+	}
+
+	return b.Delete([]byte(item.Key()))
+}
+
+func (t *Tx) NextSequence(item BucketItem) (uint64, error) {
+	b := t.tx.Bucket([]byte(item.Bucket()))
+	if b == nil {
+		return 0, bolt.ErrBucketNotFound
+	}
+
+	return b.NextSequence()
+}
+
+func (t *Tx) SetIndex(item KeyValue) error {
+	b, err := t.tx.CreateBucketIfNotExists([]byte(contentIndexBucket))
+	if err != nil {
+		return err
+	}
+
+	return b.Put([]byte(item.Key()), item.Value())
+}
+
+func (t *Tx) RemoveIndex(slug string) error {
+	b := t.tx.Bucket([]byte(contentIndexBucket))
+	if b == nil {
+		return bolt.ErrBucketNotFound
+	}
+
+	return b.Delete([]byte(slug))
+}
+
+// CheckSlugForDuplicate returns slug, suffixed with the first free number
+// if it is taken in the content index.
+func (t *Tx) CheckSlugForDuplicate(slug string) (string, error) {
+	b := t.tx.Bucket([]byte(contentIndexBucket))
+	if b == nil {
+		return "", bolt.ErrBucketNotFound
+	}
+
+	original := slug
+	for i := 1; b.Get([]byte(slug)) != nil; i++ {
+		slug = fmt.Sprintf("%s-%d", original, i)
+	}
+
+	return slug, nil
+}
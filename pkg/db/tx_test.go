@@ -0,0 +1,60 @@
+package db
+
+import (
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type testItem struct {
+	bucket, key string
+	value       []byte
+}
+
+func (i *testItem) Bucket() string { return i.bucket }
+func (i *testItem) Key() string    { return i.key }
+func (i *testItem) Value() []byte  { return i.value }
+
+func TestUpdate(t *testing.T) {
+	c := qt.New(t)
+
+	s, err := NewStore(t.TempDir(), []string{"Post", contentIndexBucket})
+	c.Assert(err, qt.IsNil)
+	defer s.Close()
+
+	post := func(key, value string) *testItem {
+		return &testItem{bucket: "Post", key: key, value: []byte(value)}
+	}
+
+	errFail := errors.New("fail")
+	err = s.Update(func(tx *Tx) error {
+		c.Assert(tx.Set(post("1", "a")), qt.IsNil)
+		c.Assert(tx.SetIndex(post("a", "Post:1")), qt.IsNil)
+
+		// A transaction reads its own writes.
+		v, err := tx.Get(post("1", ""))
+		c.Assert(err, qt.IsNil)
+		c.Assert(string(v), qt.Equals, "a")
+		slug, err := tx.CheckSlugForDuplicate("a")
+		c.Assert(err, qt.IsNil)
+		c.Assert(slug, qt.Equals, "a-1")
+
+		return errFail
+	})
+	c.Assert(err, qt.Equals, errFail)
+
+	v, err := s.Get(post("1", ""))
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.IsNil)
+	slug, err := s.CheckSlugForDuplicate("a")
+	c.Assert(err, qt.IsNil)
+	c.Assert(slug, qt.Equals, "a")
+
+	c.Assert(s.Update(func(tx *Tx) error {
+		return tx.Set(post("1", "b"))
+	}), qt.IsNil)
+	v, err = s.Get(post("1", ""))
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(v), qt.Equals, "b")
+}
@@ -39,6 +39,20 @@ func GetNumWorkerMultiplier() int {
 	return runtime.NumCPU()
 }
 
+// GetNumJobWorkers returns the number of build, preview and deploy jobs
+// the server runs at a time. Each build uses GetNumWorkerMultiplier workers
+// of its own, so it defaults to 2.
+// It returns the value in HUGOVERSE_JOBWORKERS OS env variable if set to a
+// positive integer.
+func GetNumJobWorkers() int {
+	if n := os.Getenv("HUGOVERSE_JOBWORKERS"); n != "" {
+		if p, err := strconv.Atoi(n); err == nil && p > 0 {
+			return p
+		}
+	}
+	return 2
+}
+
 // GetMemoryLimit returns the upper memory limit in bytes for Hugo's in-memory caches.
 // Note that this does not represent "all of the memory" that Hugo will use,
 // so it needs to be set to a lower number than the available system memory.
@@ -0,0 +1,229 @@
+// Package gitmap maps the files in a Git repository to the last commit that
+// touched them. It reads the repository's object database directly and does
+// not need a git executable.
+package gitmap
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"time"
+)
+
+// ErrNotARepository is returned when no Git repository is found in or above
+// the given directory.
+var ErrNotARepository = errors.New("not a git repository (or any of the parent directories)")
+
+type GitRepo struct {
+	// TopLevelAbsPath contains the absolute path of the top-level directory,
+	// i.e. the directory holding the .git folder.
+	// Note that this follows Git's way of handling paths, so expect to get forward slashes,
+	// even on Windows.
+	TopLevelAbsPath string
+
+	// The files in this Git repository.
+	Files GitMap
+}
+
+// GitMap maps filenames, relative to the top level directory, to Git revision information.
+type GitMap map[string]*GitInfo
+
+// GitInfo holds information about a Git commit.
+type GitInfo struct {
+	Hash            string    `json:"hash"`            // Commit hash
+	AbbreviatedHash string    `json:"abbreviatedHash"` // Abbreviated commit hash
+	Subject         string    `json:"subject"`         // The commit message's subject/title line
+	AuthorName      string    `json:"authorName"`      // The author name
+	AuthorEmail     string    `json:"authorEmail"`     // The author email address
+	AuthorDate      time.Time `json:"authorDate"`      // The author date
+	CommitDate      time.Time `json:"commitDate"`      // The commit date
+	Body            string    `json:"body"`            // The commit message body
+}
+
+// Options for the Map function
+type Options struct {
+	Repository string // Path to the repository to map, may be any directory inside the work tree
+	Revision   string // Use blank or HEAD for the currently active revision
+}
+
+const abbreviatedHashLen = 7
+
+// Map creates a GitRepo with a file map from the given options.
+//
+// The history is read in one pass, newest commit first, the same way as
+// "git log --name-only --no-merges" does it: the first commit seen that
+// changed a file is the one recorded for it.
+func Map(opts Options) (*GitRepo, error) {
+	r, err := openRepository(opts.Repository)
+	if err != nil {
+		return nil, err
+	}
+	defer r.close()
+
+	head, err := r.resolve(opts.Revision)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(GitMap)
+
+	if err := r.walk(head, func(c *commit, changed []string) {
+		var info *GitInfo
+		for _, filename := range changed {
+			if _, found := m[filename]; found {
+				continue
+			}
+			if info == nil {
+				info = c.gitInfo()
+			}
+			m[filename] = info
+		}
+	}); err != nil {
+		return nil, err
+	}
+
+	return &GitRepo{
+		TopLevelAbsPath: filepath.ToSlash(r.workTree),
+		Files:           m,
+	}, nil
+}
+
+// walk visits all commits reachable from start in reverse chronological
+// order of their commit date, and calls fn with the files changed by every
+// commit that is not a merge.
+func (r *repository) walk(start hash, fn func(c *commit, changed []string)) error {
+	seen := map[hash]bool{start: true}
+
+	first, err := r.commit(start)
+	if err != nil {
+		return err
+	}
+	q := &commitQueue{first}
+
+	for q.Len() > 0 {
+		c := heap.Pop(q).(*commit)
+
+		parents := c.parents
+		if r.shallow[c.hash] {
+			parents = nil
+		}
+
+		for _, ph := range parents {
+			if seen[ph] {
+				continue
+			}
+			seen[ph] = true
+			pc, err := r.commit(ph)
+			if err != nil {
+				return fmt.Errorf("commit %s: %w", c.hash, err)
+			}
+			heap.Push(q, pc)
+		}
+
+		if len(parents) > 1 {
+			continue
+		}
+
+		var parentTree hash
+		if len(parents) == 1 {
+			pc, err := r.commit(parents[0])
+			if err != nil {
+				return err
+			}
+			parentTree = pc.tree
+		}
+
+		var changed []string
+		if err := r.diffTrees("", parentTree, c.tree, func(filename string) {
+			changed = append(changed, filename)
+		}); err != nil {
+			return fmt.Errorf("commit %s: %w", c.hash, err)
+		}
+
+		fn(c, changed)
+	}
+
+	return nil
+}
+
+// diffTrees calls fn for every non-tree entry that differs between the
+// trees a and b. A zero hash is an empty tree.
+func (r *repository) diffTrees(dir string, a, b hash, fn func(filename string)) error {
+	if a == b {
+		return nil
+	}
+
+	ta, err := r.tree(a)
+	if err != nil {
+		return err
+	}
+	tb, err := r.tree(b)
+	if err != nil {
+		return err
+	}
+
+	for name, eb := range tb {
+		filename := path.Join(dir, name)
+		ea, found := ta[name]
+
+		switch {
+		case found && ea.hash == eb.hash && ea.isTree() == eb.isTree():
+			continue
+		case eb.isTree():
+			var sub hash
+			if found && ea.isTree() {
+				sub = ea.hash
+			} else if found {
+				fn(filename)
+			}
+			if err := r.diffTrees(filename, sub, eb.hash, fn); err != nil {
+				return err
+			}
+		default:
+			if found && ea.isTree() {
+				if err := r.diffTrees(filename, ea.hash, hash{}, fn); err != nil {
+					return err
+				}
+			}
+			fn(filename)
+		}
+	}
+
+	for name, ea := range ta {
+		if _, found := tb[name]; found {
+			continue
+		}
+		filename := path.Join(dir, name)
+		if ea.isTree() {
+			if err := r.diffTrees(filename, ea.hash, hash{}, fn); err != nil {
+				return err
+			}
+			continue
+		}
+		fn(filename)
+	}
+
+	return nil
+}
+
+// commitQueue is a max heap on commit date.
+type commitQueue []*commit
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	if q[i].commitDate.Equal(q[j].commitDate) {
+		return q[i].hash.String() < q[j].hash.String()
+	}
+	return q[i].commitDate.After(q[j].commitDate)
+}
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)   { *q = append(*q, x.(*commit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	n := len(old)
+	c := old[n-1]
+	*q = old[:n-1]
+	return c
+}
@@ -0,0 +1,156 @@
+package gitmap
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func gitCmd(c *qt.C, dir string, date string, args ...string) string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=Jane Doe", "GIT_AUTHOR_EMAIL=jane@example.com",
+		"GIT_COMMITTER_NAME=John Doe", "GIT_COMMITTER_EMAIL=john@example.com",
+		"GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date,
+		"GIT_CONFIG_GLOBAL=/dev/null", "GIT_CONFIG_NOSYSTEM=1",
+	)
+	out, err := cmd.CombinedOutput()
+	c.Assert(err, qt.IsNil, qt.Commentf("git %v: %s", args, out))
+	return strings.TrimSpace(string(out))
+}
+
+func writeFile(c *qt.C, dir, name, content string) {
+	filename := filepath.Join(dir, filepath.FromSlash(name))
+	c.Assert(os.MkdirAll(filepath.Dir(filename), 0o755), qt.IsNil)
+	c.Assert(os.WriteFile(filename, []byte(content), 0o644), qt.IsNil)
+}
+
+func newTestRepo(c *qt.C) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		c.Skip("git not found in $PATH")
+	}
+
+	dir := c.TempDir()
+	gitCmd(c, dir, "", "init", "-q", "-b", "main")
+
+	writeFile(c, dir, "content/_index.md", "home")
+	writeFile(c, dir, "content/posts/first.md", "first")
+	writeFile(c, dir, "config.toml", "title = 'test'")
+	gitCmd(c, dir, "", "add", "-A")
+	gitCmd(c, dir, "2024-01-01T10:00:00+01:00", "commit", "-q", "-m", "Initial commit")
+
+	writeFile(c, dir, "content/posts/second.md", "second")
+	writeFile(c, dir, "content/posts/first.md", "first, updated")
+	gitCmd(c, dir, "", "add", "-A")
+	gitCmd(c, dir, "2024-02-01T10:00:00Z", "commit", "-q", "-m", "Add second post\n\nAnd update the first.")
+
+	gitCmd(c, dir, "", "checkout", "-q", "-b", "feature")
+	writeFile(c, dir, "content/about/index.md", "about")
+	gitCmd(c, dir, "", "add", "-A")
+	gitCmd(c, dir, "2024-03-01T10:00:00Z", "commit", "-q", "-m", "Add about")
+
+	gitCmd(c, dir, "", "checkout", "-q", "main")
+	c.Assert(os.Remove(filepath.Join(dir, "config.toml")), qt.IsNil)
+	gitCmd(c, dir, "", "add", "-A")
+	gitCmd(c, dir, "2024-04-01T10:00:00Z", "commit", "-q", "-m", "Remove config")
+	gitCmd(c, dir, "2024-05-01T10:00:00Z", "merge", "-q", "--no-ff", "-m", "Merge feature", "feature")
+
+	return dir
+}
+
+func TestMap(t *testing.T) {
+	c := qt.New(t)
+	dir := newTestRepo(c)
+
+	check := func(c *qt.C, repo *GitRepo) {
+		c.Assert(repo.TopLevelAbsPath, qt.Equals, filepath.ToSlash(dir))
+		c.Assert(repo.Files, qt.HasLen, 5)
+
+		first := repo.Files["content/posts/first.md"]
+		c.Assert(first, qt.Not(qt.IsNil))
+		c.Assert(first.Subject, qt.Equals, "Add second post")
+		c.Assert(first.Body, qt.Equals, "And update the first.")
+		c.Assert(first.AuthorName, qt.Equals, "Jane Doe")
+		c.Assert(first.AuthorEmail, qt.Equals, "jane@example.com")
+		c.Assert(first.CommitDate.Equal(time.Date(2024, 2, 1, 10, 0, 0, 0, time.UTC)), qt.IsTrue)
+		c.Assert(first.Hash, qt.Equals, gitCmd(c, dir, "", "log", "-1", "--format=%H", "--", "content/posts/first.md"))
+		c.Assert(first.AbbreviatedHash, qt.Equals, first.Hash[:7])
+
+		home := repo.Files["content/_index.md"]
+		c.Assert(home.Subject, qt.Equals, "Initial commit")
+		_, offset := home.AuthorDate.Zone()
+		c.Assert(offset, qt.Equals, 3600)
+
+		// Changed in a branch, the merge commit itself is skipped.
+		c.Assert(repo.Files["content/about/index.md"].Subject, qt.Equals, "Add about")
+		// Deleted files are listed as well, just as in git log.
+		c.Assert(repo.Files["config.toml"].Subject, qt.Equals, "Remove config")
+	}
+
+	c.Run("Loose objects", func(c *qt.C) {
+		repo, err := Map(Options{Repository: filepath.Join(dir, "content")})
+		c.Assert(err, qt.IsNil)
+		check(c, repo)
+	})
+
+	c.Run("Packed", func(c *qt.C) {
+		gitCmd(c, dir, "", "gc", "-q", "--aggressive")
+		repo, err := Map(Options{Repository: dir})
+		c.Assert(err, qt.IsNil)
+		check(c, repo)
+	})
+
+	c.Run("Revision", func(c *qt.C) {
+		repo, err := Map(Options{Repository: dir, Revision: "feature"})
+		c.Assert(err, qt.IsNil)
+		c.Assert(repo.Files["config.toml"].Subject, qt.Equals, "Initial commit")
+	})
+
+	c.Run("Not a repository", func(c *qt.C) {
+		_, err := Map(Options{Repository: c.TempDir()})
+		c.Assert(err, qt.Not(qt.IsNil))
+	})
+}
+
+func TestBaseCache(t *testing.T) {
+	c := qt.New(t)
+
+	p := &pack{}
+	key := func(offset int64) baseKey { return baseKey{p: p, offset: offset} }
+	object := func(size int) packObject { return packObject{typ: objBlob, data: make([]byte, size)} }
+
+	bc := newBaseCache(10)
+	bc.add(key(1), object(4))
+	bc.add(key(2), object(4))
+	_, found := bc.get(key(1))
+	c.Assert(found, qt.IsTrue)
+
+	// The base used least recently is evicted to make room.
+	bc.add(key(3), object(4))
+	_, found = bc.get(key(2))
+	c.Assert(found, qt.IsFalse)
+	_, found = bc.get(key(1))
+	c.Assert(found, qt.IsTrue)
+	c.Assert(bc.size, qt.Equals, 8)
+
+	// Adding a base again replaces it.
+	bc.add(key(3), object(6))
+	c.Assert(bc.size, qt.Equals, 10)
+	c.Assert(bc.ll.Len(), qt.Equals, 2)
+
+	// Bases larger than the cache are not kept.
+	bc.add(key(4), object(11))
+	_, found = bc.get(key(4))
+	c.Assert(found, qt.IsFalse)
+	c.Assert(bc.size, qt.Equals, 10)
+
+	// Bases of other packs are kept apart.
+	_, found = bc.get(baseKey{p: &pack{}, offset: 1})
+	c.Assert(found, qt.IsFalse)
+}
@@ -0,0 +1,204 @@
+package gitmap
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type objectType int
+
+const (
+	objCommit   objectType = 1
+	objTree     objectType = 2
+	objBlob     objectType = 3
+	objTag      objectType = 4
+	objOfsDelta objectType = 6
+	objRefDelta objectType = 7
+)
+
+func (t objectType) String() string {
+	switch t {
+	case objCommit:
+		return "commit"
+	case objTree:
+		return "tree"
+	case objBlob:
+		return "blob"
+	case objTag:
+		return "tag"
+	case objOfsDelta:
+		return "ofs-delta"
+	case objRefDelta:
+		return "ref-delta"
+	default:
+		return "unknown"
+	}
+}
+
+func parseObjectType(s string) (objectType, error) {
+	switch s {
+	case "commit":
+		return objCommit, nil
+	case "tree":
+		return objTree, nil
+	case "blob":
+		return objBlob, nil
+	case "tag":
+		return objTag, nil
+	default:
+		return 0, fmt.Errorf("unknown object type %q", s)
+	}
+}
+
+type commit struct {
+	hash    hash
+	tree    hash
+	parents []hash
+
+	authorName  string
+	authorEmail string
+	authorDate  time.Time
+	commitDate  time.Time
+
+	message string
+}
+
+func parseCommit(h hash, data []byte) (*commit, error) {
+	c := &commit{hash: h}
+
+	header, message, _ := bytes.Cut(data, []byte("\n\n"))
+	c.message = string(message)
+
+	for _, line := range strings.Split(string(header), "\n") {
+		if strings.HasPrefix(line, " ") {
+			// Continuation of a multi-line header, e.g. gpgsig.
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+
+		var err error
+		switch key {
+		case "tree":
+			c.tree, err = parseHash(value)
+		case "parent":
+			var p hash
+			if p, err = parseHash(value); err == nil {
+				c.parents = append(c.parents, p)
+			}
+		case "author":
+			c.authorName, c.authorEmail, c.authorDate, err = parseSignature(value)
+		case "committer":
+			_, _, c.commitDate, err = parseSignature(value)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("commit %s: %w", h, err)
+		}
+	}
+
+	return c, nil
+}
+
+// parseSignature parses the author and committer lines on the form
+// "Name <email> 1700000000 +0100".
+func parseSignature(s string) (name, email string, when time.Time, err error) {
+	lt := strings.LastIndexByte(s, '<')
+	gt := strings.LastIndexByte(s, '>')
+	if lt < 0 || gt < lt {
+		return "", "", time.Time{}, fmt.Errorf("invalid signature %q", s)
+	}
+
+	name = strings.TrimSpace(s[:lt])
+	email = s[lt+1 : gt]
+
+	fields := strings.Fields(s[gt+1:])
+	if len(fields) != 2 {
+		return name, email, time.Time{}, fmt.Errorf("invalid signature date %q", s)
+	}
+
+	sec, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return name, email, time.Time{}, fmt.Errorf("invalid signature date %q: %w", s, err)
+	}
+
+	tz := fields[1]
+	if len(tz) != 5 {
+		return name, email, time.Time{}, fmt.Errorf("invalid signature time zone %q", s)
+	}
+	hours, err1 := strconv.Atoi(tz[1:3])
+	minutes, err2 := strconv.Atoi(tz[3:5])
+	if err1 != nil || err2 != nil {
+		return name, email, time.Time{}, fmt.Errorf("invalid signature time zone %q", s)
+	}
+	offset := hours*3600 + minutes*60
+	if tz[0] == '-' {
+		offset = -offset
+	}
+
+	return name, email, time.Unix(sec, 0).In(time.FixedZone("", offset)), nil
+}
+
+// subjectAndBody splits the commit message the same way as the %s and %b
+// placeholders in git log does it.
+func (c *commit) subjectAndBody() (string, string) {
+	msg := strings.TrimLeft(c.message, "\n")
+	subject, body, _ := strings.Cut(msg, "\n\n")
+	return strings.Join(strings.Fields(subject), " "), strings.TrimSpace(body)
+}
+
+func (c *commit) gitInfo() *GitInfo {
+	subject, body := c.subjectAndBody()
+	h := c.hash.String()
+
+	return &GitInfo{
+		Hash:            h,
+		AbbreviatedHash: h[:abbreviatedHashLen],
+		Subject:         subject,
+		AuthorName:      c.authorName,
+		AuthorEmail:     c.authorEmail,
+		AuthorDate:      c.authorDate,
+		CommitDate:      c.commitDate,
+		Body:            body,
+	}
+}
+
+type treeEntry struct {
+	mode string
+	hash hash
+}
+
+func (e treeEntry) isTree() bool {
+	return e.mode == "40000"
+}
+
+type tree map[string]treeEntry
+
+func parseTree(data []byte) (tree, error) {
+	t := make(tree)
+
+	for len(data) > 0 {
+		sp := bytes.IndexByte(data, ' ')
+		if sp < 0 {
+			return nil, fmt.Errorf("invalid tree entry mode")
+		}
+		mode := string(data[:sp])
+		data = data[sp+1:]
+
+		nul := bytes.IndexByte(data, 0)
+		if nul < 0 || len(data) < nul+1+len(hash{}) {
+			return nil, fmt.Errorf("invalid tree entry name")
+		}
+		name := string(data[:nul])
+		data = data[nul+1:]
+
+		var h hash
+		copy(h[:], data)
+		data = data[len(h):]
+
+		t[name] = treeEntry{mode: mode, hash: h}
+	}
+
+	return t, nil
+}
@@ -0,0 +1,448 @@
+package gitmap
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"container/list"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var errObjectNotFound = errors.New("object not found")
+
+// objectStore reads objects from the loose object directories and the
+// pack files of a repository, including any alternates.
+type objectStore struct {
+	dirs  []string
+	packs []*pack
+
+	// Resolved delta bases of the packs.
+	bases *baseCache
+}
+
+func newObjectStore(dir string) (*objectStore, error) {
+	s := &objectStore{bases: newBaseCache(maxBaseCacheSize)}
+	if err := s.addDir(dir, 0); err != nil {
+		s.close()
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *objectStore) addDir(dir string, depth int) error {
+	if depth > 5 {
+		return fmt.Errorf("%s: too many levels of alternates", dir)
+	}
+	s.dirs = append(s.dirs, dir)
+
+	idxs, err := filepath.Glob(filepath.Join(dir, "pack", "*.idx"))
+	if err != nil {
+		return err
+	}
+	for _, idx := range idxs {
+		p, err := openPack(strings.TrimSuffix(idx, ".idx"))
+		if err != nil {
+			return err
+		}
+		s.packs = append(s.packs, p)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "info", "alternates"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		alt := strings.TrimSpace(sc.Text())
+		if alt == "" || alt[0] == '#' {
+			continue
+		}
+		if !filepath.IsAbs(alt) {
+			alt = filepath.Join(dir, alt)
+		}
+		if err := s.addDir(filepath.Clean(alt), depth+1); err != nil {
+			return err
+		}
+	}
+
+	return sc.Err()
+}
+
+func (s *objectStore) close() {
+	for _, p := range s.packs {
+		p.close()
+	}
+}
+
+func (s *objectStore) read(h hash) (objectType, []byte, error) {
+	for _, p := range s.packs {
+		if offset, found := p.find(h); found {
+			return p.readAt(s, offset)
+		}
+	}
+
+	hs := h.String()
+	for _, dir := range s.dirs {
+		typ, data, err := readLooseObject(filepath.Join(dir, hs[:2], hs[2:]))
+		if err == nil {
+			return typ, data, nil
+		}
+		if !os.IsNotExist(err) {
+			return 0, nil, fmt.Errorf("object %s: %w", hs, err)
+		}
+	}
+
+	return 0, nil, fmt.Errorf("%w: %s", errObjectNotFound, hs)
+}
+
+// readLooseObject reads a zlib compressed object file on the form
+// "<type> <size>\x00<content>".
+func readLooseObject(filename string) (objectType, []byte, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer f.Close()
+
+	zr, err := zlib.NewReader(f)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer zr.Close()
+
+	b, err := io.ReadAll(zr)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	header, data, found := bytes.Cut(b, []byte{0})
+	if !found {
+		return 0, nil, errors.New("invalid object header")
+	}
+	typs, sizes, _ := strings.Cut(string(header), " ")
+	typ, err := parseObjectType(typs)
+	if err != nil {
+		return 0, nil, err
+	}
+	if size, err := strconv.Atoi(sizes); err != nil || size != len(data) {
+		return 0, nil, errors.New("invalid object size")
+	}
+
+	return typ, data, nil
+}
+
+// pack is a pack file with its version 2 index.
+type pack struct {
+	name string
+	f    *os.File
+
+	fanout  [256]uint32
+	hashes  []byte // Sorted object names, 20 bytes each.
+	offsets []byte // 4 bytes per object.
+	large   []byte // 8 bytes per large offset.
+}
+
+type packObject struct {
+	typ  objectType
+	data []byte
+}
+
+var packIdxMagic = []byte{0xff, 't', 'O', 'c'}
+
+func openPack(base string) (*pack, error) {
+	idx, err := os.ReadFile(base + ".idx")
+	if err != nil {
+		return nil, err
+	}
+	if len(idx) < 8+256*4 || !bytes.Equal(idx[:4], packIdxMagic) || binary.BigEndian.Uint32(idx[4:8]) != 2 {
+		return nil, fmt.Errorf("%s.idx: unsupported pack index version", base)
+	}
+
+	p := &pack{name: base}
+	for i := range p.fanout {
+		p.fanout[i] = binary.BigEndian.Uint32(idx[8+i*4:])
+	}
+
+	n := int(p.fanout[255])
+	pos := 8 + 256*4
+	if len(idx) < pos+n*(20+4+4) {
+		return nil, fmt.Errorf("%s.idx: truncated pack index", base)
+	}
+	p.hashes = idx[pos : pos+n*20]
+	pos += n * 20
+	pos += n * 4 // CRC32 values
+	p.offsets = idx[pos : pos+n*4]
+	pos += n * 4
+	p.large = idx[pos:]
+
+	f, err := os.Open(base + ".pack")
+	if err != nil {
+		return nil, err
+	}
+	p.f = f
+
+	return p, nil
+}
+
+func (p *pack) close() {
+	if p.f != nil {
+		p.f.Close()
+	}
+}
+
+func (p *pack) find(h hash) (int64, bool) {
+	lo := 0
+	if h[0] > 0 {
+		lo = int(p.fanout[h[0]-1])
+	}
+	hi := int(p.fanout[h[0]])
+
+	i := lo + sort.Search(hi-lo, func(i int) bool {
+		return bytes.Compare(p.hashes[(lo+i)*20:(lo+i+1)*20], h[:]) >= 0
+	})
+	if i >= hi || !bytes.Equal(p.hashes[i*20:(i+1)*20], h[:]) {
+		return 0, false
+	}
+
+	offset := binary.BigEndian.Uint32(p.offsets[i*4:])
+	if offset&0x80000000 == 0 {
+		return int64(offset), true
+	}
+	li := int(offset & 0x7fffffff)
+	if len(p.large) < (li+1)*8 {
+		return 0, false
+	}
+
+	return int64(binary.BigEndian.Uint64(p.large[li*8:])), true
+}
+
+// readBase reads a delta base. Bases are usually shared by many objects,
+// so the ones used last are kept in memory.
+func (p *pack) readBase(s *objectStore, offset int64) (objectType, []byte, error) {
+	key := baseKey{p: p, offset: offset}
+	if o, found := s.bases.get(key); found {
+		return o.typ, o.data, nil
+	}
+
+	typ, data, err := p.readAt(s, offset)
+	if err != nil {
+		return 0, nil, err
+	}
+	s.bases.add(key, packObject{typ: typ, data: data})
+
+	return typ, data, nil
+}
+
+// maxBaseCacheSize is the size in bytes of the delta bases kept in memory
+// by an object store.
+const maxBaseCacheSize = 32 << 20
+
+type baseKey struct {
+	p      *pack
+	offset int64
+}
+
+type baseEntry struct {
+	key baseKey
+	o   packObject
+}
+
+// baseCache keeps the delta bases used last, up to a total size in bytes.
+type baseCache struct {
+	maxSize int
+	size    int
+
+	ll      *list.List // Most recently used first.
+	entries map[baseKey]*list.Element
+}
+
+func newBaseCache(maxSize int) *baseCache {
+	return &baseCache{maxSize: maxSize, ll: list.New(), entries: make(map[baseKey]*list.Element)}
+}
+
+func (c *baseCache) get(key baseKey) (packObject, bool) {
+	e, found := c.entries[key]
+	if !found {
+		return packObject{}, false
+	}
+	c.ll.MoveToFront(e)
+	return e.Value.(*baseEntry).o, true
+}
+
+// add adds o, evicting the bases used least recently to make room for it.
+// Bases larger than the cache are not kept.
+func (c *baseCache) add(key baseKey, o packObject) {
+	if len(o.data) > c.maxSize {
+		return
+	}
+	if e, found := c.entries[key]; found {
+		c.size -= len(e.Value.(*baseEntry).o.data)
+		c.ll.Remove(e)
+	}
+
+	c.entries[key] = c.ll.PushFront(&baseEntry{key: key, o: o})
+	c.size += len(o.data)
+
+	for c.size > c.maxSize {
+		e := c.ll.Back()
+		be := e.Value.(*baseEntry)
+		c.ll.Remove(e)
+		delete(c.entries, be.key)
+		c.size -= len(be.o.data)
+	}
+}
+
+// readAt reads and, if needed, undeltifies the object at offset.
+func (p *pack) readAt(s *objectStore, offset int64) (objectType, []byte, error) {
+	r := bufio.NewReader(io.NewSectionReader(p.f, offset, 1<<62))
+
+	c, err := r.ReadByte()
+	if err != nil {
+		return 0, nil, err
+	}
+	typ := objectType((c >> 4) & 7)
+	for c&0x80 != 0 {
+		// The rest of the inflated size, we don't need it.
+		if c, err = r.ReadByte(); err != nil {
+			return 0, nil, err
+		}
+	}
+
+	var (
+		baseType objectType
+		baseData []byte
+	)
+
+	switch typ {
+	case objCommit, objTree, objBlob, objTag:
+	case objOfsDelta:
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, nil, err
+		}
+		rel := int64(c & 0x7f)
+		for c&0x80 != 0 {
+			if c, err = r.ReadByte(); err != nil {
+				return 0, nil, err
+			}
+			rel = ((rel + 1) << 7) | int64(c&0x7f)
+		}
+		if baseType, baseData, err = p.readBase(s, offset-rel); err != nil {
+			return 0, nil, err
+		}
+	case objRefDelta:
+		var bh hash
+		if _, err := io.ReadFull(r, bh[:]); err != nil {
+			return 0, nil, err
+		}
+		if baseType, baseData, err = s.read(bh); err != nil {
+			return 0, nil, err
+		}
+	default:
+		return 0, nil, fmt.Errorf("%s.pack: invalid object type %d at offset %d", p.name, typ, offset)
+	}
+
+	zr, err := zlib.NewReader(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	data, err := io.ReadAll(zr)
+	zr.Close()
+	if err != nil {
+		return 0, nil, err
+	}
+
+	if typ == objOfsDelta || typ == objRefDelta {
+		if data, err = applyDelta(baseData, data); err != nil {
+			return 0, nil, fmt.Errorf("%s.pack: offset %d: %w", p.name, offset, err)
+		}
+		typ = baseType
+	}
+
+	return typ, data, nil
+}
+
+func applyDelta(base, delta []byte) ([]byte, error) {
+	srcSize, delta := deltaHeaderSize(delta)
+	if srcSize != len(base) {
+		return nil, errors.New("delta base size mismatch")
+	}
+	dstSize, delta := deltaHeaderSize(delta)
+
+	dst := make([]byte, 0, dstSize)
+
+	for len(delta) > 0 {
+		cmd := delta[0]
+		delta = delta[1:]
+
+		switch {
+		case cmd&0x80 != 0:
+			var offset, size int
+			for i := 0; i < 4; i++ {
+				if cmd&(1<<i) != 0 {
+					if len(delta) == 0 {
+						return nil, errors.New("truncated delta")
+					}
+					offset |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			for i := 0; i < 3; i++ {
+				if cmd&(1<<(4+i)) != 0 {
+					if len(delta) == 0 {
+						return nil, errors.New("truncated delta")
+					}
+					size |= int(delta[0]) << (8 * i)
+					delta = delta[1:]
+				}
+			}
+			if size == 0 {
+				size = 0x10000
+			}
+			if offset+size > len(base) {
+				return nil, errors.New("delta copy out of range")
+			}
+			dst = append(dst, base[offset:offset+size]...)
+		case cmd != 0:
+			n := int(cmd)
+			if n > len(delta) {
+				return nil, errors.New("truncated delta")
+			}
+			dst = append(dst, delta[:n]...)
+			delta = delta[n:]
+		default:
+			return nil, errors.New("invalid delta opcode")
+		}
+	}
+
+	if len(dst) != dstSize {
+		return nil, errors.New("delta result size mismatch")
+	}
+
+	return dst, nil
+}
+
+func deltaHeaderSize(b []byte) (int, []byte) {
+	var size, shift int
+	for i, c := range b {
+		size |= int(c&0x7f) << shift
+		shift += 7
+		if c&0x80 == 0 {
+			return size, b[i+1:]
+		}
+	}
+	return size, nil
+}
@@ -0,0 +1,318 @@
+package gitmap
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type hash [20]byte
+
+func (h hash) String() string {
+	return hex.EncodeToString(h[:])
+}
+
+func (h hash) isZero() bool {
+	return h == hash{}
+}
+
+func parseHash(s string) (hash, error) {
+	var h hash
+	if len(s) != 2*len(h) {
+		return h, fmt.Errorf("invalid object name %q", s)
+	}
+	if _, err := hex.Decode(h[:], []byte(s)); err != nil {
+		return h, fmt.Errorf("invalid object name %q: %w", s, err)
+	}
+	return h, nil
+}
+
+type repository struct {
+	workTree  string
+	gitDir    string
+	commonDir string
+
+	objects *objectStore
+	shallow map[hash]bool
+
+	commits map[hash]*commit
+	trees   map[hash]tree
+}
+
+// openRepository finds the repository the directory dir belongs to.
+func openRepository(dir string) (*repository, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for d := abs; ; {
+		gitDir, ok, err := findGitDir(d)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return newRepository(d, gitDir)
+		}
+
+		parent := filepath.Dir(d)
+		if parent == d {
+			return nil, ErrNotARepository
+		}
+		d = parent
+	}
+}
+
+// findGitDir looks for a .git directory, or a .git file pointing to one as
+// used by worktrees and submodules, in dir.
+func findGitDir(dir string) (string, bool, error) {
+	dotGit := filepath.Join(dir, ".git")
+	fi, err := os.Stat(dotGit)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", false, nil
+		}
+		return "", false, err
+	}
+	if fi.IsDir() {
+		return dotGit, true, nil
+	}
+
+	b, err := os.ReadFile(dotGit)
+	if err != nil {
+		return "", false, err
+	}
+	line := strings.TrimSpace(string(b))
+	if !strings.HasPrefix(line, "gitdir:") {
+		return "", false, fmt.Errorf("invalid .git file %q", dotGit)
+	}
+	gitDir := strings.TrimSpace(strings.TrimPrefix(line, "gitdir:"))
+	if !filepath.IsAbs(gitDir) {
+		gitDir = filepath.Join(dir, gitDir)
+	}
+
+	return filepath.Clean(gitDir), true, nil
+}
+
+func newRepository(workTree, gitDir string) (*repository, error) {
+	r := &repository{
+		workTree:  workTree,
+		gitDir:    gitDir,
+		commonDir: gitDir,
+		shallow:   make(map[hash]bool),
+		commits:   make(map[hash]*commit),
+		trees:     make(map[hash]tree),
+	}
+
+	// Linked worktrees keep their objects and refs in the main repository.
+	if b, err := os.ReadFile(filepath.Join(gitDir, "commondir")); err == nil {
+		common := strings.TrimSpace(string(b))
+		if !filepath.IsAbs(common) {
+			common = filepath.Join(gitDir, common)
+		}
+		r.commonDir = filepath.Clean(common)
+	}
+
+	objects, err := newObjectStore(filepath.Join(r.commonDir, "objects"))
+	if err != nil {
+		return nil, err
+	}
+	r.objects = objects
+
+	if err := r.readShallow(); err != nil {
+		r.close()
+		return nil, err
+	}
+
+	return r, nil
+}
+
+func (r *repository) close() {
+	if r.objects != nil {
+		r.objects.close()
+	}
+}
+
+// readShallow reads the commits cut off by a shallow clone, these must be
+// treated as if they had no parents.
+func (r *repository) readShallow() error {
+	f, err := os.Open(filepath.Join(r.commonDir, "shallow"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		h, err := parseHash(strings.TrimSpace(s.Text()))
+		if err != nil {
+			return err
+		}
+		r.shallow[h] = true
+	}
+	return s.Err()
+}
+
+// resolve resolves a revision to a commit hash.
+// Supported are blank and HEAD, full object names and ref names, e.g. main,
+// v1.0.0 or refs/heads/main.
+func (r *repository) resolve(rev string) (hash, error) {
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	if h, err := parseHash(rev); err == nil {
+		return r.peel(h)
+	}
+
+	candidates := []string{rev}
+	if rev != "HEAD" && !strings.HasPrefix(rev, "refs/") {
+		candidates = append(candidates, "refs/tags/"+rev, "refs/heads/"+rev, "refs/remotes/"+rev)
+	}
+
+	for _, name := range candidates {
+		h, found, err := r.readRef(name, 0)
+		if err != nil {
+			return hash{}, err
+		}
+		if found {
+			return r.peel(h)
+		}
+	}
+
+	return hash{}, fmt.Errorf("unknown revision %q", rev)
+}
+
+// peel follows annotated tags down to the commit they point to.
+func (r *repository) peel(h hash) (hash, error) {
+	for i := 0; i < 10; i++ {
+		typ, data, err := r.objects.read(h)
+		if err != nil {
+			return hash{}, err
+		}
+		switch typ {
+		case objCommit:
+			return h, nil
+		case objTag:
+			line, _, _ := bytes.Cut(data, []byte("\n"))
+			target, found := bytes.CutPrefix(line, []byte("object "))
+			if !found {
+				return hash{}, fmt.Errorf("tag %s: missing object", h)
+			}
+			if h, err = parseHash(string(target)); err != nil {
+				return hash{}, err
+			}
+		default:
+			return hash{}, fmt.Errorf("object %s is a %s, not a commit", h, typ)
+		}
+	}
+	return hash{}, fmt.Errorf("tag %s: too many levels of tags", h)
+}
+
+func (r *repository) readRef(name string, depth int) (hash, bool, error) {
+	if depth > 5 {
+		return hash{}, false, fmt.Errorf("ref %q: too many levels of symbolic refs", name)
+	}
+
+	// HEAD is per worktree, all other refs live in the common dir.
+	dir := r.commonDir
+	if name == "HEAD" {
+		dir = r.gitDir
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+	if err == nil {
+		content := strings.TrimSpace(string(b))
+		if target, found := strings.CutPrefix(content, "ref:"); found {
+			return r.readRef(strings.TrimSpace(target), depth+1)
+		}
+		h, err := parseHash(content)
+		return h, err == nil, err
+	}
+	if !os.IsNotExist(err) {
+		return hash{}, false, err
+	}
+
+	return r.readPackedRef(name)
+}
+
+func (r *repository) readPackedRef(name string) (hash, bool, error) {
+	f, err := os.Open(filepath.Join(r.commonDir, "packed-refs"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return hash{}, false, nil
+		}
+		return hash{}, false, err
+	}
+	defer f.Close()
+
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		if line == "" || line[0] == '#' || line[0] == '^' {
+			continue
+		}
+		hs, ref, found := strings.Cut(line, " ")
+		if !found || ref != name {
+			continue
+		}
+		h, err := parseHash(hs)
+		return h, err == nil, err
+	}
+
+	return hash{}, false, s.Err()
+}
+
+func (r *repository) commit(h hash) (*commit, error) {
+	if c, found := r.commits[h]; found {
+		return c, nil
+	}
+
+	typ, data, err := r.objects.read(h)
+	if err != nil {
+		return nil, err
+	}
+	if typ != objCommit {
+		return nil, fmt.Errorf("object %s is a %s, not a commit", h, typ)
+	}
+
+	c, err := parseCommit(h, data)
+	if err != nil {
+		return nil, err
+	}
+	r.commits[h] = c
+
+	return c, nil
+}
+
+func (r *repository) tree(h hash) (tree, error) {
+	if h.isZero() {
+		return nil, nil
+	}
+	if t, found := r.trees[h]; found {
+		return t, nil
+	}
+
+	typ, data, err := r.objects.read(h)
+	if err != nil {
+		return nil, err
+	}
+	if typ != objTree {
+		return nil, fmt.Errorf("object %s is a %s, not a tree", h, typ)
+	}
+
+	t, err := parseTree(data)
+	if err != nil {
+		return nil, fmt.Errorf("tree %s: %w", h, err)
+	}
+	r.trees[h] = t
+
+	return t, nil
+}
@@ -121,6 +121,8 @@ func IsNotExist(err error) bool {
 
 var nilPointerErrRe = regexp.MustCompile(`at <(.*)>: error calling (.*?): runtime error: invalid memory address or nil pointer dereference`)
 
+// ImproveIfNilPointer improves the message of a nil pointer error in a
+// template, keeping inErr in the chain of the error returned.
 func ImproveIfNilPointer(inErr error) (outErr error) {
 	outErr = inErr
 
@@ -134,6 +136,15 @@ func ImproveIfNilPointer(inErr error) (outErr error) {
 	receiverName := parts[len(parts)-2]
 	receiver := strings.Join(parts[:len(parts)-1], ".")
 	s := fmt.Sprintf("– %s is nil; wrap it in if or with: {{ with %s }}{{ .%s }}{{ end }}", receiverName, receiver, field)
-	outErr = errors.New(nilPointerErrRe.ReplaceAllString(inErr.Error(), s))
+	outErr = &improvedError{msg: nilPointerErrRe.ReplaceAllString(inErr.Error(), s), err: inErr}
 	return
 }
+
+// improvedError is an error with a better message than the one it wraps.
+type improvedError struct {
+	msg string
+	err error
+}
+
+func (e *improvedError) Error() string { return e.msg }
+func (e *improvedError) Unwrap() error { return e.err }
@@ -78,3 +78,17 @@ func TestNewFileErrorExtractFromMessage(t *testing.T) {
 		c.Assert(errors.Unwrap(got), qt.Not(qt.IsNil))
 	}
 }
+
+func TestExtractTemplatePosition(t *testing.T) {
+	t.Parallel()
+
+	c := qt.New(t)
+
+	name, lno, col := ExtractTemplatePosition(errors.New(`execute of template failed: template: index.html:2:5: executing "index.html" at <partial "foo.html" .>: error calling partial: template: partials/foo.html:3:6: executing "partials/foo.html" at <.ThisDoesNotExist>: can't evaluate field ThisDoesNotExist`))
+	c.Assert(name, qt.Equals, "partials/foo.html")
+	c.Assert(lno, qt.Equals, 3)
+	c.Assert(col, qt.Equals, 6)
+
+	name, _, _ = ExtractTemplatePosition(errors.New("no template here"))
+	c.Assert(name, qt.Equals, "")
+}
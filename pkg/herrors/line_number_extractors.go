@@ -61,3 +61,29 @@ func extractLineNo(re *regexp.Regexp) lineNumberExtractor {
 		return 0, col
 	}
 }
+
+// templateExecErrRe matches the position prefix of Go template execution errors,
+// e.g. `template: partials/nav.html:12:5: executing "partials/nav.html" at <.Foo>`.
+var templateExecErrRe = regexp.MustCompile(`template: ([^:\s]+):(\d+):(\d*):?`)
+
+// ExtractTemplatePosition extracts the name of the failing template along with
+// the line and column from a template execution error.
+// Errors from nested templates, e.g. partials, carry the position of every
+// template in the call chain; the innermost, where the error happened, is returned.
+// It returns an empty name if none found.
+func ExtractTemplatePosition(e error) (name string, lno, col int) {
+	if e == nil {
+		return "", 0, 0
+	}
+	all := templateExecErrRe.FindAllStringSubmatch(e.Error(), -1)
+	if len(all) == 0 {
+		return "", 0, 0
+	}
+	m := all[len(all)-1]
+	lno, _ = strconv.Atoi(m[2])
+	col, _ = strconv.Atoi(m[3])
+	if col <= 0 {
+		col = 1
+	}
+	return m[1], lno, col
+}
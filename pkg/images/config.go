@@ -0,0 +1,20 @@
+package images
+
+import (
+	"image"
+	"io"
+
+	// Register the formats we can probe.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+)
+
+// DecodeConfig returns the color model and dimensions of the image in r
+// along with the format name, without decoding the entire image.
+func DecodeConfig(r io.Reader) (image.Config, string, error) {
+	return image.DecodeConfig(r)
+}
@@ -0,0 +1,196 @@
+package qr
+
+import (
+	"strings"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestReedSolomon(t *testing.T) {
+	c := qt.New(t)
+
+	// The 1-M "HELLO WORLD" example from the spec.
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	ecc := reedSolomonRemainder(data, reedSolomonDivisor(10))
+	c.Assert(ecc, qt.DeepEquals, []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23})
+}
+
+func TestFormatBits(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		level Level
+		mask  int
+		bits  string
+	}{
+		{Low, 0, "111011111000100"},
+		{Medium, 0, "101010000010010"},
+		{Quartile, 6, "010111011011010"},
+		{High, 4, "000011101100010"},
+	} {
+		code := newCode(1, test.level)
+		code.drawFormatBits(test.mask)
+		c.Assert(readFormatBits(code), qt.Equals, test.bits, qt.Commentf("%d/%d", test.level, test.mask))
+	}
+}
+
+func TestEncode(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		text    string
+		level   Level
+		version int
+	}{
+		{strings.Repeat("a", 17), Low, 1},
+		{strings.Repeat("a", 18), Low, 2},
+		{"https://gohugo.io/", Medium, 2},
+		{"Hugoverse 🚀", High, 2},
+		{strings.Repeat("0123456789", 30), Quartile, 16},
+		{strings.Repeat("x", 2953), Low, 40},
+	} {
+		code, err := Encode(test.text, test.level)
+		c.Assert(err, qt.IsNil)
+		c.Assert(code.Version, qt.Equals, test.version)
+		c.Assert(code.Size, qt.Equals, test.version*4+17)
+		c.Assert(decode(c, code), qt.Equals, test.text)
+	}
+
+	_, err := Encode(strings.Repeat("x", 2954), Low)
+	c.Assert(err, qt.Equals, ErrTooLong)
+}
+
+func TestImage(t *testing.T) {
+	c := qt.New(t)
+
+	code, err := Encode("hello", Medium)
+	c.Assert(err, qt.IsNil)
+	img := code.Image(3)
+	c.Assert(img.Bounds().Dx(), qt.Equals, (21+8)*3)
+
+	// The top left corner of the finder pattern after the quiet zone.
+	r, _, _, _ := img.At(12, 12).RGBA()
+	c.Assert(r, qt.Equals, uint32(0))
+	r, _, _, _ = img.At(11, 11).RGBA()
+	c.Assert(r, qt.Equals, uint32(0xffff))
+}
+
+func readFormatBits(code *Code) string {
+	var bits []byte
+	for i := 14; i >= 0; i-- {
+		var dark bool
+		switch {
+		case i <= 5:
+			dark = code.modules[i][8]
+		case i == 6:
+			dark = code.modules[7][8]
+		case i == 7:
+			dark = code.modules[8][8]
+		case i == 8:
+			dark = code.modules[8][7]
+		default:
+			dark = code.modules[8][14-i]
+		}
+		if dark {
+			bits = append(bits, '1')
+		} else {
+			bits = append(bits, '0')
+		}
+	}
+	return string(bits)
+}
+
+// decode reads the code back the way a reader would, checking the
+// error correction codewords on the way.
+func decode(c *qt.C, code *Code) string {
+	format := readFormatBits(code)
+	var formatBits int
+	for _, b := range format {
+		formatBits = formatBits<<1 | int(b-'0')
+	}
+	formatBits ^= 0x5412
+	c.Assert(formatBits>>13, qt.Equals, code.Level.formatBits())
+	mask := (formatBits >> 10) & 7
+	c.Assert(mask, qt.Equals, code.Mask)
+
+	// Unmask a copy and read the codewords in the zigzag order.
+	read := newCode(code.Version, code.Level)
+	read.drawFunctionPatterns()
+	for y := range code.modules {
+		copy(read.modules[y], code.modules[y])
+	}
+	read.applyMask(mask)
+
+	rawCodewords := numRawDataModules(code.Version) / 8
+	var bb bitBuffer
+	for right := read.Size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < read.Size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = read.Size - 1 - vert
+				}
+				if !read.isFunction[y][x] && len(bb) < rawCodewords*8 {
+					bb = append(bb, read.modules[y][x])
+				}
+			}
+		}
+	}
+	codewords := bb.bytes()
+
+	// Deinterleave.
+	numBlocks := numErrorCorrectionBlocks[code.Level][code.Version]
+	blockECCLen := eccCodewordsPerBlock[code.Level][code.Version]
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortDataLen := rawCodewords/numBlocks - blockECCLen
+
+	blocks := make([][]byte, numBlocks)
+	i := 0
+	for k := 0; k < shortDataLen+1; k++ {
+		for j := range blocks {
+			if k == shortDataLen && j < numShortBlocks {
+				continue
+			}
+			blocks[j] = append(blocks[j], codewords[i])
+			i++
+		}
+	}
+	var data []byte
+	divisor := reedSolomonDivisor(blockECCLen)
+	for j := range blocks {
+		ecc := make([]byte, blockECCLen)
+		for k := range ecc {
+			ecc[k] = codewords[i+k*numBlocks+j]
+		}
+		c.Assert(reedSolomonRemainder(blocks[j], divisor), qt.DeepEquals, ecc)
+		data = append(data, blocks[j]...)
+	}
+
+	// Byte mode segment.
+	var bits bitBuffer
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	readInt := func(n int) int {
+		v := 0
+		for _, b := range bits[:n] {
+			v <<= 1
+			if b {
+				v |= 1
+			}
+		}
+		bits = bits[n:]
+		return v
+	}
+	c.Assert(readInt(4), qt.Equals, 0x4)
+	n := readInt(charCountBits(code.Version))
+	text := make([]byte, n)
+	for k := range text {
+		text[k] = byte(readInt(8))
+	}
+	return string(text)
+}
@@ -16,11 +16,13 @@
 package loggers
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/mdfriday/hugoverse/pkg/terminal"
 	"io"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bep/logg"
@@ -59,7 +61,8 @@ func New(opts Options) Logger {
 	}
 
 	var logHandler logg.Handler
-	if terminal.PrintANSIColors(os.Stdout) {
+	// Colour only what goes to a terminal.
+	if f, ok := opts.Stdout.(*os.File); ok && terminal.PrintANSIColors(f) {
 		logHandler = newDefaultHandler(opts.Stdout, opts.Stderr)
 	} else {
 		logHandler = newNoColoursHandler(opts.Stdout, opts.Stderr, false, nil)
@@ -173,6 +176,17 @@ func LevelLoggerToWriter(l logg.LevelLogger) io.Writer {
 	return logWriter{l: l}
 }
 
+// NewPrintfLogger creates a logger passing each line it logs, from the
+// info level on, to printf, e.g. to keep the log of one build apart.
+func NewPrintfLogger(printf func(format string, v ...any)) Logger {
+	w := &printfWriter{printf: printf}
+	return New(Options{
+		Level:  logg.LevelInfo,
+		Stdout: w,
+		Stderr: w,
+	})
+}
+
 type Logger interface {
 	Debug() logg.LevelLogger
 	Debugf(format string, v ...any)
@@ -362,6 +376,30 @@ func (w logWriter) Write(p []byte) (n int, err error) {
 	return len(p), nil
 }
 
+// printfWriter passes the lines written to it to printf, holding back an
+// unfinished line until the rest of it is written.
+type printfWriter struct {
+	mu     sync.Mutex
+	printf func(format string, v ...any)
+	buf    []byte
+}
+
+func (w *printfWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		w.printf("%s", w.buf[:i])
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
+
 func TimeTrackf(l logg.LevelLogger, start time.Time, fields logg.Fields, format string, a ...any) {
 	elapsed := time.Since(start)
 	if fields != nil {
@@ -16,6 +16,7 @@
 package loggers_test
 
 import (
+	"fmt"
 	"io"
 	"strings"
 	"testing"
@@ -152,3 +153,17 @@ func TestReset(t *testing.T) {
 
 	}
 }
+
+func TestPrintfLogger(t *testing.T) {
+	c := qt.New(t)
+
+	var lines []string
+	l := loggers.NewPrintfLogger(func(format string, v ...any) {
+		lines = append(lines, fmt.Sprintf(format, v...))
+	})
+
+	l.Printf("Building %s", "site")
+	l.Warnf("two\nlines")
+	l.Debugf("dropped")
+	c.Assert(lines, qt.DeepEquals, []string{"Building site", "WARN  two", "lines"})
+}
@@ -1,8 +1,12 @@
 package metadecoders
 
 import (
+	"bytes"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	xml "github.com/clbanning/mxj/v2"
 	toml "github.com/pelletier/go-toml/v2"
 	"github.com/spf13/afero"
 	"github.com/spf13/cast"
@@ -92,6 +96,10 @@ func (d Decoder) UnmarshalTo(data []byte, f Format, v any) error {
 				}
 			}
 		}
+	case CSV:
+		return d.unmarshalCSV(data, v)
+	case XML:
+		return d.unmarshalXML(data, v)
 	default:
 		return fmt.Errorf("unmarshal of format %q is not supported", f)
 	}
@@ -108,6 +116,8 @@ func (d Decoder) UnmarshalTo(data []byte, f Format, v any) error {
 func (d Decoder) Unmarshal(data []byte, f Format) (any, error) {
 	if data == nil {
 		switch f {
+		case CSV:
+			return make([][]string, 0), nil
 		default:
 			return make(map[string]any), nil
 		}
@@ -118,6 +128,57 @@ func (d Decoder) Unmarshal(data []byte, f Format) (any, error) {
 	return v, err
 }
 
+func (d Decoder) unmarshalCSV(data []byte, v any) error {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = d.Delimiter
+	r.Comment = d.Comment
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return err
+	}
+
+	switch vv := v.(type) {
+	case *any:
+		*vv = records
+	case *[][]string:
+		*vv = records
+	default:
+		return fmt.Errorf("CSV cannot be unmarshaled into %T", v)
+	}
+
+	return nil
+}
+
+func (d Decoder) unmarshalXML(data []byte, v any) error {
+	xmlRoot, err := xml.NewMapXml(data)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal XML: %w", err)
+	}
+	if len(xmlRoot) != 1 {
+		return errors.New("failed to unmarshal XML: expected exactly one root element")
+	}
+
+	// The root element itself is not interesting, unwrap it.
+	xmlValue := make(map[string]any)
+	for _, val := range xmlRoot {
+		if m, ok := val.(map[string]any); ok {
+			xmlValue = m
+		}
+	}
+
+	switch vv := v.(type) {
+	case *map[string]any:
+		*vv = xmlValue
+	case *any:
+		*vv = xmlValue
+	default:
+		return fmt.Errorf("XML cannot be unmarshaled into %T", v)
+	}
+
+	return nil
+}
+
 // OptionsKey is used in cache keys.
 func (d Decoder) OptionsKey() string {
 	var sb strings.Builder
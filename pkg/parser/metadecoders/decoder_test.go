@@ -0,0 +1,52 @@
+package metadecoders
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestFormatFromString(t *testing.T) {
+	c := qt.New(t)
+
+	for _, test := range []struct {
+		s      string
+		expect Format
+	}{
+		{"json", JSON},
+		{"yaml", YAML},
+		{"yml", YAML},
+		{"toml", TOML},
+		{"config.toml", TOML},
+		{"data/authors.CSV", CSV},
+		{"feed.xml", XML},
+		{"org", ORG},
+		{"foo", ""},
+	} {
+		c.Assert(FormatFromString(test.s), qt.Equals, test.expect, qt.Commentf(test.s))
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	c := qt.New(t)
+
+	v, err := Default.Unmarshal([]byte("a,b\n1,2\n"), CSV)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.DeepEquals, [][]string{{"a", "b"}, {"1", "2"}})
+
+	d := Decoder{Delimiter: ';', Comment: '#'}
+	v, err = d.Unmarshal([]byte("# comment\na;b\n"), CSV)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.DeepEquals, [][]string{{"a", "b"}})
+
+	v, err = Default.Unmarshal([]byte(`<root><title>Hugo</title><count>3</count></root>`), XML)
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.DeepEquals, map[string]any{"title": "Hugo", "count": "3"})
+
+	m, err := Default.UnmarshalToMap([]byte(`<root><title>Hugo</title></root>`), XML)
+	c.Assert(err, qt.IsNil)
+	c.Assert(m["title"], qt.Equals, "Hugo")
+
+	_, err = Default.Unmarshal([]byte("a,b\n1\n"), CSV)
+	c.Assert(err, qt.Not(qt.IsNil))
+}
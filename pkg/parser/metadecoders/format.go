@@ -27,8 +27,18 @@ func FormatFromString(formatStr string) Format {
 		formatStr = strings.TrimPrefix(filepath.Ext(formatStr), ".")
 	}
 	switch formatStr {
+	case "yaml", "yml":
+		return YAML
+	case "json":
+		return JSON
 	case "toml":
 		return TOML
+	case "org":
+		return ORG
+	case "csv":
+		return CSV
+	case "xml":
+		return XML
 	}
 
 	return ""
@@ -0,0 +1,58 @@
+package css
+
+import (
+	"github.com/mdfriday/hugoverse/internal/domain/resources"
+	"github.com/mdfriday/hugoverse/pkg/template/funcs/resource"
+	"github.com/mdfriday/hugoverse/pkg/template/funcs/resource/resourcehelpers"
+)
+
+func New(client Client, res resource.Resource) (*Namespace, error) {
+	rs, err := resource.New(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Namespace{
+		cssClient: client,
+		resources: rs,
+	}, nil
+}
+
+// Namespace provides template functions for the "css" namespace.
+type Namespace struct {
+	cssClient Client
+	resources *resource.Namespace
+}
+
+// Sass converts the given Resource with Dart Sass, see resources.ToCSS.
+func (ns *Namespace) Sass(args ...any) (resources.Resource, error) {
+	return ns.resources.ToCSS(args...)
+}
+
+// Build bundles the given CSS Resource with ESBuild.
+// @import statements are inlined, CSS nesting is lowered and vendor prefixes
+// are added for the configured targets.
+func (ns *Namespace) Build(args ...any) (resources.Resource, error) {
+	var (
+		r          resources.Resource
+		m          map[string]any
+		targetPath string
+		err        error
+		ok         bool
+	)
+
+	r, targetPath, ok = resourcehelpers.ResolveIfFirstArgIsString(args)
+
+	if !ok {
+		r, m, err = resourcehelpers.ResolveArgs(args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if targetPath != "" {
+		m = map[string]any{"targetPath": targetPath}
+	}
+
+	return ns.cssClient.BuildCSS(r, m)
+}
@@ -0,0 +1,7 @@
+package css
+
+import "github.com/mdfriday/hugoverse/internal/domain/resources"
+
+type Client interface {
+	BuildCSS(res resources.Resource, opts map[string]any) (resources.Resource, error)
+}
@@ -0,0 +1,114 @@
+// Package debug provides template functions to help debugging templates.
+package debug
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/mdfriday/hugoverse/pkg/loggers"
+)
+
+// New returns a new instance of the debug-namespaced template functions.
+func New(log loggers.Logger) *Namespace {
+	if log == nil {
+		log = loggers.NewDefault()
+	}
+	return &Namespace{
+		log:    log,
+		timers: make(map[string]*timerStats),
+	}
+}
+
+// Namespace provides template functions for the "debug" namespace.
+type Namespace struct {
+	log loggers.Logger
+
+	mu     sync.Mutex
+	timers map[string]*timerStats
+}
+
+type timerStats struct {
+	count   int
+	elapsed time.Duration
+}
+
+// Dump returns a indented JSON representation of val, e.g. to inspect
+// the data context of a template.
+func (ns *Namespace) Dump(val any) string {
+	b, err := json.MarshalIndent(val, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("debug.Dump: failed to marshal %T: %s", val, err)
+	}
+	return string(b)
+}
+
+// Timer starts a named timer. Call Stop on it to record the elapsed time,
+// e.g. {{ $t := debug.Timer "nav" }}...{{ $t.Stop }}.
+// Timers with the same name are aggregated.
+func (ns *Namespace) Timer(name string) *Timer {
+	return &Timer{name: name, start: time.Now(), ns: ns}
+}
+
+// TimerSummary is the aggregated result of the timers with the same name.
+type TimerSummary struct {
+	Name    string
+	Count   int
+	Elapsed time.Duration
+}
+
+// Timers returns the aggregated timers, the slowest first.
+func (ns *Namespace) Timers() []TimerSummary {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	summaries := make([]TimerSummary, 0, len(ns.timers))
+	for name, s := range ns.timers {
+		summaries = append(summaries, TimerSummary{Name: name, Count: s.count, Elapsed: s.elapsed})
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Elapsed == summaries[j].Elapsed {
+			return summaries[i].Name < summaries[j].Name
+		}
+		return summaries[i].Elapsed > summaries[j].Elapsed
+	})
+	return summaries
+}
+
+func (ns *Namespace) record(name string, d time.Duration) TimerSummary {
+	ns.mu.Lock()
+	defer ns.mu.Unlock()
+
+	s, found := ns.timers[name]
+	if !found {
+		s = &timerStats{}
+		ns.timers[name] = s
+	}
+	s.count++
+	s.elapsed += d
+
+	return TimerSummary{Name: name, Count: s.count, Elapsed: s.elapsed}
+}
+
+// Timer is a running timer started with debug.Timer.
+type Timer struct {
+	name  string
+	start time.Time
+	ns    *Namespace
+
+	stopOnce sync.Once
+}
+
+// Stop stops the timer and logs the elapsed time along with the totals for
+// the timer name. Stopping a timer more than once has no effect.
+// It returns an empty string so it can be used directly in a template.
+func (t *Timer) Stop() string {
+	t.stopOnce.Do(func() {
+		d := time.Since(t.start)
+		s := t.ns.record(t.name, d)
+		t.ns.log.Infof("timer: %s: %s (total: %s, count: %d)", t.name, d, s.Elapsed, s.Count)
+	})
+	return ""
+}
@@ -0,0 +1,35 @@
+package debug
+
+import (
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestDump(t *testing.T) {
+	c := qt.New(t)
+	ns := New(nil)
+
+	c.Assert(ns.Dump(map[string]any{"a": 1}), qt.Equals, "{\n  \"a\": 1\n}")
+	c.Assert(ns.Dump(func() {}), qt.Contains, "failed to marshal func()")
+}
+
+func TestTimer(t *testing.T) {
+	c := qt.New(t)
+	ns := New(nil)
+
+	for i := 0; i < 3; i++ {
+		tm := ns.Timer("nav")
+		c.Assert(tm.Stop(), qt.Equals, "")
+		tm.Stop()
+	}
+	ns.Timer("footer").Stop()
+
+	timers := ns.Timers()
+	c.Assert(timers, qt.HasLen, 2)
+	counts := map[string]int{}
+	for _, s := range timers {
+		counts[s.Name] = s.Count
+	}
+	c.Assert(counts, qt.DeepEquals, map[string]int{"nav": 3, "footer": 1})
+}
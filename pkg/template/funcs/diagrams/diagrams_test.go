@@ -0,0 +1,144 @@
+package diagrams
+
+import (
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestSequence(t *testing.T) {
+	c := qt.New(t)
+
+	seq, err := parseSequence(`
+# A comment.
+participant Web Server as Web
+Alice -> Web: GET /
+note over Web: Render
+Web -> Web
+Web --> Alice: 200 OK
+`)
+	c.Assert(err, qt.IsNil)
+	c.Assert(seq.participants, qt.DeepEquals, []string{"Web Server", "Alice"})
+	c.Assert(seq.events, qt.HasLen, 4)
+	c.Assert(seq.events[0], qt.Equals, seqEvent{kind: seqMessage, from: 1, to: 0, text: "GET /"})
+	c.Assert(seq.events[1], qt.Equals, seqEvent{kind: seqNote, from: 0, to: 0, text: "Render", placement: "over"})
+	c.Assert(seq.events[3].dashed, qt.IsTrue)
+
+	_, err = parseSequence("Alice -> Bob\nnote left of Alice, Bob: x")
+	var perr *ParseError
+	c.Assert(errors.As(err, &perr), qt.IsTrue)
+	c.Assert(perr.Line, qt.Equals, 2)
+
+	_, err = parseSequence("Alice\n")
+	c.Assert(err, qt.ErrorMatches, `line 1: invalid statement "Alice"`)
+
+	d, err := New().Sequence("Alice -> Bob: Hello", map[string]any{"stroke": "#c00", "class": "wide"})
+	c.Assert(err, qt.IsNil)
+	c.Assert(string(d.Inner()), qt.Contains, "var(--diagram-stroke,#c00)")
+	c.Assert(d.Width() > 0 && d.Height() > 0, qt.IsTrue)
+
+	_, err = New().Sequence("Alice -> Bob", map[string]any{"fill": "red}</style>"})
+	c.Assert(err, qt.ErrorMatches, `invalid fill color .*`)
+}
+
+func TestFlowchart(t *testing.T) {
+	c := qt.New(t)
+
+	g, err := parseFlowchart(`
+direction LR
+start([Start]) -> check{Valid?} -> done((Done)): yes
+check --> start: no
+`)
+	c.Assert(err, qt.IsNil)
+	c.Assert(g.horizontal, qt.IsTrue)
+	c.Assert(g.nodes, qt.HasLen, 3)
+	c.Assert(g.ids["start"].shape, qt.Equals, shapeRound)
+	c.Assert(g.ids["check"].label, qt.Equals, "Valid?")
+	c.Assert(g.ids["check"].shape, qt.Equals, shapeDiamond)
+	c.Assert(g.ids["done"].shape, qt.Equals, shapeCircle)
+	c.Assert(g.edges, qt.HasLen, 3)
+	c.Assert(g.edges[1].label, qt.Equals, "yes")
+	c.Assert(g.edges[2].dashed, qt.IsTrue)
+
+	for _, test := range []struct {
+		src string
+		err string
+	}{
+		{"a ->", `line 1: missing node`},
+		{"a\nb => c", `line 2: unexpected "=> c", expected -> or -->`},
+		{"a[A", `line 1: node "a": missing "]"`},
+		{"a: x", `line 1: label without an edge`},
+		{"direction XY", `line 1: invalid direction "XY", expected TB or LR`},
+	} {
+		_, err := parseFlowchart(test.src)
+		c.Assert(err, qt.ErrorMatches, test.err, qt.Commentf(test.src))
+	}
+}
+
+func TestDot(t *testing.T) {
+	c := qt.New(t)
+
+	g, err := parseDot(`
+/* A comment. */
+digraph G {
+	rankdir=LR;
+	node [shape=box];
+	a [label="Parse\nsource"];
+	a -> b -> c [label="next", style=dashed]; // A comment.
+	subgraph cluster_0 {
+		node [shape=diamond];
+		d;
+	}
+	e;
+}`)
+	c.Assert(err, qt.IsNil)
+	c.Assert(g.horizontal, qt.IsTrue)
+	c.Assert(g.nodes, qt.HasLen, 5)
+	c.Assert(g.ids["a"].label, qt.Equals, "Parse\nsource")
+	c.Assert(g.ids["b"].shape, qt.Equals, shapeBox)
+	c.Assert(g.ids["d"].shape, qt.Equals, shapeDiamond)
+	c.Assert(g.ids["e"].shape, qt.Equals, shapeBox)
+	c.Assert(g.edges, qt.HasLen, 2)
+	c.Assert(g.edges[1].label, qt.Equals, "next")
+	c.Assert(g.edges[1].dashed, qt.IsTrue)
+	c.Assert(g.edges[1].directed, qt.IsTrue)
+
+	g, err = parseDot("graph { a -- b }")
+	c.Assert(err, qt.IsNil)
+	c.Assert(g.edges[0].directed, qt.IsFalse)
+
+	for _, test := range []struct {
+		src string
+		err string
+	}{
+		{"graph {\n a -> b\n}", `line 2: edge operator -> in an undirected graph`},
+		{"digraph {\n a -> \n}", `line 3: expected a node after ->, got "}"`},
+		{"digraph { a", `line 1: missing }`},
+		{"digraph {\n\"a }", `line 2: unterminated string`},
+		{"tree { a }", `line 1: expected graph or digraph, got "tree"`},
+	} {
+		_, err := parseDot(test.src)
+		c.Assert(err, qt.ErrorMatches, test.err, qt.Commentf(test.src))
+	}
+}
+
+func TestGraphLayout(t *testing.T) {
+	c := qt.New(t)
+
+	g, err := parseFlowchart("a -> b -> c\na -> c\nc -> a")
+	c.Assert(err, qt.IsNil)
+
+	width, height := g.layout()
+	a, b, cc := g.ids["a"], g.ids["b"], g.ids["c"]
+	c.Assert([]int{a.rank, b.rank, cc.rank}, qt.DeepEquals, []int{0, 1, 2})
+	c.Assert(a.y < b.y && b.y < cc.y, qt.IsTrue)
+	c.Assert(cc.y+cc.h/2 < height, qt.IsTrue)
+	c.Assert(width > 0, qt.IsTrue)
+
+	// The edges skipping a rank pass beside b.
+	for _, e := range g.edges[2:] {
+		c.Assert(e.via, qt.HasLen, 1)
+		c.Assert(e.via[0].x != b.x, qt.IsTrue)
+	}
+}
@@ -0,0 +1,465 @@
+package diagrams
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Dot creates an SVG diagram from the Graphviz DOT graph in v, laid out
+// in ranks like Flowchart. It supports a subset of the language: graph and
+// digraph, node and edge statements with their attribute lists, the node,
+// edge and graph default attributes and subgraphs, which are flattened.
+// The attributes used are rankdir (TB or LR), label, shape (box, ellipse,
+// circle, diamond), style (dashed, rounded); the others are ignored.
+// The optional opts is a map of Theme colors, e.g. the code block attributes.
+func (d *Namespace) Dot(v any, opts ...any) (SVGDiagram, error) {
+	src, err := sourceString(v)
+	if err != nil {
+		return nil, err
+	}
+	theme, err := themeFromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := parseDot(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.render(src, theme), nil
+}
+
+type dotTokenKind int
+
+const (
+	dotEOF dotTokenKind = iota
+	dotID
+	dotPunct
+	dotEdgeOp
+)
+
+type dotToken struct {
+	kind dotTokenKind
+	val  string
+	line int
+}
+
+func (t dotToken) String() string {
+	if t.kind == dotEOF {
+		return "end of input"
+	}
+	return fmt.Sprintf("%q", t.val)
+}
+
+// lexDot splits src into tokens, dropping the comments.
+func lexDot(src string) ([]dotToken, error) {
+	var tokens []dotToken
+	line := 1
+	atLineStart := true
+
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '\n':
+			line++
+			atLineStart = true
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '#' && atLineStart:
+			// A preprocessor output line.
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(src[i:], "//"):
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(src[i:], "/*"):
+			end := strings.Index(src[i+2:], "*/")
+			if end == -1 {
+				return nil, parseErrorf(line, "unterminated comment")
+			}
+			line += strings.Count(src[i:i+2+end], "\n")
+			i += end + 4
+			continue
+		}
+		atLineStart = false
+
+		switch {
+		case strings.HasPrefix(src[i:], "->"), strings.HasPrefix(src[i:], "--"):
+			tokens = append(tokens, dotToken{kind: dotEdgeOp, val: src[i : i+2], line: line})
+			i += 2
+		case strings.ContainsRune("{}[];,=:", rune(c)):
+			tokens = append(tokens, dotToken{kind: dotPunct, val: string(c), line: line})
+			i++
+		case c == '"':
+			var b strings.Builder
+			start := line
+			i++
+			for ; i < len(src) && src[i] != '"'; i++ {
+				if src[i] == '\\' && i+1 < len(src) {
+					switch src[i+1] {
+					case '"':
+						b.WriteByte('"')
+						i++
+						continue
+					case '\n':
+						// A line continuation.
+						line++
+						i++
+						continue
+					}
+				}
+				if src[i] == '\n' {
+					line++
+				}
+				b.WriteByte(src[i])
+			}
+			if i == len(src) {
+				return nil, parseErrorf(start, "unterminated string")
+			}
+			i++
+			tokens = append(tokens, dotToken{kind: dotID, val: b.String(), line: start})
+		case c == '<':
+			return nil, parseErrorf(line, "HTML labels are not supported")
+		default:
+			j := i
+			for j < len(src) {
+				r, size := utf8.DecodeRuneInString(src[j:])
+				if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' &&
+					!(r == '-' && j == i) {
+					break
+				}
+				j += size
+			}
+			if j == i {
+				return nil, parseErrorf(line, "unexpected %q", src[i:i+1])
+			}
+			tokens = append(tokens, dotToken{kind: dotID, val: src[i:j], line: line})
+			i = j
+		}
+	}
+
+	return append(tokens, dotToken{kind: dotEOF, line: line}), nil
+}
+
+type dotParser struct {
+	g        *graph
+	tokens   []dotToken
+	pos      int
+	directed bool
+
+	nodeDefaults map[string]string
+	edgeDefaults map[string]string
+}
+
+func parseDot(src string) (*graph, error) {
+	tokens, err := lexDot(src)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &dotParser{
+		g:            newGraph(),
+		tokens:       tokens,
+		nodeDefaults: make(map[string]string),
+		edgeDefaults: make(map[string]string),
+	}
+	if err := p.parseGraph(); err != nil {
+		return nil, err
+	}
+	if len(p.g.nodes) == 0 {
+		return nil, parseErrorf(1, "no nodes")
+	}
+
+	return p.g, nil
+}
+
+func (p *dotParser) peek() dotToken {
+	return p.tokens[p.pos]
+}
+
+func (p *dotParser) next() dotToken {
+	t := p.tokens[p.pos]
+	if t.kind != dotEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *dotParser) accept(val string) bool {
+	if t := p.peek(); t.kind == dotPunct && t.val == val {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *dotParser) expect(val string) error {
+	if !p.accept(val) {
+		t := p.peek()
+		return parseErrorf(t.line, "expected %q, got %s", val, t)
+	}
+	return nil
+}
+
+// keyword reports whether t is the keyword kw, keywords are case-insensitive.
+func (t dotToken) keyword(kw string) bool {
+	return t.kind == dotID && strings.EqualFold(t.val, kw)
+}
+
+func (p *dotParser) parseGraph() error {
+	if p.peek().keyword("strict") {
+		p.next()
+	}
+	switch t := p.next(); {
+	case t.keyword("digraph"):
+		p.directed = true
+	case t.keyword("graph"):
+	default:
+		return parseErrorf(t.line, "expected graph or digraph, got %s", t)
+	}
+	if p.peek().kind == dotID {
+		p.next()
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+	if err := p.parseStatements(); err != nil {
+		return err
+	}
+	if t := p.peek(); t.kind != dotEOF {
+		return parseErrorf(t.line, "unexpected %s after the graph", t)
+	}
+
+	return nil
+}
+
+// parseStatements parses the statements up to and including the closing brace.
+func (p *dotParser) parseStatements() error {
+	for !p.accept("}") {
+		t := p.peek()
+		switch {
+		case t.kind == dotEOF:
+			return parseErrorf(t.line, "missing }")
+		case p.accept(";"):
+		case t.keyword("subgraph") || (t.kind == dotPunct && t.val == "{"):
+			if err := p.parseSubgraph(); err != nil {
+				return err
+			}
+		case t.keyword("graph") || t.keyword("node") || t.keyword("edge"):
+			p.next()
+			attrs, err := p.parseAttrs()
+			if err != nil {
+				return err
+			}
+			switch strings.ToLower(t.val) {
+			case "graph":
+				p.graphAttrs(attrs)
+			case "node":
+				mergeAttrs(p.nodeDefaults, attrs)
+			case "edge":
+				mergeAttrs(p.edgeDefaults, attrs)
+			}
+		case t.kind == dotID:
+			if err := p.parseNodeOrEdge(); err != nil {
+				return err
+			}
+		default:
+			return parseErrorf(t.line, "unexpected %s", t)
+		}
+	}
+
+	return nil
+}
+
+func (p *dotParser) parseSubgraph() error {
+	if p.peek().keyword("subgraph") {
+		p.next()
+		if p.peek().kind == dotID {
+			p.next()
+		}
+	}
+	if err := p.expect("{"); err != nil {
+		return err
+	}
+
+	// Subgraphs are flattened, their defaults are scoped.
+	nodeDefaults, edgeDefaults := p.nodeDefaults, p.edgeDefaults
+	p.nodeDefaults = mergeAttrs(make(map[string]string), nodeDefaults)
+	p.edgeDefaults = mergeAttrs(make(map[string]string), edgeDefaults)
+	defer func() {
+		p.nodeDefaults, p.edgeDefaults = nodeDefaults, edgeDefaults
+	}()
+
+	if err := p.parseStatements(); err != nil {
+		return err
+	}
+	if t := p.peek(); t.kind == dotEdgeOp {
+		return parseErrorf(t.line, "subgraphs as edge endpoints are not supported")
+	}
+
+	return nil
+}
+
+func (p *dotParser) parseNodeOrEdge() error {
+	first := p.next()
+
+	// A graph attribute, e.g. rankdir=LR.
+	if p.accept("=") {
+		v := p.next()
+		if v.kind != dotID {
+			return parseErrorf(v.line, "expected a value for %q, got %s", first.val, v)
+		}
+		p.graphAttrs(map[string]string{first.val: v.val})
+		return nil
+	}
+
+	ids := []string{first.val}
+	p.skipPort()
+	for p.peek().kind == dotEdgeOp {
+		op := p.next()
+		if (op.val == "->") != p.directed {
+			kind := "an undirected"
+			if p.directed {
+				kind = "a directed"
+			}
+			return parseErrorf(op.line, "edge operator %s in %s graph", op.val, kind)
+		}
+		t := p.next()
+		if t.kind != dotID {
+			if t.kind == dotPunct && t.val == "{" || t.keyword("subgraph") {
+				return parseErrorf(t.line, "subgraphs as edge endpoints are not supported")
+			}
+			return parseErrorf(t.line, "expected a node after %s, got %s", op.val, t)
+		}
+		ids = append(ids, t.val)
+		p.skipPort()
+	}
+
+	attrs, err := p.parseAttrs()
+	if err != nil {
+		return err
+	}
+
+	if len(ids) == 1 {
+		p.applyNodeAttrs(p.node(ids[0]), attrs)
+		return nil
+	}
+
+	edgeAttrs := mergeAttrs(mergeAttrs(make(map[string]string), p.edgeDefaults), attrs)
+	for i := 1; i < len(ids); i++ {
+		p.node(ids[i-1])
+		p.node(ids[i])
+		e := p.g.addEdge(ids[i-1], ids[i])
+		e.directed = p.directed
+		e.label = dotLabel(edgeAttrs["label"])
+		e.dashed = strings.Contains(edgeAttrs["style"], "dashed") || strings.Contains(edgeAttrs["style"], "dotted")
+		if edgeAttrs["dir"] == "none" {
+			e.directed = false
+		}
+	}
+
+	return nil
+}
+
+// node returns the node id, applying the node defaults on first use.
+func (p *dotParser) node(id string) *graphNode {
+	if n, found := p.g.ids[id]; found {
+		return n
+	}
+	n := p.g.node(id)
+	n.shape = shapeEllipse
+	p.applyNodeAttrs(n, p.nodeDefaults)
+	return n
+}
+
+func (p *dotParser) applyNodeAttrs(n *graphNode, attrs map[string]string) {
+	if label, found := attrs["label"]; found {
+		n.label = dotLabel(label)
+	}
+	if shape, found := attrs["shape"]; found {
+		switch strings.ToLower(shape) {
+		case "box", "rect", "rectangle", "square", "record", "plain", "plaintext", "note", "tab", "folder":
+			n.shape = shapeBox
+		case "mrecord":
+			n.shape = shapeRound
+		case "circle", "doublecircle", "point":
+			n.shape = shapeCircle
+		case "diamond":
+			n.shape = shapeDiamond
+		default:
+			n.shape = shapeEllipse
+		}
+	}
+	if strings.Contains(attrs["style"], "rounded") && n.shape == shapeBox {
+		n.shape = shapeRound
+	}
+}
+
+func (p *dotParser) graphAttrs(attrs map[string]string) {
+	if dir, found := attrs["rankdir"]; found {
+		switch strings.ToUpper(dir) {
+		case "LR", "RL":
+			p.g.horizontal = true
+		default:
+			p.g.horizontal = false
+		}
+	}
+}
+
+// skipPort skips the port of a node id, e.g. :n in a:n -> b.
+func (p *dotParser) skipPort() {
+	for i := 0; i < 2 && p.accept(":"); i++ {
+		if p.peek().kind == dotID {
+			p.next()
+		}
+	}
+}
+
+// parseAttrs parses zero or more attribute lists, e.g. [label="a", shape=box].
+func (p *dotParser) parseAttrs() (map[string]string, error) {
+	attrs := make(map[string]string)
+	for p.accept("[") {
+		for !p.accept("]") {
+			k := p.next()
+			if k.kind != dotID {
+				return nil, parseErrorf(k.line, "expected an attribute name, got %s", k)
+			}
+			v := "true"
+			if p.accept("=") {
+				t := p.next()
+				if t.kind != dotID {
+					return nil, parseErrorf(t.line, "expected a value for %q, got %s", k.val, t)
+				}
+				v = t.val
+			}
+			attrs[strings.ToLower(k.val)] = v
+			if !p.accept(",") {
+				p.accept(";")
+			}
+		}
+	}
+	return attrs, nil
+}
+
+func mergeAttrs(dst, src map[string]string) map[string]string {
+	for k, v := range src {
+		dst[k] = v
+	}
+	return dst
+}
+
+// dotLabel turns the line break escapes of a DOT label into line breaks.
+func dotLabel(s string) string {
+	s = strings.NewReplacer(`\n`, "\n", `\l`, "\n", `\r`, "\n").Replace(s)
+	return strings.TrimSuffix(s, "\n")
+}
@@ -0,0 +1,174 @@
+package diagrams
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Flowchart creates an SVG flowchart from the text in v, e.g.
+//
+//	direction LR
+//	start([Start]) -> check{Valid?}
+//	check -> save[Save]: yes
+//	check --> start: no
+//
+// A node is declared by its first use, the brackets after its id set the
+// label and the shape: [box], ([rounded]), {diamond} and ((circle)).
+// Edges are drawn with -> or a dashed -->, and can be chained; the text
+// after a colon labels the edges of the statement. The direction is TB,
+// the default, or LR. Lines starting with # are comments, \n breaks a label.
+// The optional opts is a map of Theme colors, e.g. the code block attributes.
+func (d *Namespace) Flowchart(v any, opts ...any) (SVGDiagram, error) {
+	src, err := sourceString(v)
+	if err != nil {
+		return nil, err
+	}
+	theme, err := themeFromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	g, err := parseFlowchart(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return g.render(src, theme), nil
+}
+
+// flowShapes maps the brackets around a node label to the node shape,
+// the longest first.
+var flowShapes = []struct {
+	open, close string
+	shape       string
+}{
+	{"((", "))", shapeCircle},
+	{"([", "])", shapeRound},
+	{"[", "]", shapeBox},
+	{"(", ")", shapeRound},
+	{"{", "}", shapeDiamond},
+}
+
+func parseFlowchart(src string) (*graph, error) {
+	g := newGraph()
+
+	for i, line := range strings.Split(src, "\n") {
+		lineNum := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if fields := strings.Fields(line); len(fields) == 2 && isFlowDirective(fields[0], fields[1]) {
+			dir := fields[1]
+			switch strings.ToUpper(dir) {
+			case "TB", "TD":
+				g.horizontal = false
+			case "LR":
+				g.horizontal = true
+			default:
+				return nil, parseErrorf(lineNum, "invalid direction %q, expected TB or LR", dir)
+			}
+			continue
+		}
+
+		var (
+			ids    []string
+			dashed []bool
+			rest   = line
+		)
+		for {
+			id, r, err := parseFlowNode(g, rest)
+			if err != nil {
+				return nil, parseErrorf(lineNum, "%s", err)
+			}
+			ids = append(ids, id)
+
+			rest = strings.TrimLeftFunc(r, unicode.IsSpace)
+			if rest == "" {
+				break
+			}
+			if strings.HasPrefix(rest, ":") {
+				if len(ids) < 2 {
+					return nil, parseErrorf(lineNum, "label without an edge")
+				}
+				break
+			}
+
+			switch {
+			case strings.HasPrefix(rest, "-->"):
+				dashed = append(dashed, true)
+				rest = rest[3:]
+			case strings.HasPrefix(rest, "->"):
+				dashed = append(dashed, false)
+				rest = rest[2:]
+			default:
+				return nil, parseErrorf(lineNum, "unexpected %q, expected -> or -->", rest)
+			}
+			rest = strings.TrimLeftFunc(rest, unicode.IsSpace)
+		}
+
+		var label string
+		if strings.HasPrefix(rest, ":") {
+			label = unescapeLabel(strings.TrimSpace(rest[1:]))
+		}
+		for j := 1; j < len(ids); j++ {
+			e := g.addEdge(ids[j-1], ids[j])
+			e.dashed = dashed[j-1]
+			e.label = label
+		}
+	}
+
+	if len(g.nodes) == 0 {
+		return nil, parseErrorf(1, "no nodes")
+	}
+
+	return g, nil
+}
+
+// isFlowDirective reports whether keyword and arg set the direction,
+// e.g. direction LR, or the Mermaid style flowchart LR.
+func isFlowDirective(keyword, arg string) bool {
+	switch strings.ToLower(keyword) {
+	case "direction", "flowchart", "graph":
+		return strings.IndexFunc(arg, func(r rune) bool { return !unicode.IsLetter(r) }) == -1
+	}
+	return false
+}
+
+// parseFlowNode parses the node at the start of s, setting its label and
+// shape if given, and returns its id and the rest of s.
+func parseFlowNode(g *graph, s string) (string, string, error) {
+	end := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.'
+	})
+	if end == -1 {
+		end = len(s)
+	}
+	if end == 0 {
+		if s == "" {
+			return "", "", errors.New("missing node")
+		}
+		return "", "", fmt.Errorf("invalid node id at %q", s)
+	}
+	id, rest := s[:end], s[end:]
+	n := g.node(id)
+
+	for _, sh := range flowShapes {
+		if !strings.HasPrefix(rest, sh.open) {
+			continue
+		}
+		i := strings.Index(rest[len(sh.open):], sh.close)
+		if i == -1 {
+			return "", "", fmt.Errorf("node %q: missing %q", id, sh.close)
+		}
+		n.label = unescapeLabel(unquote(rest[len(sh.open) : len(sh.open)+i]))
+		n.shape = sh.shape
+		rest = rest[len(sh.open)+i+len(sh.close):]
+		break
+	}
+
+	return id, rest, nil
+}
@@ -0,0 +1,445 @@
+package diagrams
+
+import (
+	"math"
+	"slices"
+	"sort"
+)
+
+// Node shapes of a graph.
+const (
+	shapeBox     = "box"
+	shapeRound   = "round"
+	shapeDiamond = "diamond"
+	shapeCircle  = "circle"
+	shapeEllipse = "ellipse"
+)
+
+const (
+	graphNodeHeight = 36
+	graphNodePad    = 14
+	graphNodeGap    = 30
+	graphRankGap    = 50
+	graphSweeps     = 4
+
+	graphVirtualSize = 12
+	graphParallelGap = 12
+)
+
+type graphNode struct {
+	id    string
+	label string
+	shape string
+
+	rank  int
+	order float64
+
+	// Virtual nodes route the edges spanning several ranks.
+	virtual bool
+
+	// Center and size of the laid out node.
+	x, y, w, h float64
+}
+
+type graphEdge struct {
+	from, to *graphNode
+	label    string
+	dashed   bool
+	directed bool
+
+	// The virtual nodes the edge passes through, from the first rank.
+	via []*graphNode
+}
+
+// graph is the model shared by Flowchart and Dot, laid out in ranks
+// top to bottom, or left to right if horizontal is set.
+type graph struct {
+	nodes      []*graphNode
+	ids        map[string]*graphNode
+	edges      []*graphEdge
+	horizontal bool
+}
+
+func newGraph() *graph {
+	return &graph{ids: make(map[string]*graphNode)}
+}
+
+// node returns the node id, adding it on first use.
+func (g *graph) node(id string) *graphNode {
+	if n, found := g.ids[id]; found {
+		return n
+	}
+	n := &graphNode{id: id, label: id, shape: shapeBox}
+	g.ids[id] = n
+	g.nodes = append(g.nodes, n)
+	return n
+}
+
+func (g *graph) addEdge(from, to string) *graphEdge {
+	e := &graphEdge{from: g.node(from), to: g.node(to), directed: true}
+	g.edges = append(g.edges, e)
+	return e
+}
+
+// layout assigns the nodes to ranks by their longest path from a
+// source, ignoring the edges closing a cycle, routes the edges spanning
+// several ranks through virtual nodes, orders the nodes within the ranks
+// to reduce the edge crossings and positions them.
+// It returns the size of the graph.
+func (g *graph) layout() (float64, float64) {
+	for _, n := range g.nodes {
+		tw, th := textSize(n.label)
+		n.w, n.h = tw+2*graphNodePad, max(th+16, graphNodeHeight)
+		switch n.shape {
+		case shapeDiamond:
+			n.w, n.h = n.w*1.4, n.h*1.4
+		case shapeCircle:
+			n.w = max(n.w, n.h)
+			n.h = n.w
+		case shapeEllipse:
+			n.w *= 1.2
+		}
+	}
+
+	// Longest path ranking.
+	in := make(map[*graphNode]int)
+	out := make(map[*graphNode][]*graphNode)
+	for _, e := range g.forwardEdges() {
+		in[e.to]++
+		out[e.from] = append(out[e.from], e.to)
+	}
+	var queue []*graphNode
+	for _, n := range g.nodes {
+		n.rank = 0
+		if in[n] == 0 {
+			queue = append(queue, n)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, m := range out[n] {
+			m.rank = max(m.rank, n.rank+1)
+			if in[m]--; in[m] == 0 {
+				queue = append(queue, m)
+			}
+		}
+	}
+
+	var ranks [][]*graphNode
+	addToRank := func(n *graphNode) {
+		for len(ranks) <= n.rank {
+			ranks = append(ranks, nil)
+		}
+		n.order = float64(len(ranks[n.rank]))
+		ranks[n.rank] = append(ranks[n.rank], n)
+	}
+	for _, n := range g.nodes {
+		addToRank(n)
+	}
+
+	// Split the edges into segments between adjacent ranks.
+	var segments [][2]*graphNode
+	for _, e := range g.edges {
+		e.via = nil
+		if e.from == e.to {
+			continue
+		}
+		lo, hi := e.from, e.to
+		if lo.rank > hi.rank {
+			lo, hi = hi, lo
+		}
+		prev := lo
+		for r := lo.rank + 1; r < hi.rank; r++ {
+			v := &graphNode{rank: r, virtual: true, w: graphVirtualSize, h: graphVirtualSize}
+			addToRank(v)
+			segments = append(segments, [2]*graphNode{prev, v})
+			e.via = append(e.via, v)
+			prev = v
+		}
+		segments = append(segments, [2]*graphNode{prev, hi})
+		if lo != e.from {
+			slices.Reverse(e.via)
+		}
+	}
+
+	// Barycenter ordering, sweeping down and up.
+	neighbours := func(n *graphNode, up bool) []*graphNode {
+		var ns []*graphNode
+		for _, s := range segments {
+			if up && s[1] == n {
+				ns = append(ns, s[0])
+			}
+			if !up && s[0] == n {
+				ns = append(ns, s[1])
+			}
+		}
+		return ns
+	}
+	reorder := func(rank []*graphNode, up bool) {
+		bary := make(map[*graphNode]float64, len(rank))
+		for _, n := range rank {
+			ns := neighbours(n, up)
+			if len(ns) == 0 {
+				bary[n] = n.order
+				continue
+			}
+			var sum float64
+			for _, m := range ns {
+				sum += m.order
+			}
+			bary[n] = sum / float64(len(ns))
+		}
+		sort.SliceStable(rank, func(i, j int) bool {
+			return bary[rank[i]] < bary[rank[j]]
+		})
+		for i, n := range rank {
+			n.order = float64(i)
+		}
+	}
+	for i := 0; i < graphSweeps; i++ {
+		for r := 1; r < len(ranks); r++ {
+			reorder(ranks[r], true)
+		}
+		for r := len(ranks) - 2; r >= 0; r-- {
+			reorder(ranks[r], false)
+		}
+	}
+
+	// Positions, along is the axis of the ranks, across the one within.
+	along := func(n *graphNode) float64 {
+		if g.horizontal {
+			return n.w
+		}
+		return n.h
+	}
+	across := func(n *graphNode) float64 {
+		if g.horizontal {
+			return n.h
+		}
+		return n.w
+	}
+
+	var extent float64
+	rankExtents := make([]float64, len(ranks))
+	for r, rank := range ranks {
+		for i, n := range rank {
+			if i > 0 {
+				rankExtents[r] += graphNodeGap
+			}
+			rankExtents[r] += across(n)
+		}
+		extent = max(extent, rankExtents[r])
+	}
+
+	pos := float64(diagramPad)
+	for r, rank := range ranks {
+		var size float64
+		for _, n := range rank {
+			size = max(size, along(n))
+		}
+		c := diagramPad + (extent-rankExtents[r])/2
+		for _, n := range rank {
+			a, b := pos+size/2, c+across(n)/2
+			if g.horizontal {
+				n.x, n.y = a, b
+			} else {
+				n.x, n.y = b, a
+			}
+			c += across(n) + graphNodeGap
+		}
+		pos += size + graphRankGap
+	}
+	pos += diagramPad - graphRankGap
+
+	// Make room for the self loops right of the nodes.
+	var loop float64
+	for _, e := range g.edges {
+		if e.from == e.to {
+			tw, _ := textSize(e.label)
+			loop = max(loop, 30+tw)
+		}
+	}
+
+	if g.horizontal {
+		return pos + loop, extent + 2*diagramPad
+	}
+	return extent + 2*diagramPad + loop, pos
+}
+
+// forwardEdges returns the edges without the self loops and with the
+// edges closing a cycle, found by a depth first search, reversed.
+func (g *graph) forwardEdges() []*graphEdge {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[*graphNode]int)
+	out := make(map[*graphNode][]*graphEdge)
+	for _, e := range g.edges {
+		out[e.from] = append(out[e.from], e)
+	}
+
+	var forward []*graphEdge
+	var visit func(n *graphNode)
+	visit = func(n *graphNode) {
+		state[n] = visiting
+		for _, e := range out[n] {
+			switch {
+			case e.from == e.to:
+			case state[e.to] == visiting:
+				forward = append(forward, &graphEdge{from: e.to, to: e.from})
+			default:
+				forward = append(forward, e)
+				if state[e.to] == unvisited {
+					visit(e.to)
+				}
+			}
+		}
+		state[n] = done
+	}
+	for _, n := range g.nodes {
+		if state[n] == unvisited {
+			visit(n)
+		}
+	}
+
+	return forward
+}
+
+// boundary returns the point where the line from the center of n
+// towards x, y leaves the shape of n.
+func (n *graphNode) boundary(x, y float64) (float64, float64) {
+	dx, dy := x-n.x, y-n.y
+	if dx == 0 && dy == 0 {
+		return n.x, n.y
+	}
+	hw, hh := n.w/2, n.h/2
+
+	var t float64
+	switch n.shape {
+	case shapeDiamond:
+		t = 1 / (math.Abs(dx)/hw + math.Abs(dy)/hh)
+	case shapeCircle, shapeEllipse:
+		t = 1 / math.Sqrt(dx*dx/(hw*hw)+dy*dy/(hh*hh))
+	default:
+		t = math.Min(hw/math.Abs(dx), hh/math.Abs(dy))
+	}
+
+	return n.x + dx*t, n.y + dy*t
+}
+
+func (g *graph) render(src string, theme Theme) svgDiagram {
+	width, height := g.layout()
+	w := newSVGWriter(src, theme, width, height)
+	offsets := g.parallelOffsets()
+
+	for _, e := range g.edges {
+		class := "line"
+		if e.dashed {
+			class += " dashed"
+		}
+
+		if e.from == e.to {
+			n := e.from
+			x, y := n.x+n.w/2, n.y
+			w.line(class, e.directed, x, y-8, x+20, y-8, x+20, y+8, x, y+8)
+			if e.label != "" {
+				tw, _ := textSize(e.label)
+				w.label(x+26+tw/2, y, e.label)
+			}
+			continue
+		}
+
+		points := e.points(offsets[e])
+		w.line(class, e.directed, points...)
+		if e.label != "" {
+			// On the middle segment, or the middle virtual node.
+			i := len(points) / 2
+			if i%2 == 0 {
+				w.label((points[i-2]+points[i])/2, (points[i-1]+points[i+1])/2, e.label)
+			} else {
+				w.label(points[i-1], points[i], e.label)
+			}
+		}
+	}
+
+	for _, n := range g.nodes {
+		switch n.shape {
+		case shapeDiamond:
+			w.polygon("node", n.x, n.y-n.h/2, n.x+n.w/2, n.y, n.x, n.y+n.h/2, n.x-n.w/2, n.y)
+		case shapeCircle, shapeEllipse:
+			w.ellipse("node", n.x, n.y, n.w/2, n.h/2)
+		case shapeRound:
+			w.rect("node", n.x-n.w/2, n.y-n.h/2, n.w, n.h, n.h/2)
+		default:
+			w.rect("node", n.x-n.w/2, n.y-n.h/2, n.w, n.h, 3)
+		}
+		w.text(n.x, n.y, n.label)
+	}
+
+	return w.diagram(width, height)
+}
+
+// parallelOffsets spreads the edges connecting the same two nodes
+// directly, in either direction, so they do not overlap.
+func (g *graph) parallelOffsets() map[*graphEdge]float64 {
+	type pair struct{ a, b *graphNode }
+	groups := make(map[pair][]*graphEdge)
+	var keys []pair
+	for _, e := range g.edges {
+		if e.from == e.to || len(e.via) > 0 {
+			continue
+		}
+		k := pair{e.from, e.to}
+		if _, found := groups[k]; !found {
+			if _, found := groups[pair{e.to, e.from}]; found {
+				k = pair{e.to, e.from}
+			} else {
+				keys = append(keys, k)
+			}
+		}
+		groups[k] = append(groups[k], e)
+	}
+
+	offsets := make(map[*graphEdge]float64)
+	for _, k := range keys {
+		edges := groups[k]
+		for i, e := range edges {
+			off := (float64(i) - float64(len(edges)-1)/2) * graphParallelGap
+			if e.from != k.a {
+				// The perpendicular of the reverse direction points the other way.
+				off = -off
+			}
+			offsets[e] = off
+		}
+	}
+
+	return offsets
+}
+
+// points returns the polyline of e from the boundary of its start node
+// through its virtual nodes to the boundary of its end node, moved
+// sideways by offset.
+func (e *graphEdge) points(offset float64) []float64 {
+	first, last := e.to, e.from
+	if len(e.via) > 0 {
+		first, last = e.via[0], e.via[len(e.via)-1]
+	}
+
+	var dx, dy float64
+	if offset != 0 {
+		ux, uy := e.to.x-e.from.x, e.to.y-e.from.y
+		l := math.Hypot(ux, uy)
+		dx, dy = -uy/l*offset, ux/l*offset
+	}
+
+	x1, y1 := e.from.boundary(first.x, first.y)
+	x2, y2 := e.to.boundary(last.x, last.y)
+	points := []float64{x1 + dx, y1 + dy}
+	for _, v := range e.via {
+		points = append(points, v.x, v.y)
+	}
+	return append(points, x2+dx, y2+dy)
+}
@@ -0,0 +1,276 @@
+package diagrams
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Sequence creates an SVG sequence diagram from the text in v, e.g.
+//
+//	participant Alice
+//	participant Web Server as Web
+//	Alice -> Web: GET /
+//	note over Web: Render the page
+//	Web --> Alice: 200 OK
+//
+// Participants are declared by their first use unless declared upfront.
+// A --> draws a dashed reply, notes go left of, right of or over one or
+// two participants. Lines starting with # are comments, \n breaks a label.
+// The optional opts is a map of Theme colors, e.g. the code block attributes.
+func (d *Namespace) Sequence(v any, opts ...any) (SVGDiagram, error) {
+	src, err := sourceString(v)
+	if err != nil {
+		return nil, err
+	}
+	theme, err := themeFromOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	seq, err := parseSequence(src)
+	if err != nil {
+		return nil, err
+	}
+
+	return seq.render(src, theme), nil
+}
+
+const (
+	seqBoxHeight  = 36
+	seqBoxPadding = 12
+	seqMinWidth   = 60
+	seqGap        = 30
+	seqSelfWidth  = 40
+)
+
+type seqEventKind int
+
+const (
+	seqMessage seqEventKind = iota
+	seqNote
+)
+
+type seqEvent struct {
+	kind     seqEventKind
+	from, to int
+	text     string
+	dashed   bool
+
+	// Placement of a note: left, right or over.
+	placement string
+}
+
+type sequence struct {
+	participants []string
+	ids          map[string]int
+	events       []seqEvent
+}
+
+var (
+	seqParticipantRe = regexp.MustCompile(`^(?i:participant|actor)\s+(.+?)(?:\s+as\s+(\S+))?$`)
+	seqNoteRe        = regexp.MustCompile(`^(?i:note)\s+(?i:(left of|right of|over))\s+([^:]+?)\s*:\s*(.*)$`)
+	seqMessageRe     = regexp.MustCompile(`^([^:]+?)\s*(-->|->)\s*([^:]+?)\s*(?::\s*(.*))?$`)
+)
+
+func parseSequence(src string) (*sequence, error) {
+	seq := &sequence{ids: make(map[string]int)}
+
+	for i, line := range strings.Split(src, "\n") {
+		lineNum := i + 1
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") || strings.EqualFold(line, "sequenceDiagram") {
+			continue
+		}
+
+		if m := seqParticipantRe.FindStringSubmatch(line); m != nil {
+			label, id := unquote(m[1]), m[2]
+			if id == "" {
+				id = label
+			}
+			if _, found := seq.ids[id]; found {
+				return nil, parseErrorf(lineNum, "participant %q already declared", id)
+			}
+			seq.ids[id] = len(seq.participants)
+			seq.participants = append(seq.participants, unescapeLabel(label))
+			continue
+		}
+
+		if m := seqNoteRe.FindStringSubmatch(line); m != nil {
+			placement := strings.ToLower(strings.Fields(m[1])[0])
+			names := strings.Split(m[2], ",")
+			if len(names) > 2 || (len(names) == 2 && placement != "over") {
+				return nil, parseErrorf(lineNum, "a note %s spans at most one participant", m[1])
+			}
+			from := seq.participant(strings.TrimSpace(names[0]))
+			to := seq.participant(strings.TrimSpace(names[len(names)-1]))
+			seq.events = append(seq.events, seqEvent{
+				kind: seqNote, from: min(from, to), to: max(from, to),
+				text: unescapeLabel(m[3]), placement: placement,
+			})
+			continue
+		}
+
+		if m := seqMessageRe.FindStringSubmatch(line); m != nil {
+			seq.events = append(seq.events, seqEvent{
+				kind:   seqMessage,
+				from:   seq.participant(unquote(m[1])),
+				to:     seq.participant(unquote(m[3])),
+				text:   unescapeLabel(m[4]),
+				dashed: m[2] == "-->",
+			})
+			continue
+		}
+
+		return nil, parseErrorf(lineNum, "invalid statement %q", line)
+	}
+
+	if len(seq.participants) == 0 {
+		return nil, parseErrorf(1, "no participants")
+	}
+
+	return seq, nil
+}
+
+// participant returns the index of the participant id, declaring it
+// on first use.
+func (s *sequence) participant(id string) int {
+	if i, found := s.ids[id]; found {
+		return i
+	}
+	i := len(s.participants)
+	s.ids[id] = i
+	s.participants = append(s.participants, unescapeLabel(id))
+	return i
+}
+
+func (s *sequence) render(src string, theme Theme) svgDiagram {
+	n := len(s.participants)
+	widths := make([]float64, n)
+	xs := make([]float64, n)
+	for i, p := range s.participants {
+		w, _ := textSize(p)
+		widths[i] = max(w+2*seqBoxPadding, seqMinWidth)
+		if i > 0 {
+			xs[i] = xs[i-1] + widths[i-1]/2 + widths[i]/2 + seqGap
+		}
+	}
+
+	// Make room for the labels, moving participant hi and the ones right
+	// of it when it is too close to participant lo.
+	spread := func(lo, hi int, need float64) {
+		if lo < 0 || hi >= n {
+			return
+		}
+		if d := need - (xs[hi] - xs[lo]); d > 0 {
+			for i := hi; i < n; i++ {
+				xs[i] += d
+			}
+		}
+	}
+	for _, e := range s.events {
+		w, _ := textSize(e.text)
+		switch {
+		case e.kind == seqMessage && e.from == e.to:
+			spread(e.from, e.from+1, seqSelfWidth+w+seqGap)
+		case e.kind == seqMessage:
+			spread(min(e.from, e.to), max(e.from, e.to), w+2*seqGap)
+		case e.placement == "over":
+			spread(e.from, e.to, w+2*seqBoxPadding)
+		case e.placement == "right":
+			spread(e.from, e.from+1, w+2*seqBoxPadding+seqGap)
+		case e.placement == "left":
+			spread(e.from-1, e.from, w+2*seqBoxPadding+seqGap)
+		}
+	}
+
+	// Lay out the events top down and collect the horizontal extent.
+	type noteBox struct{ x, y, w, h float64 }
+	minX, maxX := xs[0]-widths[0]/2, xs[n-1]+widths[n-1]/2
+	y := float64(seqBoxHeight + 2*diagramPad)
+	ys := make([]float64, len(s.events))
+	notes := make([]noteBox, len(s.events))
+	for i, e := range s.events {
+		w, h := textSize(e.text)
+		if e.kind == seqMessage {
+			if e.text == "" {
+				h = 0
+			}
+			ys[i] = y + h + 6
+			y = ys[i] + 18
+			if e.from == e.to {
+				y += 20
+				maxX = max(maxX, xs[e.from]+seqSelfWidth+w+diagramPad)
+			}
+			continue
+		}
+
+		nb := noteBox{w: w + 2*seqBoxPadding, h: h + 12, y: y}
+		switch e.placement {
+		case "left":
+			nb.x = xs[e.from] - 10 - nb.w
+		case "right":
+			nb.x = xs[e.from] + 10
+		default:
+			lo, hi := xs[e.from]-seqMinWidth/2, xs[e.to]+seqMinWidth/2
+			nb.w = max(nb.w, hi-lo)
+			nb.x = (lo+hi)/2 - nb.w/2
+		}
+		notes[i] = nb
+		minX, maxX = min(minX, nb.x), max(maxX, nb.x+nb.w)
+		y += nb.h + 12
+	}
+
+	dx := diagramPad - minX
+	width := maxX - minX + 2*diagramPad
+	bottom := y + 10
+	height := bottom + seqBoxHeight + diagramPad
+
+	w := newSVGWriter(src, theme, width, height)
+	for i, p := range s.participants {
+		x := xs[i] + dx
+		w.line("line lifeline", false, x, diagramPad+seqBoxHeight, x, bottom)
+		for _, top := range []float64{diagramPad, bottom} {
+			w.rect("node", x-widths[i]/2, top, widths[i], seqBoxHeight, 3)
+			w.text(x, top+seqBoxHeight/2, p)
+		}
+	}
+
+	for i, e := range s.events {
+		if e.kind == seqNote {
+			nb := notes[i]
+			w.rect("note", nb.x+dx, nb.y, nb.w, nb.h, 0)
+			w.text(nb.x+dx+nb.w/2, nb.y+nb.h/2, e.text)
+			continue
+		}
+
+		class := "line"
+		if e.dashed {
+			class += " dashed"
+		}
+		x1, x2, ly := xs[e.from]+dx, xs[e.to]+dx, ys[i]
+		_, h := textSize(e.text)
+		if e.from == e.to {
+			w.line(class, true, x1, ly, x1+seqSelfWidth, ly, x1+seqSelfWidth, ly+20, x1, ly+20)
+			if e.text != "" {
+				tw, _ := textSize(e.text)
+				w.text(x1+seqSelfWidth+6+tw/2, ly+10, e.text)
+			}
+			continue
+		}
+		w.line(class, true, x1, ly, x2, ly)
+		if e.text != "" {
+			w.text((x1+x2)/2, ly-h/2-4, e.text)
+		}
+	}
+
+	return w.diagram(width, height)
+}
+
+// unquote removes the double quotes around s, if any.
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
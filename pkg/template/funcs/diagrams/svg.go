@@ -0,0 +1,260 @@
+package diagrams
+
+import (
+	"fmt"
+	"hash/fnv"
+	"html/template"
+	"io"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cast"
+)
+
+const (
+	fontSize    = 14
+	lineHeight  = 18
+	charWidth   = 8.4
+	fontFamily  = "-apple-system,BlinkMacSystemFont,Segoe UI,Helvetica,Arial,sans-serif"
+	diagramPad  = 10
+	arrowMarker = "diagram-arrow"
+)
+
+// Theme holds the colors of the diagrams rendered by Sequence, Flowchart
+// and Dot. Each color is the fallback of a CSS custom property, so a site
+// can restyle the inline SVG from its stylesheet, e.g.
+//
+//	:root { --diagram-stroke: #ccc; }
+type Theme struct {
+	// Fill of the nodes and participant boxes, --diagram-fill.
+	Fill string
+	// Stroke of the borders, lifelines and edges, --diagram-stroke.
+	Stroke string
+	// Text color, --diagram-text.
+	Text string
+	// Fill of notes and edge labels, --diagram-note.
+	Note string
+	// Background of the diagram, --diagram-background.
+	Background string
+}
+
+// DefaultTheme is the theme used for the colors not set in the options.
+var DefaultTheme = Theme{
+	Fill:       "#f6f8fa",
+	Stroke:     "#57606a",
+	Text:       "#24292f",
+	Note:       "#fff8c5",
+	Background: "transparent",
+}
+
+// colorRe matches the color values allowed in the style sheet, e.g.
+// #fff, rebeccapurple and rgb(0, 0, 0).
+var colorRe = regexp.MustCompile(`^[#a-zA-Z0-9(),.% -]+$`)
+
+// themeFromOptions overrides the colors of DefaultTheme with the ones in
+// opts, e.g. the attributes of a code block, other options are ignored.
+func themeFromOptions(opts []any) (Theme, error) {
+	theme := DefaultTheme
+	if len(opts) == 0 || opts[0] == nil {
+		return theme, nil
+	}
+	if len(opts) > 1 {
+		return theme, fmt.Errorf("too many arguments, expected a map of options")
+	}
+
+	m, err := cast.ToStringMapE(opts[0])
+	if err != nil {
+		return theme, fmt.Errorf("invalid options: %w", err)
+	}
+
+	for k, v := range m {
+		var color *string
+		switch strings.ToLower(k) {
+		case "fill":
+			color = &theme.Fill
+		case "stroke":
+			color = &theme.Stroke
+		case "text", "color":
+			color = &theme.Text
+		case "note":
+			color = &theme.Note
+		case "background":
+			color = &theme.Background
+		default:
+			continue
+		}
+		s := cast.ToString(v)
+		if !colorRe.MatchString(s) {
+			return theme, fmt.Errorf("invalid %s color %q", k, s)
+		}
+		*color = s
+	}
+
+	return theme, nil
+}
+
+// ParseError is returned for invalid diagram source, Line is
+// relative to the start of the source.
+type ParseError struct {
+	Line int
+	Msg  string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.Line, e.Msg)
+}
+
+func parseErrorf(line int, format string, args ...any) error {
+	return &ParseError{Line: line, Msg: fmt.Sprintf(format, args...)}
+}
+
+// sourceString reads the diagram source from v.
+func sourceString(v any) (string, error) {
+	switch vv := v.(type) {
+	case io.Reader:
+		b, err := io.ReadAll(vv)
+		return string(b), err
+	default:
+		return cast.ToStringE(v)
+	}
+}
+
+type svgDiagram struct {
+	inner  string
+	width  int
+	height int
+}
+
+func (d svgDiagram) Inner() template.HTML {
+	return template.HTML(d.inner)
+}
+
+func (d svgDiagram) Wrapped() template.HTML {
+	return template.HTML(fmt.Sprintf(
+		`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" width="%d" height="%d">%s</svg>`,
+		d.width, d.height, d.width, d.height, d.inner))
+}
+
+func (d svgDiagram) Width() int {
+	return d.width
+}
+
+func (d svgDiagram) Height() int {
+	return d.height
+}
+
+// svgWriter builds the inner markup of a diagram.
+type svgWriter struct {
+	b  strings.Builder
+	id string
+}
+
+// newSVGWriter starts a diagram with the theme's style sheet and arrow
+// marker. The ids are derived from src to keep several diagrams on the
+// same page apart.
+func newSVGWriter(src string, theme Theme, width, height float64) *svgWriter {
+	h := fnv.New32a()
+	h.Write([]byte(src))
+	w := &svgWriter{id: fmt.Sprintf("d%x", h.Sum32())}
+
+	fmt.Fprintf(&w.b, `<style>`+
+		`.%[1]s{font-family:%[2]s;font-size:%[3]dpx}`+
+		`.%[1]s .bg{fill:var(--diagram-background,%[8]s)}`+
+		`.%[1]s .node{fill:var(--diagram-fill,%[4]s);stroke:var(--diagram-stroke,%[5]s);stroke-width:1.5}`+
+		`.%[1]s .note{fill:var(--diagram-note,%[7]s);stroke:var(--diagram-stroke,%[5]s)}`+
+		`.%[1]s .line{fill:none;stroke:var(--diagram-stroke,%[5]s);stroke-width:1.5}`+
+		`.%[1]s .dashed{stroke-dasharray:5,4}`+
+		`.%[1]s .lifeline{stroke-dasharray:3,3;stroke-width:1}`+
+		`.%[1]s .arrow{fill:var(--diagram-stroke,%[5]s)}`+
+		`.%[1]s .label{fill:var(--diagram-note,%[7]s);opacity:.9}`+
+		`.%[1]s text{fill:var(--diagram-text,%[6]s);text-anchor:middle;dominant-baseline:central}`+
+		`</style>`,
+		w.id, fontFamily, fontSize, theme.Fill, theme.Stroke, theme.Text, theme.Note, theme.Background)
+	fmt.Fprintf(&w.b, `<defs><marker id="%s-%s" viewBox="0 0 10 10" refX="10" refY="5" markerWidth="8" markerHeight="8" orient="auto-start-reverse">`+
+		`<path class="arrow" d="M0,0L10,5L0,10z"/></marker></defs>`, w.id, arrowMarker)
+	fmt.Fprintf(&w.b, `<g class="%s"><rect class="bg" x="0" y="0" width="%s" height="%s"/>`, w.id, num(width), num(height))
+
+	return w
+}
+
+func (w *svgWriter) rect(class string, x, y, width, height, radius float64) {
+	fmt.Fprintf(&w.b, `<rect class="%s" x="%s" y="%s" width="%s" height="%s" rx="%s"/>`,
+		class, num(x), num(y), num(width), num(height), num(radius))
+}
+
+func (w *svgWriter) ellipse(class string, cx, cy, rx, ry float64) {
+	fmt.Fprintf(&w.b, `<ellipse class="%s" cx="%s" cy="%s" rx="%s" ry="%s"/>`,
+		class, num(cx), num(cy), num(rx), num(ry))
+}
+
+func (w *svgWriter) polygon(class string, points ...float64) {
+	fmt.Fprintf(&w.b, `<polygon class="%s" points="%s"/>`, class, pointList(points))
+}
+
+// line draws a polyline through points, with an arrow head at the end
+// if arrow is set.
+func (w *svgWriter) line(class string, arrow bool, points ...float64) {
+	fmt.Fprintf(&w.b, `<polyline class="%s" points="%s"`, class, pointList(points))
+	if arrow {
+		fmt.Fprintf(&w.b, ` marker-end="url(#%s-%s)"`, w.id, arrowMarker)
+	}
+	w.b.WriteString("/>")
+}
+
+// text writes the lines of s centered on x, y.
+func (w *svgWriter) text(x, y float64, s string) {
+	lines := strings.Split(s, "\n")
+	y -= float64(len(lines)-1) * lineHeight / 2
+	for i, l := range lines {
+		fmt.Fprintf(&w.b, `<text x="%s" y="%s">%s</text>`,
+			num(x), num(y+float64(i)*lineHeight), template.HTMLEscapeString(l))
+	}
+}
+
+// label writes s centered on x, y on a background.
+func (w *svgWriter) label(x, y float64, s string) {
+	width, height := textSize(s)
+	w.rect("label", x-width/2-2, y-height/2, width+4, height, 2)
+	w.text(x, y, s)
+}
+
+func (w *svgWriter) diagram(width, height float64) svgDiagram {
+	w.b.WriteString("</g>")
+	return svgDiagram{inner: w.b.String(), width: int(math.Ceil(width)), height: int(math.Ceil(height))}
+}
+
+// textSize estimates the size of s rendered in the diagram font,
+// lines are separated by \n.
+func textSize(s string) (float64, float64) {
+	lines := strings.Split(s, "\n")
+	var width float64
+	for _, l := range lines {
+		width = max(width, float64(utf8.RuneCountInString(l))*charWidth)
+	}
+	return width, float64(len(lines)) * lineHeight
+}
+
+// unescapeLabel turns the \n escapes in a label into line breaks.
+func unescapeLabel(s string) string {
+	return strings.ReplaceAll(s, `\n`, "\n")
+}
+
+func num(f float64) string {
+	return strconv.FormatFloat(math.Round(f*10)/10, 'f', -1, 64)
+}
+
+func pointList(points []float64) string {
+	var b strings.Builder
+	for i := 0; i+1 < len(points); i += 2 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(num(points[i]))
+		b.WriteByte(',')
+		b.WriteString(num(points[i+1]))
+	}
+	return b.String()
+}
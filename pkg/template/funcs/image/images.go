@@ -1,13 +1,87 @@
 package image
 
-// New returns a new instance of the resources-namespaced template functions.
-func New(img Image) (*Namespace, error) {
+import (
+	"errors"
+	"github.com/mdfriday/hugoverse/internal/domain/resources"
+	"github.com/mdfriday/hugoverse/pkg/images/qr"
+	"github.com/mitchellh/mapstructure"
+	"github.com/spf13/cast"
+	"image"
+)
+
+// New returns a new instance of the images-namespaced template functions.
+func New(img Image, rs Resources) (*Namespace, error) {
 	return &Namespace{
 		Image: img,
+		rs:    rs,
 	}, nil
 }
 
-// Namespace provides template functions for the "resources" namespace.
+// Namespace provides template functions for the "images" namespace.
 type Namespace struct {
 	Image
+
+	rs Resources
+}
+
+// Config returns the image.Config for the specified path, a http(s) URL or
+// a filename relative to the working dir.
+func (ns *Namespace) Config(path any) (image.Config, error) {
+	filename, err := cast.ToStringE(path)
+	if err != nil {
+		return image.Config{}, err
+	}
+	return ns.rs.ImageConfig(filename)
+}
+
+// QROptions are the options for images.QR.
+type QROptions struct {
+	// The error correction level, one of low, medium, quartile or high.
+	Level string
+
+	// The number of pixels per module.
+	Scale int
+
+	// The directory the image is published to, relative to the publish dir.
+	TargetDir string
+}
+
+var defaultQROptions = QROptions{
+	Level:     "medium",
+	Scale:     4,
+	TargetDir: "",
+}
+
+// QR encodes the given text as a QR code and returns it as a PNG image
+// resource, e.g. {{ images.QR "https://example.org" (dict "level" "high" "scale" 3) }}.
+func (ns *Namespace) QR(args ...any) (resources.Resource, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, errors.New("images.QR: requires 1 or 2 arguments")
+	}
+
+	text, err := cast.ToStringE(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	opts := defaultQROptions
+	if len(args) == 2 {
+		m, err := cast.ToStringMapE(args[1])
+		if err != nil {
+			return nil, err
+		}
+		if err := mapstructure.WeakDecode(m, &opts); err != nil {
+			return nil, err
+		}
+	}
+
+	level, err := qr.ParseLevel(opts.Level)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Scale < 2 {
+		return nil, errors.New("images.QR: scale must be at least 2")
+	}
+
+	return ns.rs.QR(text, level, opts.Scale, opts.TargetDir)
 }
@@ -1,8 +1,18 @@
 package image
 
-import "github.com/disintegration/gift"
+import (
+	"github.com/disintegration/gift"
+	"github.com/mdfriday/hugoverse/internal/domain/resources"
+	"github.com/mdfriday/hugoverse/pkg/images/qr"
+	"image"
+)
 
 type Image interface {
 	AutoOrient() gift.Filter
 	Process(spec any) gift.Filter
 }
+
+type Resources interface {
+	ImageConfig(path string) (image.Config, error)
+	QR(text string, level qr.Level, scale int, targetDir string) (resources.Resource, error)
+}
@@ -16,10 +16,15 @@
 package partials
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
+	"github.com/mdfriday/hugoverse/pkg/cache/filecache"
 	"github.com/mdfriday/hugoverse/pkg/identity"
+	"html/template"
+	"io"
+	"reflect"
 	"strings"
 	"time"
 
@@ -63,7 +68,9 @@ func (p *partialCache) clear() {
 }
 
 // New returns a new instance of the templates-namespaced template functions.
-func New(cb func(ctx context.Context, name string, data any) (tmpl string, res any, err error)) *Namespace {
+// The store is optional, if set and enabled, the output of partialCached is
+// persisted between builds.
+func New(cb TemplateExecutor, id TemplateIdentity, store *filecache.Cache) *Namespace {
 	// This lazycache was introduced in Hugo 0.111.0.
 	// We're going to expand and consolidate all memory caches in Hugo using this,
 	// so just set a high limit for now.
@@ -72,18 +79,30 @@ func New(cb func(ctx context.Context, name string, data any) (tmpl string, res a
 	cache := &partialCache{cache: lru}
 	defer cache.clear()
 
+	if store != nil && !store.Enabled() {
+		store = nil
+	}
+
 	return &Namespace{
 		cachedPartials:   cache,
 		templateExecutor: cb,
+		templateIdentity: id,
+		store:            store,
 	}
 }
 
 type TemplateExecutor func(ctx context.Context, name string, data any) (tmpl string, res any, err error)
 
+// TemplateIdentity returns an identity of the named template changing with
+// its source, false if not found.
+type TemplateIdentity func(name string) (string, bool)
+
 // Namespace provides template functions for the "templates" namespace.
 type Namespace struct {
 	cachedPartials   *partialCache
 	templateExecutor TemplateExecutor
+	templateIdentity TemplateIdentity
+	store            *filecache.Cache
 }
 
 // contextWrapper makes room for a return value in a partial invocation.
@@ -114,6 +133,117 @@ func (ns *Namespace) Include(ctx context.Context, name string, contextList ...an
 	return res.result, nil
 }
 
+// IncludeCached executes and caches partial templates.  The cache is created with name+variants as the key.
+// Note that ctx is provided by Hugo, not the end user.
+func (ns *Namespace) IncludeCached(ctx context.Context, name string, context any, variants ...any) (any, error) {
+	key := partialCacheKey{
+		Name:     name,
+		Variants: variants,
+	}
+
+	r, _, err := ns.cachedPartials.cache.GetOrCreate(key.Key(), func(string) (includeResult, error) {
+		r := ns.includeStored(ctx, key, context)
+		return r, r.err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return r.result, nil
+}
+
+const (
+	storedHTML = "html\n"
+	storedText = "text\n"
+)
+
+// includeStored is include backed by the persistent store, if enabled.
+// Only rendered output is stored, not the values of partials with a return
+// statement. It's keyed by the sources of the partial and those it includes,
+// the variants and the context data, so only partials with plain context
+// data, e.g. a dict of strings, are stored; a page is only known by its
+// identity, not by its value.
+func (ns *Namespace) includeStored(ctx context.Context, key partialCacheKey, data any) includeResult {
+	if ns.store == nil || ns.templateIdentity == nil || !isPlain(reflect.ValueOf(data), 0) {
+		return ns.includWithTimeout(ctx, key.Name, data)
+	}
+
+	tid, found := ns.templateIdentity(key.templateName())
+	if !found {
+		return ns.includWithTimeout(ctx, key.Name, data)
+	}
+	id := "partials/" + identity.HashString(tid, key.Key(), data)
+
+	if _, b, err := ns.store.GetBytes(id); err == nil && b != nil {
+		switch {
+		case bytes.HasPrefix(b, []byte(storedHTML)):
+			return includeResult{name: key.templateName(), result: template.HTML(b[len(storedHTML):])}
+		case bytes.HasPrefix(b, []byte(storedText)):
+			return includeResult{name: key.templateName(), result: string(b[len(storedText):])}
+		}
+	}
+
+	r := ns.includWithTimeout(ctx, key.Name, data)
+	if r.err != nil {
+		return r
+	}
+
+	var stored string
+	switch v := r.result.(type) {
+	case template.HTML:
+		stored = storedHTML + string(v)
+	case string:
+		stored = storedText + v
+	default:
+		return r
+	}
+
+	if _, w, err := ns.store.WriteCloser(id); err == nil {
+		_, _ = io.WriteString(w, stored)
+		_ = w.Close()
+	}
+
+	return r
+}
+
+// maxPlainDepth bounds the nesting of plain data.
+const maxPlainDepth = 32
+
+// isPlain reports whether v is plain data, a scalar, or slices and maps of
+// plain data, which hashes to the same value for the same content.
+func isPlain(v reflect.Value, depth int) bool {
+	if depth > maxPlainDepth {
+		return false
+	}
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	case reflect.Interface, reflect.Ptr:
+		return v.IsNil() || isPlain(v.Elem(), depth+1)
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if !isPlain(v.Index(i), depth+1) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if !isPlain(iter.Key(), depth+1) || !isPlain(iter.Value(), depth+1) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 func (ns *Namespace) includWithTimeout(ctx context.Context, name string, dataList ...any) includeResult {
 	// Create a new context with a timeout not connected to the incoming context.
 	// TODO hardcode timeout here for now
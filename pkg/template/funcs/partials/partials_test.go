@@ -0,0 +1,72 @@
+package partials
+
+import (
+	"context"
+	"html/template"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/mdfriday/hugoverse/pkg/cache/filecache"
+	"github.com/spf13/afero"
+)
+
+func TestIncludeCached(t *testing.T) {
+	c := qt.New(t)
+
+	var calls int
+	exec := func(ctx context.Context, name string, data any) (string, any, error) {
+		calls++
+		return name, template.HTML("<nav>" + data.(string) + "</nav>"), nil
+	}
+	source := "v1"
+	id := func(name string) (string, bool) { return name + source, true }
+	store := filecache.NewCache(afero.NewMemMapFs(), -1, "")
+
+	ns := New(exec, id, store)
+	for i := 0; i < 2; i++ {
+		v, err := ns.IncludeCached(context.Background(), "nav.html", "a", "main")
+		c.Assert(err, qt.IsNil)
+		c.Assert(v, qt.Equals, template.HTML("<nav>a</nav>"))
+	}
+	c.Assert(calls, qt.Equals, 1)
+
+	// A new build is served from the store.
+	ns = New(exec, id, store)
+	v, err := ns.IncludeCached(context.Background(), "nav.html", "a", "main")
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.Equals, template.HTML("<nav>a</nav>"))
+	c.Assert(calls, qt.Equals, 1)
+
+	// Other context data is rendered.
+	ns = New(exec, id, store)
+	v, err = ns.IncludeCached(context.Background(), "nav.html", "b", "main")
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.Equals, template.HTML("<nav>b</nav>"))
+	c.Assert(calls, qt.Equals, 2)
+
+	// Changing the template invalidates the stored output.
+	source = "v2"
+	ns = New(exec, id, store)
+	v, err = ns.IncludeCached(context.Background(), "nav.html", "b", "main")
+	c.Assert(err, qt.IsNil)
+	c.Assert(v, qt.Equals, template.HTML("<nav>b</nav>"))
+	c.Assert(calls, qt.Equals, 3)
+
+	// Data only known by its identity, e.g. a page, is not stored.
+	type page struct{ Title string }
+	exec = func(ctx context.Context, name string, data any) (string, any, error) {
+		calls++
+		return name, template.HTML("<nav>" + data.(*page).Title + "</nav>"), nil
+	}
+	for i := 0; i < 2; i++ {
+		ns = New(exec, id, store)
+		v, err = ns.IncludeCached(context.Background(), "nav.html", &page{Title: "p"}, "main")
+		c.Assert(err, qt.IsNil)
+		c.Assert(v, qt.Equals, template.HTML("<nav>p</nav>"))
+	}
+	c.Assert(calls, qt.Equals, 5)
+
+	// The store is opt-in.
+	ns = New(exec, id, filecache.NewCache(afero.NewMemMapFs(), 0, ""))
+	c.Assert(ns.store, qt.IsNil)
+}
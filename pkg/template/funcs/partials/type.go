@@ -0,0 +1,8 @@
+package partials
+
+import "github.com/mdfriday/hugoverse/pkg/cache/filecache"
+
+type Cache interface {
+	// PartialsCache is the persistent store for partialCached.
+	PartialsCache() *filecache.Cache
+}
@@ -40,6 +40,36 @@ func (ns *Namespace) Get(filename any) resources.Resource {
 	return r
 }
 
+// GetRemote gets the URL (via HTTP(s)) in the first argument in args and creates Resource object that can be used for
+// further transformations.
+//
+// A second argument may be provided with an option map, e.g. method, headers
+// and body. Errors are returned as a Resource with .Err set, so they can be
+// handled in the template.
+func (ns *Namespace) GetRemote(args ...any) resources.Resource {
+	if len(args) < 1 {
+		panic(errors.New("must provide an URL"))
+	}
+	if len(args) > 2 {
+		panic(errors.New("must not provide more arguments than URL and options"))
+	}
+
+	urlstr, err := cast.ToStringE(args[0])
+	if err != nil {
+		panic(err)
+	}
+
+	var options map[string]any
+	if len(args) > 1 {
+		options, err = maps.ToStringMapE(args[1])
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return ns.resourceService.GetRemote(urlstr, options)
+}
+
 // Copy copies r to the new targetPath in s.
 func (ns *Namespace) Copy(s any, r resources.Resource) (resources.Resource, error) {
 	targetPath, err := cast.ToStringE(s)
@@ -74,6 +104,11 @@ func (ns *Namespace) Minify(r resources.Resource) (resources.Resource, error) {
 	return ns.resourceService.Minify(r)
 }
 
+// PostProcess processes r after the build.
+func (ns *Namespace) PostProcess(r resources.Resource) (resources.PostPublishedResource, error) {
+	return ns.resourceService.PostProcess(r)
+}
+
 // ExecuteAsTemplate creates a Resource from a Go template, parsed and executed with
 // the given data, and published to the relative target path.
 func (ns *Namespace) ExecuteAsTemplate(ctx context.Context, args ...any) (resources.Resource, error) {
@@ -9,6 +9,7 @@ type Resource interface {
 	GetResource(pathname string) (resources.Resource, error)
 	GetMatch(pattern string) (resources.Resource, error)
 	Copy(r resources.Resource, targetPath string) (resources.Resource, error)
+	GetRemote(uri string, options map[string]any) resources.Resource
 
 	Minify(r resources.Resource) (resources.Resource, error)
 
@@ -19,4 +20,6 @@ type Resource interface {
 	ToCSS(res resources.Resource, args map[string]any) (resources.Resource, error)
 
 	Concat(targetPath string, r []resources.Resource) (resources.Resource, error)
+
+	PostProcess(r resources.Resource) (resources.PostPublishedResource, error)
 }
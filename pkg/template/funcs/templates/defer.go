@@ -0,0 +1,72 @@
+package templates
+
+import (
+	"github.com/mdfriday/hugoverse/internal/domain/resources"
+	"github.com/mdfriday/hugoverse/pkg/identity"
+	"sync"
+)
+
+// DeferredTemplatePrefix is the name prefix of the templates extracted from
+// the templates.Defer blocks.
+const DeferredTemplatePrefix = "_internal/deferred/"
+
+// DeferredExecution is a templates.Defer block waiting for the build to be
+// done.
+type DeferredExecution struct {
+	// Placeholder is written to the output in place of the block.
+	Placeholder string
+	// TemplateName is the name of the template holding the block.
+	TemplateName string
+	// Data is the dot of the block.
+	Data any
+}
+
+// Deferred holds the deferred executions of a build.
+// It is safe for concurrent use.
+type Deferred struct {
+	mu         sync.Mutex
+	executions map[string]*DeferredExecution
+}
+
+func NewDeferred() *Deferred {
+	return &Deferred{executions: make(map[string]*DeferredExecution)}
+}
+
+// Add registers an execution of the given template, identified by the
+// template name and key, and returns its placeholder. The first registration
+// of an identity wins.
+func (d *Deferred) Add(templateName, key string, data any) string {
+	id := identity.HashString(templateName, key)
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if e, found := d.executions[id]; found {
+		return e.Placeholder
+	}
+
+	e := &DeferredExecution{
+		// The placeholder has the resources.PostProcess prefix so the
+		// published files holding it are flagged for post processing.
+		Placeholder:  resources.PostProcessPrefix + "_defer_" + id + resources.PostProcessSuffix,
+		TemplateName: templateName,
+		Data:         data,
+	}
+	d.executions[id] = e
+
+	return e.Placeholder
+}
+
+// Drain returns and removes all executions.
+func (d *Deferred) Drain() []*DeferredExecution {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	executions := make([]*DeferredExecution, 0, len(d.executions))
+	for _, e := range d.executions {
+		executions = append(executions, e)
+	}
+	d.executions = make(map[string]*DeferredExecution)
+
+	return executions
+}
@@ -0,0 +1,59 @@
+// Package templates provides template functions for working with templates.
+package templates
+
+import (
+	"errors"
+	"fmt"
+	"github.com/mdfriday/hugoverse/pkg/maps"
+	"github.com/mitchellh/mapstructure"
+)
+
+// New returns a new instance of the templates-namespaced template functions.
+func New(deferred *Deferred) *Namespace {
+	return &Namespace{deferred: deferred}
+}
+
+// Namespace provides template functions for the "templates" namespace.
+type Namespace struct {
+	deferred *Deferred
+}
+
+// DeferOpts are the options accepted by templates.Defer.
+type DeferOpts struct {
+	// Key identifies the block, blocks with the same key are executed once.
+	Key string
+
+	// Data is the dot of the block.
+	Data any
+}
+
+// Defer defers the execution of the wrapping with block until all pages are
+// rendered, e.g.
+//
+//	{{ with (templates.Defer (dict "key" "global")) }}
+//	  {{ $css := resources.Get "css/main.css" | css.Build }}
+//	{{ end }}
+//
+// Variables from outside the block are not available, pass them as data.
+// The block is rewritten to a call to DoDefer when the template is parsed,
+// so this is only called when used outside a with block.
+func (ns *Namespace) Defer(args ...any) (bool, error) {
+	return false, errors.New("templates.Defer must be used as the pipeline of a with block")
+}
+
+// DoDefer registers the deferred execution of the named template and returns
+// its placeholder. For internal use.
+func (ns *Namespace) DoDefer(templateName string, args ...any) (string, error) {
+	var opts DeferOpts
+	if len(args) > 0 {
+		m, err := maps.ToStringMapE(args[0])
+		if err != nil {
+			return "", fmt.Errorf("templates.Defer: invalid options: %w", err)
+		}
+		if err := mapstructure.WeakDecode(m, &opts); err != nil {
+			return "", fmt.Errorf("templates.Defer: failed to decode options: %w", err)
+		}
+	}
+
+	return ns.deferred.Add(templateName, opts.Key, opts.Data), nil
+}
@@ -0,0 +1,235 @@
+// Package validate checks the form values of content items against
+// declarative rules, e.g. required fields, patterns or unique values, and
+// reports what fails by field.
+package validate
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError is a rule failed by the value of a field.
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (e *FieldError) Error() string {
+	return e.Field + " " + e.Message
+}
+
+// Errors are the rules failed by the values of an item, in the order of
+// the rules.
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	s := make([]string, len(e))
+	for i, fe := range e {
+		s[i] = fe.Error()
+	}
+	return strings.Join(s, "; ")
+}
+
+// Fields returns the messages of e by field.
+func (e Errors) Fields() map[string][]string {
+	m := make(map[string][]string)
+	for _, fe := range e {
+		m[fe.Field] = append(m[fe.Field], fe.Message)
+	}
+	return m
+}
+
+// Lookup looks up the other items of the type validated, for the rules
+// across items.
+type Lookup interface {
+	// Exists reports whether an item other than the one validated has
+	// value in field, and the values in scope in the fields of scope.
+	Exists(field, value string, scope map[string]string) (bool, error)
+}
+
+// Rule checks the values of one or more fields of an item. Rules other
+// than Required let empty values pass.
+type Rule struct {
+	// Name names the rule in errors, e.g. required.
+	Name string
+	// Fields are the fields checked, errors are reported on the first.
+	Fields []string
+
+	check func(values url.Values, l Lookup) (string, error)
+}
+
+// Check returns the errors of the rules failed by values, or nil if all
+// pass. Lookup is needed by Unique only, and may be nil otherwise.
+func Check(rules []Rule, values url.Values, l Lookup) (Errors, error) {
+	var errs Errors
+	for _, r := range rules {
+		msg, err := r.check(values, l)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s of %s: %w", r.Name, r.Fields[0], err)
+		}
+		if msg != "" {
+			errs = append(errs, &FieldError{Field: r.Fields[0], Rule: r.Name, Message: msg})
+		}
+	}
+	return errs, nil
+}
+
+// set returns the values of field which aren't empty.
+func set(values url.Values, field string) []string {
+	var vs []string
+	for _, v := range values[field] {
+		if strings.TrimSpace(v) != "" {
+			vs = append(vs, v)
+		}
+	}
+	return vs
+}
+
+// each returns a rule checking each value of field with fn.
+func each(name, field string, fn func(v string) string) Rule {
+	return Rule{
+		Name:   name,
+		Fields: []string{field},
+		check: func(values url.Values, _ Lookup) (string, error) {
+			for _, v := range set(values, field) {
+				if msg := fn(v); msg != "" {
+					return msg, nil
+				}
+			}
+			return "", nil
+		},
+	}
+}
+
+// Required fails if field has no value.
+func Required(field string) Rule {
+	return Rule{
+		Name:   "required",
+		Fields: []string{field},
+		check: func(values url.Values, _ Lookup) (string, error) {
+			if len(set(values, field)) == 0 {
+				return "is required", nil
+			}
+			return "", nil
+		},
+	}
+}
+
+// Length fails if a value of field has fewer than min or more than max
+// characters. A max of 0 has no upper bound.
+func Length(field string, min, max int) Rule {
+	return each("length", field, func(v string) string {
+		n := utf8.RuneCountInString(v)
+		if n < min {
+			return fmt.Sprintf("must have at least %d characters", min)
+		}
+		if max > 0 && n > max {
+			return fmt.Sprintf("must have at most %d characters", max)
+		}
+		return ""
+	})
+}
+
+// Regex fails if a value of field doesn't match re. Message describes the
+// values expected, e.g. "must be lower case".
+func Regex(field string, re *regexp.Regexp, message string) Rule {
+	if message == "" {
+		message = "must match " + re.String()
+	}
+	return each("regex", field, func(v string) string {
+		if !re.MatchString(v) {
+			return message
+		}
+		return ""
+	})
+}
+
+// URL fails if a value of field isn't an absolute http or https URL.
+func URL(field string) Rule {
+	return each("url", field, func(v string) string {
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "must be a URL, e.g. https://example.org/"
+		}
+		return ""
+	})
+}
+
+// Enum fails if a value of field isn't one of options.
+func Enum(field string, options ...string) Rule {
+	return each("enum", field, func(v string) string {
+		for _, o := range options {
+			if v == o {
+				return ""
+			}
+		}
+		return "must be one of " + strings.Join(options, ", ")
+	})
+}
+
+// Range fails if a value of field isn't a number in [min, max]. A nil
+// bound is open.
+func Range(field string, min, max *float64) Rule {
+	return each("range", field, func(v string) string {
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return "must be a number"
+		}
+		if min != nil && n < *min {
+			return fmt.Sprintf("must be at least %v", *min)
+		}
+		if max != nil && n > *max {
+			return fmt.Sprintf("must be at most %v", *max)
+		}
+		return ""
+	})
+}
+
+// Unique fails if another item of the type has the value of field, and the
+// same values in the fields of scope, e.g. Unique("path", "site") for a
+// path unique within a site.
+func Unique(field string, scope ...string) Rule {
+	msg := "is already taken"
+	if len(scope) > 0 {
+		msg += " within its " + strings.Join(scope, ", ")
+	}
+	return Rule{
+		Name:   "unique",
+		Fields: append([]string{field}, scope...),
+		check: func(values url.Values, l Lookup) (string, error) {
+			v := values.Get(field)
+			if strings.TrimSpace(v) == "" {
+				return "", nil
+			}
+			if l == nil {
+				return "", fmt.Errorf("no lookup")
+			}
+			sc := make(map[string]string, len(scope))
+			for _, f := range scope {
+				sc[f] = values.Get(f)
+			}
+			exists, err := l.Exists(field, v, sc)
+			if err != nil || !exists {
+				return "", err
+			}
+			return msg, nil
+		},
+	}
+}
+
+// Cross checks fields together with fn, which returns why values fail,
+// e.g. "must be after start", or "" if they pass.
+func Cross(name string, fields []string, fn func(values url.Values) string) Rule {
+	return Rule{
+		Name:   name,
+		Fields: fields,
+		check: func(values url.Values, _ Lookup) (string, error) {
+			return fn(values), nil
+		},
+	}
+}
@@ -0,0 +1,73 @@
+package validate
+
+import (
+	"net/url"
+	"regexp"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+type lookup map[string]string
+
+func (l lookup) Exists(field, value string, scope map[string]string) (bool, error) {
+	return l[field+"="+value+"@"+scope["site"]] != "", nil
+}
+
+func TestCheck(t *testing.T) {
+	c := qt.New(t)
+
+	zero := 0.0
+	rules := []Rule{
+		Required("title"),
+		Length("title", 0, 5),
+		Regex("sub", regexp.MustCompile(`^[a-z]+$`), "must be lower case"),
+		URL("base_url"),
+		Enum("kind", "a", "b"),
+		Range("price", &zero, nil),
+		Unique("path", "site"),
+		Cross("order", []string{"end", "start"}, func(v url.Values) string {
+			if v.Get("end") < v.Get("start") {
+				return "must be after start"
+			}
+			return ""
+		}),
+	}
+	l := lookup{"path=/a@1": "taken"}
+
+	errs, err := Check(rules, url.Values{
+		"title":    {"Hello"},
+		"sub":      {"abc"},
+		"base_url": {"https://example.org/"},
+		"kind":     {"a"},
+		"price":    {"1"},
+		"path":     {"/a"},
+		"site":     {"2"},
+		"start":    {"1"},
+		"end":      {"2"},
+	}, l)
+	c.Assert(err, qt.IsNil)
+	c.Assert(errs, qt.HasLen, 0)
+
+	errs, err = Check(rules, url.Values{
+		"sub":      {"ABC"},
+		"base_url": {"example.org"},
+		"kind":     {"c"},
+		"price":    {"-1"},
+		"path":     {"/a"},
+		"site":     {"1"},
+		"start":    {"2"},
+		"end":      {"1"},
+	}, l)
+	c.Assert(err, qt.IsNil)
+	c.Assert(errs.Fields(), qt.DeepEquals, map[string][]string{
+		"title":    {"is required"},
+		"sub":      {"must be lower case"},
+		"base_url": {"must be a URL, e.g. https://example.org/"},
+		"kind":     {"must be one of a, b"},
+		"price":    {"must be at least 0"},
+		"path":     {"is already taken within its site"},
+		"end":      {"must be after start"},
+	})
+	c.Assert(errs[0].Rule, qt.Equals, "required")
+}